@@ -30,6 +30,8 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufapiclient"
 	"github.com/bufbuild/buf/private/bufpkg/bufapimodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufconnect"
@@ -38,6 +40,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulecache"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulelocal"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/bufpkg/bufreflect"
 	"github.com/bufbuild/buf/private/bufpkg/buftransport"
@@ -52,10 +55,13 @@ import (
 	"github.com/bufbuild/buf/private/pkg/git"
 	"github.com/bufbuild/buf/private/pkg/httpauth"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/oci"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/bufbuild/buf/private/pkg/transport/http/httpclient"
 	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"golang.org/x/term"
 )
@@ -95,6 +101,8 @@ var (
 			inputHTTPSPasswordEnvKey,
 		),
 	)
+	// defaultOCIClient is the client we use for OCI registries.
+	defaultOCIClient = oci.NewClient(defaultHTTPClient, defaultHTTPAuthenticator)
 	// defaultGitClonerOptions defines the default git clone options.
 	defaultGitClonerOptions = git.ClonerOptions{
 		HTTPSUsernameEnvKey:      inputHTTPSUsernameEnvKey,
@@ -103,7 +111,8 @@ var (
 		SSHKnownHostsFilesEnvKey: inputSSHKnownHostsFilesEnvKey,
 	}
 
-	// AllCacheModuleRelDirPaths are all directory paths for all time concerning the module cache.
+	// AllCacheModuleRelDirPaths are all directory paths for all time concerning the module cache,
+	// as well as the other caches written by buf.
 	//
 	// These are normalized.
 	// These are relative to container.CacheDirPath().
@@ -115,6 +124,8 @@ var (
 		v1CacheModuleDataRelDirPath,
 		v1CacheModuleLockRelDirPath,
 		v1CacheModuleSumRelDirPath,
+		v1CacheGenerateRelDirPath,
+		v1CacheHTTPRelDirPath,
 	}
 
 	// ErrNotATTY is returned when an input io.Reader is not a TTY where it is expected.
@@ -148,6 +159,18 @@ var (
 	// These digests are used to make sure that the data written is actually what we expect, and if it is not,
 	// we clear an entry from the cache, i.e. delete the relevant data directory.
 	v1CacheModuleSumRelDirPath = normalpath.Join("v1", "module", "sum")
+	// v1CacheGenerateRelDirPath is the relative path to the cache directory where generation results are stored.
+	//
+	// Normalized.
+	// These are the CodeGeneratorResponses of previous plugin invocations, keyed on a digest of the plugin
+	// and its inputs.
+	v1CacheGenerateRelDirPath = normalpath.Join("v1", "generate")
+	// v1CacheHTTPRelDirPath is the relative path to the cache directory where http and https inputs are stored.
+	//
+	// Normalized.
+	// Only inputs that are pinned to a sha256 digest are stored, keyed on the digest, as only then is it known
+	// that the content at the URL has not changed.
+	v1CacheHTTPRelDirPath = normalpath.Join("v1", "http")
 
 	// allVisibiltyStrings are the possible options that a user can set the visibility flag with.
 	allVisibiltyStrings = []string{
@@ -257,6 +280,33 @@ Symlinks are never followed in Windows.`,
 	)
 }
 
+// BindBaseline binds the baseline and write-baseline flags.
+func BindBaseline(
+	flagSet *pflag.FlagSet,
+	baselineAddr *string,
+	baselineFlagName string,
+	writeBaselineAddr *string,
+	writeBaselineFlagName string,
+) {
+	flagSet.StringVar(
+		baselineAddr,
+		baselineFlagName,
+		"",
+		fmt.Sprintf(
+			`The baseline file written with --%s.
+Violations recorded in the baseline are not reported.`,
+			writeBaselineFlagName,
+		),
+	)
+	flagSet.StringVar(
+		writeBaselineAddr,
+		writeBaselineFlagName,
+		"",
+		`Write all current violations to the given baseline file instead of reporting them.
+Violations are recorded by rule, file, and element, so the baseline remains valid when lines move.`,
+	)
+}
+
 // BindVisibility binds the visibility flag.
 func BindVisibility(flagSet *pflag.FlagSet, addr *string, flagName string) {
 	flagSet.StringVar(
@@ -368,6 +418,40 @@ func NewStorageosProvider(disableSymlinks bool) storageos.Provider {
 	return storageos.NewProvider(storageos.ProviderWithSymlinks())
 }
 
+// NewGenerateCacheReadWriteBucket returns a new ReadWriteBucket for the generation cache,
+// creating the cache directory if it does not exist.
+func NewGenerateCacheReadWriteBucket(container appflag.Container) (storage.ReadWriteBucket, error) {
+	cacheGenerateDirPath := normalpath.Join(container.CacheDirPath(), v1CacheGenerateRelDirPath)
+	if err := checkExistingCacheDirs(container.CacheDirPath(), container.CacheDirPath(), cacheGenerateDirPath); err != nil {
+		return nil, err
+	}
+	if err := createCacheDirs(cacheGenerateDirPath); err != nil {
+		return nil, err
+	}
+	// do NOT want to enable symlinks for our cache
+	return storageos.NewProvider().NewReadWriteBucket(cacheGenerateDirPath)
+}
+
+// newHTTPCacheReadWriteBucketFunc returns a function that returns a new ReadWriteBucket for the
+// cache of http and https inputs that are pinned to a sha256 digest, creating the cache directory
+// if it does not exist.
+//
+// The function is only called when a pinned input is read, so that the cache directory is not
+// created for any other input.
+func newHTTPCacheReadWriteBucketFunc(container appname.Container) func() (storage.ReadWriteBucket, error) {
+	return func() (storage.ReadWriteBucket, error) {
+		cacheHTTPDirPath := normalpath.Join(container.CacheDirPath(), v1CacheHTTPRelDirPath)
+		if err := checkExistingCacheDirs(container.CacheDirPath(), container.CacheDirPath(), cacheHTTPDirPath); err != nil {
+			return nil, err
+		}
+		if err := createCacheDirs(cacheHTTPDirPath); err != nil {
+			return nil, err
+		}
+		// do NOT want to enable symlinks for our cache
+		return storageos.NewProvider().NewReadWriteBucket(cacheHTTPDirPath)
+	}
+}
+
 // NewWireImageConfigReader returns a new ImageConfigReader.
 func NewWireImageConfigReader(
 	container appflag.Container,
@@ -376,15 +460,16 @@ func NewWireImageConfigReader(
 	registryProvider registryv1alpha1apiclient.Provider,
 ) (bufwire.ImageConfigReader, error) {
 	logger := container.Logger()
-	moduleResolver := bufapimodule.NewModuleResolver(logger, registryProvider)
+	moduleResolver := newModuleResolver(logger, registryProvider)
 	moduleReader, err := NewModuleReaderAndCreateCacheDirs(container, registryProvider)
 	if err != nil {
 		return nil, err
 	}
+	fetchReader := newFetchReader(container, storageosProvider, runner, moduleResolver, moduleReader)
 	return bufwire.NewImageConfigReader(
 		logger,
 		storageosProvider,
-		newFetchReader(logger, storageosProvider, runner, moduleResolver, moduleReader),
+		fetchReader,
 		bufmodulebuild.NewModuleBucketBuilder(logger),
 		bufmodulebuild.NewModuleFileSetBuilder(logger, moduleReader),
 		bufimagebuild.NewBuilder(logger),
@@ -399,15 +484,16 @@ func NewWireModuleConfigReader(
 	registryProvider registryv1alpha1apiclient.Provider,
 ) (bufwire.ModuleConfigReader, error) {
 	logger := container.Logger()
-	moduleResolver := bufapimodule.NewModuleResolver(logger, registryProvider)
+	moduleResolver := newModuleResolver(logger, registryProvider)
 	moduleReader, err := NewModuleReaderAndCreateCacheDirs(container, registryProvider)
 	if err != nil {
 		return nil, err
 	}
+	fetchReader := newFetchReader(container, storageosProvider, runner, moduleResolver, moduleReader)
 	return bufwire.NewModuleConfigReader(
 		logger,
 		storageosProvider,
-		newFetchReader(logger, storageosProvider, runner, moduleResolver, moduleReader),
+		fetchReader,
 		bufmodulebuild.NewModuleBucketBuilder(logger),
 	), nil
 }
@@ -422,11 +508,12 @@ func NewWireModuleConfigReaderForModuleReader(
 	moduleReader bufmodule.ModuleReader,
 ) (bufwire.ModuleConfigReader, error) {
 	logger := container.Logger()
-	moduleResolver := bufapimodule.NewModuleResolver(logger, registryProvider)
+	moduleResolver := newModuleResolver(logger, registryProvider)
+	fetchReader := newFetchReader(container, storageosProvider, runner, moduleResolver, moduleReader)
 	return bufwire.NewModuleConfigReader(
 		logger,
 		storageosProvider,
-		newFetchReader(logger, storageosProvider, runner, moduleResolver, moduleReader),
+		fetchReader,
 		bufmodulebuild.NewModuleBucketBuilder(logger),
 	), nil
 }
@@ -439,15 +526,16 @@ func NewWireFileLister(
 	registryProvider registryv1alpha1apiclient.Provider,
 ) (bufwire.FileLister, error) {
 	logger := container.Logger()
-	moduleResolver := bufapimodule.NewModuleResolver(logger, registryProvider)
+	moduleResolver := newModuleResolver(logger, registryProvider)
 	moduleReader, err := NewModuleReaderAndCreateCacheDirs(container, registryProvider)
 	if err != nil {
 		return nil, err
 	}
+	fetchReader := newFetchReader(container, storageosProvider, runner, moduleResolver, moduleReader)
 	return bufwire.NewFileLister(
 		logger,
 		storageosProvider,
-		newFetchReader(logger, storageosProvider, runner, moduleResolver, moduleReader),
+		fetchReader,
 		bufmodulebuild.NewModuleBucketBuilder(logger),
 		bufmodulebuild.NewModuleFileSetBuilder(logger, moduleReader),
 		bufimagebuild.NewBuilder(logger),
@@ -455,14 +543,17 @@ func NewWireFileLister(
 }
 
 // NewWireImageReader returns a new ImageReader.
+//
+// The container is used for the location of the cache.
 func NewWireImageReader(
 	logger *zap.Logger,
+	container appname.Container,
 	storageosProvider storageos.Provider,
 	runner command.Runner,
 ) bufwire.ImageReader {
 	return bufwire.NewImageReader(
 		logger,
-		newFetchImageReader(logger, storageosProvider, runner),
+		newFetchImageReader(logger, container, storageosProvider, runner),
 	)
 }
 
@@ -472,9 +563,17 @@ func NewWireImageWriter(
 ) bufwire.ImageWriter {
 	return bufwire.NewImageWriter(
 		logger,
-		buffetch.NewWriter(
-			logger,
-		),
+		NewFetchWriter(logger),
+	)
+}
+
+// NewFetchWriter returns a new buffetch.Writer with the default OCI client.
+func NewFetchWriter(
+	logger *zap.Logger,
+) buffetch.Writer {
+	return buffetch.NewWriter(
+		logger,
+		buffetch.WriterWithOCI(defaultOCIClient),
 	)
 }
 
@@ -573,7 +672,20 @@ func newModuleReaderAndCreateCacheDirs(
 		registryProvider,
 		moduleReaderOptions...,
 	)
-	return moduleReader, nil
+	// Modules in local registries are read directly, and are never cached.
+	return bufmodulelocal.NewModuleReader(storageos.NewProvider(), moduleReader), nil
+}
+
+// newModuleResolver returns a new ModuleResolver that resolves modules in local
+// registries directly, and all other modules with the registry.
+func newModuleResolver(
+	logger *zap.Logger,
+	registryProvider registryv1alpha1apiclient.Provider,
+) bufmodule.ModuleResolver {
+	return bufmodulelocal.NewModuleResolver(
+		storageos.NewProvider(),
+		bufapimodule.NewModuleResolver(logger, registryProvider),
+	)
 }
 
 // NewConfig creates a new Config.
@@ -698,7 +810,7 @@ func ReadModuleWithWorkspacesDisabled(
 		return nil, nil, err
 	}
 	sourceBucket, err := newFetchSourceReader(
-		container.Logger(),
+		container,
 		storageosProvider,
 		runner,
 	).GetSourceBucket(
@@ -873,6 +985,53 @@ func validateErrorFormatFlag(validFormatStrings []string, errorFormatString stri
 	return appcmd.NewInvalidArgumentErrorf("--%s: invalid format: %q", errorFormatFlagName, errorFormatString)
 }
 
+// NewLintHandler returns a new buflint.Handler that runs the lint plugins of configs
+// as binaries, with the environment of the container.
+func NewLintHandler(container appflag.Container, runner command.Runner) buflint.Handler {
+	return buflint.NewHandler(
+		container.Logger(),
+		buflint.HandlerWithPluginRunner(
+			bufcheckplugin.NewRunner(
+				runner,
+				bufcheckplugin.RunnerWithEnv(app.EnvironMap(container)),
+			),
+		),
+	)
+}
+
+// ReadBaselineFile reads the baseline file at the path.
+//
+// Returns nil if the path is empty.
+func ReadBaselineFile(path string) (_ *bufbaseline.Baseline, retErr error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	baseline, err := bufbaseline.ReadBaseline(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// WriteBaselineFile writes the baseline file to the path.
+func WriteBaselineFile(path string, baseline *bufbaseline.Baseline) (retErr error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	return bufbaseline.WriteBaseline(file, baseline)
+}
+
 // promptUser reads a line from Stdin, prompting the user with the prompt first.
 // The prompt is repeatedly shown until the user provides a non-empty response.
 // ErrNotATTY is returned if the input containers Stdin is not a terminal.
@@ -940,12 +1099,13 @@ func promptUser(container app.Container, prompt string, isPassword bool) (string
 // newFetchReader creates a new buffetch.Reader with the default HTTP client
 // and git cloner.
 func newFetchReader(
-	logger *zap.Logger,
+	container appflag.Container,
 	storageosProvider storageos.Provider,
 	runner command.Runner,
 	moduleResolver bufmodule.ModuleResolver,
 	moduleReader bufmodule.ModuleReader,
 ) buffetch.Reader {
+	logger := container.Logger()
 	return buffetch.NewReader(
 		logger,
 		storageosProvider,
@@ -954,22 +1114,27 @@ func newFetchReader(
 		git.NewCloner(logger, storageosProvider, runner, defaultGitClonerOptions),
 		moduleResolver,
 		moduleReader,
+		buffetch.ReaderWithHTTPCache(newHTTPCacheReadWriteBucketFunc(container)),
+		buffetch.ReaderWithOCI(defaultOCIClient),
 	)
 }
 
 // newFetchSourceReader creates a new buffetch.SourceReader with the default HTTP client
 // and git cloner.
 func newFetchSourceReader(
-	logger *zap.Logger,
+	container appflag.Container,
 	storageosProvider storageos.Provider,
 	runner command.Runner,
 ) buffetch.SourceReader {
+	logger := container.Logger()
 	return buffetch.NewSourceReader(
 		logger,
 		storageosProvider,
 		defaultHTTPClient,
 		defaultHTTPAuthenticator,
 		git.NewCloner(logger, storageosProvider, runner, defaultGitClonerOptions),
+		buffetch.ReaderWithHTTPCache(newHTTPCacheReadWriteBucketFunc(container)),
+		buffetch.ReaderWithOCI(defaultOCIClient),
 	)
 }
 
@@ -977,6 +1142,7 @@ func newFetchSourceReader(
 // and git cloner.
 func newFetchImageReader(
 	logger *zap.Logger,
+	container appname.Container,
 	storageosProvider storageos.Provider,
 	runner command.Runner,
 ) buffetch.ImageReader {
@@ -986,6 +1152,8 @@ func newFetchImageReader(
 		defaultHTTPClient,
 		defaultHTTPAuthenticator,
 		git.NewCloner(logger, storageosProvider, runner, defaultGitClonerOptions),
+		buffetch.ReaderWithHTTPCache(newHTTPCacheReadWriteBucketFunc(container)),
+		buffetch.ReaderWithOCI(defaultOCIClient),
 	)
 }
 
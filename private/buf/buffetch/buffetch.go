@@ -24,11 +24,18 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/git"
 	"github.com/bufbuild/buf/private/pkg/httpauth"
+	"github.com/bufbuild/buf/private/pkg/oci"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"go.uber.org/zap"
 )
 
+// OCIPathPrefix is the prefix of paths that reference OCI artifacts.
+//
+// Example: oci://registry.local/protos/payments:v3
+const OCIPathPrefix = "oci://"
+
 const (
 	// ImageEncodingBin is the binary image encoding.
 	ImageEncodingBin ImageEncoding = iota + 1
@@ -166,6 +173,19 @@ type RefParser interface {
 	GetRef(ctx context.Context, value string) (Ref, error)
 }
 
+// IsLocalRef returns true if the Ref is a local directory or a local .proto file.
+//
+// All other Refs, such as archives, git repositories, and modules, may be read
+// from remote locations.
+func IsLocalRef(ref Ref) bool {
+	switch ref.internalRef().(type) {
+	case internal.DirRef, internal.ProtoFileRef:
+		return true
+	default:
+		return false
+	}
+}
+
 // NewRefParser returns a new RefParser.
 //
 // This defaults to dir or module.
@@ -225,6 +245,12 @@ type ReadWriteBucketCloser internal.ReadWriteBucketCloser
 // ReadBucketCloserWithTerminateFileProvider is a ReadWriteBucketCloser with a TerminateFileProvider.
 type ReadBucketCloserWithTerminateFileProvider internal.ReadBucketCloserWithTerminateFileProvider
 
+// WriteCommitCloser is a WriteCloser returned from PutImageFile.
+// We need to surface the internal.WriteCommitCloser
+// interface to other packages, so we use a type
+// declaration to do so.
+type WriteCommitCloser internal.WriteCommitCloser
+
 // ImageReader is an image reader.
 type ImageReader interface {
 	// GetImageFile gets the image file.
@@ -288,6 +314,7 @@ func NewReader(
 	gitCloner git.Cloner,
 	moduleResolver bufmodule.ModuleResolver,
 	moduleReader bufmodule.ModuleReader,
+	options ...ReaderOption,
 ) Reader {
 	return newReader(
 		logger,
@@ -297,6 +324,7 @@ func NewReader(
 		gitCloner,
 		moduleResolver,
 		moduleReader,
+		options...,
 	)
 }
 
@@ -307,6 +335,7 @@ func NewImageReader(
 	httpClient *http.Client,
 	httpAuthenticator httpauth.Authenticator,
 	gitCloner git.Cloner,
+	options ...ReaderOption,
 ) ImageReader {
 	return newImageReader(
 		logger,
@@ -314,6 +343,7 @@ func NewImageReader(
 		httpClient,
 		httpAuthenticator,
 		gitCloner,
+		options...,
 	)
 }
 
@@ -324,6 +354,7 @@ func NewSourceReader(
 	httpClient *http.Client,
 	httpAuthenticator httpauth.Authenticator,
 	gitCloner git.Cloner,
+	options ...ReaderOption,
 ) SourceReader {
 	return newSourceReader(
 		logger,
@@ -331,9 +362,36 @@ func NewSourceReader(
 		httpClient,
 		httpAuthenticator,
 		gitCloner,
+		options...,
 	)
 }
 
+// ReaderOption is an option for NewReader, NewImageReader, and NewSourceReader.
+type ReaderOption func(*readerOptions)
+
+// ReaderWithHTTPCache returns a new ReaderOption that caches http and https
+// files that are pinned to a sha256 digest in the bucket returned by getCacheBucket.
+//
+// Files are only cached if they are pinned, as only then is it known that
+// the content at the URL has not changed. getCacheBucket is only called when
+// such a file is read, so that the cache is not created for other inputs.
+func ReaderWithHTTPCache(getCacheBucket func() (storage.ReadWriteBucket, error)) ReaderOption {
+	return func(readerOptions *readerOptions) {
+		readerOptions.getHTTPCacheBucket = getCacheBucket
+	}
+}
+
+// ReaderWithOCI returns a new ReaderOption that enables reading OCI artifacts
+// with the client.
+//
+// Source and module artifacts are read as tarballs, and image artifacts are read
+// as image files.
+func ReaderWithOCI(ociClient oci.Client) ReaderOption {
+	return func(readerOptions *readerOptions) {
+		readerOptions.ociClient = ociClient
+	}
+}
+
 // NewModuleFetcher returns a new ModuleFetcher.
 func NewModuleFetcher(
 	logger *zap.Logger,
@@ -352,22 +410,47 @@ func NewModuleFetcher(
 // Writer is a writer for Buf.
 type Writer interface {
 	// PutImageFile puts the image file.
+	//
+	// The content must be committed once it was written successfully,
+	// otherwise it may be discarded on Close.
 	PutImageFile(
 		ctx context.Context,
 		container app.EnvStdoutContainer,
 		imageRef ImageRef,
-	) (io.WriteCloser, error)
+	) (WriteCommitCloser, error)
+	// PutSourceBucket puts the contents of the bucket as an archive.
+	//
+	// Returns an error if the SourceRef does not reference an archive.
+	PutSourceBucket(
+		ctx context.Context,
+		container app.EnvStdoutContainer,
+		sourceRef SourceRef,
+		readBucket storage.ReadBucket,
+	) error
 }
 
 // NewWriter returns a new Writer.
 func NewWriter(
 	logger *zap.Logger,
+	options ...WriterOption,
 ) Writer {
 	return newWriter(
 		logger,
+		options...,
 	)
 }
 
+// WriterOption is an option for NewWriter.
+type WriterOption func(*writer)
+
+// WriterWithOCI returns a new WriterOption that enables writing OCI artifacts
+// with the client.
+func WriterWithOCI(ociClient oci.Client) WriterOption {
+	return func(writer *writer) {
+		writer.ociClient = ociClient
+	}
+}
+
 type getSourceBucketOptions struct {
 	workspacesDisabled bool
 }
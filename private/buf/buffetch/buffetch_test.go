@@ -16,12 +16,24 @@ package buffetch
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/bufbuild/buf/private/buf/buffetch/internal"
 	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/httpauth"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/oci"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -57,6 +69,197 @@ func TestRoundTripBinZst(t *testing.T) {
 	)
 }
 
+func TestIsLocalRef(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	refParser := NewRefParser(zap.NewNop(), RefParserWithProtoFileRefAllowed())
+	for value, expected := range map[string]bool{
+		".":                              true,
+		"foo/bar":                        true,
+		"foo/bar.proto":                  true,
+		"foo.tar.gz":                     false,
+		"foo.bin":                        false,
+		"https://github.com/foo/bar.git": false,
+		"buf.build/foo/bar":              false,
+		"https://example.com/foo.tar.gz#strip_components=1": false,
+	} {
+		ref, err := refParser.GetRef(ctx, value)
+		require.NoError(t, err, value)
+		require.Equal(t, expected, IsLocalRef(ref), value)
+	}
+}
+
+func TestReadHTTPSHA256(t *testing.T) {
+	t.Parallel()
+	data := []byte("one")
+	digest := sha256.Sum256(data)
+	dataSHA256 := hex.EncodeToString(digest[:])
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = responseWriter.Write(data)
+	}))
+	defer server.Close()
+
+	logger := zap.NewNop()
+	refParser := newRefParser(logger)
+	cacheBucket := storagemem.NewReadWriteBucket()
+	var getCacheBucketCalls int32
+	reader := internal.NewReader(
+		logger,
+		storageos.NewProvider(),
+		internal.WithReaderHTTP(server.Client(), httpauth.NewNopAuthenticator()),
+		internal.WithReaderHTTPCache(
+			func() (storage.ReadWriteBucket, error) {
+				atomic.AddInt32(&getCacheBucketCalls, 1)
+				return cacheBucket, nil
+			},
+		),
+	)
+	ctx := context.Background()
+	container := app.NewContainer(nil, nil, nil, nil)
+
+	// Files that are not pinned do not use the cache.
+	parsedRef, err := refParser.getParsedRef(ctx, server.URL+"/file.bin", allFormats)
+	require.NoError(t, err)
+	fileRef, ok := parsedRef.(internal.FileRef)
+	require.True(t, ok)
+	readCloser, err := reader.GetFile(ctx, container, fileRef)
+	require.NoError(t, err)
+	require.NoError(t, readCloser.Close())
+	require.Equal(t, int32(0), atomic.LoadInt32(&getCacheBucketCalls))
+	atomic.StoreInt32(&requests, 0)
+
+	parsedRef, err = refParser.getParsedRef(ctx, server.URL+"/file.bin#sha256="+dataSHA256, allFormats)
+	require.NoError(t, err)
+	fileRef, ok = parsedRef.(internal.FileRef)
+	require.True(t, ok)
+	require.Equal(t, dataSHA256, fileRef.SHA256())
+	for i := 0; i < 2; i++ {
+		readCloser, err := reader.GetFile(ctx, container, fileRef)
+		require.NoError(t, err)
+		actualData, err := io.ReadAll(readCloser)
+		require.NoError(t, err)
+		require.NoError(t, readCloser.Close())
+		require.Equal(t, string(data), string(actualData))
+	}
+	// The second read is served from the cache.
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	otherDigest := sha256.Sum256([]byte("two"))
+	otherSHA256 := hex.EncodeToString(otherDigest[:])
+	parsedRef, err = refParser.getParsedRef(ctx, server.URL+"/file.bin#sha256="+otherSHA256, allFormats)
+	require.NoError(t, err)
+	fileRef, ok = parsedRef.(internal.FileRef)
+	require.True(t, ok)
+	_, err = reader.GetFile(ctx, container, fileRef)
+	require.Equal(t, internal.NewSHA256MismatchError(server.URL+"/file.bin", otherSHA256, dataSHA256), err)
+	// Files that do not match are never cached.
+	_, err = cacheBucket.Stat(ctx, normalpath.Join(otherSHA256[:2], otherSHA256[2:]))
+	require.True(t, storage.IsNotExist(err))
+}
+
+func TestRoundTripOCI(t *testing.T) {
+	t.Parallel()
+	logger := zap.NewNop()
+	ociClient := newTestOCIClient()
+	writer := newWriter(logger, WriterWithOCI(ociClient))
+	reader := newReader(
+		logger,
+		storageos.NewProvider(),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		ReaderWithOCI(ociClient),
+	)
+	ctx := context.Background()
+	container := app.NewContainer(nil, nil, nil, nil)
+
+	imageRef, err := newImageRefParser(logger).GetImageRef(ctx, "oci://registry.local/protos/image:v1.0.0")
+	require.NoError(t, err)
+	writeCloser, err := writer.PutImageFile(ctx, container, imageRef)
+	require.NoError(t, err)
+	_, err = writeCloser.Write([]byte("one"))
+	require.NoError(t, err)
+	writeCloser.Commit()
+	require.NoError(t, writeCloser.Close())
+	readCloser, err := reader.GetImageFile(ctx, container, imageRef)
+	require.NoError(t, err)
+	data, err := io.ReadAll(readCloser)
+	require.NoError(t, err)
+	require.NoError(t, readCloser.Close())
+	require.Equal(t, "one", string(data))
+
+	sourceRef, err := newSourceRefParser(logger).GetSourceRef(ctx, "oci://registry.local/protos/source:v1")
+	require.NoError(t, err)
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	require.NoError(t, storage.PutPath(ctx, readWriteBucket, "a/a.proto", []byte("syntax = \"proto3\";")))
+	require.NoError(t, writer.PutSourceBucket(ctx, container, sourceRef, readWriteBucket))
+	readBucketCloser, err := reader.GetSourceBucket(ctx, container, sourceRef, GetSourceBucketWithWorkspacesDisabled())
+	require.NoError(t, err)
+	data, err = storage.ReadPath(ctx, readBucketCloser, "a/a.proto")
+	require.NoError(t, err)
+	require.NoError(t, readBucketCloser.Close())
+	require.Equal(t, "syntax = \"proto3\";", string(data))
+
+	// An image cannot be read as a source.
+	sourceRef, err = newSourceRefParser(logger).GetSourceRef(ctx, "oci://registry.local/protos/image:v1.0.0")
+	require.NoError(t, err)
+	_, err = reader.GetSourceBucket(ctx, container, sourceRef)
+	require.Equal(t, internal.NewOCIImageNotArchiveError("registry.local/protos/image:v1.0.0"), err)
+	// An image is read with format=bin.
+	ref, err := newRefParser(logger).GetRef(ctx, "oci://registry.local/protos/image:v1.0.0#format=bin")
+	require.NoError(t, err)
+	binImageRef, ok := ref.(ImageRef)
+	require.True(t, ok)
+	readCloser, err = reader.GetImageFile(ctx, container, binImageRef)
+	require.NoError(t, err)
+	data, err = io.ReadAll(readCloser)
+	require.NoError(t, err)
+	require.NoError(t, readCloser.Close())
+	require.Equal(t, "one", string(data))
+	// A source cannot be read as an image.
+	imageRef, err = newImageRefParser(logger).GetImageRef(ctx, "oci://registry.local/protos/source:v1")
+	require.NoError(t, err)
+	_, err = reader.GetImageFile(ctx, container, imageRef)
+	require.Equal(
+		t,
+		internal.NewOCIMediaTypeMismatchError(
+			"registry.local/protos/source:v1",
+			"config",
+			"application/vnd.buf.image.config.v1+json",
+			"application/vnd.buf.module.config.v1+json",
+		),
+		err,
+	)
+	// The content is not pushed if it was not committed.
+	imageRef, err = newImageRefParser(logger).GetImageRef(ctx, "oci://registry.local/protos/image:v2.0.0")
+	require.NoError(t, err)
+	writeCloser, err = writer.PutImageFile(ctx, container, imageRef)
+	require.NoError(t, err)
+	_, err = writeCloser.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, writeCloser.Close())
+	_, err = reader.GetImageFile(ctx, container, imageRef)
+	require.Error(t, err)
+	// The content is only pushed once.
+	writeCloser, err = writer.PutImageFile(ctx, container, imageRef)
+	require.NoError(t, err)
+	writeCloser.Commit()
+	require.NoError(t, writeCloser.Close())
+	require.NoError(t, writeCloser.Close())
+	_, err = writeCloser.Write([]byte("two"))
+	require.Error(t, err)
+	readCloser, err = reader.GetImageFile(ctx, container, imageRef)
+	require.NoError(t, err)
+	data, err = io.ReadAll(readCloser)
+	require.NoError(t, err)
+	require.NoError(t, readCloser.Close())
+	require.Empty(t, data)
+}
+
 func testRoundTripLocalFile(
 	t *testing.T,
 	filename string,
@@ -88,6 +291,7 @@ func testRoundTripLocalFile(
 	require.NoError(t, err)
 	_, err = writeCloser.Write(expectedData)
 	require.NoError(t, err)
+	writeCloser.Commit()
 	require.NoError(t, writeCloser.Close())
 
 	readCloser, err := reader.GetFile(ctx, container, fileRef)
@@ -114,3 +318,40 @@ func testNewFetchWriter(logger *zap.Logger) internal.Writer {
 		internal.WithWriterLocal(),
 	)
 }
+
+type testOCIClient struct {
+	lock      sync.Mutex
+	artifacts map[string]*oci.Artifact
+}
+
+func newTestOCIClient() *testOCIClient {
+	return &testOCIClient{
+		artifacts: make(map[string]*oci.Artifact),
+	}
+}
+
+func (c *testOCIClient) Pull(
+	_ context.Context,
+	_ app.EnvContainer,
+	reference oci.Reference,
+) (*oci.Artifact, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	artifact, ok := c.artifacts[reference.String()]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", reference.String())
+	}
+	return artifact, nil
+}
+
+func (c *testOCIClient) Push(
+	_ context.Context,
+	_ app.EnvContainer,
+	reference oci.Reference,
+	artifact *oci.Artifact,
+) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.artifacts[reference.String()] = artifact
+	return nil
+}
@@ -29,6 +29,10 @@ const (
 	formatJSONGZ = "jsongz"
 	// formatMod is the module format.
 	formatMod = "mod"
+	// formatOCI is the OCI artifact format.
+	//
+	// The artifact is a tar gzipped archive.
+	formatOCI = "oci"
 	// formatTar is the tar format.
 	formatTar = "tar"
 	// formatTargz is the tar gzipped format.
@@ -56,6 +60,7 @@ var (
 	sourceFormats = []string{
 		formatDir,
 		formatGit,
+		formatOCI,
 		formatProtoFile,
 		formatTar,
 		formatTargz,
@@ -65,6 +70,7 @@ var (
 	sourceFormatsNotDeprecated = []string{
 		formatDir,
 		formatGit,
+		formatOCI,
 		formatProtoFile,
 		formatTar,
 		formatZip,
@@ -72,6 +78,7 @@ var (
 	sourceDirFormatsNotDeprecated = []string{
 		formatDir,
 		formatGit,
+		formatOCI,
 		formatTar,
 		formatZip,
 	}
@@ -88,6 +95,7 @@ var (
 		formatDir,
 		formatGit,
 		formatMod,
+		formatOCI,
 		formatProtoFile,
 		formatTar,
 		formatTargz,
@@ -98,6 +106,7 @@ var (
 		formatDir,
 		formatGit,
 		formatMod,
+		formatOCI,
 		formatProtoFile,
 		formatTar,
 		formatZip,
@@ -111,6 +120,7 @@ var (
 		formatJSON,
 		formatJSONGZ,
 		formatMod,
+		formatOCI,
 		formatProtoFile,
 		formatTar,
 		formatTargz,
@@ -123,6 +133,7 @@ var (
 		formatGit,
 		formatJSON,
 		formatMod,
+		formatOCI,
 		formatProtoFile,
 		formatTar,
 		formatZip,
@@ -28,6 +28,7 @@ type archiveRef struct {
 	compressionType CompressionType
 	stripComponents uint32
 	subDirPath      string
+	sha256          string
 }
 
 func newArchiveRef(
@@ -37,6 +38,7 @@ func newArchiveRef(
 	compressionType CompressionType,
 	stripComponents uint32,
 	subDirPath string,
+	sha256 string,
 ) (*archiveRef, error) {
 	if archiveType == ArchiveTypeZip && compressionType != CompressionTypeNone {
 		return nil, NewCannotSpecifyCompressionForZipError()
@@ -45,6 +47,7 @@ func newArchiveRef(
 		format,
 		path,
 		compressionType,
+		sha256,
 	)
 	if err != nil {
 		return nil, err
@@ -64,6 +67,7 @@ func newArchiveRef(
 		singleRef.CompressionType(),
 		stripComponents,
 		subDirPath,
+		singleRef.SHA256(),
 	), nil
 }
 
@@ -75,6 +79,7 @@ func newDirectArchiveRef(
 	compressionType CompressionType,
 	stripComponents uint32,
 	subDirPath string,
+	sha256 string,
 ) *archiveRef {
 	return &archiveRef{
 		format:          format,
@@ -84,6 +89,7 @@ func newDirectArchiveRef(
 		compressionType: compressionType,
 		stripComponents: stripComponents,
 		subDirPath:      subDirPath,
+		sha256:          sha256,
 	}
 }
 
@@ -115,6 +121,10 @@ func (r *archiveRef) SubDirPath() string {
 	return r.subDirPath
 }
 
+func (r *archiveRef) SHA256() string {
+	return r.sha256
+}
+
 func (*archiveRef) ref()        {}
 func (*archiveRef) fileRef()    {}
 func (*archiveRef) bucketRef()  {}
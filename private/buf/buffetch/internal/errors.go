@@ -48,6 +48,11 @@ func NewCannotSpecifyTagWithRefError() error {
 	return fmt.Errorf(`cannot specify "tag" with "ref"`)
 }
 
+// NewCannotSpecifyMergeBaseWithTagOrRefError is a fetch error.
+func NewCannotSpecifyMergeBaseWithTagOrRefError() error {
+	return fmt.Errorf(`cannot specify "merge-base" with "tag" or "ref"`)
+}
+
 // NewDepthParseError is a fetch error.
 func NewDepthParseError(s string) error {
 	return fmt.Errorf(`could not parse "depth" value %q`, s)
@@ -93,6 +98,16 @@ func NewOptionsCouldNotParseStripComponentsError(s string) error {
 	return fmt.Errorf("could not parse strip_components value %q", s)
 }
 
+// NewOptionsCouldNotParseSHA256Error is a fetch error.
+func NewOptionsCouldNotParseSHA256Error(s string) error {
+	return fmt.Errorf("could not parse sha256 value %q: must be a lowercase hex-encoded sha256 digest", s)
+}
+
+// NewSHA256MismatchError is a fetch error.
+func NewSHA256MismatchError(path string, expected string, actual string) error {
+	return fmt.Errorf("sha256 digest of %q was %s but expected %s", path, actual, expected)
+}
+
 // NewOptionsCouldNotParseRecurseSubmodulesError is a fetch error.
 func NewOptionsCouldNotParseRecurseSubmodulesError(s string) error {
 	return fmt.Errorf("could not parse recurse_submodules value %q", s)
@@ -121,6 +136,16 @@ func NewFormatUnknownError(formatString string) error {
 	return fmt.Errorf("unknown format: %q", formatString)
 }
 
+// NewOCIMediaTypeMismatchError is a fetch error.
+func NewOCIMediaTypeMismatchError(path string, kind string, expected string, actual string) error {
+	return fmt.Errorf("OCI artifact %q has %s media type %q but expected %q", path, kind, actual, expected)
+}
+
+// NewOCIImageNotArchiveError is a fetch error.
+func NewOCIImageNotArchiveError(path string) error {
+	return fmt.Errorf(`OCI artifact %q is an image and not an archive, use "oci://%s#format=bin" to read it as an image`, path, path)
+}
+
 // NewReadDisabledError is a fetch error.
 func NewReadDisabledError(scheme string) error {
 	return fmt.Errorf("reading assets from %s disabled", scheme)
@@ -146,6 +171,11 @@ func NewReadStdioDisabledError() error {
 	return NewReadDisabledError("stdin")
 }
 
+// NewReadOCIDisabledError is a fetch error.
+func NewReadOCIDisabledError() error {
+	return NewReadDisabledError("oci")
+}
+
 // NewReadModuleDisabledError is a fetch error.
 func NewReadModuleDisabledError() error {
 	return NewReadDisabledError("module")
@@ -166,6 +196,11 @@ func NewWriteLocalDisabledError() error {
 	return NewWriteDisabledError("local")
 }
 
+// NewWriteOCIDisabledError is a fetch error.
+func NewWriteOCIDisabledError() error {
+	return NewWriteDisabledError("oci")
+}
+
 // NewWriteStdioDisabledError is a fetch error.
 func NewWriteStdioDisabledError() error {
 	return NewWriteDisabledError("stdout")
@@ -24,6 +24,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/git"
 	"github.com/bufbuild/buf/private/pkg/httpauth"
+	"github.com/bufbuild/buf/private/pkg/oci"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.uber.org/zap"
@@ -47,6 +48,11 @@ const (
 	FileSchemeStdout
 	// FileSchemeNull is the null file scheme.
 	FileSchemeNull
+	// FileSchemeOCI is the OCI file scheme.
+	//
+	// The path is an OCI reference of the form registry/repository[:tag], and
+	// the file is the single layer of the artifact.
+	FileSchemeOCI
 
 	// GitSchemeHTTP is the http git scheme.
 	GitSchemeHTTP GitScheme = iota + 1
@@ -95,11 +101,17 @@ type FileRef interface {
 	// Path is the path to the reference.
 	//
 	// This will be the non-empty path minus the scheme for http and https files.
+	// This will be the non-empty OCI reference minus the scheme for OCI files.
 	// This will be the non-empty normalized file path for local files.
 	// This will be empty for stdio and null files.
 	Path() string
 	FileScheme() FileScheme
 	CompressionType() CompressionType
+	// SHA256 is the expected lowercase hex-encoded sha256 digest of the file
+	// as it is read, that is before decompression.
+	//
+	// This will be empty if the file is not pinned to a digest.
+	SHA256() string
 	fileRef()
 }
 
@@ -117,7 +129,7 @@ type SingleRef interface {
 
 // NewSingleRef returns a new SingleRef.
 func NewSingleRef(path string, compressionType CompressionType) (SingleRef, error) {
-	return newSingleRef("", path, compressionType)
+	return newSingleRef("", path, compressionType, "")
 }
 
 // ArchiveRef is an archive reference.
@@ -143,7 +155,7 @@ func NewArchiveRef(
 	stripComponents uint32,
 	subDirPath string,
 ) (ArchiveRef, error) {
-	return newArchiveRef("", path, archiveType, compressionType, stripComponents, subDirPath)
+	return newArchiveRef("", path, archiveType, compressionType, stripComponents, subDirPath, "")
 }
 
 // DirRef is a local directory reference.
@@ -258,6 +270,7 @@ func NewDirectParsedSingleRef(
 		path,
 		fileScheme,
 		compressionType,
+		"",
 	)
 }
 
@@ -287,6 +300,7 @@ func NewDirectParsedArchiveRef(
 		compressionType,
 		stripComponents,
 		subDirPath,
+		"",
 	)
 }
 
@@ -386,6 +400,17 @@ type TerminateFile interface {
 	Path() string
 }
 
+// TerminateFileDirPathsFunc returns the normalized directory paths that the
+// configuration in the TerminateFile refers to, relative to the directory of the
+// TerminateFile.
+//
+// The readBucket is for the directory of the TerminateFile.
+type TerminateFileDirPathsFunc func(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	terminateFile TerminateFile,
+) ([]string, error)
+
 // ReadBucketCloserWithTerminateFileProvider is a ReadBucketCloser with a TerminateFileProvider.
 type ReadBucketCloserWithTerminateFileProvider interface {
 	ReadBucketCloser
@@ -466,6 +491,19 @@ func NewReader(
 	)
 }
 
+// WriteCommitCloser is a WriteCloser returned from PutFile.
+type WriteCommitCloser interface {
+	io.WriteCloser
+
+	// Commit marks the written content as complete.
+	//
+	// Files that are published as a whole, such as OCI artifacts, are only
+	// published on Close if Commit was called, so that a caller that fails
+	// while writing does not publish partial content. Other files are written
+	// as the content is written, and Commit has no effect.
+	Commit()
+}
+
 // Writer is a writer.
 type Writer interface {
 	// PutFile puts the file.
@@ -474,7 +512,7 @@ type Writer interface {
 		container app.EnvStdoutContainer,
 		fileRef FileRef,
 		options ...PutFileOption,
-	) (io.WriteCloser, error)
+	) (WriteCommitCloser, error)
 }
 
 // NewWriter returns a new Writer.
@@ -519,13 +557,23 @@ type RawRef struct {
 	// This is defined as anything that can be given to git checkout.
 	GitRef string
 	// Only set for git formats
+	// Specifies a git reference to compute the merge base with. The merge base of
+	// this reference and HEAD, or GitBranch if set, is checked out.
+	// Can be used on its own or with GitBranch. Not allowed with GitTag or GitRef.
+	GitMergeBase string
+	// Only set for git formats
 	GitRecurseSubmodules bool
 	// Only set for git formats.
 	// The depth to use when cloning a repository. Only allowed when GitRef
 	// is set. Defaults to 50 if unset.
+	// When GitMergeBase is set, the full history is fetched if the merge
+	// base is not within this depth.
 	GitDepth uint32
 	// Only set for archive formats
 	ArchiveStripComponents uint32
+	// Only set for single, archive formats
+	// The lowercase hex-encoded sha256 digest that the file must match
+	SHA256 string
 	// Only set for proto file ref format.
 	// Sets whether or not to include the files in the rest of the package
 	// in the image for the ProtoFileRef.
@@ -695,6 +743,26 @@ func WithReaderHTTP(httpClient *http.Client, httpAuthenticator httpauth.Authenti
 	}
 }
 
+// WithReaderHTTPCache caches http and https files that are pinned to a sha256
+// digest in the bucket returned by getCacheBucket, keyed on the digest.
+//
+// getCacheBucket is only called when a pinned http or https file is read.
+// Cached files are verified against the digest when read, and are downloaded
+// again if they do not match.
+func WithReaderHTTPCache(getCacheBucket func() (storage.ReadWriteBucket, error)) ReaderOption {
+	return func(reader *reader) {
+		reader.getHTTPCacheBucket = getCacheBucket
+	}
+}
+
+// WithReaderOCI enables OCI.
+func WithReaderOCI(ociClient oci.Client) ReaderOption {
+	return func(reader *reader) {
+		reader.ociEnabled = true
+		reader.ociClient = ociClient
+	}
+}
+
 // WithReaderGit enables Git.
 func WithReaderGit(gitCloner git.Cloner) ReaderOption {
 	return func(reader *reader) {
@@ -739,6 +807,14 @@ func WithWriterLocal() WriterOption {
 	}
 }
 
+// WithWriterOCI enables OCI.
+func WithWriterOCI(ociClient oci.Client) WriterOption {
+	return func(writer *writer) {
+		writer.ociEnabled = true
+		writer.ociClient = ociClient
+	}
+}
+
 // WithWriterStdio enables stdio.
 func WithWriterStdio() WriterOption {
 	return func(writer *writer) {
@@ -800,6 +876,20 @@ func WithGetBucketTerminateFileNames(terminateFileNames [][]string) GetBucketOpt
 	}
 }
 
+// WithGetBucketTerminateFileDirPathsFunc only applies to git repositories when
+// subdir is specified.
+//
+// Only the subdir is checked out of a git repository if one is given. If a terminate
+// file is found in a parent directory of the subdir, the directories returned by
+// this function for the terminate file are checked out as well.
+//
+// If this is not set, the directory of the terminate file is checked out.
+func WithGetBucketTerminateFileDirPathsFunc(terminateFileDirPathsFunc TerminateFileDirPathsFunc) GetBucketOption {
+	return func(getBucketOptions *getBucketOptions) {
+		getBucketOptions.terminateFileDirPathsFunc = terminateFileDirPathsFunc
+	}
+}
+
 // PutFileOption is a PutFile option.
 type PutFileOption func(*putFileOptions)
 
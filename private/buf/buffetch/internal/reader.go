@@ -30,6 +30,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/httpauth"
 	"github.com/bufbuild/buf/private/pkg/ioextended"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/oci"
 	"github.com/bufbuild/buf/private/pkg/osextended"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storagearchive"
@@ -49,13 +50,17 @@ type reader struct {
 	localEnabled bool
 	stdioEnabled bool
 
-	httpEnabled       bool
-	httpClient        *http.Client
-	httpAuthenticator httpauth.Authenticator
+	httpEnabled        bool
+	httpClient         *http.Client
+	httpAuthenticator  httpauth.Authenticator
+	getHTTPCacheBucket func() (storage.ReadWriteBucket, error)
 
 	gitEnabled bool
 	gitCloner  git.Cloner
 
+	ociEnabled bool
+	ociClient  oci.Client
+
 	moduleEnabled  bool
 	moduleReader   bufmodule.ModuleReader
 	moduleResolver bufmodule.ModuleResolver
@@ -137,6 +142,7 @@ func (r *reader) GetBucket(
 			container,
 			t,
 			getBucketOptions.terminateFileNames,
+			getBucketOptions.terminateFileDirPathsFunc,
 		)
 	case ProtoFileRef:
 		return r.getProtoFileBucket(
@@ -448,6 +454,7 @@ func (r *reader) getGitBucket(
 	container app.EnvStdinContainer,
 	gitRef GitRef,
 	terminateFileNames [][]string,
+	terminateFileDirPathsFunc TerminateFileDirPathsFunc,
 ) (_ ReadBucketCloserWithTerminateFileProvider, retErr error) {
 	if !r.gitEnabled {
 		return nil, NewReadGitDisabledError()
@@ -463,19 +470,33 @@ func (r *reader) getGitBucket(
 	if err != nil {
 		return nil, err
 	}
-	readWriteBucket := storagemem.NewReadWriteBucket()
-	if err := r.gitCloner.CloneToBucket(
-		ctx,
-		container,
-		gitURL,
-		gitRef.Depth(),
-		readWriteBucket,
-		git.CloneToBucketOptions{
-			Name:              gitRef.GitName(),
-			RecurseSubmodules: gitRef.RecurseSubmodules(),
-		},
-	); err != nil {
-		return nil, fmt.Errorf("could not clone %s: %v", gitURL, err)
+	cloneToBucket := func(sparseCheckoutDirPaths []string) (storage.ReadWriteBucket, error) {
+		readWriteBucket := storagemem.NewReadWriteBucket()
+		if err := r.gitCloner.CloneToBucket(
+			ctx,
+			container,
+			gitURL,
+			gitRef.Depth(),
+			readWriteBucket,
+			git.CloneToBucketOptions{
+				Name:                   gitRef.GitName(),
+				RecurseSubmodules:      gitRef.RecurseSubmodules(),
+				SparseCheckoutDirPaths: sparseCheckoutDirPaths,
+			},
+		); err != nil {
+			return nil, fmt.Errorf("could not clone %s: %v", gitURL, err)
+		}
+		return readWriteBucket, nil
+	}
+	// Only the subdirectory is checked out if one is given, along with the files
+	// in its parent directories, which include any terminate files.
+	var sparseCheckoutDirPaths []string
+	if subDirPath != "." {
+		sparseCheckoutDirPaths = []string{subDirPath}
+	}
+	readWriteBucket, err := cloneToBucket(sparseCheckoutDirPaths)
+	if err != nil {
+		return nil, err
 	}
 	terminateFileProvider, err := getTerminateFileProviderForBucket(ctx, readWriteBucket, subDirPath, terminateFileNames)
 	if err != nil {
@@ -487,6 +508,29 @@ func (r *reader) getGitBucket(
 	if len(terminateFiles) != 0 {
 		terminateFileDirectoryPath = terminateFiles[0].Path()
 	}
+	if len(sparseCheckoutDirPaths) != 0 && terminateFileDirectoryPath != "" && terminateFileDirectoryPath != subDirPath {
+		// The terminate file is in a parent directory of the subdirectory, so the
+		// workspace or module may reference files outside of the sparse checkout.
+		sparseCheckoutDirPaths, err = getTerminateFileSparseCheckoutDirPaths(
+			ctx,
+			readWriteBucket,
+			terminateFiles[0],
+			terminateFileDirPathsFunc,
+		)
+		if err != nil {
+			return nil, err
+		}
+		r.logger.Debug(
+			"git_sparse_checkout_widen",
+			zap.String("subdir", subDirPath),
+			zap.String("terminate_file_directory", terminateFileDirectoryPath),
+			zap.Strings("sparse_checkout_directories", sparseCheckoutDirPaths),
+		)
+		readWriteBucket, err = cloneToBucket(sparseCheckoutDirPaths)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if terminateFileDirectoryPath != "" {
 		relativeSubDirPath, err := normalpath.Rel(terminateFileDirectoryPath, subDirPath)
 		if err != nil {
@@ -600,6 +644,79 @@ func (r *reader) getFileReadCloserAndSizePotentiallyCompressed(
 	ctx context.Context,
 	container app.EnvStdinContainer,
 	fileRef FileRef,
+) (io.ReadCloser, int64, error) {
+	if fileRef.SHA256() != "" {
+		data, err := r.getFileDataPotentiallyCompressedVerified(ctx, container, fileRef)
+		if err != nil {
+			return nil, -1, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+	}
+	return r.getFileReadCloserAndSizePotentiallyCompressedUnverified(ctx, container, fileRef)
+}
+
+// getFileDataPotentiallyCompressedVerified reads the entire file into memory, so that
+// none of it is used if it does not match the sha256 digest of the FileRef.
+//
+// If the file is read over http or https and an http cache is configured, the file
+// is read from and written to the cache, keyed on the digest.
+func (r *reader) getFileDataPotentiallyCompressedVerified(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	fileRef FileRef,
+) (_ []byte, retErr error) {
+	expectedSHA256 := fileRef.SHA256()
+	fileScheme := fileRef.FileScheme()
+	var httpCacheBucket storage.ReadWriteBucket
+	if r.getHTTPCacheBucket != nil && (fileScheme == FileSchemeHTTP || fileScheme == FileSchemeHTTPS) {
+		var err error
+		httpCacheBucket, err = r.getHTTPCacheBucket()
+		if err != nil {
+			return nil, err
+		}
+	}
+	cachePath := normalpath.Join(expectedSHA256[:2], expectedSHA256[2:])
+	if httpCacheBucket != nil {
+		data, err := storage.ReadPath(ctx, httpCacheBucket, cachePath)
+		if err == nil {
+			if getSHA256Hex(data) == expectedSHA256 {
+				r.logger.Debug("http_cache_hit", zap.String("sha256", expectedSHA256))
+				return data, nil
+			}
+			// The cache entry is corrupt, for example from an interrupted write, so download again.
+			r.logger.Debug("http_cache_corrupt", zap.String("sha256", expectedSHA256))
+		} else if !storage.IsNotExist(err) {
+			r.logger.Debug("http_cache_read_failed", zap.String("sha256", expectedSHA256), zap.Error(err))
+		}
+	}
+	readCloser, _, err := r.getFileReadCloserAndSizePotentiallyCompressedUnverified(ctx, container, fileRef)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, readCloser.Close())
+	}()
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, err
+	}
+	if actualSHA256 := getSHA256Hex(data); actualSHA256 != expectedSHA256 {
+		return nil, NewSHA256MismatchError(getFileRefDisplayPath(fileRef), expectedSHA256, actualSHA256)
+	}
+	if httpCacheBucket != nil {
+		// Failing to write to the cache is not fatal, the file is just downloaded again next time.
+		if err := storage.PutPath(ctx, httpCacheBucket, cachePath, data); err != nil {
+			r.logger.Debug("http_cache_write_failed", zap.String("sha256", expectedSHA256), zap.Error(err))
+		}
+	}
+	return data, nil
+}
+
+// returns -1 if size unknown
+func (r *reader) getFileReadCloserAndSizePotentiallyCompressedUnverified(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	fileRef FileRef,
 ) (io.ReadCloser, int64, error) {
 	switch fileScheme := fileRef.FileScheme(); fileScheme {
 	case FileSchemeHTTP:
@@ -630,6 +747,11 @@ func (r *reader) getFileReadCloserAndSizePotentiallyCompressed(
 			return nil, -1, NewReadStdioDisabledError()
 		}
 		return io.NopCloser(container.Stdin()), -1, nil
+	case FileSchemeOCI:
+		if !r.ociEnabled {
+			return nil, -1, NewReadOCIDisabledError()
+		}
+		return r.getFileReadCloserAndSizePotentiallyCompressedOCI(ctx, container, fileRef)
 	case FileSchemeStdout:
 		return nil, -1, errors.New("cannot read from stdout")
 	case FileSchemeNull:
@@ -673,6 +795,57 @@ func (r *reader) getFileReadCloserAndSizePotentiallyCompressedHTTP(
 	return response.Body, response.ContentLength, nil
 }
 
+func (r *reader) getFileReadCloserAndSizePotentiallyCompressedOCI(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	fileRef FileRef,
+) (io.ReadCloser, int64, error) {
+	if r.ociClient == nil {
+		return nil, -1, errors.New("oci client is nil")
+	}
+	reference, err := oci.ParseReference(fileRef.Path())
+	if err != nil {
+		return nil, -1, err
+	}
+	configMediaType, layerMediaType, err := getOCIMediaTypes(fileRef)
+	if err != nil {
+		return nil, -1, err
+	}
+	artifact, err := r.ociClient.Pull(ctx, container, reference)
+	if err != nil {
+		return nil, -1, err
+	}
+	if artifact.ConfigMediaType != configMediaType {
+		if artifact.ConfigMediaType == ociSingleConfigMediaType && configMediaType == ociArchiveConfigMediaType {
+			// The format of oci:// inputs defaults to an archive, as the media types
+			// are only known once the artifact is pulled.
+			return nil, -1, NewOCIImageNotArchiveError(reference.String())
+		}
+		return nil, -1, NewOCIMediaTypeMismatchError(reference.String(), "config", configMediaType, artifact.ConfigMediaType)
+	}
+	if artifact.LayerMediaType != layerMediaType {
+		return nil, -1, NewOCIMediaTypeMismatchError(reference.String(), "layer", layerMediaType, artifact.LayerMediaType)
+	}
+	return io.NopCloser(bytes.NewReader(artifact.Content)), int64(len(artifact.Content)), nil
+}
+
+// getFileRefDisplayPath returns the path of the FileRef for use in errors,
+// with the scheme attached for http and https files.
+func getFileRefDisplayPath(fileRef FileRef) string {
+	switch fileRef.FileScheme() {
+	case FileSchemeHTTP:
+		return "http://" + fileRef.Path()
+	case FileSchemeHTTPS:
+		return "https://" + fileRef.Path()
+	case FileSchemeOCI:
+		return "oci://" + fileRef.Path()
+	case FileSchemeStdio, FileSchemeStdin:
+		return "stdin"
+	default:
+		return fileRef.Path()
+	}
+}
+
 func getGitURL(gitRef GitRef) (string, error) {
 	switch gitScheme := gitRef.GitScheme(); gitScheme {
 	case GitSchemeHTTP:
@@ -694,6 +867,39 @@ func getGitURL(gitRef GitRef) (string, error) {
 	}
 }
 
+// getTerminateFileSparseCheckoutDirPaths returns the directory paths to check out
+// for the terminate file, relative to the root of the readBucket.
+//
+// Returns nil if the entire repository needs to be checked out.
+func getTerminateFileSparseCheckoutDirPaths(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	terminateFile TerminateFile,
+	terminateFileDirPathsFunc TerminateFileDirPathsFunc,
+) ([]string, error) {
+	terminateFileDirPaths := []string{"."}
+	if terminateFileDirPathsFunc != nil {
+		var err error
+		terminateFileDirPaths, err = terminateFileDirPathsFunc(
+			ctx,
+			storage.MapReadBucket(readBucket, storage.MapOnPrefix(terminateFile.Path())),
+			terminateFile,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sparseCheckoutDirPaths := make([]string, 0, len(terminateFileDirPaths))
+	for _, terminateFileDirPath := range terminateFileDirPaths {
+		sparseCheckoutDirPath := normalpath.Join(terminateFile.Path(), terminateFileDirPath)
+		if sparseCheckoutDirPath == "." {
+			return nil, nil
+		}
+		sparseCheckoutDirPaths = append(sparseCheckoutDirPaths, sparseCheckoutDirPath)
+	}
+	return sparseCheckoutDirPaths, nil
+}
+
 // getTerminateFileProviderForBucket returns the directory path that contains
 // one of the terminateFileNames, starting with the subDirPath and ascending until the root
 // of the bucket.
@@ -896,7 +1102,8 @@ func newGetFileOptions() *getFileOptions {
 }
 
 type getBucketOptions struct {
-	terminateFileNames [][]string
+	terminateFileNames        [][]string
+	terminateFileDirPathsFunc TerminateFileDirPathsFunc
 }
 
 func newGetBucketOptions() *getBucketOptions {
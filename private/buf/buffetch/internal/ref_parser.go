@@ -153,6 +153,8 @@ func (a *refParser) getRawRef(value string) (*RawRef, error) {
 			rawRef.GitTag = value
 		case "ref":
 			rawRef.GitRef = value
+		case "merge-base":
+			rawRef.GitMergeBase = value
 		case "depth":
 			depth, err := strconv.ParseUint(value, 10, 32)
 			if err != nil {
@@ -180,6 +182,11 @@ func (a *refParser) getRawRef(value string) (*RawRef, error) {
 				return nil, NewOptionsCouldNotParseStripComponentsError(value)
 			}
 			rawRef.ArchiveStripComponents = uint32(stripComponents)
+		case "sha256":
+			if !isSHA256Hex(value) {
+				return nil, NewOptionsCouldNotParseSHA256Error(value)
+			}
+			rawRef.SHA256 = value
 		case "subdir":
 			subDirPath, err := normalpath.NormalizeAndValidate(value)
 			if err != nil {
@@ -213,16 +220,19 @@ func (a *refParser) getRawRef(value string) (*RawRef, error) {
 		if rawRef.GitRef != "" && rawRef.GitTag != "" {
 			return nil, NewCannotSpecifyTagWithRefError()
 		}
+		if rawRef.GitMergeBase != "" && (rawRef.GitRef != "" || rawRef.GitTag != "") {
+			return nil, NewCannotSpecifyMergeBaseWithTagOrRefError()
+		}
 		if rawRef.GitDepth == 0 {
 			// Default to 1
 			rawRef.GitDepth = 1
-			if rawRef.GitRef != "" {
-				// Default to 50 when using ref
+			if rawRef.GitRef != "" || rawRef.GitMergeBase != "" {
+				// Default to 50 when using ref or merge-base
 				rawRef.GitDepth = 50
 			}
 		}
 	} else {
-		if rawRef.GitBranch != "" || rawRef.GitTag != "" || rawRef.GitRef != "" || rawRef.GitRecurseSubmodules || rawRef.GitDepth > 0 {
+		if rawRef.GitBranch != "" || rawRef.GitTag != "" || rawRef.GitRef != "" || rawRef.GitMergeBase != "" || rawRef.GitRecurseSubmodules || rawRef.GitDepth > 0 {
 			return nil, NewOptionsInvalidForFormatError(rawRef.Format, value)
 		}
 	}
@@ -237,7 +247,7 @@ func (a *refParser) getRawRef(value string) (*RawRef, error) {
 		}
 	}
 	if !singleOK && !archiveOK {
-		if rawRef.CompressionType != 0 {
+		if rawRef.CompressionType != 0 || rawRef.SHA256 != "" {
 			return nil, NewOptionsInvalidForFormatError(rawRef.Format, value)
 		}
 	}
@@ -261,6 +271,7 @@ func getSingleRef(
 		rawRef.Format,
 		rawRef.Path,
 		compressionType,
+		rawRef.SHA256,
 	)
 }
 
@@ -280,6 +291,7 @@ func getArchiveRef(
 		compressionType,
 		rawRef.ArchiveStripComponents,
 		rawRef.SubDirPath,
+		rawRef.SHA256,
 	)
 }
 
@@ -295,7 +307,7 @@ func getDirRef(
 func getGitRef(
 	rawRef *RawRef,
 ) (ParsedGitRef, error) {
-	gitRefName, err := getGitRefName(rawRef.Path, rawRef.GitBranch, rawRef.GitTag, rawRef.GitRef)
+	gitRefName, err := getGitRefName(rawRef.Path, rawRef.GitBranch, rawRef.GitTag, rawRef.GitRef, rawRef.GitMergeBase)
 	if err != nil {
 		return nil, err
 	}
@@ -318,8 +330,8 @@ func getModuleRef(
 	)
 }
 
-func getGitRefName(path string, branch string, tag string, ref string) (git.Name, error) {
-	if branch == "" && tag == "" && ref == "" {
+func getGitRefName(path string, branch string, tag string, ref string, mergeBase string) (git.Name, error) {
+	if branch == "" && tag == "" && ref == "" && mergeBase == "" {
 		return nil, nil
 	}
 	if branch != "" && tag != "" {
@@ -330,6 +342,16 @@ func getGitRefName(path string, branch string, tag string, ref string) (git.Name
 		// already did this in getRawRef but just in case
 		return nil, NewCannotSpecifyTagWithRefError()
 	}
+	if mergeBase != "" && (ref != "" || tag != "") {
+		// already did this in getRawRef but just in case
+		return nil, NewCannotSpecifyMergeBaseWithTagOrRefError()
+	}
+	if mergeBase != "" && branch != "" {
+		return git.NewMergeBaseNameWithBranch(mergeBase, branch), nil
+	}
+	if mergeBase != "" {
+		return git.NewMergeBaseName(mergeBase), nil
+	}
 	if ref != "" && branch != "" {
 		return git.NewRefNameWithBranch(ref, branch), nil
 	}
@@ -28,6 +28,7 @@ var (
 		"http://":  FileSchemeHTTP,
 		"https://": FileSchemeHTTPS,
 		"file://":  FileSchemeLocal,
+		"oci://":   FileSchemeOCI,
 	}
 )
 
@@ -36,12 +37,14 @@ type singleRef struct {
 	path            string
 	fileScheme      FileScheme
 	compressionType CompressionType
+	sha256          string
 }
 
 func newSingleRef(
 	format string,
 	path string,
 	compressionType CompressionType,
+	sha256 string,
 ) (*singleRef, error) {
 	if path == "" {
 		return nil, NewNoPathError()
@@ -55,6 +58,7 @@ func newSingleRef(
 			"",
 			FileSchemeStdio,
 			compressionType,
+			sha256,
 		), nil
 	}
 	if app.IsDevStdin(path) {
@@ -63,6 +67,7 @@ func newSingleRef(
 			"",
 			FileSchemeStdin,
 			compressionType,
+			sha256,
 		), nil
 	}
 	if app.IsDevStdout(path) {
@@ -71,6 +76,7 @@ func newSingleRef(
 			"",
 			FileSchemeStdout,
 			compressionType,
+			sha256,
 		), nil
 	}
 	if app.IsDevNull(path) {
@@ -79,6 +85,7 @@ func newSingleRef(
 			"",
 			FileSchemeNull,
 			compressionType,
+			sha256,
 		), nil
 	}
 	for prefix, fileScheme := range fileSchemePrefixToFileScheme {
@@ -95,6 +102,7 @@ func newSingleRef(
 				path,
 				fileScheme,
 				compressionType,
+				sha256,
 			), nil
 		}
 	}
@@ -106,6 +114,7 @@ func newSingleRef(
 		normalpath.Normalize(path),
 		FileSchemeLocal,
 		compressionType,
+		sha256,
 	), nil
 }
 
@@ -114,12 +123,14 @@ func newDirectSingleRef(
 	path string,
 	fileScheme FileScheme,
 	compressionType CompressionType,
+	sha256 string,
 ) *singleRef {
 	return &singleRef{
 		format:          format,
 		path:            path,
 		fileScheme:      fileScheme,
 		compressionType: compressionType,
+		sha256:          sha256,
 	}
 }
 
@@ -139,6 +150,10 @@ func (r *singleRef) CompressionType() CompressionType {
 	return r.compressionType
 }
 
+func (r *singleRef) SHA256() string {
+	return r.sha256
+}
+
 func (*singleRef) ref()       {}
 func (*singleRef) fileRef()   {}
 func (*singleRef) singleRef() {}
@@ -15,8 +15,28 @@
 package internal
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/oci"
+)
+
+const (
+	// ociArchiveConfigMediaType is the config media type of OCI artifacts
+	// that contain an archive, such as an exported module.
+	ociArchiveConfigMediaType = "application/vnd.buf.module.config.v1+json"
+	// ociSingleConfigMediaType is the config media type of OCI artifacts
+	// that contain a single file, such as an image.
+	ociSingleConfigMediaType = "application/vnd.buf.image.config.v1+json"
+	// ociSingleLayerMediaType is the layer media type of OCI artifacts
+	// that contain a single file, before any compression suffix.
+	ociSingleLayerMediaType = "application/vnd.buf.image.layer.v1"
+	// ociZipLayerMediaType is the layer media type of OCI artifacts that
+	// contain a zip archive.
+	ociZipLayerMediaType = "application/zip"
 )
 
 func normalizeFormat(format string) string {
@@ -34,3 +54,64 @@ func formatsToString(formats map[string]struct{}) string {
 	sort.Strings(s)
 	return "[" + strings.Join(s, ",") + "]"
 }
+
+// isSHA256Hex returns true if the value is a lowercase hex-encoded sha256 digest.
+func isSHA256Hex(value string) bool {
+	if len(value) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+	for _, c := range value {
+		if !(('0' <= c && c <= '9') || ('a' <= c && c <= 'f')) {
+			return false
+		}
+	}
+	return true
+}
+
+// getSHA256Hex returns the lowercase hex-encoded sha256 digest of the data.
+func getSHA256Hex(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+// getOCIMediaTypes returns the config and layer media types of the OCI artifact for the FileRef.
+//
+// Artifacts are always written with these media types, and are verified to have
+// these media types when read, so that for example an image is never read as
+// an archive, or a zstd-compressed archive as a gzipped archive.
+func getOCIMediaTypes(fileRef FileRef) (string, string, error) {
+	compressionType := fileRef.CompressionType()
+	switch t := fileRef.(type) {
+	case SingleRef:
+		switch compressionType {
+		case CompressionTypeNone:
+			return ociSingleConfigMediaType, ociSingleLayerMediaType, nil
+		case CompressionTypeGzip:
+			return ociSingleConfigMediaType, ociSingleLayerMediaType + "+gzip", nil
+		case CompressionTypeZstd:
+			return ociSingleConfigMediaType, ociSingleLayerMediaType + "+zstd", nil
+		default:
+			return "", "", fmt.Errorf("unknown CompressionType: %v", compressionType)
+		}
+	case ArchiveRef:
+		switch archiveType := t.ArchiveType(); archiveType {
+		case ArchiveTypeTar:
+			switch compressionType {
+			case CompressionTypeNone:
+				return ociArchiveConfigMediaType, oci.MediaTypeImageLayerTar, nil
+			case CompressionTypeGzip:
+				return ociArchiveConfigMediaType, oci.MediaTypeImageLayerTarGzip, nil
+			case CompressionTypeZstd:
+				return ociArchiveConfigMediaType, oci.MediaTypeImageLayerTarZstd, nil
+			default:
+				return "", "", fmt.Errorf("unknown CompressionType: %v", compressionType)
+			}
+		case ArchiveTypeZip:
+			return ociArchiveConfigMediaType, ociZipLayerMediaType, nil
+		default:
+			return "", "", fmt.Errorf("unknown ArchiveType: %v", archiveType)
+		}
+	default:
+		return "", "", fmt.Errorf("unknown FileRef type: %T", fileRef)
+	}
+}
@@ -15,6 +15,7 @@
 package internal
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"errors"
@@ -24,6 +25,7 @@ import (
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/ioextended"
+	"github.com/bufbuild/buf/private/pkg/oci"
 	"github.com/klauspost/compress/zstd"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -36,6 +38,9 @@ type writer struct {
 	httpEnabled  bool
 	localEnabled bool
 	stdioEnabled bool
+
+	ociEnabled bool
+	ociClient  oci.Client
 }
 
 func newWriter(
@@ -56,7 +61,7 @@ func (w *writer) PutFile(
 	container app.EnvStdoutContainer,
 	fileRef FileRef,
 	options ...PutFileOption,
-) (io.WriteCloser, error) {
+) (WriteCommitCloser, error) {
 	putFileOptions := newPutFileOptions()
 	for _, option := range options {
 		option(putFileOptions)
@@ -86,7 +91,7 @@ func (w *writer) putSingle(
 	container app.EnvStdoutContainer,
 	singleRef SingleRef,
 	noFileCompression bool,
-) (io.WriteCloser, error) {
+) (WriteCommitCloser, error) {
 	return w.putFileWriteCloser(ctx, container, singleRef, noFileCompression)
 }
 
@@ -95,7 +100,7 @@ func (w *writer) putArchiveFile(
 	container app.EnvStdoutContainer,
 	archiveRef ArchiveRef,
 	noFileCompression bool,
-) (io.WriteCloser, error) {
+) (WriteCommitCloser, error) {
 	return w.putFileWriteCloser(ctx, container, archiveRef, noFileCompression)
 }
 
@@ -104,16 +109,31 @@ func (w *writer) putFileWriteCloser(
 	container app.EnvStdoutContainer,
 	fileRef FileRef,
 	noFileCompression bool,
-) (_ io.WriteCloser, retErr error) {
+) (WriteCommitCloser, error) {
 	writeCloser, err := w.putFileWriteCloserPotentiallyUncompressed(ctx, container, fileRef)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if retErr != nil {
-			retErr = multierr.Append(retErr, writeCloser.Close())
-		}
-	}()
+	commit := func() {}
+	if ociWriteCloser, ok := writeCloser.(*ociWriteCloser); ok {
+		commit = ociWriteCloser.commit
+	}
+	compressedWriteCloser, err := getCompressedWriteCloser(writeCloser, fileRef, noFileCompression)
+	if err != nil {
+		// Nothing was committed, so nothing is pushed for OCI artifacts.
+		return nil, multierr.Append(err, writeCloser.Close())
+	}
+	return &writeCommitCloser{
+		WriteCloser: compressedWriteCloser,
+		commit:      commit,
+	}, nil
+}
+
+func getCompressedWriteCloser(
+	writeCloser io.WriteCloser,
+	fileRef FileRef,
+	noFileCompression bool,
+) (io.WriteCloser, error) {
 	if noFileCompression {
 		return writeCloser, nil
 	}
@@ -172,6 +192,11 @@ func (w *writer) putFileWriteCloserPotentiallyUncompressed(
 			return nil, NewWriteStdioDisabledError()
 		}
 		return ioextended.NopWriteCloser(container.Stdout()), nil
+	case FileSchemeOCI:
+		if !w.ociEnabled {
+			return nil, NewWriteOCIDisabledError()
+		}
+		return w.putFileWriteCloserPotentiallyUncompressedOCI(ctx, container, fileRef)
 	case FileSchemeStdin:
 		return nil, errors.New("cannot write to stdin")
 	case FileSchemeNull:
@@ -181,6 +206,98 @@ func (w *writer) putFileWriteCloserPotentiallyUncompressed(
 	}
 }
 
+// putFileWriteCloserPotentiallyUncompressedOCI buffers the file in memory, and
+// pushes it as the single layer of an OCI artifact when the WriteCloser is
+// committed and closed.
+func (w *writer) putFileWriteCloserPotentiallyUncompressedOCI(
+	ctx context.Context,
+	container app.EnvStdoutContainer,
+	fileRef FileRef,
+) (io.WriteCloser, error) {
+	if w.ociClient == nil {
+		return nil, errors.New("oci client is nil")
+	}
+	reference, err := oci.ParseReference(fileRef.Path())
+	if err != nil {
+		return nil, err
+	}
+	if reference.Tag() == "" {
+		return nil, fmt.Errorf("cannot write to OCI reference %q: a tag is required", fileRef.Path())
+	}
+	configMediaType, layerMediaType, err := getOCIMediaTypes(fileRef)
+	if err != nil {
+		return nil, err
+	}
+	return &ociWriteCloser{
+		ctx:             ctx,
+		container:       container,
+		ociClient:       w.ociClient,
+		reference:       reference,
+		configMediaType: configMediaType,
+		layerMediaType:  layerMediaType,
+	}, nil
+}
+
+// writeCommitCloser is a WriteCommitCloser that commits the underlying
+// ociWriteCloser, if any.
+type writeCommitCloser struct {
+	io.WriteCloser
+
+	commit func()
+}
+
+func (w *writeCommitCloser) Commit() {
+	w.commit()
+}
+
+// ociWriteCloser buffers the written content, and pushes it on Close.
+//
+// The content is only pushed once, and is not pushed at all if the
+// writeCloser was not committed.
+type ociWriteCloser struct {
+	ctx             context.Context
+	container       app.EnvContainer
+	ociClient       oci.Client
+	reference       oci.Reference
+	configMediaType string
+	layerMediaType  string
+	buffer          bytes.Buffer
+	committed       bool
+	closed          bool
+}
+
+func (o *ociWriteCloser) Write(p []byte) (int, error) {
+	if o.closed {
+		return 0, fmt.Errorf("cannot write to OCI reference %q: already closed", o.reference.String())
+	}
+	return o.buffer.Write(p)
+}
+
+func (o *ociWriteCloser) Close() error {
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	if !o.committed {
+		return nil
+	}
+	return o.ociClient.Push(
+		o.ctx,
+		o.container,
+		o.reference,
+		&oci.Artifact{
+			ConfigMediaType: o.configMediaType,
+			LayerMediaType:  o.layerMediaType,
+			Content:         o.buffer.Bytes(),
+		},
+	)
+}
+
+// commit marks the written content as complete, so that it is pushed on Close.
+func (o *ociWriteCloser) commit() {
+	o.committed = true
+}
+
 type putFileOptions struct {
 	noFileCompression bool
 }
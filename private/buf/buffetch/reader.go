@@ -26,6 +26,8 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/git"
 	"github.com/bufbuild/buf/private/pkg/httpauth"
+	"github.com/bufbuild/buf/private/pkg/oci"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.uber.org/zap"
 )
@@ -42,24 +44,28 @@ func newReader(
 	gitCloner git.Cloner,
 	moduleResolver bufmodule.ModuleResolver,
 	moduleReader bufmodule.ModuleReader,
+	options ...ReaderOption,
 ) *reader {
 	return &reader{
 		internalReader: internal.NewReader(
 			logger,
 			storageosProvider,
-			internal.WithReaderHTTP(
-				httpClient,
-				httpAuthenticator,
-			),
-			internal.WithReaderGit(
-				gitCloner,
-			),
-			internal.WithReaderLocal(),
-			internal.WithReaderStdio(),
-			internal.WithReaderModule(
-				moduleResolver,
-				moduleReader,
-			),
+			append(
+				newReaderOptions(options...).internalReaderOptions(),
+				internal.WithReaderHTTP(
+					httpClient,
+					httpAuthenticator,
+				),
+				internal.WithReaderGit(
+					gitCloner,
+				),
+				internal.WithReaderLocal(),
+				internal.WithReaderStdio(),
+				internal.WithReaderModule(
+					moduleResolver,
+					moduleReader,
+				),
+			)...,
 		),
 	}
 }
@@ -70,17 +76,21 @@ func newImageReader(
 	httpClient *http.Client,
 	httpAuthenticator httpauth.Authenticator,
 	gitCloner git.Cloner,
+	options ...ReaderOption,
 ) *reader {
 	return &reader{
 		internalReader: internal.NewReader(
 			logger,
 			storageosProvider,
-			internal.WithReaderHTTP(
-				httpClient,
-				httpAuthenticator,
-			),
-			internal.WithReaderLocal(),
-			internal.WithReaderStdio(),
+			append(
+				newReaderOptions(options...).internalReaderOptions(),
+				internal.WithReaderHTTP(
+					httpClient,
+					httpAuthenticator,
+				),
+				internal.WithReaderLocal(),
+				internal.WithReaderStdio(),
+			)...,
 		),
 	}
 }
@@ -91,20 +101,24 @@ func newSourceReader(
 	httpClient *http.Client,
 	httpAuthenticator httpauth.Authenticator,
 	gitCloner git.Cloner,
+	options ...ReaderOption,
 ) *reader {
 	return &reader{
 		internalReader: internal.NewReader(
 			logger,
 			storageosProvider,
-			internal.WithReaderHTTP(
-				httpClient,
-				httpAuthenticator,
-			),
-			internal.WithReaderGit(
-				gitCloner,
-			),
-			internal.WithReaderLocal(),
-			internal.WithReaderStdio(),
+			append(
+				newReaderOptions(options...).internalReaderOptions(),
+				internal.WithReaderHTTP(
+					httpClient,
+					httpAuthenticator,
+				),
+				internal.WithReaderGit(
+					gitCloner,
+				),
+				internal.WithReaderLocal(),
+				internal.WithReaderStdio(),
+			)...,
 		),
 	}
 }
@@ -150,6 +164,7 @@ func (a *reader) GetSourceBucket(
 		getBucketOptions = append(
 			getBucketOptions,
 			internal.WithGetBucketTerminateFileNames([][]string{bufwork.AllConfigFilePaths, bufconfig.AllConfigFilePaths}),
+			internal.WithGetBucketTerminateFileDirPathsFunc(getTerminateFileDirPaths),
 		)
 	}
 	return a.internalReader.GetBucket(
@@ -167,3 +182,52 @@ func (a *reader) GetModule(
 ) (bufmodule.Module, error) {
 	return a.internalReader.GetModule(ctx, container, moduleRef.internalModuleRef())
 }
+
+// getTerminateFileDirPaths returns the workspace directories for a workspace
+// configuration file, and the directory of the module for a module configuration file.
+func getTerminateFileDirPaths(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	terminateFile internal.TerminateFile,
+) ([]string, error) {
+	for _, configFilePath := range bufwork.AllConfigFilePaths {
+		if terminateFile.Name() == configFilePath {
+			workspaceConfig, err := bufwork.GetConfigForBucket(ctx, readBucket, terminateFile.Path())
+			if err != nil {
+				return nil, err
+			}
+			return workspaceConfig.Directories, nil
+		}
+	}
+	return []string{"."}, nil
+}
+
+type readerOptions struct {
+	getHTTPCacheBucket func() (storage.ReadWriteBucket, error)
+	ociClient          oci.Client
+}
+
+func newReaderOptions(options ...ReaderOption) *readerOptions {
+	readerOptions := &readerOptions{}
+	for _, option := range options {
+		option(readerOptions)
+	}
+	return readerOptions
+}
+
+func (o *readerOptions) internalReaderOptions() []internal.ReaderOption {
+	var internalReaderOptions []internal.ReaderOption
+	if o.getHTTPCacheBucket != nil {
+		internalReaderOptions = append(
+			internalReaderOptions,
+			internal.WithReaderHTTPCache(o.getHTTPCacheBucket),
+		)
+	}
+	if o.ociClient != nil {
+		internalReaderOptions = append(
+			internalReaderOptions,
+			internal.WithReaderOCI(o.ociClient),
+		)
+	}
+	return internalReaderOptions
+}
@@ -71,6 +71,13 @@ func newRefParser(logger *zap.Logger, options ...RefParserOption) *refParser {
 			formatZip,
 			internal.ArchiveTypeZip,
 		),
+		internal.WithArchiveFormat(
+			formatOCI,
+			internal.ArchiveTypeTar,
+			internal.WithArchiveDefaultCompressionType(
+				internal.CompressionTypeGzip,
+			),
+		),
 		internal.WithGitFormat(formatGit),
 		internal.WithDirFormat(formatDir),
 		internal.WithModuleFormat(formatMod),
@@ -131,6 +138,13 @@ func newSourceRefParser(logger *zap.Logger) *refParser {
 				formatZip,
 				internal.ArchiveTypeZip,
 			),
+			internal.WithArchiveFormat(
+				formatOCI,
+				internal.ArchiveTypeTar,
+				internal.WithArchiveDefaultCompressionType(
+					internal.CompressionTypeGzip,
+				),
+			),
 			internal.WithGitFormat(formatGit),
 			internal.WithDirFormat(formatDir),
 		),
@@ -169,6 +183,13 @@ func newSourceOrModuleRefParser(logger *zap.Logger) *refParser {
 				formatZip,
 				internal.ArchiveTypeZip,
 			),
+			internal.WithArchiveFormat(
+				formatOCI,
+				internal.ArchiveTypeTar,
+				internal.WithArchiveDefaultCompressionType(
+					internal.CompressionTypeGzip,
+				),
+			),
 			internal.WithGitFormat(formatGit),
 			internal.WithDirFormat(formatDir),
 			internal.WithModuleFormat(formatMod),
@@ -330,6 +351,8 @@ func newRawRefProcessor(allowProtoFileRef bool) func(*internal.RawRef) error {
 		var compressionType internal.CompressionType
 		if rawRef.Path == "-" || app.IsDevNull(rawRef.Path) || app.IsDevStdin(rawRef.Path) || app.IsDevStdout(rawRef.Path) {
 			format = formatBin
+		} else if isOCIPath(rawRef.Path) {
+			format = formatOCI
 		} else {
 			switch filepath.Ext(rawRef.Path) {
 			case ".bin":
@@ -399,6 +422,10 @@ func newRawRefProcessor(allowProtoFileRef bool) func(*internal.RawRef) error {
 }
 
 func processRawRefSource(rawRef *internal.RawRef) error {
+	if isOCIPath(rawRef.Path) {
+		rawRef.Format = formatOCI
+		return nil
+	}
 	// if format option is not set and path is "-", default to bin
 	var format string
 	var compressionType internal.CompressionType
@@ -437,6 +464,10 @@ func processRawRefSource(rawRef *internal.RawRef) error {
 }
 
 func processRawRefSourceOrModule(rawRef *internal.RawRef) error {
+	if isOCIPath(rawRef.Path) {
+		rawRef.Format = formatOCI
+		return nil
+	}
 	// if format option is not set and path is "-", default to bin
 	var format string
 	var compressionType internal.CompressionType
@@ -482,7 +513,8 @@ func processRawRefImage(rawRef *internal.RawRef) error {
 	// if format option is not set and path is "-", default to bin
 	var format string
 	var compressionType internal.CompressionType
-	if rawRef.Path == "-" || app.IsDevNull(rawRef.Path) || app.IsDevStdin(rawRef.Path) || app.IsDevStdout(rawRef.Path) {
+	if rawRef.Path == "-" || app.IsDevNull(rawRef.Path) || app.IsDevStdin(rawRef.Path) || app.IsDevStdout(rawRef.Path) || isOCIPath(rawRef.Path) {
+		// OCI artifacts do not have extensions, and tags may contain periods
 		format = formatBin
 	} else {
 		switch filepath.Ext(rawRef.Path) {
@@ -535,6 +567,13 @@ func parseImageEncoding(format string) (ImageEncoding, error) {
 	}
 }
 
+// isOCIPath returns true if the path is an OCI reference.
+//
+// OCI references are detected before any extensions, as tags may contain periods.
+func isOCIPath(path string) bool {
+	return strings.HasPrefix(path, OCIPathPrefix)
+}
+
 // TODO: this is a terrible heuristic, and we shouldn't be using what amounts
 // to heuristics here (technically this is a documentable rule, but still)
 func assumeModuleOrDir(path string) (string, error) {
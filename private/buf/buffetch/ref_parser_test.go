@@ -129,6 +129,42 @@ func TestGetParsedRefSuccess(t *testing.T) {
 		),
 		"path/to/file.tar#strip_components=1",
 	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedArchiveRef(
+			formatOCI,
+			"registry.local/protos/payments:v3",
+			internal.FileSchemeOCI,
+			internal.ArchiveTypeTar,
+			internal.CompressionTypeGzip,
+			0,
+			"",
+		),
+		"oci://registry.local/protos/payments:v3",
+	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedArchiveRef(
+			formatOCI,
+			"registry.local/protos/payments:v1.2.0",
+			internal.FileSchemeOCI,
+			internal.ArchiveTypeTar,
+			internal.CompressionTypeZstd,
+			0,
+			"proto",
+		),
+		"oci://registry.local/protos/payments:v1.2.0#compression=zstd,subdir=proto",
+	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedSingleRef(
+			formatBin,
+			"registry.local/protos/payments:v3",
+			internal.FileSchemeOCI,
+			internal.CompressionTypeNone,
+		),
+		"oci://registry.local/protos/payments:v3#format=bin",
+	)
 	testGetParsedRefSuccess(
 		t,
 		internal.NewDirectParsedArchiveRef(
@@ -402,6 +438,32 @@ func TestGetParsedRefSuccess(t *testing.T) {
 		),
 		"ssh://user@hello.com:path/to/dir.git#ref=refs/remotes/origin/HEAD,branch=main,depth=10",
 	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedGitRef(
+			formatGit,
+			"path/to/dir.git",
+			internal.GitSchemeLocal,
+			git.NewMergeBaseName("origin/main"),
+			false,
+			50,
+			"proto",
+		),
+		"path/to/dir.git#merge-base=origin/main,subdir=proto",
+	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedGitRef(
+			formatGit,
+			"path/to/dir.git",
+			internal.GitSchemeLocal,
+			git.NewMergeBaseNameWithBranch("main", "feature"),
+			false,
+			10,
+			"",
+		),
+		"path/to/dir.git#merge-base=main,branch=feature,depth=10",
+	)
 	testGetParsedRefSuccess(
 		t,
 		internal.NewDirectParsedGitRef(
@@ -927,6 +989,21 @@ func TestGetParsedRefError(t *testing.T) {
 		internal.NewCannotSpecifyTagWithRefError(),
 		"path/to/foo#format=git,tag=foo,ref=bar",
 	)
+	testGetParsedRefError(
+		t,
+		internal.NewCannotSpecifyMergeBaseWithTagOrRefError(),
+		"path/to/foo#format=git,merge-base=main,ref=bar",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewCannotSpecifyMergeBaseWithTagOrRefError(),
+		"path/to/foo#format=git,merge-base=main,tag=bar",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewOptionsInvalidForFormatError(formatTar, "path/to/foo.tar#merge-base=main"),
+		"path/to/foo.tar#merge-base=main",
+	)
 	testGetParsedRefError(
 		t,
 		internal.NewDepthParseError("bar"),
@@ -967,6 +1044,21 @@ func TestGetParsedRefError(t *testing.T) {
 		internal.NewCompressionUnknownError("foo"),
 		"path/to/foo.tar.gz#compression=foo",
 	)
+	testGetParsedRefError(
+		t,
+		internal.NewOptionsCouldNotParseSHA256Error("foo"),
+		"path/to/foo.tar.gz#sha256=foo",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewOptionsCouldNotParseSHA256Error("2C26B46B68FFC68FF99B453C1D30413413422D706483BFA0F98A5E886266E7AE"),
+		"path/to/foo.tar.gz#sha256=2C26B46B68FFC68FF99B453C1D30413413422D706483BFA0F98A5E886266E7AE",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewOptionsInvalidForFormatError(formatDir, "path/to/some/foo#sha256=2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"),
+		"path/to/some/foo#sha256=2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+	)
 	testGetParsedRefError(
 		t,
 		internal.NewOptionsInvalidKeyError("foo"),
@@ -1014,6 +1106,23 @@ func TestGetParsedRefError(t *testing.T) {
 	)
 }
 
+func TestGetParsedRefSHA256(t *testing.T) {
+	t.Parallel()
+	const sha256Hex = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	for _, value := range []string{
+		"https://example.com/path/to/file.bin#sha256=" + sha256Hex,
+		"https://example.com/path/to/file.tar.gz#sha256=" + sha256Hex,
+		"https://example.com/path/to/file.zip#sha256=" + sha256Hex + ",strip_components=1",
+		"path/to/file.tar#sha256=" + sha256Hex,
+	} {
+		parsedRef, err := newRefParser(zap.NewNop()).getParsedRef(context.Background(), value, allFormats)
+		require.NoError(t, err, value)
+		fileRef, ok := parsedRef.(internal.FileRef)
+		require.True(t, ok, value)
+		assert.Equal(t, sha256Hex, fileRef.SHA256(), value)
+	}
+}
+
 func testGetParsedRefSuccess(
 	t *testing.T,
 	expectedRef internal.ParsedRef,
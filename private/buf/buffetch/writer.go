@@ -15,34 +15,95 @@
 package buffetch
 
 import (
+	"bytes"
 	"context"
-	"io"
+	"fmt"
 
 	"github.com/bufbuild/buf/private/buf/buffetch/internal"
 	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/oci"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagearchive"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
 type writer struct {
 	internalWriter internal.Writer
+
+	ociClient oci.Client
 }
 
 func newWriter(
 	logger *zap.Logger,
+	options ...WriterOption,
 ) *writer {
-	return &writer{
-		internalWriter: internal.NewWriter(
-			logger,
-			internal.WithWriterLocal(),
-			internal.WithWriterStdio(),
-		),
+	writer := &writer{}
+	for _, option := range options {
+		option(writer)
+	}
+	internalWriterOptions := []internal.WriterOption{
+		internal.WithWriterLocal(),
+		internal.WithWriterStdio(),
+	}
+	if writer.ociClient != nil {
+		internalWriterOptions = append(
+			internalWriterOptions,
+			internal.WithWriterOCI(writer.ociClient),
+		)
 	}
+	writer.internalWriter = internal.NewWriter(
+		logger,
+		internalWriterOptions...,
+	)
+	return writer
 }
 
 func (w *writer) PutImageFile(
 	ctx context.Context,
 	container app.EnvStdoutContainer,
 	imageRef ImageRef,
-) (io.WriteCloser, error) {
+) (WriteCommitCloser, error) {
 	return w.internalWriter.PutFile(ctx, container, imageRef.internalFileRef())
 }
+
+func (w *writer) PutSourceBucket(
+	ctx context.Context,
+	container app.EnvStdoutContainer,
+	sourceRef SourceRef,
+	readBucket storage.ReadBucket,
+) (retErr error) {
+	archiveRef, ok := sourceRef.internalBucketRef().(internal.ArchiveRef)
+	if !ok {
+		return fmt.Errorf("cannot write source to %T: only archives are supported", sourceRef.internalBucketRef())
+	}
+	if archiveRef.SubDirPath() != "" || archiveRef.StripComponents() != 0 {
+		return fmt.Errorf("cannot write source to %q: subdir and strip_components are not supported for writes", archiveRef.Path())
+	}
+	// The archive is built in memory so that nothing is written if building fails.
+	buffer := bytes.NewBuffer(nil)
+	switch archiveType := archiveRef.ArchiveType(); archiveType {
+	case internal.ArchiveTypeTar:
+		if err := storagearchive.Tar(ctx, readBucket, buffer); err != nil {
+			return err
+		}
+	case internal.ArchiveTypeZip:
+		if err := storagearchive.Zip(ctx, readBucket, buffer, true); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown ArchiveType: %v", archiveType)
+	}
+	writeCloser, err := w.internalWriter.PutFile(ctx, container, archiveRef)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, writeCloser.Close())
+	}()
+	if _, err := writeCloser.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+	writeCloser.Commit()
+	return nil
+}
@@ -20,10 +20,12 @@ package bufgen
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
 	"github.com/bufbuild/buf/private/gen/proto/apiclient/buf/alpha/registry/v1alpha1/registryv1alpha1apiclient"
@@ -51,8 +53,16 @@ const (
 	StrategyDirectory Strategy = 1
 	// StrategyAll is the strategy that says to generate with all files at once.
 	StrategyAll Strategy = 2
+	// StrategyPackage is the strategy that says to generate per package.
+	StrategyPackage Strategy = 3
+	// StrategyFile is the strategy that says to generate per file.
+	StrategyFile Strategy = 4
 )
 
+// ErrGeneratedOutOfDate is returned from Generate when GenerateWithCheck is set
+// and the generated content on disk is not up to date.
+var ErrGeneratedOutOfDate = errors.New("generated content is not up to date")
+
 // Strategy is a generation stategy.
 type Strategy int
 
@@ -65,6 +75,10 @@ func ParseStrategy(s string) (Strategy, error) {
 		return StrategyDirectory, nil
 	case "all":
 		return StrategyAll, nil
+	case "package":
+		return StrategyPackage, nil
+	case "file":
+		return StrategyFile, nil
 	default:
 		return 0, fmt.Errorf("unknown strategy: %s", s)
 	}
@@ -77,6 +91,10 @@ func (s Strategy) String() string {
 		return "directory"
 	case StrategyAll:
 		return "all"
+	case StrategyPackage:
+		return "package"
+	case StrategyFile:
+		return "file"
 	default:
 		return strconv.Itoa(int(s))
 	}
@@ -157,12 +175,89 @@ func GenerateWithIncludeWellKnownTypes() GenerateOption {
 	}
 }
 
+// GenerateWithClean says to delete stale files from the out directories of
+// all plugins, as if every plugin had Clean set.
+//
+// See PluginConfig.Clean.
+func GenerateWithClean() GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.clean = true
+	}
+}
+
+// GenerateWithCheck says to compare the generated content to the content
+// on disk instead of writing it.
+//
+// A unified diff of any differences is written to stdout, and Generate
+// returns ErrGeneratedOutOfDate if there are any differences. Nothing is
+// written to disk.
+func GenerateWithCheck() GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.check = true
+	}
+}
+
+// GenerateWithCacheBucket returns a new GenerateOption that caches the
+// CodeGeneratorResponse of each plugin in the given bucket.
+//
+// When a plugin is invoked with the same plugin version, options, and
+// CodeGeneratorRequests as its previous invocation, the cached response is
+// used instead of invoking the plugin. Only the last response of every plugin
+// configuration is kept. Remote plugins without a pinned version and revision
+// are never cached.
+func GenerateWithCacheBucket(cacheBucket storage.ReadWriteBucket) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.cacheBucket = cacheBucket
+	}
+}
+
+// GenerateWithGeneratedFilesFunc returns a new GenerateOption that calls f with
+// the files generated by each plugin once they have been written, and once all
+// of the post commands have completed.
+//
+// This has no effect if GenerateWithCheck is set, as nothing is written.
+func GenerateWithGeneratedFilesFunc(f func([]*GeneratedFiles) error) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.generatedFilesFunc = f
+	}
+}
+
+// GeneratedFiles are the files generated by a single plugin.
+type GeneratedFiles struct {
+	// The name of the plugin, as returned by PluginConfig.PluginName.
+	PluginName string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	// The output directory, or .jar or .zip archive, of the plugin,
+	// including the base output directory.
+	Out string `json:"out,omitempty" yaml:"out,omitempty"`
+	// The sorted paths of the files generated by the plugin, including Out.
+	//
+	// For .jar and .zip outputs, this only contains the archive itself.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
+
 // Config is a configuration.
 type Config struct {
 	// Required
 	PluginConfigs []*PluginConfig
 	// Optional
 	ManagedConfig *ManagedConfig
+	// Optional, the inputs to generate from if no input is
+	// specified on the command line
+	InputConfigs []*InputConfig
+}
+
+// InputConfig is an input configuration.
+type InputConfig struct {
+	// Required, any input accepted by buf generate, such as a
+	// directory, a git repository, or a module
+	Input string
+	// Optional, if set, only the files within these paths are generated
+	Paths []string
+	// Optional, if set, the files within these paths are not generated
+	ExcludePaths []string
+	// Optional, if set, only these fully-qualified types, or all types
+	// within these packages, and their dependencies are generated
+	Types []string
 }
 
 // PluginConfig is a plugin configuration.
@@ -181,6 +276,22 @@ type PluginConfig struct {
 	Path string
 	// Required
 	Strategy Strategy
+	// Optional, if set, files previously generated to Out
+	// that are no longer generated are deleted
+	Clean bool
+	// Optional, if set, the plugin is only sent the files
+	// within these paths
+	IncludePaths []string
+	// Optional, if set, the plugin is not sent the files
+	// within these paths
+	ExcludePaths []string
+	// Optional, if set, the plugin is only sent these fully-qualified
+	// types, or all types within these packages, and their dependencies
+	Types []string
+	// Optional, the commands to run after the generated files are
+	// written, each given the paths of the files generated by the plugin
+	// as additional arguments
+	Post [][]string
 }
 
 // PluginName returns this PluginConfig's plugin name.
@@ -220,6 +331,14 @@ type ManagedConfig struct {
 	JavaPackagePrefix     *JavaPackagePrefixConfig
 	OptimizeFor           *descriptorpb.FileOptions_OptimizeMode
 	GoPackagePrefixConfig *GoPackagePrefixConfig
+	SwiftPrefix           *SwiftPrefixConfig
+	PhpClassPrefix        *PhpClassPrefixConfig
+	CcGenericServices     *GenericServicesConfig
+	JavaGenericServices   *GenericServicesConfig
+	PyGenericServices     *GenericServicesConfig
+	JSType                *JSTypeConfig
+	FieldOptions          []bufimagemodify.FieldOptionOverride
+	MessageOptions        []bufimagemodify.MessageOptionOverride
 	Override              map[string]map[string]string
 }
 
@@ -239,6 +358,39 @@ type GoPackagePrefixConfig struct {
 	Override map[bufmoduleref.ModuleIdentity]string
 }
 
+// SwiftPrefixConfig is the swift_prefix configuration.
+type SwiftPrefixConfig struct {
+	Default string
+	Except  []bufmoduleref.ModuleIdentity
+	// bufmoduleref.ModuleIdentity -> swift_prefix.
+	Override map[bufmoduleref.ModuleIdentity]string
+}
+
+// PhpClassPrefixConfig is the php_class_prefix configuration.
+type PhpClassPrefixConfig struct {
+	Default string
+	Except  []bufmoduleref.ModuleIdentity
+	// bufmoduleref.ModuleIdentity -> php_class_prefix.
+	Override map[bufmoduleref.ModuleIdentity]string
+}
+
+// GenericServicesConfig is the configuration for one of the
+// cc_generic_services, java_generic_services, or py_generic_services options.
+type GenericServicesConfig struct {
+	Default bool
+	Except  []bufmoduleref.ModuleIdentity
+	// bufmoduleref.ModuleIdentity -> generic services value.
+	Override map[bufmoduleref.ModuleIdentity]bool
+}
+
+// JSTypeConfig is the jstype configuration.
+type JSTypeConfig struct {
+	Default descriptorpb.FieldOptions_JSType
+	Except  []bufmoduleref.ModuleIdentity
+	// bufmoduleref.ModuleIdentity -> jstype.
+	Override map[bufmoduleref.ModuleIdentity]descriptorpb.FieldOptions_JSType
+}
+
 // ReadConfig reads the configuration from the OS or an override, if any.
 //
 // Only use in CLI tools.
@@ -282,18 +434,34 @@ type ExternalConfigV1 struct {
 	Version string                   `json:"version,omitempty" yaml:"version,omitempty"`
 	Plugins []ExternalPluginConfigV1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 	Managed ExternalManagedConfigV1  `json:"managed,omitempty" yaml:"managed,omitempty"`
+	Inputs  []ExternalInputConfigV1  `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+}
+
+// ExternalInputConfigV1 is an external input configuration.
+type ExternalInputConfigV1 struct {
+	Input        string   `json:"input,omitempty" yaml:"input,omitempty"`
+	Paths        []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	ExcludePaths []string `json:"exclude_paths,omitempty" yaml:"exclude_paths,omitempty"`
+	Types        []string `json:"types,omitempty" yaml:"types,omitempty"`
 }
 
 // ExternalPluginConfigV1 is an external plugin configuration.
 type ExternalPluginConfigV1 struct {
-	Plugin   string      `json:"plugin,omitempty" yaml:"plugin,omitempty"`
-	Revision int         `json:"revision,omitempty" yaml:"revision,omitempty"`
-	Name     string      `json:"name,omitempty" yaml:"name,omitempty"`
-	Remote   string      `json:"remote,omitempty" yaml:"remote,omitempty"`
-	Out      string      `json:"out,omitempty" yaml:"out,omitempty"`
-	Opt      interface{} `json:"opt,omitempty" yaml:"opt,omitempty"`
-	Path     string      `json:"path,omitempty" yaml:"path,omitempty"`
-	Strategy string      `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Plugin       string      `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	Revision     int         `json:"revision,omitempty" yaml:"revision,omitempty"`
+	Name         string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Remote       string      `json:"remote,omitempty" yaml:"remote,omitempty"`
+	Out          string      `json:"out,omitempty" yaml:"out,omitempty"`
+	Opt          interface{} `json:"opt,omitempty" yaml:"opt,omitempty"`
+	Path         string      `json:"path,omitempty" yaml:"path,omitempty"`
+	Strategy     string      `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Clean        bool        `json:"clean,omitempty" yaml:"clean,omitempty"`
+	IncludePaths []string    `json:"include_paths,omitempty" yaml:"include_paths,omitempty"`
+	ExcludePaths []string    `json:"exclude_paths,omitempty" yaml:"exclude_paths,omitempty"`
+	Types        []string    `json:"types,omitempty" yaml:"types,omitempty"`
+	// Post is a list of commands, each either a single string that is split
+	// on whitespace, or a list of strings.
+	Post []interface{} `json:"post,omitempty" yaml:"post,omitempty"`
 }
 
 // ExternalManagedConfigV1 is an external managed mode configuration.
@@ -307,6 +475,14 @@ type ExternalManagedConfigV1 struct {
 	JavaPackagePrefix   ExternalJavaPackagePrefixConfigV1 `json:"java_package_prefix,omitempty" yaml:"java_package_prefix,omitempty"`
 	OptimizeFor         string                            `json:"optimize_for,omitempty" yaml:"optimize_for,omitempty"`
 	GoPackagePrefix     ExternalGoPackagePrefixConfigV1   `json:"go_package_prefix,omitempty" yaml:"go_package_prefix,omitempty"`
+	SwiftPrefix         ExternalSwiftPrefixConfigV1       `json:"swift_prefix,omitempty" yaml:"swift_prefix,omitempty"`
+	PhpClassPrefix      ExternalPhpClassPrefixConfigV1    `json:"php_class_prefix,omitempty" yaml:"php_class_prefix,omitempty"`
+	CcGenericServices   ExternalGenericServicesConfigV1   `json:"cc_generic_services,omitempty" yaml:"cc_generic_services,omitempty"`
+	JavaGenericServices ExternalGenericServicesConfigV1   `json:"java_generic_services,omitempty" yaml:"java_generic_services,omitempty"`
+	PyGenericServices   ExternalGenericServicesConfigV1   `json:"py_generic_services,omitempty" yaml:"py_generic_services,omitempty"`
+	JSType              ExternalJSTypeConfigV1            `json:"jstype,omitempty" yaml:"jstype,omitempty"`
+	FieldOptions        []ExternalFieldOptionConfigV1     `json:"field_options,omitempty" yaml:"field_options,omitempty"`
+	MessageOptions      []ExternalMessageOptionConfigV1   `json:"message_options,omitempty" yaml:"message_options,omitempty"`
 	Override            map[string]map[string]string      `json:"override,omitempty" yaml:"override,omitempty"`
 }
 
@@ -318,6 +494,14 @@ func (e ExternalManagedConfigV1) IsEmpty() bool {
 		e.JavaPackagePrefix.IsEmpty() &&
 		e.OptimizeFor == "" &&
 		e.GoPackagePrefix.IsEmpty() &&
+		e.SwiftPrefix.IsEmpty() &&
+		e.PhpClassPrefix.IsEmpty() &&
+		e.CcGenericServices.IsEmpty() &&
+		e.JavaGenericServices.IsEmpty() &&
+		e.PyGenericServices.IsEmpty() &&
+		e.JSType.IsEmpty() &&
+		len(e.FieldOptions) == 0 &&
+		len(e.MessageOptions) == 0 &&
 		len(e.Override) == 0
 }
 
@@ -381,6 +565,208 @@ func (e ExternalGoPackagePrefixConfigV1) IsEmpty() bool {
 		len(e.Override) == 0
 }
 
+// ExternalSwiftPrefixConfigV1 is the external swift_prefix configuration.
+type ExternalSwiftPrefixConfigV1 struct {
+	Default  string            `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string          `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]string `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalSwiftPrefixConfigV1) IsEmpty() bool {
+	return e.Default == "" &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
+// UnmarshalYAML satisfies the yaml.Unmarshaler interface. This is done to accept
+// a plain string value for swift_prefix.
+func (e *ExternalSwiftPrefixConfigV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return e.unmarshalWith(unmarshal)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. This is done to accept
+// a plain string value for swift_prefix.
+func (e *ExternalSwiftPrefixConfigV1) UnmarshalJSON(data []byte) error {
+	unmarshal := func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	}
+
+	return e.unmarshalWith(unmarshal)
+}
+
+// unmarshalWith is used to unmarshal into json/yaml. See https://abhinavg.net/posts/flexible-yaml for details.
+func (e *ExternalSwiftPrefixConfigV1) unmarshalWith(unmarshal func(interface{}) error) error {
+	var prefix string
+	if err := unmarshal(&prefix); err == nil {
+		e.Default = prefix
+		return nil
+	}
+
+	type rawExternalSwiftPrefixConfigV1 ExternalSwiftPrefixConfigV1
+	if err := unmarshal((*rawExternalSwiftPrefixConfigV1)(e)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ExternalPhpClassPrefixConfigV1 is the external php_class_prefix configuration.
+type ExternalPhpClassPrefixConfigV1 struct {
+	Default  string            `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string          `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]string `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalPhpClassPrefixConfigV1) IsEmpty() bool {
+	return e.Default == "" &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
+// UnmarshalYAML satisfies the yaml.Unmarshaler interface. This is done to accept
+// a plain string value for php_class_prefix.
+func (e *ExternalPhpClassPrefixConfigV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return e.unmarshalWith(unmarshal)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. This is done to accept
+// a plain string value for php_class_prefix.
+func (e *ExternalPhpClassPrefixConfigV1) UnmarshalJSON(data []byte) error {
+	unmarshal := func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	}
+
+	return e.unmarshalWith(unmarshal)
+}
+
+// unmarshalWith is used to unmarshal into json/yaml. See https://abhinavg.net/posts/flexible-yaml for details.
+func (e *ExternalPhpClassPrefixConfigV1) unmarshalWith(unmarshal func(interface{}) error) error {
+	var prefix string
+	if err := unmarshal(&prefix); err == nil {
+		e.Default = prefix
+		return nil
+	}
+
+	type rawExternalPhpClassPrefixConfigV1 ExternalPhpClassPrefixConfigV1
+	if err := unmarshal((*rawExternalPhpClassPrefixConfigV1)(e)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ExternalGenericServicesConfigV1 is the external configuration for one of the
+// cc_generic_services, java_generic_services, or py_generic_services options.
+type ExternalGenericServicesConfigV1 struct {
+	Default  *bool           `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string        `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]bool `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalGenericServicesConfigV1) IsEmpty() bool {
+	return e.Default == nil &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
+// UnmarshalYAML satisfies the yaml.Unmarshaler interface. This is done to accept
+// a plain boolean value.
+func (e *ExternalGenericServicesConfigV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return e.unmarshalWith(unmarshal)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. This is done to accept
+// a plain boolean value.
+func (e *ExternalGenericServicesConfigV1) UnmarshalJSON(data []byte) error {
+	unmarshal := func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	}
+
+	return e.unmarshalWith(unmarshal)
+}
+
+// unmarshalWith is used to unmarshal into json/yaml. See https://abhinavg.net/posts/flexible-yaml for details.
+func (e *ExternalGenericServicesConfigV1) unmarshalWith(unmarshal func(interface{}) error) error {
+	var value bool
+	if err := unmarshal(&value); err == nil {
+		e.Default = &value
+		return nil
+	}
+
+	type rawExternalGenericServicesConfigV1 ExternalGenericServicesConfigV1
+	if err := unmarshal((*rawExternalGenericServicesConfigV1)(e)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ExternalJSTypeConfigV1 is the external jstype configuration.
+type ExternalJSTypeConfigV1 struct {
+	Default  string            `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string          `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]string `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalJSTypeConfigV1) IsEmpty() bool {
+	return e.Default == "" &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
+// UnmarshalYAML satisfies the yaml.Unmarshaler interface. This is done to accept
+// a plain string value for jstype.
+func (e *ExternalJSTypeConfigV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return e.unmarshalWith(unmarshal)
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. This is done to accept
+// a plain string value for jstype.
+func (e *ExternalJSTypeConfigV1) UnmarshalJSON(data []byte) error {
+	unmarshal := func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	}
+
+	return e.unmarshalWith(unmarshal)
+}
+
+// unmarshalWith is used to unmarshal into json/yaml. See https://abhinavg.net/posts/flexible-yaml for details.
+func (e *ExternalJSTypeConfigV1) unmarshalWith(unmarshal func(interface{}) error) error {
+	var jsType string
+	if err := unmarshal(&jsType); err == nil {
+		e.Default = jsType
+		return nil
+	}
+
+	type rawExternalJSTypeConfigV1 ExternalJSTypeConfigV1
+	if err := unmarshal((*rawExternalJSTypeConfigV1)(e)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ExternalFieldOptionConfigV1 is the external configuration of a field option override.
+type ExternalFieldOptionConfigV1 struct {
+	Selector string   `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Types    []string `json:"types,omitempty" yaml:"types,omitempty"`
+	Option   string   `json:"option,omitempty" yaml:"option,omitempty"`
+	// Value is a string, bool, or number.
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// ExternalMessageOptionConfigV1 is the external configuration of a message option override.
+type ExternalMessageOptionConfigV1 struct {
+	Selector string `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Option   string `json:"option,omitempty" yaml:"option,omitempty"`
+	// Value is a string, bool, or number.
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
 // ExternalConfigV1Beta1 is an external configuration.
 type ExternalConfigV1Beta1 struct {
 	Version string                        `json:"version,omitempty" yaml:"version,omitempty"`
@@ -0,0 +1,267 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
+	"github.com/bufbuild/buf/private/bufpkg/bufremoteplugin"
+	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoexec"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// cacheKeyVersion is written as the first component of every cache key.
+//
+// This should be incremented whenever the composition of the cache key
+// changes, so that stale entries are never replayed.
+const cacheKeyVersion = "v1"
+
+// responseCache is a cache of CodeGeneratorResponses.
+//
+// Every plugin configuration, that is the plugin identity, the plugin options,
+// and the output directory, has a single entry that holds the response to the
+// last CodeGeneratorRequests sent to the plugin, along with a digest of these
+// requests. A response is only replayed if the digest of the current requests
+// matches, and a new response replaces the previous one, so that the size of
+// the cache is bounded by the number of plugin configurations and not by the
+// number of invocations. Failures to read from or write to the cache are never
+// fatal; the plugin is simply invoked instead.
+type responseCache struct {
+	logger          *zap.Logger
+	readWriteBucket storage.ReadWriteBucket
+}
+
+func newResponseCache(
+	logger *zap.Logger,
+	readWriteBucket storage.ReadWriteBucket,
+) *responseCache {
+	return &responseCache{
+		logger:          logger.Named("cache"),
+		readWriteBucket: readWriteBucket,
+	}
+}
+
+// Get returns the cached CodeGeneratorResponse for the key, or nil if there is none.
+func (r *responseCache) Get(ctx context.Context, key *cacheKey) *pluginpb.CodeGeneratorResponse {
+	data, err := storage.ReadPath(ctx, r.readWriteBucket, keyToPath(key.slot))
+	if err != nil {
+		if !storage.IsNotExist(err) {
+			r.logger.Debug("read_failed", zap.String("slot", key.slot), zap.Error(err))
+		}
+		return nil
+	}
+	// The entry is the digest of the requests followed by the response.
+	if len(data) < len(key.digest) || string(data[:len(key.digest)]) != key.digest {
+		r.logger.Debug("miss", zap.String("slot", key.slot))
+		return nil
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(data[len(key.digest):], response); err != nil {
+		r.logger.Debug("unmarshal_failed", zap.String("slot", key.slot), zap.Error(err))
+		return nil
+	}
+	r.logger.Debug("hit", zap.String("slot", key.slot))
+	return response
+}
+
+// Put stores the CodeGeneratorResponse for the key, replacing the
+// previous response of the plugin configuration.
+//
+// Responses that contain an error are not stored.
+func (r *responseCache) Put(ctx context.Context, key *cacheKey, response *pluginpb.CodeGeneratorResponse) {
+	if response.GetError() != "" {
+		return
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(response)
+	if err != nil {
+		r.logger.Debug("marshal_failed", zap.String("slot", key.slot), zap.Error(err))
+		return
+	}
+	if err := storage.PutPath(ctx, r.readWriteBucket, keyToPath(key.slot), append([]byte(key.digest), data...)); err != nil {
+		r.logger.Debug("write_failed", zap.String("slot", key.slot), zap.Error(err))
+	}
+}
+
+// cacheKey is the key of a CodeGeneratorResponse in the responseCache.
+type cacheKey struct {
+	// slot identifies the plugin configuration.
+	slot string
+	// digest identifies the CodeGeneratorRequests sent to the plugin.
+	digest string
+}
+
+// getCacheKey returns the cache key for the plugin.
+//
+// If the plugin cannot be identified precisely, such as a remote plugin
+// without a pinned version and revision, or a local plugin that cannot be
+// found on the PATH, this returns false and the plugin should not be cached.
+func getCacheKey(
+	imageProvider *imageProvider,
+	image bufimage.Image,
+	pluginConfig *PluginConfig,
+	includeImports bool,
+	includeWellKnownTypes bool,
+) (*cacheKey, bool, error) {
+	identity, ok := getPluginIdentity(pluginConfig)
+	if !ok {
+		return nil, false, nil
+	}
+	var requests []*pluginpb.CodeGeneratorRequest
+	if pluginConfig.IsRemote() {
+		// Remote plugins are always sent the entire image.
+		requests = bufimage.ImagesToCodeGeneratorRequests(
+			[]bufimage.Image{image},
+			pluginConfig.Opt,
+			nil,
+			includeImports,
+			includeWellKnownTypes,
+		)
+	} else {
+		pluginImages, err := imageProvider.GetImages(pluginConfig.Strategy)
+		if err != nil {
+			return nil, false, err
+		}
+		requests = bufimage.ImagesToCodeGeneratorRequests(
+			pluginImages,
+			pluginConfig.Opt,
+			nil,
+			includeImports,
+			includeWellKnownTypes,
+		)
+	}
+	slotHash := sha256.New()
+	writeCacheKeyComponent(slotHash, cacheKeyVersion)
+	for _, component := range identity {
+		writeCacheKeyComponent(slotHash, component)
+	}
+	writeCacheKeyComponent(slotHash, pluginConfig.Opt)
+	writeCacheKeyComponent(slotHash, pluginConfig.Out)
+	writeCacheKeyComponent(slotHash, strconv.FormatBool(includeImports))
+	writeCacheKeyComponent(slotHash, strconv.FormatBool(includeWellKnownTypes))
+	digestHash := sha256.New()
+	marshalOptions := proto.MarshalOptions{Deterministic: true}
+	for _, request := range requests {
+		data, err := marshalOptions.Marshal(request)
+		if err != nil {
+			return nil, false, err
+		}
+		writeCacheKeyComponent(digestHash, string(data))
+	}
+	return &cacheKey{
+		slot:   hex.EncodeToString(slotHash.Sum(nil)),
+		digest: hex.EncodeToString(digestHash.Sum(nil)),
+	}, true, nil
+}
+
+// getPluginIdentity returns the components that identify the specific
+// version of the plugin that will be invoked.
+func getPluginIdentity(pluginConfig *PluginConfig) ([]string, bool) {
+	switch {
+	case pluginConfig.Plugin != "":
+		if _, err := bufpluginref.PluginReferenceForString(pluginConfig.Plugin, pluginConfig.Revision); err != nil {
+			// No version was specified, so the latest version is used.
+			return nil, false
+		}
+		if pluginConfig.Revision == 0 {
+			// No revision was specified, so the latest revision of the version is used.
+			return nil, false
+		}
+		return []string{"plugin", pluginConfig.Plugin, strconv.Itoa(pluginConfig.Revision)}, true
+	case pluginConfig.Remote != "":
+		_, _, _, version, err := bufremoteplugin.ParsePluginVersionPath(pluginConfig.Remote)
+		if err != nil || version == "" {
+			return nil, false
+		}
+		return []string{"remote", pluginConfig.Remote}, true
+	default:
+		binaryPath, ok := getLocalPluginBinaryPath(pluginConfig)
+		if !ok {
+			return nil, false
+		}
+		binaryDigest, err := getFileDigest(binaryPath)
+		if err != nil {
+			return nil, false
+		}
+		return []string{
+			"local",
+			pluginConfig.Name,
+			binaryPath,
+			binaryDigest,
+		}, true
+	}
+}
+
+// getLocalPluginBinaryPath resolves the binary that appprotoexec will invoke
+// for the local plugin.
+func getLocalPluginBinaryPath(pluginConfig *PluginConfig) (string, bool) {
+	if pluginConfig.Path != "" {
+		if appprotoexec.IsWASMPluginPath(pluginConfig.Path) {
+			binaryPath, err := filepath.Abs(pluginConfig.Path)
+			return binaryPath, err == nil
+		}
+		binaryPath, err := exec.LookPath(pluginConfig.Path)
+		return binaryPath, err == nil
+	}
+	if binaryPath, err := exec.LookPath("protoc-gen-" + pluginConfig.Name); err == nil {
+		return binaryPath, true
+	}
+	if _, ok := appprotoexec.ProtocProxyPluginNames[pluginConfig.Name]; ok {
+		binaryPath, err := exec.LookPath("protoc")
+		return binaryPath, err == nil
+	}
+	return "", false
+}
+
+// getFileDigest returns the hex-encoded sha256 digest of the file.
+func getFileDigest(filePath string) (_ string, retErr error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeCacheKeyComponent writes the length-prefixed component to the hash
+// so that adjacent components cannot be confused with each other.
+func writeCacheKeyComponent(hash hash.Hash, component string) {
+	_, _ = fmt.Fprintf(hash, "%d:%s", len(component), component)
+}
+
+func keyToPath(key string) string {
+	return normalpath.Join(key[:2], key[2:])
+}
@@ -0,0 +1,225 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestResponseCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	responseCache := newResponseCache(zap.NewNop(), storagemem.NewReadWriteBucket())
+	key := &cacheKey{
+		slot:   "0123456789abcdef",
+		digest: "0123",
+	}
+	assert.Nil(t, responseCache.Get(ctx, key))
+	response := &pluginpb.CodeGeneratorResponse{
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{
+				Name:    proto.String("a.txt"),
+				Content: proto.String("a"),
+			},
+		},
+	}
+	responseCache.Put(ctx, key, response)
+	cachedResponse := responseCache.Get(ctx, key)
+	require.NotNil(t, cachedResponse)
+	assert.True(t, proto.Equal(response, cachedResponse))
+	// The response for other requests replaces the response in the slot.
+	otherKey := &cacheKey{
+		slot:   key.slot,
+		digest: "4567",
+	}
+	assert.Nil(t, responseCache.Get(ctx, otherKey))
+	responseCache.Put(ctx, otherKey, &pluginpb.CodeGeneratorResponse{})
+	assert.NotNil(t, responseCache.Get(ctx, otherKey))
+	assert.Nil(t, responseCache.Get(ctx, key))
+	// Responses with errors are never cached.
+	errorKey := &cacheKey{
+		slot:   "fedcba9876543210",
+		digest: "0123",
+	}
+	responseCache.Put(ctx, errorKey, &pluginpb.CodeGeneratorResponse{Error: proto.String("failure")})
+	assert.Nil(t, responseCache.Get(ctx, errorKey))
+}
+
+func TestGetCacheKeyRemote(t *testing.T) {
+	t.Parallel()
+	image := testGetImage(t, "a")
+	imageProvider := newImageProvider(image)
+	pluginConfig := &PluginConfig{
+		Plugin:   "buf.build/protocolbuffers/go:v1.28.1",
+		Revision: 1,
+		Out:      "gen",
+		Opt:      "paths=source_relative",
+	}
+	key, ok, err := getCacheKey(imageProvider, image, pluginConfig, false, false)
+	require.NoError(t, err)
+	require.True(t, ok)
+	// The key is stable across invocations.
+	sameKey, ok, err := getCacheKey(imageProvider, image, pluginConfig, false, false)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, key, sameKey)
+	// The out directory has its own slot, but does not affect the requests.
+	otherOutKey, ok, err := getCacheKey(
+		imageProvider,
+		image,
+		&PluginConfig{
+			Plugin:   pluginConfig.Plugin,
+			Revision: pluginConfig.Revision,
+			Out:      "other",
+			Opt:      pluginConfig.Opt,
+		},
+		false,
+		false,
+	)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.NotEqual(t, key.slot, otherOutKey.slot)
+	assert.Equal(t, key.digest, otherOutKey.digest)
+	otherSlots := make(map[string]struct{})
+	for _, otherPluginConfig := range []*PluginConfig{
+		{
+			Plugin:   "buf.build/protocolbuffers/go:v1.28.0",
+			Revision: pluginConfig.Revision,
+			Out:      pluginConfig.Out,
+			Opt:      pluginConfig.Opt,
+		},
+		{
+			Plugin:   pluginConfig.Plugin,
+			Revision: 2,
+			Out:      pluginConfig.Out,
+			Opt:      pluginConfig.Opt,
+		},
+		{
+			Plugin:   pluginConfig.Plugin,
+			Revision: pluginConfig.Revision,
+			Out:      pluginConfig.Out,
+		},
+	} {
+		otherKey, ok, err := getCacheKey(imageProvider, image, otherPluginConfig, false, false)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.NotEqual(t, key.slot, otherKey.slot)
+		otherSlots[otherKey.slot] = struct{}{}
+	}
+	assert.Len(t, otherSlots, 3)
+	includeImportsKey, ok, err := getCacheKey(imageProvider, image, pluginConfig, true, false)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.NotEqual(t, key.slot, includeImportsKey.slot)
+	// Other requests use the same slot.
+	otherImage := testGetImage(t, "b")
+	otherImageKey, ok, err := getCacheKey(newImageProvider(otherImage), otherImage, pluginConfig, false, false)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, key.slot, otherImageKey.slot)
+	assert.NotEqual(t, key.digest, otherImageKey.digest)
+}
+
+func TestGetCacheKeyLocal(t *testing.T) {
+	t.Parallel()
+	image := testGetImage(t, "a")
+	imageProvider := newImageProvider(image)
+	binaryPath := filepath.Join(t.TempDir(), "protoc-gen-test")
+	require.NoError(t, os.WriteFile(binaryPath, []byte("one"), 0755))
+	pluginConfig := &PluginConfig{
+		Name:     "test",
+		Path:     binaryPath,
+		Out:      "gen",
+		Strategy: StrategyDirectory,
+	}
+	key, ok, err := getCacheKey(imageProvider, image, pluginConfig, false, false)
+	require.NoError(t, err)
+	require.True(t, ok)
+	// The slot changes with the content of the binary.
+	require.NoError(t, os.WriteFile(binaryPath, []byte("two"), 0755))
+	otherKey, ok, err := getCacheKey(imageProvider, image, pluginConfig, false, false)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.NotEqual(t, key.slot, otherKey.slot)
+	assert.Equal(t, key.digest, otherKey.digest)
+}
+
+func TestGetCacheKeyUnpinned(t *testing.T) {
+	t.Parallel()
+	image := testGetImage(t, "a")
+	imageProvider := newImageProvider(image)
+	for _, pluginConfig := range []*PluginConfig{
+		{
+			Plugin: "buf.build/protocolbuffers/go",
+		},
+		{
+			// The latest revision of the version is used.
+			Plugin: "buf.build/protocolbuffers/go:v1.28.1",
+		},
+		{
+			Remote: "buf.build/protocolbuffers/plugins/go",
+		},
+		{
+			Name: "this-plugin-does-not-exist",
+		},
+	} {
+		_, ok, err := getCacheKey(imageProvider, image, pluginConfig, false, false)
+		require.NoError(t, err)
+		assert.False(t, ok, pluginConfig.PluginName())
+	}
+	_, ok, err := getCacheKey(
+		imageProvider,
+		image,
+		&PluginConfig{
+			Remote: "buf.build/protocolbuffers/plugins/go:v1.28.1-1",
+		},
+		false,
+		false,
+	)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func testGetImage(t *testing.T, packageName string) bufimage.Image {
+	imageFile, err := bufimage.NewImageFile(
+		&descriptorpb.FileDescriptorProto{
+			Name:    proto.String(packageName + "/" + packageName + ".proto"),
+			Package: proto.String(packageName),
+			Syntax:  proto.String("proto3"),
+		},
+		nil,
+		"",
+		"",
+		false,
+		false,
+		nil,
+	)
+	require.NoError(t, err)
+	image, err := bufimage.NewImage([]bufimage.ImageFile{imageFile})
+	require.NoError(t, err)
+	return image
+}
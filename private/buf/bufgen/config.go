@@ -20,7 +20,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
 	"github.com/bufbuild/buf/private/bufpkg/bufremoteplugin"
@@ -137,15 +141,31 @@ func newConfigV1(externalConfig ExternalConfigV1, id string) (*Config, error) {
 		if err != nil {
 			return nil, err
 		}
+		includePaths, err := normalizeAndValidatePluginPaths(plugin.IncludePaths)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include_paths: %w", id, err)
+		}
+		excludePaths, err := normalizeAndValidatePluginPaths(plugin.ExcludePaths)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid exclude_paths: %w", id, err)
+		}
 		pluginConfig := &PluginConfig{
-			Plugin:   plugin.Plugin,
-			Revision: plugin.Revision,
-			Name:     plugin.Name,
-			Remote:   plugin.Remote,
-			Out:      plugin.Out,
-			Opt:      opt,
-			Path:     plugin.Path,
-			Strategy: strategy,
+			Plugin:       plugin.Plugin,
+			Revision:     plugin.Revision,
+			Name:         plugin.Name,
+			Remote:       plugin.Remote,
+			Out:          plugin.Out,
+			Opt:          opt,
+			Path:         plugin.Path,
+			Strategy:     strategy,
+			Clean:        plugin.Clean,
+			IncludePaths: includePaths,
+			ExcludePaths: excludePaths,
+			Types:        plugin.Types,
+		}
+		pluginConfig.Post, err = newPostCommandsV1(plugin.Post)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid post for plugin %s: %w", id, pluginConfig.PluginName(), err)
 		}
 		if pluginConfig.IsRemote() {
 			// Always use StrategyAll for remote plugins
@@ -153,9 +173,22 @@ func newConfigV1(externalConfig ExternalConfigV1, id string) (*Config, error) {
 		}
 		pluginConfigs = append(pluginConfigs, pluginConfig)
 	}
+	var inputConfigs []*InputConfig
+	for _, input := range externalConfig.Inputs {
+		inputConfigs = append(
+			inputConfigs,
+			&InputConfig{
+				Input:        input.Input,
+				Paths:        input.Paths,
+				ExcludePaths: input.ExcludePaths,
+				Types:        input.Types,
+			},
+		)
+	}
 	return &Config{
 		PluginConfigs: pluginConfigs,
 		ManagedConfig: managedConfig,
+		InputConfigs:  inputConfigs,
 	}, nil
 }
 
@@ -182,6 +215,11 @@ func validateExternalConfigV1(externalConfig ExternalConfigV1, id string) error
 		if plugin.Out == "" {
 			return fmt.Errorf("%s: plugin %s out is required", id, pluginIdentifier)
 		}
+		for _, typeName := range plugin.Types {
+			if typeName == "" {
+				return fmt.Errorf("%s: plugin %s types cannot contain an empty value", id, pluginIdentifier)
+			}
+		}
 		switch {
 		case plugin.Plugin != "":
 			if bufpluginref.IsPluginReferenceOrIdentity(pluginIdentifier) {
@@ -216,9 +254,69 @@ func validateExternalConfigV1(externalConfig ExternalConfigV1, id string) error
 			return errors.New("one of plugin, name, or remote is required")
 		}
 	}
+	for _, input := range externalConfig.Inputs {
+		if input.Input == "" {
+			return fmt.Errorf("%s: input is required for each of the inputs", id)
+		}
+		for _, typeName := range input.Types {
+			if typeName == "" {
+				return fmt.Errorf("%s: input %s types cannot contain an empty value", id, input.Input)
+			}
+		}
+	}
 	return nil
 }
 
+// normalizeAndValidatePluginPaths normalizes the include or exclude paths
+// of a plugin, which are relative to the root of the input.
+func normalizeAndValidatePluginPaths(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	normalizedPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		normalizedPath, err := normalpath.NormalizeAndValidate(path)
+		if err != nil {
+			return nil, err
+		}
+		normalizedPaths = append(normalizedPaths, normalizedPath)
+	}
+	return normalizedPaths, nil
+}
+
+// newPostCommandsV1 returns the arguments of each of the post commands.
+//
+// Each command is either a single string that is split on whitespace,
+// or a list of strings that are used as-is.
+func newPostCommandsV1(externalPost []interface{}) ([][]string, error) {
+	if len(externalPost) == 0 {
+		return nil, nil
+	}
+	post := make([][]string, 0, len(externalPost))
+	for _, externalCommand := range externalPost {
+		var args []string
+		switch t := externalCommand.(type) {
+		case string:
+			args = strings.Fields(t)
+		case []interface{}:
+			for _, elem := range t {
+				arg, ok := elem.(string)
+				if !ok {
+					return nil, fmt.Errorf("command arguments must be strings, got %v", elem)
+				}
+				args = append(args, arg)
+			}
+		default:
+			return nil, fmt.Errorf("command must be a string or a list of strings, got %v", externalCommand)
+		}
+		if len(args) == 0 || args[0] == "" {
+			return nil, errors.New("command must not be empty")
+		}
+		post = append(post, args)
+	}
+	return post, nil
+}
+
 func checkPathAndStrategyUnset(id string, plugin ExternalPluginConfigV1, pluginIdentifier string) error {
 	if plugin.Path != "" {
 		return fmt.Errorf("%s: remote plugin %s cannot specify a path", id, pluginIdentifier)
@@ -255,6 +353,38 @@ func newManagedConfigV1(externalManagedConfig ExternalManagedConfigV1) (*Managed
 	if err != nil {
 		return nil, err
 	}
+	swiftPrefixConfig, err := newSwiftPrefixConfigV1(externalManagedConfig.SwiftPrefix)
+	if err != nil {
+		return nil, err
+	}
+	phpClassPrefixConfig, err := newPhpClassPrefixConfigV1(externalManagedConfig.PhpClassPrefix)
+	if err != nil {
+		return nil, err
+	}
+	ccGenericServicesConfig, err := newGenericServicesConfigV1("cc_generic_services", externalManagedConfig.CcGenericServices)
+	if err != nil {
+		return nil, err
+	}
+	javaGenericServicesConfig, err := newGenericServicesConfigV1("java_generic_services", externalManagedConfig.JavaGenericServices)
+	if err != nil {
+		return nil, err
+	}
+	pyGenericServicesConfig, err := newGenericServicesConfigV1("py_generic_services", externalManagedConfig.PyGenericServices)
+	if err != nil {
+		return nil, err
+	}
+	jsTypeConfig, err := newJSTypeConfigV1(externalManagedConfig.JSType)
+	if err != nil {
+		return nil, err
+	}
+	fieldOptions, err := newFieldOptionOverridesV1(externalManagedConfig.FieldOptions)
+	if err != nil {
+		return nil, err
+	}
+	messageOptions, err := newMessageOptionOverridesV1(externalManagedConfig.MessageOptions)
+	if err != nil {
+		return nil, err
+	}
 	override := externalManagedConfig.Override
 	for overrideID, overrideValue := range override {
 		for importPath := range overrideValue {
@@ -282,6 +412,14 @@ func newManagedConfigV1(externalManagedConfig ExternalManagedConfigV1) (*Managed
 		JavaPackagePrefix:     javaPackagePrefixConfig,
 		OptimizeFor:           optimizeFor,
 		GoPackagePrefixConfig: goPackagePrefixConfig,
+		SwiftPrefix:           swiftPrefixConfig,
+		PhpClassPrefix:        phpClassPrefixConfig,
+		CcGenericServices:     ccGenericServicesConfig,
+		JavaGenericServices:   javaGenericServicesConfig,
+		PyGenericServices:     pyGenericServicesConfig,
+		JSType:                jsTypeConfig,
+		FieldOptions:          fieldOptions,
+		MessageOptions:        messageOptions,
 		Override:              override,
 	}, nil
 }
@@ -372,6 +510,265 @@ func newGoPackagePrefixConfigV1(externalGoPackagePrefixConfig ExternalGoPackageP
 	}, nil
 }
 
+func newSwiftPrefixConfigV1(externalSwiftPrefixConfig ExternalSwiftPrefixConfigV1) (*SwiftPrefixConfig, error) {
+	if externalSwiftPrefixConfig.IsEmpty() {
+		return nil, nil
+	}
+	if externalSwiftPrefixConfig.Default == "" {
+		return nil, errors.New("swift_prefix setting requires a default value")
+	}
+	except, overrideModuleIdentities, err := newExceptAndOverrideModuleIdentitiesV1(
+		"swift_prefix",
+		externalSwiftPrefixConfig.Except,
+		stringMapKeys(externalSwiftPrefixConfig.Override),
+	)
+	if err != nil {
+		return nil, err
+	}
+	override := make(map[bufmoduleref.ModuleIdentity]string, len(externalSwiftPrefixConfig.Override))
+	for moduleName, swiftPrefix := range externalSwiftPrefixConfig.Override {
+		override[overrideModuleIdentities[moduleName]] = swiftPrefix
+	}
+	return &SwiftPrefixConfig{
+		Default:  externalSwiftPrefixConfig.Default,
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+func newPhpClassPrefixConfigV1(externalPhpClassPrefixConfig ExternalPhpClassPrefixConfigV1) (*PhpClassPrefixConfig, error) {
+	if externalPhpClassPrefixConfig.IsEmpty() {
+		return nil, nil
+	}
+	if externalPhpClassPrefixConfig.Default == "" {
+		return nil, errors.New("php_class_prefix setting requires a default value")
+	}
+	except, overrideModuleIdentities, err := newExceptAndOverrideModuleIdentitiesV1(
+		"php_class_prefix",
+		externalPhpClassPrefixConfig.Except,
+		stringMapKeys(externalPhpClassPrefixConfig.Override),
+	)
+	if err != nil {
+		return nil, err
+	}
+	override := make(map[bufmoduleref.ModuleIdentity]string, len(externalPhpClassPrefixConfig.Override))
+	for moduleName, phpClassPrefix := range externalPhpClassPrefixConfig.Override {
+		override[overrideModuleIdentities[moduleName]] = phpClassPrefix
+	}
+	return &PhpClassPrefixConfig{
+		Default:  externalPhpClassPrefixConfig.Default,
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+func newGenericServicesConfigV1(optionName string, externalGenericServicesConfig ExternalGenericServicesConfigV1) (*GenericServicesConfig, error) {
+	if externalGenericServicesConfig.IsEmpty() {
+		return nil, nil
+	}
+	if externalGenericServicesConfig.Default == nil {
+		return nil, fmt.Errorf("%s setting requires a default value", optionName)
+	}
+	overrideModuleNames := make([]string, 0, len(externalGenericServicesConfig.Override))
+	for moduleName := range externalGenericServicesConfig.Override {
+		overrideModuleNames = append(overrideModuleNames, moduleName)
+	}
+	except, overrideModuleIdentities, err := newExceptAndOverrideModuleIdentitiesV1(
+		optionName,
+		externalGenericServicesConfig.Except,
+		overrideModuleNames,
+	)
+	if err != nil {
+		return nil, err
+	}
+	override := make(map[bufmoduleref.ModuleIdentity]bool, len(externalGenericServicesConfig.Override))
+	for moduleName, value := range externalGenericServicesConfig.Override {
+		override[overrideModuleIdentities[moduleName]] = value
+	}
+	return &GenericServicesConfig{
+		Default:  *externalGenericServicesConfig.Default,
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+func newJSTypeConfigV1(externalJSTypeConfig ExternalJSTypeConfigV1) (*JSTypeConfig, error) {
+	if externalJSTypeConfig.IsEmpty() {
+		return nil, nil
+	}
+	if externalJSTypeConfig.Default == "" {
+		return nil, errors.New("jstype setting requires a default value")
+	}
+	defaultJSType, err := parseJSTypeV1(externalJSTypeConfig.Default)
+	if err != nil {
+		return nil, err
+	}
+	except, overrideModuleIdentities, err := newExceptAndOverrideModuleIdentitiesV1(
+		"jstype",
+		externalJSTypeConfig.Except,
+		stringMapKeys(externalJSTypeConfig.Override),
+	)
+	if err != nil {
+		return nil, err
+	}
+	override := make(map[bufmoduleref.ModuleIdentity]descriptorpb.FieldOptions_JSType, len(externalJSTypeConfig.Override))
+	for moduleName, jsTypeString := range externalJSTypeConfig.Override {
+		jsType, err := parseJSTypeV1(jsTypeString)
+		if err != nil {
+			return nil, err
+		}
+		override[overrideModuleIdentities[moduleName]] = jsType
+	}
+	return &JSTypeConfig{
+		Default:  defaultJSType,
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+func parseJSTypeV1(value string) (descriptorpb.FieldOptions_JSType, error) {
+	jsType, ok := descriptorpb.FieldOptions_JSType_value[value]
+	if !ok {
+		return 0, fmt.Errorf(
+			"invalid jstype value; expected one of %v",
+			enumMapToStringSlice(descriptorpb.FieldOptions_JSType_value),
+		)
+	}
+	return descriptorpb.FieldOptions_JSType(jsType), nil
+}
+
+// newExceptAndOverrideModuleIdentitiesV1 validates the except and override module names
+// of the managed mode option with the given name. It returns the except ModuleIdentities,
+// and the ModuleIdentity for each of the override module names.
+func newFieldOptionOverridesV1(externalFieldOptionConfigs []ExternalFieldOptionConfigV1) ([]bufimagemodify.FieldOptionOverride, error) {
+	if len(externalFieldOptionConfigs) == 0 {
+		return nil, nil
+	}
+	fieldOptionOverrides := make([]bufimagemodify.FieldOptionOverride, len(externalFieldOptionConfigs))
+	for i, externalFieldOptionConfig := range externalFieldOptionConfigs {
+		value, err := parseOptionValueV1("field_options", externalFieldOptionConfig.Option, externalFieldOptionConfig.Value)
+		if err != nil {
+			return nil, err
+		}
+		var types []descriptorpb.FieldDescriptorProto_Type
+		for _, typeString := range externalFieldOptionConfig.Types {
+			fieldType, err := parseFieldTypeV1(typeString)
+			if err != nil {
+				return nil, fmt.Errorf("field_options setting for %s has an invalid type: %w", externalFieldOptionConfig.Option, err)
+			}
+			types = append(types, fieldType)
+		}
+		fieldOptionOverrides[i] = bufimagemodify.FieldOptionOverride{
+			Selector: externalFieldOptionConfig.Selector,
+			Types:    types,
+			Option:   externalFieldOptionConfig.Option,
+			Value:    value,
+		}
+	}
+	return fieldOptionOverrides, nil
+}
+
+func newMessageOptionOverridesV1(externalMessageOptionConfigs []ExternalMessageOptionConfigV1) ([]bufimagemodify.MessageOptionOverride, error) {
+	if len(externalMessageOptionConfigs) == 0 {
+		return nil, nil
+	}
+	messageOptionOverrides := make([]bufimagemodify.MessageOptionOverride, len(externalMessageOptionConfigs))
+	for i, externalMessageOptionConfig := range externalMessageOptionConfigs {
+		value, err := parseOptionValueV1("message_options", externalMessageOptionConfig.Option, externalMessageOptionConfig.Value)
+		if err != nil {
+			return nil, err
+		}
+		messageOptionOverrides[i] = bufimagemodify.MessageOptionOverride{
+			Selector: externalMessageOptionConfig.Selector,
+			Option:   externalMessageOptionConfig.Option,
+			Value:    value,
+		}
+	}
+	return messageOptionOverrides, nil
+}
+
+// parseOptionValueV1 validates the option, and returns the string form of its value.
+func parseOptionValueV1(settingName string, option string, value interface{}) (string, error) {
+	if option == "" {
+		return "", fmt.Errorf("%s setting requires an option", settingName)
+	}
+	switch t := value.(type) {
+	case string:
+		return t, nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case int:
+		return strconv.Itoa(t), nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case nil:
+		return "", fmt.Errorf("%s setting for %s requires a value", settingName, option)
+	default:
+		return "", fmt.Errorf("%s setting for %s has an invalid value of type %T", settingName, option, value)
+	}
+}
+
+// parseFieldTypeV1 parses the field type, which is either the name of
+// the type as used in a .proto file, such as "int64", or the name of the
+// FieldDescriptorProto_Type value, such as "TYPE_INT64".
+func parseFieldTypeV1(value string) (descriptorpb.FieldDescriptorProto_Type, error) {
+	fieldType, ok := descriptorpb.FieldDescriptorProto_Type_value[value]
+	if !ok {
+		fieldType, ok = descriptorpb.FieldDescriptorProto_Type_value["TYPE_"+strings.ToUpper(value)]
+	}
+	if !ok {
+		return 0, fmt.Errorf(
+			"invalid field type %q; expected one of %v",
+			value,
+			enumMapToStringSlice(descriptorpb.FieldDescriptorProto_Type_value),
+		)
+	}
+	return descriptorpb.FieldDescriptorProto_Type(fieldType), nil
+}
+
+func newExceptAndOverrideModuleIdentitiesV1(
+	optionName string,
+	exceptModuleNames []string,
+	overrideModuleNames []string,
+) ([]bufmoduleref.ModuleIdentity, map[string]bufmoduleref.ModuleIdentity, error) {
+	seenModuleIdentities := make(map[string]struct{}, len(exceptModuleNames))
+	except := make([]bufmoduleref.ModuleIdentity, 0, len(exceptModuleNames))
+	for _, moduleName := range exceptModuleNames {
+		moduleIdentity, err := bufmoduleref.ModuleIdentityForString(moduleName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s except: %w", optionName, err)
+		}
+		if _, ok := seenModuleIdentities[moduleIdentity.IdentityString()]; ok {
+			return nil, nil, fmt.Errorf("invalid %s except: %q is defined multiple times", optionName, moduleIdentity.IdentityString())
+		}
+		seenModuleIdentities[moduleIdentity.IdentityString()] = struct{}{}
+		except = append(except, moduleIdentity)
+	}
+	// Sort so that errors are deterministic.
+	sort.Strings(overrideModuleNames)
+	overrideModuleIdentities := make(map[string]bufmoduleref.ModuleIdentity, len(overrideModuleNames))
+	for _, moduleName := range overrideModuleNames {
+		moduleIdentity, err := bufmoduleref.ModuleIdentityForString(moduleName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s override key: %w", optionName, err)
+		}
+		if _, ok := seenModuleIdentities[moduleIdentity.IdentityString()]; ok {
+			return nil, nil, fmt.Errorf("invalid %s override: %q is already defined as an except", optionName, moduleIdentity.IdentityString())
+		}
+		seenModuleIdentities[moduleIdentity.IdentityString()] = struct{}{}
+		overrideModuleIdentities[moduleName] = moduleIdentity
+	}
+	return except, overrideModuleIdentities, nil
+}
+
+func stringMapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func newConfigV1Beta1(externalConfig ExternalConfigV1Beta1, id string) (*Config, error) {
 	managedConfig, err := newManagedConfigV1Beta1(externalConfig.Options, externalConfig.Managed)
 	if err != nil {
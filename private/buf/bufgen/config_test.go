@@ -24,6 +24,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/descriptorpb"
@@ -189,10 +190,14 @@ func TestReadConfigV1(t *testing.T) {
 		},
 		PluginConfigs: []*PluginConfig{
 			{
-				Name:     "go",
-				Out:      "gen/go",
-				Path:     "/path/to/foo",
-				Strategy: StrategyAll,
+				Name:         "go",
+				Out:          "gen/go",
+				Path:         "/path/to/foo",
+				Strategy:     StrategyAll,
+				Clean:        true,
+				IncludePaths: []string{"foo"},
+				ExcludePaths: []string{"foo/internal"},
+				Types:        []string{"acme.foo.v1", "acme.bar.v1.Bar"},
 			},
 		},
 	}
@@ -236,6 +241,127 @@ func TestReadConfigV1(t *testing.T) {
 			},
 		},
 	}
+	successConfig7 := &Config{
+		ManagedConfig: &ManagedConfig{
+			SwiftPrefix: &SwiftPrefixConfig{
+				Default:  "ACME",
+				Except:   make([]bufmoduleref.ModuleIdentity, 0),
+				Override: make(map[bufmoduleref.ModuleIdentity]string),
+			},
+			PhpClassPrefix: &PhpClassPrefixConfig{
+				Default:  "ACME",
+				Except:   []bufmoduleref.ModuleIdentity{moduleIdentity},
+				Override: make(map[bufmoduleref.ModuleIdentity]string),
+			},
+			CcGenericServices: &GenericServicesConfig{
+				Default:  true,
+				Except:   make([]bufmoduleref.ModuleIdentity, 0),
+				Override: make(map[bufmoduleref.ModuleIdentity]bool),
+			},
+			JavaGenericServices: &GenericServicesConfig{
+				Default:  true,
+				Except:   []bufmoduleref.ModuleIdentity{moduleIdentity},
+				Override: make(map[bufmoduleref.ModuleIdentity]bool),
+			},
+			PyGenericServices: &GenericServicesConfig{
+				Default:  false,
+				Except:   make([]bufmoduleref.ModuleIdentity, 0),
+				Override: make(map[bufmoduleref.ModuleIdentity]bool),
+			},
+			JSType: &JSTypeConfig{
+				Default:  descriptorpb.FieldOptions_JS_STRING,
+				Except:   make([]bufmoduleref.ModuleIdentity, 0),
+				Override: make(map[bufmoduleref.ModuleIdentity]descriptorpb.FieldOptions_JSType),
+			},
+		},
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+	}
+	successConfig8 := &Config{
+		ManagedConfig: &ManagedConfig{
+			FieldOptions: []bufimagemodify.FieldOptionOverride{
+				{
+					Selector: "acme.weather.v1",
+					Types: []descriptorpb.FieldDescriptorProto_Type{
+						descriptorpb.FieldDescriptorProto_TYPE_INT64,
+						descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+					},
+					Option: "jstype",
+					Value:  "JS_STRING",
+				},
+				{
+					Option: "(acme.option.v1.max_length)",
+					Value:  "64",
+				},
+			},
+			MessageOptions: []bufimagemodify.MessageOptionOverride{
+				{
+					Selector: "acme.weather.v1.Weather",
+					Option:   "deprecated",
+					Value:    "true",
+				},
+			},
+		},
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+	}
+	successConfig9 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+				Post: [][]string{
+					{"gofmt", "-w"},
+					{"goimports", "-local", "github.com/acme/weather", "-w"},
+				},
+			},
+		},
+	}
+	successConfig10 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+		InputConfigs: []*InputConfig{
+			{
+				Input:        "proto",
+				Paths:        []string{"proto/acme/weather/v1"},
+				ExcludePaths: []string{"proto/acme/weather/v1/internal"},
+				Types:        []string{"acme.weather.v1"},
+			},
+			{
+				Input: "buf.build/acme/petapis",
+			},
+		},
+	}
+	successConfig11 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "grpc-gateway",
+				Out:      "gen/go",
+				Strategy: StrategyPackage,
+			},
+			{
+				Name:     "python",
+				Out:      "gen/python",
+				Strategy: StrategyFile,
+			},
+		},
+	}
 
 	ctx := context.Background()
 	provider := NewProvider(zap.NewNop())
@@ -370,6 +496,36 @@ func TestReadConfigV1(t *testing.T) {
 	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(string(data)))
 	require.NoError(t, err)
 	require.Equal(t, successConfig6, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success7.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig7, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success7.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig7, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success8.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig8, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success8.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig8, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success9.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig9, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success9.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig9, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success10.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig10, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success10.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig10, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success11.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig11, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success11.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig11, config)
 
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error1.yaml"))
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error2.yaml"))
@@ -380,6 +536,17 @@ func TestReadConfigV1(t *testing.T) {
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error7.yaml"))
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error8.yaml"))
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error9.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error10.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error11.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error12.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error13.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error14.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error15.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error16.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error17.yaml"))
+	_, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_error15.yaml")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid post for plugin go")
 
 	successConfig = &Config{
 		PluginConfigs: []*PluginConfig{
@@ -427,6 +594,55 @@ func TestReadConfigV1(t *testing.T) {
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "go_gen_error7.yaml"))
 }
 
+func TestReadConfigV1ManagedModuleOverrides(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	config, err := ReadConfig(
+		ctx,
+		provider,
+		readBucket,
+		ReadConfigWithOverride(`version: v1
+managed:
+  enabled: true
+  swift_prefix:
+    default: ACME
+    override:
+      someremote.com/owner/repo: REPO
+  py_generic_services:
+    default: false
+    override:
+      someremote.com/owner/repo: true
+  jstype:
+    default: JS_STRING
+    override:
+      someremote.com/owner/repo: JS_NUMBER
+plugins:
+  - name: go
+    out: gen/go
+`),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, config.ManagedConfig)
+	require.Len(t, config.ManagedConfig.SwiftPrefix.Override, 1)
+	for moduleIdentity, value := range config.ManagedConfig.SwiftPrefix.Override {
+		assert.Equal(t, "someremote.com/owner/repo", moduleIdentity.IdentityString())
+		assert.Equal(t, "REPO", value)
+	}
+	require.Len(t, config.ManagedConfig.PyGenericServices.Override, 1)
+	for moduleIdentity, value := range config.ManagedConfig.PyGenericServices.Override {
+		assert.Equal(t, "someremote.com/owner/repo", moduleIdentity.IdentityString())
+		assert.True(t, value)
+	}
+	require.Len(t, config.ManagedConfig.JSType.Override, 1)
+	for moduleIdentity, value := range config.ManagedConfig.JSType.Override {
+		assert.Equal(t, "someremote.com/owner/repo", moduleIdentity.IdentityString())
+		assert.Equal(t, descriptorpb.FieldOptions_JS_NUMBER, value)
+	}
+}
+
 func testReadConfigError(t *testing.T, provider Provider, readBucket storage.ReadBucket, testFilePath string) {
 	ctx := context.Background()
 	_, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(testFilePath))
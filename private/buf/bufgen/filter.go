@@ -0,0 +1,223 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// filterImageForPlugin returns the subset of the image that is sent to the plugin,
+// as specified by the include_paths, exclude_paths, and types of the plugin.
+//
+// Returns false if the plugin does not filter the image, in which case the
+// given image is returned. Returns a nil image if no files within the image
+// match the include_paths and exclude_paths of the plugin.
+func filterImageForPlugin(image bufimage.Image, pluginConfig *PluginConfig) (bufimage.Image, bool, error) {
+	if len(pluginConfig.IncludePaths) == 0 && len(pluginConfig.ExcludePaths) == 0 && len(pluginConfig.Types) == 0 {
+		return image, false, nil
+	}
+	var err error
+	if len(pluginConfig.IncludePaths) > 0 || len(pluginConfig.ExcludePaths) > 0 {
+		if !hasImageFilesWithinPaths(image, pluginConfig.IncludePaths, pluginConfig.ExcludePaths) {
+			return nil, true, nil
+		}
+		// The input may already have been narrowed with --path, in which case
+		// the paths of the plugin may legitimately not exist within the image.
+		image, err = bufimage.ImageWithOnlyPathsAllowNotExist(
+			image,
+			pluginConfig.IncludePaths,
+			pluginConfig.ExcludePaths,
+		)
+		if err != nil {
+			return nil, false, fmt.Errorf("plugin %s: %w", pluginConfig.PluginName(), err)
+		}
+	}
+	if len(pluginConfig.Types) > 0 {
+		image, err = filterImageByTypes(image, pluginConfig.Types)
+		if err != nil {
+			return nil, false, fmt.Errorf("plugin %s: %w", pluginConfig.PluginName(), err)
+		}
+	}
+	return image, true, nil
+}
+
+// FilterImageForInput returns the subset of the image that is generated
+// for the input, as specified by the types of the input.
+//
+// The paths and exclude paths of the input are expected to have been
+// applied when the image was built. The given image is not modified.
+func FilterImageForInput(image bufimage.Image, inputConfig *InputConfig) (bufimage.Image, error) {
+	if len(inputConfig.Types) == 0 {
+		return image, nil
+	}
+	image, err := filterImageByTypes(image, inputConfig.Types)
+	if err != nil {
+		return nil, fmt.Errorf("input %s: %w", inputConfig.Input, err)
+	}
+	return image, nil
+}
+
+// filterImageByTypes returns a copy of the image that only contains the
+// types, or all of the types within the packages, and their dependencies.
+func filterImageByTypes(image bufimage.Image, types []string) (bufimage.Image, error) {
+	typeNames := getTypeNames(image, types)
+	if len(typeNames) == 0 {
+		return nil, fmt.Errorf("no types found for %s", strings.Join(types, ", "))
+	}
+	// ImageFilteredByTypes modifies the files of the image in place,
+	// and the image may be shared, for example between all plugins.
+	image, err := cloneImage(image)
+	if err != nil {
+		return nil, err
+	}
+	return bufimageutil.ImageFilteredByTypes(image, typeNames...)
+}
+
+// hasImageFilesWithinPaths returns true if any non-import file of the image is
+// within the include paths, if any, and not within the exclude paths.
+func hasImageFilesWithinPaths(image bufimage.Image, includePaths []string, excludePaths []string) bool {
+	includePathMap := stringutil.SliceToMap(includePaths)
+	excludePathMap := stringutil.SliceToMap(excludePaths)
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			continue
+		}
+		if len(includePaths) > 0 && !normalpath.MapHasEqualOrContainingPath(includePathMap, imageFile.Path(), normalpath.Relative) {
+			continue
+		}
+		if normalpath.MapHasEqualOrContainingPath(excludePathMap, imageFile.Path(), normalpath.Relative) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// getTypeNames expands the types of a plugin or input into the fully-qualified
+// type names accepted by bufimageutil.ImageFilteredByTypes.
+//
+// A value that is the fully-qualified name of a message, enum, or service is
+// used as-is. Otherwise, if the value is a package or a package prefix, it is
+// expanded to all the top-level types within the matching non-import files.
+// Any other value is passed through so that ImageFilteredByTypes reports it.
+func getTypeNames(image bufimage.Image, types []string) []string {
+	typeNameToDeclared := make(map[string]struct{})
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			continue
+		}
+		fileDescriptorProto := imageFile.Proto()
+		prefix := getTypeNamePrefix(fileDescriptorProto)
+		addMessageTypeNames(typeNameToDeclared, prefix, fileDescriptorProto.GetMessageType())
+		for _, enumDescriptorProto := range fileDescriptorProto.GetEnumType() {
+			typeNameToDeclared[prefix+enumDescriptorProto.GetName()] = struct{}{}
+		}
+		for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+			typeNameToDeclared[prefix+serviceDescriptorProto.GetName()] = struct{}{}
+		}
+	}
+	var typeNames []string
+	seen := make(map[string]struct{})
+	addTypeName := func(typeName string) {
+		if _, ok := seen[typeName]; !ok {
+			seen[typeName] = struct{}{}
+			typeNames = append(typeNames, typeName)
+		}
+	}
+	for _, typeName := range types {
+		if _, ok := typeNameToDeclared[typeName]; ok {
+			addTypeName(typeName)
+			continue
+		}
+		var isPackage bool
+		for _, imageFile := range image.Files() {
+			if imageFile.IsImport() {
+				continue
+			}
+			fileDescriptorProto := imageFile.Proto()
+			packageName := fileDescriptorProto.GetPackage()
+			if packageName != typeName && !strings.HasPrefix(packageName, typeName+".") {
+				continue
+			}
+			isPackage = true
+			prefix := getTypeNamePrefix(fileDescriptorProto)
+			for _, descriptorProto := range fileDescriptorProto.GetMessageType() {
+				addTypeName(prefix + descriptorProto.GetName())
+			}
+			for _, enumDescriptorProto := range fileDescriptorProto.GetEnumType() {
+				addTypeName(prefix + enumDescriptorProto.GetName())
+			}
+			for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+				addTypeName(prefix + serviceDescriptorProto.GetName())
+			}
+		}
+		if !isPackage {
+			addTypeName(typeName)
+		}
+	}
+	return typeNames
+}
+
+func addMessageTypeNames(
+	typeNameToDeclared map[string]struct{},
+	prefix string,
+	descriptorProtos []*descriptorpb.DescriptorProto,
+) {
+	for _, descriptorProto := range descriptorProtos {
+		typeName := prefix + descriptorProto.GetName()
+		typeNameToDeclared[typeName] = struct{}{}
+		for _, enumDescriptorProto := range descriptorProto.GetEnumType() {
+			typeNameToDeclared[typeName+"."+enumDescriptorProto.GetName()] = struct{}{}
+		}
+		addMessageTypeNames(typeNameToDeclared, typeName+".", descriptorProto.GetNestedType())
+	}
+}
+
+func getTypeNamePrefix(fileDescriptorProto *descriptorpb.FileDescriptorProto) string {
+	if packageName := fileDescriptorProto.GetPackage(); packageName != "" {
+		return packageName + "."
+	}
+	return ""
+}
+
+// cloneImage returns a deep copy of the image.
+func cloneImage(image bufimage.Image) (bufimage.Image, error) {
+	imageFiles := image.Files()
+	clonedImageFiles := make([]bufimage.ImageFile, len(imageFiles))
+	for i, imageFile := range imageFiles {
+		clonedImageFile, err := bufimage.NewImageFile(
+			proto.Clone(imageFile.Proto()).(*descriptorpb.FileDescriptorProto),
+			imageFile.ModuleIdentity(),
+			imageFile.Commit(),
+			imageFile.ExternalPath(),
+			imageFile.IsImport(),
+			imageFile.IsSyntaxUnspecified(),
+			imageFile.UnusedDependencyIndexes(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		clonedImageFiles[i] = clonedImageFile
+	}
+	return bufimage.NewImage(clonedImageFiles)
+}
@@ -0,0 +1,205 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFilterImageForPluginUnfiltered(t *testing.T) {
+	t.Parallel()
+	image := testGetFilterImage(t)
+	filteredImage, filtered, err := filterImageForPlugin(image, &PluginConfig{Name: "test"})
+	require.NoError(t, err)
+	assert.False(t, filtered)
+	assert.Equal(t, image, filteredImage)
+}
+
+func TestFilterImageForPluginPaths(t *testing.T) {
+	t.Parallel()
+	image := testGetFilterImage(t)
+	filteredImage, filtered, err := filterImageForPlugin(
+		image,
+		&PluginConfig{
+			Name:         "test",
+			IncludePaths: []string{"acme"},
+			ExcludePaths: []string{"acme/priv"},
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, filtered)
+	assert.Equal(t, []string{"acme/pub/v1/pub.proto"}, testGetNonImportPaths(filteredImage))
+	// The dependencies of the included files are still sent as imports.
+	require.NotNil(t, filteredImage.GetFile("acme/priv/v1/priv.proto"))
+	assert.True(t, filteredImage.GetFile("acme/priv/v1/priv.proto").IsImport())
+
+	filteredImage, filtered, err = filterImageForPlugin(
+		image,
+		&PluginConfig{
+			Name:         "test",
+			IncludePaths: []string{"other"},
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, filtered)
+	assert.Nil(t, filteredImage)
+}
+
+func TestFilterImageForPluginTypes(t *testing.T) {
+	t.Parallel()
+	image := testGetFilterImage(t)
+	filteredImage, filtered, err := filterImageForPlugin(
+		image,
+		&PluginConfig{
+			Name:  "test",
+			Types: []string{"acme.pub"},
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, filtered)
+	assert.Equal(t, []string{"acme/priv/v1/priv.proto", "acme/pub/v1/pub.proto"}, testGetNonImportPaths(filteredImage))
+	// Only the types that acme.pub.v1.Pub depends on are kept.
+	assert.Equal(t, []string{"Shared"}, testGetMessageNames(filteredImage.GetFile("acme/priv/v1/priv.proto")))
+	// The image shared with the other plugins is not modified.
+	assert.Equal(t, []string{"Shared", "Secret"}, testGetMessageNames(image.GetFile("acme/priv/v1/priv.proto")))
+
+	filteredImage, filtered, err = filterImageForPlugin(
+		image,
+		&PluginConfig{
+			Name:  "test",
+			Types: []string{"acme.priv.v1.Secret"},
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, filtered)
+	assert.Equal(t, []string{"acme/priv/v1/priv.proto"}, testGetNonImportPaths(filteredImage))
+	assert.Equal(t, []string{"Secret"}, testGetMessageNames(filteredImage.GetFile("acme/priv/v1/priv.proto")))
+
+	_, _, err = filterImageForPlugin(
+		image,
+		&PluginConfig{
+			Name:  "test",
+			Types: []string{"acme.other"},
+		},
+	)
+	require.Error(t, err)
+}
+
+func TestFilterImageForInput(t *testing.T) {
+	t.Parallel()
+	image := testGetFilterImage(t)
+	filteredImage, err := FilterImageForInput(image, &InputConfig{Input: "."})
+	require.NoError(t, err)
+	assert.Equal(t, image, filteredImage)
+
+	filteredImage, err = FilterImageForInput(
+		image,
+		&InputConfig{
+			Input: ".",
+			Types: []string{"acme.pub.v1.Pub"},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme/priv/v1/priv.proto", "acme/pub/v1/pub.proto"}, testGetNonImportPaths(filteredImage))
+	assert.Equal(t, []string{"Shared"}, testGetMessageNames(filteredImage.GetFile("acme/priv/v1/priv.proto")))
+	assert.Equal(t, []string{"Shared", "Secret"}, testGetMessageNames(image.GetFile("acme/priv/v1/priv.proto")))
+
+	_, err = FilterImageForInput(
+		image,
+		&InputConfig{
+			Input: ".",
+			Types: []string{"acme.other"},
+		},
+	)
+	require.Error(t, err)
+}
+
+func testGetFilterImage(t *testing.T) bufimage.Image {
+	privImageFile, err := bufimage.NewImageFile(
+		&descriptorpb.FileDescriptorProto{
+			Name:    proto.String("acme/priv/v1/priv.proto"),
+			Package: proto.String("acme.priv.v1"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{
+				{Name: proto.String("Shared")},
+				{Name: proto.String("Secret")},
+			},
+		},
+		nil,
+		"",
+		"",
+		false,
+		false,
+		nil,
+	)
+	require.NoError(t, err)
+	pubImageFile, err := bufimage.NewImageFile(
+		&descriptorpb.FileDescriptorProto{
+			Name:       proto.String("acme/pub/v1/pub.proto"),
+			Package:    proto.String("acme.pub.v1"),
+			Syntax:     proto.String("proto3"),
+			Dependency: []string{"acme/priv/v1/priv.proto"},
+			MessageType: []*descriptorpb.DescriptorProto{
+				{
+					Name: proto.String("Pub"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						{
+							Name:     proto.String("shared"),
+							Number:   proto.Int32(1),
+							Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+							Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+							TypeName: proto.String(".acme.priv.v1.Shared"),
+							JsonName: proto.String("shared"),
+						},
+					},
+				},
+			},
+		},
+		nil,
+		"",
+		"",
+		false,
+		false,
+		nil,
+	)
+	require.NoError(t, err)
+	image, err := bufimage.NewImage([]bufimage.ImageFile{privImageFile, pubImageFile})
+	require.NoError(t, err)
+	return image
+}
+
+func testGetNonImportPaths(image bufimage.Image) []string {
+	var paths []string
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() {
+			paths = append(paths, imageFile.Path())
+		}
+	}
+	return paths
+}
+
+func testGetMessageNames(imageFile bufimage.ImageFile) []string {
+	var names []string
+	for _, descriptorProto := range imageFile.Proto().GetMessageType() {
+		names = append(names, descriptorProto.GetName())
+	}
+	return names
+}
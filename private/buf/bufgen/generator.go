@@ -32,6 +32,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoexec"
 	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoos"
 	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/thread"
 	"go.uber.org/multierr"
@@ -42,6 +43,7 @@ import (
 type generator struct {
 	logger                *zap.Logger
 	storageosProvider     storageos.Provider
+	runner                command.Runner
 	appprotoexecGenerator appprotoexec.Generator
 	registryProvider      registryv1alpha1apiclient.Provider
 }
@@ -55,6 +57,7 @@ func newGenerator(
 	return &generator{
 		logger:                logger,
 		storageosProvider:     storageosProvider,
+		runner:                runner,
 		appprotoexecGenerator: appprotoexec.NewGenerator(logger, storageosProvider, runner),
 		registryProvider:      registryProvider,
 	}
@@ -77,6 +80,18 @@ func newGenerator(
 //
 // This behavior is equivalent to protoc, which only writes out the content
 // for each of the plugins if all of the plugins are successful.
+//
+// If a cache bucket is provided, each CodeGeneratorResponse is stored in the
+// cache keyed on the plugin identity, plugin options, and a digest of the
+// CodeGeneratorRequests. Subsequent invocations with the same inputs replay
+// the cached response instead of executing the plugin.
+//
+// If check is set, the CodeGeneratorResponses are compared to the content on
+// disk instead of being written, and a diff is written to stdout.
+//
+// Otherwise, once all of the CodeGeneratorResponses are written, the post
+// commands of each plugin are run in the order the plugins are specified,
+// with the paths of the files generated by the plugin as arguments.
 func (g *generator) Generate(
 	ctx context.Context,
 	container app.EnvStdioContainer,
@@ -96,6 +111,10 @@ func (g *generator) Generate(
 		generateOptions.baseOutDirPath,
 		generateOptions.includeImports,
 		generateOptions.includeWellKnownTypes,
+		generateOptions.clean,
+		generateOptions.check,
+		generateOptions.cacheBucket,
+		generateOptions.generatedFilesFunc,
 	)
 }
 
@@ -107,10 +126,18 @@ func (g *generator) generate(
 	baseOutDirPath string,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	clean bool,
+	check bool,
+	cacheBucket storage.ReadWriteBucket,
+	generatedFilesFunc func([]*GeneratedFiles) error,
 ) error {
 	if err := modifyImage(ctx, g.logger, config, image); err != nil {
 		return err
 	}
+	var responseCache *responseCache
+	if cacheBucket != nil {
+		responseCache = newResponseCache(g.logger, cacheBucket)
+	}
 	responses, err := g.execPlugins(
 		ctx,
 		container,
@@ -119,6 +146,7 @@ func (g *generator) generate(
 		image,
 		includeImports,
 		includeWellKnownTypes,
+		responseCache,
 	)
 	if err != nil {
 		return err
@@ -129,26 +157,64 @@ func (g *generator) generate(
 		g.storageosProvider,
 		appprotoos.ResponseWriterWithCreateOutDirIfNotExists(),
 	)
+	outs := make([]string, len(config.PluginConfigs))
 	for i, pluginConfig := range config.PluginConfigs {
 		out := pluginConfig.Out
 		if baseOutDirPath != "" && baseOutDirPath != "." {
 			out = filepath.Join(baseOutDirPath, out)
 		}
+		outs[i] = out
 		response := responses[i]
 		if response == nil {
 			return fmt.Errorf("failed to get plugin response for %s", pluginConfig.PluginName())
 		}
+		var addResponseOptions []appprotoos.AddResponseOption
+		if clean || pluginConfig.Clean {
+			addResponseOptions = append(addResponseOptions, appprotoos.AddResponseWithClean())
+		}
 		if err := responseWriter.AddResponse(
 			ctx,
 			response,
 			out,
+			addResponseOptions...,
 		); err != nil {
 			return fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
 		}
 	}
+	if check {
+		for _, pluginConfig := range config.PluginConfigs {
+			if len(pluginConfig.Post) > 0 {
+				g.logger.Sugar().Warnf(
+					"plugin %s: post commands are not run with check, so the generated files are compared as written by the plugin",
+					pluginConfig.PluginName(),
+				)
+			}
+		}
+		diffPresent, err := responseWriter.Diff(g.runner, container.Stdout())
+		if err != nil {
+			return err
+		}
+		if diffPresent {
+			return ErrGeneratedOutOfDate
+		}
+		return nil
+	}
 	if err := responseWriter.Close(); err != nil {
 		return err
 	}
+	// The post commands are run once all of the responses are written,
+	// as a plugin may insert content into the files of another plugin.
+	allGeneratedFiles := make([]*GeneratedFiles, len(config.PluginConfigs))
+	for i, pluginConfig := range config.PluginConfigs {
+		generatedFiles := newGeneratedFiles(pluginConfig, outs[i], responses[i])
+		if err := runPostCommands(ctx, g.logger, g.runner, container, pluginConfig, generatedFiles); err != nil {
+			return err
+		}
+		allGeneratedFiles[i] = generatedFiles
+	}
+	if generatedFilesFunc != nil {
+		return generatedFilesFunc(allGeneratedFiles)
+	}
 	return nil
 }
 
@@ -160,6 +226,7 @@ func (g *generator) execPlugins(
 	image bufimage.Image,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	responseCache *responseCache,
 ) ([]*pluginpb.CodeGeneratorResponse, error) {
 	imageProvider := newImageProvider(image)
 	// Collect all of the plugin jobs so that they can be executed in parallel.
@@ -168,40 +235,23 @@ func (g *generator) execPlugins(
 	for i, pluginConfig := range config.PluginConfigs {
 		index := i
 		currentPluginConfig := pluginConfig
-		if pluginConfig.IsRemote() {
-			jobs = append(jobs, func(ctx context.Context) error {
-				response, err := g.execRemotePlugin(
-					ctx,
-					container,
-					image,
-					currentPluginConfig,
-					includeImports,
-					includeWellKnownTypes,
-				)
-				if err != nil {
-					return err
-				}
-				responses[index] = response
-				return nil
-			})
-		} else {
-			jobs = append(jobs, func(ctx context.Context) error {
-				response, err := g.execLocalPlugin(
-					ctx,
-					container,
-					g.appprotoexecGenerator,
-					imageProvider,
-					currentPluginConfig,
-					includeImports,
-					includeWellKnownTypes,
-				)
-				if err != nil {
-					return err
-				}
-				responses[index] = response
-				return nil
-			})
-		}
+		jobs = append(jobs, func(ctx context.Context) error {
+			response, err := g.execPlugin(
+				ctx,
+				container,
+				imageProvider,
+				image,
+				currentPluginConfig,
+				includeImports,
+				includeWellKnownTypes,
+				responseCache,
+			)
+			if err != nil {
+				return err
+			}
+			responses[index] = response
+			return nil
+		})
 	}
 	// We execute all of the jobs in parallel, but apply them in order so that any
 	// insertion points are handled correctly.
@@ -233,6 +283,77 @@ func (g *generator) execPlugins(
 	return responses, nil
 }
 
+// execPlugin executes the plugin, replaying the response from the
+// responseCache instead if the plugin has been executed with the
+// same inputs before.
+//
+// The responseCache may be nil, in which case the plugin is always executed.
+func (g *generator) execPlugin(
+	ctx context.Context,
+	container app.EnvStdioContainer,
+	imageProvider *imageProvider,
+	image bufimage.Image,
+	pluginConfig *PluginConfig,
+	includeImports bool,
+	includeWellKnownTypes bool,
+	responseCache *responseCache,
+) (*pluginpb.CodeGeneratorResponse, error) {
+	pluginImage, filtered, err := filterImageForPlugin(image, pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	if filtered {
+		if pluginImage == nil {
+			// Nothing within the input matched the filters of the plugin,
+			// so there is nothing to generate.
+			return &pluginpb.CodeGeneratorResponse{}, nil
+		}
+		image = pluginImage
+		imageProvider = newImageProvider(pluginImage)
+	}
+	var responseCacheKey *cacheKey
+	if responseCache != nil {
+		key, ok, err := getCacheKey(imageProvider, image, pluginConfig, includeImports, includeWellKnownTypes)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if response := responseCache.Get(ctx, key); response != nil {
+				return response, nil
+			}
+			responseCacheKey = key
+		}
+	}
+	var response *pluginpb.CodeGeneratorResponse
+	if pluginConfig.IsRemote() {
+		response, err = g.execRemotePlugin(
+			ctx,
+			container,
+			image,
+			pluginConfig,
+			includeImports,
+			includeWellKnownTypes,
+		)
+	} else {
+		response, err = g.execLocalPlugin(
+			ctx,
+			container,
+			g.appprotoexecGenerator,
+			imageProvider,
+			pluginConfig,
+			includeImports,
+			includeWellKnownTypes,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if responseCacheKey != nil {
+		responseCache.Put(ctx, responseCacheKey, response)
+	}
+	return response, nil
+}
+
 func (g *generator) execLocalPlugin(
 	ctx context.Context,
 	container app.EnvStdioContainer,
@@ -506,6 +627,112 @@ func newModifier(
 			goPackageModifier,
 		)
 	}
+	if managedConfig.SwiftPrefix != nil {
+		swiftPrefixModifier, err := bufimagemodify.SwiftPrefix(
+			logger,
+			sweeper,
+			managedConfig.SwiftPrefix.Default,
+			managedConfig.SwiftPrefix.Except,
+			managedConfig.SwiftPrefix.Override,
+			managedConfig.Override[bufimagemodify.SwiftPrefixID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct swift_prefix modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, swiftPrefixModifier)
+	}
+	if managedConfig.PhpClassPrefix != nil {
+		phpClassPrefixModifier, err := bufimagemodify.PhpClassPrefix(
+			logger,
+			sweeper,
+			managedConfig.PhpClassPrefix.Default,
+			managedConfig.PhpClassPrefix.Except,
+			managedConfig.PhpClassPrefix.Override,
+			managedConfig.Override[bufimagemodify.PhpClassPrefixID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct php_class_prefix modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, phpClassPrefixModifier)
+	}
+	if managedConfig.CcGenericServices != nil {
+		ccGenericServicesModifier, err := bufimagemodify.CcGenericServices(
+			logger,
+			sweeper,
+			managedConfig.CcGenericServices.Default,
+			managedConfig.CcGenericServices.Except,
+			managedConfig.CcGenericServices.Override,
+			managedConfig.Override[bufimagemodify.CcGenericServicesID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct cc_generic_services modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, ccGenericServicesModifier)
+	}
+	if managedConfig.JavaGenericServices != nil {
+		javaGenericServicesModifier, err := bufimagemodify.JavaGenericServices(
+			logger,
+			sweeper,
+			managedConfig.JavaGenericServices.Default,
+			managedConfig.JavaGenericServices.Except,
+			managedConfig.JavaGenericServices.Override,
+			managedConfig.Override[bufimagemodify.JavaGenericServicesID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct java_generic_services modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, javaGenericServicesModifier)
+	}
+	if managedConfig.PyGenericServices != nil {
+		pyGenericServicesModifier, err := bufimagemodify.PyGenericServices(
+			logger,
+			sweeper,
+			managedConfig.PyGenericServices.Default,
+			managedConfig.PyGenericServices.Except,
+			managedConfig.PyGenericServices.Override,
+			managedConfig.Override[bufimagemodify.PyGenericServicesID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct py_generic_services modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, pyGenericServicesModifier)
+	}
+	if managedConfig.JSType != nil {
+		jsTypeModifier, err := bufimagemodify.JSType(
+			logger,
+			sweeper,
+			managedConfig.JSType.Default,
+			managedConfig.JSType.Except,
+			managedConfig.JSType.Override,
+			managedConfig.Override[bufimagemodify.JSTypeID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct jstype modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, jsTypeModifier)
+	}
+	if len(managedConfig.FieldOptions) > 0 {
+		fieldOptionsModifier, err := bufimagemodify.FieldOptions(
+			logger,
+			sweeper,
+			managedConfig.FieldOptions,
+		)
+		if err != nil {
+			return nil, err
+		}
+		modifier = bufimagemodify.Merge(modifier, fieldOptionsModifier)
+	}
+	if len(managedConfig.MessageOptions) > 0 {
+		messageOptionsModifier, err := bufimagemodify.MessageOptions(
+			logger,
+			sweeper,
+			managedConfig.MessageOptions,
+		)
+		if err != nil {
+			return nil, err
+		}
+		modifier = bufimagemodify.Merge(modifier, messageOptionsModifier)
+	}
 	return modifier, nil
 }
 
@@ -544,6 +771,10 @@ type generateOptions struct {
 	baseOutDirPath        string
 	includeImports        bool
 	includeWellKnownTypes bool
+	clean                 bool
+	check                 bool
+	cacheBucket           storage.ReadWriteBucket
+	generatedFilesFunc    func([]*GeneratedFiles) error
 }
 
 func newGenerateOptions() *generateOptions {
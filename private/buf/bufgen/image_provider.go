@@ -24,13 +24,15 @@ import (
 // imageProvider is used to provide the images used
 // when generating with a local plugin. Each plugin is
 // in control of its own Strategy - we cache the
-// imagesByDir so that we only have to build it once for
-// all of the plugins that configure the the Directory
-// strategy.
+// images for each Strategy so that we only have to build
+// them once for all of the plugins that configure the
+// same Strategy.
 type imageProvider struct {
-	image       bufimage.Image
-	imagesByDir []bufimage.Image
-	lock        sync.Mutex
+	image           bufimage.Image
+	imagesByDir     []bufimage.Image
+	imagesByPackage []bufimage.Image
+	imagesByFile    []bufimage.Image
+	lock            sync.Mutex
 }
 
 func newImageProvider(image bufimage.Image) *imageProvider {
@@ -44,17 +46,28 @@ func (p *imageProvider) GetImages(strategy Strategy) ([]bufimage.Image, error) {
 	case StrategyAll:
 		return []bufimage.Image{p.image}, nil
 	case StrategyDirectory:
-		p.lock.Lock()
-		defer p.lock.Unlock()
-		if p.imagesByDir == nil {
-			var err error
-			p.imagesByDir, err = bufimage.ImageByDir(p.image)
-			if err != nil {
-				return nil, err
-			}
-		}
-		return p.imagesByDir, nil
+		return p.getCachedImages(&p.imagesByDir, bufimage.ImageByDir)
+	case StrategyPackage:
+		return p.getCachedImages(&p.imagesByPackage, bufimage.ImageByPackage)
+	case StrategyFile:
+		return p.getCachedImages(&p.imagesByFile, bufimage.ImageByFile)
 	default:
 		return nil, fmt.Errorf("unknown strategy: %v", strategy)
 	}
 }
+
+func (p *imageProvider) getCachedImages(
+	cachedImages *[]bufimage.Image,
+	splitImage func(bufimage.Image) ([]bufimage.Image, error),
+) ([]bufimage.Image, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if *cachedImages == nil {
+		images, err := splitImage(p.image)
+		if err != nil {
+			return nil, err
+		}
+		*cachedImages = images
+	}
+	return *cachedImages, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// newGeneratedFiles returns the files that were generated to the out
+// directory or archive from the response.
+func newGeneratedFiles(
+	pluginConfig *PluginConfig,
+	out string,
+	response *pluginpb.CodeGeneratorResponse,
+) *GeneratedFiles {
+	generatedFiles := &GeneratedFiles{
+		PluginName: pluginConfig.PluginName(),
+		Out:        out,
+	}
+	switch filepath.Ext(out) {
+	case ".jar", ".zip":
+		// The archive is always rewritten in its entirety.
+		generatedFiles.Paths = []string{out}
+		return generatedFiles
+	}
+	seenPaths := make(map[string]struct{}, len(response.File))
+	for _, file := range response.File {
+		// Files without a name are appended to the previous file.
+		if file.GetName() == "" {
+			continue
+		}
+		path := filepath.Join(out, normalpath.Unnormalize(file.GetName()))
+		if _, ok := seenPaths[path]; ok {
+			continue
+		}
+		seenPaths[path] = struct{}{}
+		generatedFiles.Paths = append(generatedFiles.Paths, path)
+	}
+	sort.Strings(generatedFiles.Paths)
+	return generatedFiles
+}
+
+// runPostCommands runs each of the post commands of the plugin in order,
+// with the paths of the generated files as additional arguments.
+//
+// The output of the commands is written to stderr, so that stdout is
+// reserved for the output of buf itself.
+func runPostCommands(
+	ctx context.Context,
+	logger *zap.Logger,
+	runner command.Runner,
+	container app.EnvStderrContainer,
+	pluginConfig *PluginConfig,
+	generatedFiles *GeneratedFiles,
+) error {
+	if len(pluginConfig.Post) == 0 {
+		return nil
+	}
+	if len(generatedFiles.Paths) == 0 {
+		// Many formatters read from stdin if no files are given.
+		logger.Debug("post_skipped", zap.String("plugin", pluginConfig.PluginName()))
+		return nil
+	}
+	for _, post := range pluginConfig.Post {
+		args := make([]string, 0, len(post)-1+len(generatedFiles.Paths))
+		args = append(args, post[1:]...)
+		args = append(args, generatedFiles.Paths...)
+		if err := runner.Run(
+			ctx,
+			post[0],
+			command.RunWithArgs(args...),
+			command.RunWithEnv(app.EnvironMap(container)),
+			command.RunWithStdout(container.Stderr()),
+			command.RunWithStderr(container.Stderr()),
+		); err != nil {
+			return fmt.Errorf("plugin %s: post command %q failed: %w", pluginConfig.PluginName(), post[0], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestNewGeneratedFiles(t *testing.T) {
+	t.Parallel()
+	response := &pluginpb.CodeGeneratorResponse{
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{
+				Name:    proto.String("b/b.txt"),
+				Content: proto.String("b"),
+			},
+			{
+				// Appended to the previous file.
+				Content: proto.String("b"),
+			},
+			{
+				Name:    proto.String("a.txt"),
+				Content: proto.String("a"),
+			},
+			{
+				Name:           proto.String("a.txt"),
+				InsertionPoint: proto.String("insertion"),
+				Content:        proto.String("a"),
+			},
+		},
+	}
+	pluginConfig := &PluginConfig{
+		Name: "test",
+		Out:  "gen",
+	}
+	generatedFiles := newGeneratedFiles(pluginConfig, filepath.Join("base", "gen"), response)
+	assert.Equal(
+		t,
+		&GeneratedFiles{
+			PluginName: "test",
+			Out:        filepath.Join("base", "gen"),
+			Paths: []string{
+				filepath.Join("base", "gen", "a.txt"),
+				filepath.Join("base", "gen", "b", "b.txt"),
+			},
+		},
+		generatedFiles,
+	)
+	generatedFiles = newGeneratedFiles(pluginConfig, filepath.Join("gen", "out.zip"), response)
+	assert.Equal(t, []string{filepath.Join("gen", "out.zip")}, generatedFiles.Paths)
+}
@@ -0,0 +1,85 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build aix || darwin || dragonfly || freebsd || (js && wasm) || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd js,wasm linux netbsd openbsd solaris
+
+package bufgen
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRunPostCommands(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	aFilePath := filepath.Join(outDirPath, "a.txt")
+	bFilePath := filepath.Join(outDirPath, "b.txt")
+	require.NoError(t, os.WriteFile(aFilePath, []byte("a\n"), 0600))
+	require.NoError(t, os.WriteFile(bFilePath, []byte("b\n"), 0600))
+	pluginConfig := &PluginConfig{
+		Name: "test",
+		Out:  outDirPath,
+		Post: [][]string{
+			{"sh", "-c", `for f in "$@"; do echo first >> "$f"; done`, "sh"},
+			{"sh", "-c", `for f in "$@"; do echo second >> "$f"; done`, "sh"},
+		},
+	}
+	err := runPostCommands(
+		context.Background(),
+		zap.NewNop(),
+		command.NewRunner(),
+		app.NewContainer(nil, nil, nil, bytes.NewBuffer(nil)),
+		pluginConfig,
+		&GeneratedFiles{
+			PluginName: "test",
+			Out:        outDirPath,
+			Paths:      []string{aFilePath},
+		},
+	)
+	require.NoError(t, err)
+	data, err := os.ReadFile(aFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nfirst\nsecond\n", string(data))
+	// Only the generated files are given to the commands.
+	data, err = os.ReadFile(bFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", string(data))
+
+	pluginConfig.Post = [][]string{{"sh", "-c", "exit 2"}}
+	err = runPostCommands(
+		context.Background(),
+		zap.NewNop(),
+		command.NewRunner(),
+		app.NewContainer(nil, nil, nil, bytes.NewBuffer(nil)),
+		pluginConfig,
+		&GeneratedFiles{
+			PluginName: "test",
+			Out:        outDirPath,
+			Paths:      []string{aFilePath},
+		},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin test: post command")
+}
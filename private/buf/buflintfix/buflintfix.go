@@ -0,0 +1,199 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package buflintfix applies mechanical fixes for lint violations to source files.
+package buflintfix
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/bufbuild/buf/private/buf/bufformat"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/jhump/protocompile/parser"
+	"github.com/jhump/protocompile/reporter"
+)
+
+const (
+	fieldLowerSnakeCaseID     = "FIELD_LOWER_SNAKE_CASE"
+	enumValueUpperSnakeCaseID = "ENUM_VALUE_UPPER_SNAKE_CASE"
+	enumValuePrefixID         = "ENUM_VALUE_PREFIX"
+	enumZeroValueSuffixID     = "ENUM_ZERO_VALUE_SUFFIX"
+	importUsedID              = "IMPORT_USED"
+	syntaxSpecifiedID         = "SYNTAX_SPECIFIED"
+
+	// defaultEnumZeroValueSuffix matches the default in the lint config.
+	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
+	// syntaxDeclaration is added to files without a syntax. Files without
+	// a syntax are proto2 files, so this does not change their semantics.
+	syntaxDeclaration = `syntax = "proto2";`
+)
+
+var fixableIDs = map[string]struct{}{
+	fieldLowerSnakeCaseID:     {},
+	enumValueUpperSnakeCaseID: {},
+	enumValuePrefixID:         {},
+	enumZeroValueSuffixID:     {},
+	importUsedID:              {},
+	syntaxSpecifiedID:         {},
+}
+
+// AllFixableIDs returns the IDs of all rules with violations that can be fixed.
+//
+// Sorted.
+func AllFixableIDs() []string {
+	ids := make([]string, 0, len(fixableIDs))
+	for id := range fixableIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// IsFixable returns true if FileAnnotations of the given type can be fixed.
+func IsFixable(typeString string) bool {
+	_, ok := fixableIDs[typeString]
+	return ok
+}
+
+// Fix applies the fixes for the FileAnnotations to the content of a single file.
+//
+// All FileAnnotations must be for the given file, and must have been produced by
+// linting the file with the given config. The fixed file is printed with bufformat,
+// so comments are preserved and the file is formatted.
+//
+// Elements are only renamed if neither their current nor their new name is
+// used as an identifier anywhere else in the file. References from other files
+// are not updated.
+//
+// Returns the fixed file content and the FileAnnotations that could not be fixed.
+// If nothing could be fixed, the returned data is nil.
+func Fix(
+	externalPath string,
+	data []byte,
+	config *buflintconfig.Config,
+	fileAnnotations []bufanalysis.FileAnnotation,
+) ([]byte, []bufanalysis.FileAnnotation, error) {
+	var fixableFileAnnotations []bufanalysis.FileAnnotation
+	var unfixedFileAnnotations []bufanalysis.FileAnnotation
+	var fixSyntax bool
+	for _, fileAnnotation := range fileAnnotations {
+		switch typeString := fileAnnotation.Type(); {
+		case typeString == syntaxSpecifiedID:
+			fixSyntax = true
+		case IsFixable(typeString):
+			fixableFileAnnotations = append(fixableFileAnnotations, fileAnnotation)
+		default:
+			unfixedFileAnnotations = append(unfixedFileAnnotations, fileAnnotation)
+		}
+	}
+	if !fixSyntax && len(fixableFileAnnotations) == 0 {
+		return nil, unfixedFileAnnotations, nil
+	}
+	var lineOffset int
+	if fixSyntax {
+		data, lineOffset = addSyntaxDeclaration(data)
+	}
+	fileNode, err := parser.Parse(externalPath, bytes.NewReader(data), reporter.NewHandler(nil))
+	if err != nil {
+		return nil, nil, err
+	}
+	enumZeroValueSuffix := config.EnumZeroValueSuffix
+	if enumZeroValueSuffix == "" {
+		enumZeroValueSuffix = defaultEnumZeroValueSuffix
+	}
+	fixer, err := newFixer(fileNode, enumZeroValueSuffix, lineOffset)
+	if err != nil {
+		return nil, nil, err
+	}
+	numFixed := 0
+	if fixSyntax {
+		numFixed++
+	}
+	for _, fileAnnotation := range fixableFileAnnotations {
+		if fixer.fix(fileAnnotation) {
+			numFixed++
+			continue
+		}
+		unfixedFileAnnotations = append(unfixedFileAnnotations, fileAnnotation)
+	}
+	if numFixed == 0 {
+		return nil, unfixedFileAnnotations, nil
+	}
+	buffer := bytes.NewBuffer(nil)
+	if err := bufformat.FormatFileNode(buffer, fileNode); err != nil {
+		return nil, nil, err
+	}
+	return buffer.Bytes(), unfixedFileAnnotations, nil
+}
+
+// addSyntaxDeclaration adds the syntax declaration to the data.
+//
+// The syntax declaration is added after any leading comments that are separated
+// from the rest of the file by a blank line, such as license headers, and before
+// any leading comments that are attached to the first element of the file.
+//
+// Returns the new data and the number of lines added.
+func addSyntaxDeclaration(data []byte) ([]byte, int) {
+	// The offset after the last detached leading comment.
+	var offset int
+	// The offset after the end of the current comment, or -1 if the current
+	// whitespace does not follow a comment.
+	commentEndOffset := -1
+	var newlines int
+	for i := 0; i < len(data); {
+		switch {
+		case data[i] == '\n':
+			newlines++
+			if commentEndOffset >= 0 && newlines >= 2 {
+				offset = commentEndOffset
+			}
+			i++
+		case data[i] == ' ' || data[i] == '\t' || data[i] == '\r':
+			i++
+		case bytes.HasPrefix(data[i:], []byte("//")):
+			lineEnd := bytes.IndexByte(data[i:], '\n')
+			if lineEnd < 0 {
+				i = len(data)
+			} else {
+				// The newline is counted as whitespace after the comment.
+				i += lineEnd
+			}
+			commentEndOffset = i
+			newlines = 0
+		case bytes.HasPrefix(data[i:], []byte("/*")):
+			commentEnd := bytes.Index(data[i+2:], []byte("*/"))
+			if commentEnd < 0 {
+				i = len(data)
+			} else {
+				i += 2 + commentEnd + 2
+			}
+			commentEndOffset = i
+			newlines = 0
+		default:
+			i = len(data)
+		}
+	}
+	if offset == 0 {
+		return append([]byte(syntaxDeclaration+"\n\n"), data...), 2
+	}
+	// The declaration is separated from the comment before and the content after by a blank line.
+	syntaxData := []byte("\n\n" + syntaxDeclaration)
+	fixedData := make([]byte, 0, len(data)+len(syntaxData))
+	fixedData = append(fixedData, data[:offset]...)
+	fixedData = append(fixedData, syntaxData...)
+	fixedData = append(fixedData, data[offset:]...)
+	return fixedData, 2
+}
@@ -0,0 +1,156 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFix(t *testing.T) {
+	t.Parallel()
+	data := []byte(`package foo.v1;
+
+import "foo/v1/bar.proto";
+
+message Foo {
+  optional string barBaz = 1;
+  optional Status status = 2 [default = none];
+}
+
+enum Status {
+  none = 0;
+  active = 1;
+}
+`)
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(0, 0, syntaxSpecifiedID),
+		newFileAnnotation(3, 1, importUsedID),
+		newFileAnnotation(6, 19, fieldLowerSnakeCaseID),
+		newFileAnnotation(11, 3, enumValuePrefixID),
+		newFileAnnotation(11, 3, enumZeroValueSuffixID),
+		newFileAnnotation(12, 3, enumValuePrefixID),
+		newFileAnnotation(12, 3, enumValueUpperSnakeCaseID),
+		newFileAnnotation(12, 3, "ENUM_PASCAL_CASE"),
+	}
+	fixedData, unfixedFileAnnotations, err := Fix(
+		"foo/v1/foo.proto",
+		data,
+		&buflintconfig.Config{},
+		fileAnnotations,
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`syntax = "proto2";
+
+package foo.v1;
+
+message Foo {
+  optional string bar_baz = 1;
+  optional Status status = 2 [default = none];
+}
+
+enum Status {
+  none = 0;
+  STATUS_ACTIVE = 1;
+}
+`,
+		string(fixedData),
+	)
+	assert.Equal(
+		t,
+		[]bufanalysis.FileAnnotation{
+			fileAnnotations[7],
+			fileAnnotations[3],
+			fileAnnotations[4],
+		},
+		unfixedFileAnnotations,
+	)
+}
+
+func TestFixSyntaxAfterLicenseHeader(t *testing.T) {
+	t.Parallel()
+	data := []byte(`// Copyright 2020 Acme, Inc.
+//
+// Licensed under the Apache License, Version 2.0.
+
+// Package foo.v1 has foos.
+package foo.v1;
+
+message Foo {
+  optional string barBaz = 1;
+}
+`)
+	fixedData, unfixedFileAnnotations, err := Fix(
+		"foo/v1/foo.proto",
+		data,
+		&buflintconfig.Config{},
+		[]bufanalysis.FileAnnotation{
+			newFileAnnotation(0, 0, syntaxSpecifiedID),
+			newFileAnnotation(9, 19, fieldLowerSnakeCaseID),
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`// Copyright 2020 Acme, Inc.
+//
+// Licensed under the Apache License, Version 2.0.
+
+syntax = "proto2";
+
+// Package foo.v1 has foos.
+package foo.v1;
+
+message Foo {
+  optional string bar_baz = 1;
+}
+`,
+		string(fixedData),
+	)
+	assert.Empty(t, unfixedFileAnnotations)
+}
+
+func TestFixNothingFixable(t *testing.T) {
+	t.Parallel()
+	fixedData, unfixedFileAnnotations, err := Fix(
+		"foo.proto",
+		[]byte(`syntax = "proto3";`),
+		&buflintconfig.Config{},
+		[]bufanalysis.FileAnnotation{
+			newFileAnnotation(1, 1, "PACKAGE_DEFINED"),
+		},
+	)
+	require.NoError(t, err)
+	assert.Nil(t, fixedData)
+	assert.Len(t, unfixedFileAnnotations, 1)
+}
+
+func newFileAnnotation(startLine int, startColumn int, typeString string) bufanalysis.FileAnnotation {
+	return bufanalysis.NewFileAnnotation(
+		nil,
+		startLine,
+		startColumn,
+		startLine,
+		startColumn,
+		typeString,
+		"",
+	)
+}
@@ -0,0 +1,182 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/jhump/protocompile/ast"
+)
+
+type fixer struct {
+	fileNode            *ast.FileNode
+	enumZeroValueSuffix string
+	lineOffset          int
+
+	positionToFieldName     map[position]*ast.IdentNode
+	positionToEnumValueName map[position]*ast.IdentNode
+	enumValueNameToEnumName map[*ast.IdentNode]string
+	positionToImport        map[position]*ast.ImportNode
+	// identCount is the number of times each identifier is used in the file,
+	// including declarations.
+	identCount map[string]int
+}
+
+// position is a 1-indexed position in the file, matching
+// the start of a FileAnnotation.
+type position struct {
+	line   int
+	column int
+}
+
+func newFixer(
+	fileNode *ast.FileNode,
+	enumZeroValueSuffix string,
+	lineOffset int,
+) (*fixer, error) {
+	fixer := &fixer{
+		fileNode:                fileNode,
+		enumZeroValueSuffix:     enumZeroValueSuffix,
+		lineOffset:              lineOffset,
+		positionToFieldName:     make(map[position]*ast.IdentNode),
+		positionToEnumValueName: make(map[position]*ast.IdentNode),
+		enumValueNameToEnumName: make(map[*ast.IdentNode]string),
+		positionToImport:        make(map[position]*ast.ImportNode),
+		identCount:              make(map[string]int),
+	}
+	if err := ast.Walk(
+		fileNode,
+		&ast.SimpleVisitor{
+			DoVisitImportNode: func(importNode *ast.ImportNode) error {
+				fixer.positionToImport[fixer.positionForNode(importNode)] = importNode
+				return nil
+			},
+			DoVisitFieldNode: func(fieldNode *ast.FieldNode) error {
+				fixer.positionToFieldName[fixer.positionForNode(fieldNode.Name)] = fieldNode.Name
+				return nil
+			},
+			DoVisitMapFieldNode: func(mapFieldNode *ast.MapFieldNode) error {
+				fixer.positionToFieldName[fixer.positionForNode(mapFieldNode.Name)] = mapFieldNode.Name
+				return nil
+			},
+			DoVisitEnumNode: func(enumNode *ast.EnumNode) error {
+				for _, enumElement := range enumNode.Decls {
+					if enumValueNode, ok := enumElement.(*ast.EnumValueNode); ok {
+						fixer.positionToEnumValueName[fixer.positionForNode(enumValueNode.Name)] = enumValueNode.Name
+						fixer.enumValueNameToEnumName[enumValueNode.Name] = enumNode.Name.Val
+					}
+				}
+				return nil
+			},
+			DoVisitIdentNode: func(identNode *ast.IdentNode) error {
+				fixer.identCount[identNode.Val]++
+				return nil
+			},
+		},
+	); err != nil {
+		return nil, err
+	}
+	return fixer, nil
+}
+
+// fix applies the fix for the FileAnnotation, returning true if it was fixed.
+func (f *fixer) fix(fileAnnotation bufanalysis.FileAnnotation) bool {
+	position := position{
+		line:   fileAnnotation.StartLine() + f.lineOffset,
+		column: fileAnnotation.StartColumn(),
+	}
+	switch fileAnnotation.Type() {
+	case fieldLowerSnakeCaseID:
+		identNode, ok := f.positionToFieldName[position]
+		if !ok {
+			return false
+		}
+		return f.rename(identNode, stringutil.ToLowerSnakeCase(identNode.Val))
+	case enumValueUpperSnakeCaseID:
+		identNode, ok := f.positionToEnumValueName[position]
+		if !ok {
+			return false
+		}
+		return f.rename(identNode, stringutil.ToUpperSnakeCase(identNode.Val))
+	case enumValuePrefixID:
+		identNode, ok := f.positionToEnumValueName[position]
+		if !ok {
+			return false
+		}
+		prefix := stringutil.ToUpperSnakeCase(f.enumValueNameToEnumName[identNode]) + "_"
+		if strings.HasPrefix(identNode.Val, prefix) {
+			// Already fixed by a previous rename.
+			return true
+		}
+		return f.rename(identNode, prefix+identNode.Val)
+	case enumZeroValueSuffixID:
+		identNode, ok := f.positionToEnumValueName[position]
+		if !ok {
+			return false
+		}
+		if strings.HasSuffix(identNode.Val, f.enumZeroValueSuffix) {
+			// Already fixed by a previous rename.
+			return true
+		}
+		return f.rename(
+			identNode,
+			stringutil.ToUpperSnakeCase(f.enumValueNameToEnumName[identNode])+f.enumZeroValueSuffix,
+		)
+	case importUsedID:
+		importNode, ok := f.positionToImport[position]
+		if !ok {
+			return false
+		}
+		fileElements := make([]ast.FileElement, 0, len(f.fileNode.Decls))
+		for _, fileElement := range f.fileNode.Decls {
+			if fileElement != importNode {
+				fileElements = append(fileElements, fileElement)
+			}
+		}
+		f.fileNode.Decls = fileElements
+		delete(f.positionToImport, position)
+		return true
+	default:
+		return false
+	}
+}
+
+// rename renames the declaration with the given identifier.
+//
+// We only rename if the identifier is not referenced elsewhere in the file and
+// the new name is not already in use, so that we never produce a file that does
+// not compile or that has a different meaning.
+func (f *fixer) rename(identNode *ast.IdentNode, newName string) bool {
+	if identNode.Val == newName {
+		return true
+	}
+	if f.identCount[identNode.Val] != 1 || f.identCount[newName] != 0 {
+		return false
+	}
+	f.identCount[identNode.Val]--
+	f.identCount[newName]++
+	identNode.Val = newName
+	return true
+}
+
+func (f *fixer) positionForNode(node ast.Node) position {
+	start := f.fileNode.NodeInfo(node).Start()
+	return position{
+		line:   start.Line,
+		column: start.Col,
+	}
+}
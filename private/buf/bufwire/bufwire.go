@@ -30,6 +30,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/proto"
@@ -82,6 +83,10 @@ type ModuleConfig interface {
 	Module() bufmodule.Module
 	Config() *bufconfig.Config
 	Workspace() bufmodule.Workspace
+	// ReadBucket returns the bucket the Module was built from.
+	//
+	// This is nil if the Module was not built from a source.
+	ReadBucket() storage.ReadBucket
 }
 
 // ModuleConfigReader is a ModuleConfig reader.
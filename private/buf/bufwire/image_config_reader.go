@@ -150,6 +150,7 @@ func (i *imageConfigReader) getSourceOrModuleImageConfigs(
 			ctx,
 			moduleConfig.Module(),
 			bufmodulebuild.WithWorkspace(moduleConfig.Workspace()),
+			bufmodulebuild.WithModuleReadBucket(moduleConfig.ReadBucket()),
 		)
 		if err != nil {
 			return nil, nil, err
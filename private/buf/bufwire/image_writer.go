@@ -78,8 +78,11 @@ func (i *imageWriter) PutImage(
 	defer func() {
 		retErr = multierr.Append(retErr, writeCloser.Close())
 	}()
-	_, err = writeCloser.Write(data)
-	return err
+	if _, err := writeCloser.Write(data); err != nil {
+		return err
+	}
+	writeCloser.Commit()
+	return nil
 }
 
 func (i *imageWriter) imageMarshal(
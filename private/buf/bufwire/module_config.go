@@ -17,19 +17,27 @@ package bufwire
 import (
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/storage"
 )
 
 type moduleConfig struct {
-	module    bufmodule.Module
-	config    *bufconfig.Config
-	workspace bufmodule.Workspace
+	module     bufmodule.Module
+	config     *bufconfig.Config
+	workspace  bufmodule.Workspace
+	readBucket storage.ReadBucket
 }
 
-func newModuleConfig(module bufmodule.Module, config *bufconfig.Config, workspace bufmodule.Workspace) *moduleConfig {
+func newModuleConfig(
+	module bufmodule.Module,
+	config *bufconfig.Config,
+	workspace bufmodule.Workspace,
+	readBucket storage.ReadBucket,
+) *moduleConfig {
 	return &moduleConfig{
-		module:    module,
-		config:    config,
-		workspace: workspace,
+		module:     module,
+		config:     config,
+		workspace:  workspace,
+		readBucket: readBucket,
 	}
 }
 
@@ -44,3 +52,7 @@ func (m *moduleConfig) Config() *bufconfig.Config {
 func (m *moduleConfig) Workspace() bufmodule.Workspace {
 	return m.workspace
 }
+
+func (m *moduleConfig) ReadBucket() storage.ReadBucket {
+	return m.readBucket
+}
@@ -230,7 +230,7 @@ func (m *moduleConfigReader) getModuleModuleConfig(
 	if err != nil {
 		return nil, err
 	}
-	return newModuleConfig(module, config, nil /* Workspaces aren't supported for ModuleRefs */), nil
+	return newModuleConfig(module, config, nil /* Workspaces aren't supported for ModuleRefs */, nil), nil
 }
 
 func (m *moduleConfigReader) getProtoFileModuleSourceConfigs(
@@ -525,6 +525,10 @@ func (m *moduleConfigReader) getModuleConfig(
 	externalExcludeDirOrFilePaths []string,
 	externalDirOrFilePathsAllowNotExist bool,
 ) (ModuleConfig, error) {
+	mappedReadBucket := readBucket
+	if subDirPath != "." {
+		mappedReadBucket = storage.MapReadBucket(readBucket, storage.MapOnPrefix(subDirPath))
+	}
 	if module, moduleConfig, ok := workspaceBuilder.GetModuleConfig(subDirPath); ok {
 		// The module was already built while we were constructing the workspace.
 		// However, we still need to perform some additional validation based on
@@ -540,11 +544,7 @@ func (m *moduleConfigReader) getModuleConfig(
 				}
 			}
 		}
-		return newModuleConfig(module, moduleConfig, workspace), nil
-	}
-	mappedReadBucket := readBucket
-	if subDirPath != "." {
-		mappedReadBucket = storage.MapReadBucket(readBucket, storage.MapOnPrefix(subDirPath))
+		return newModuleConfig(module, moduleConfig, workspace, mappedReadBucket), nil
 	}
 	moduleConfig, err := bufconfig.ReadConfigOS(
 		ctx,
@@ -666,7 +666,7 @@ func (m *moduleConfigReader) getModuleConfig(
 	if err != nil {
 		return nil, err
 	}
-	return newModuleConfig(module, moduleConfig, workspace), nil
+	return newModuleConfig(module, moduleConfig, workspace, mappedReadBucket), nil
 }
 
 func workspaceDirectoryEqualsOrContainsSubDirPath(workspaceConfig *bufwork.Config, subDirPath string) bool {
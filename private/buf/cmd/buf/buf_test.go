@@ -528,6 +528,11 @@ COMMENT_RPC                       COMMENTS                 Checks that RPCs have
 COMMENT_SERVICE                   COMMENTS                 Checks that services have non-empty comments.
 RPC_NO_CLIENT_STREAMING           UNARY_RPC                Checks that RPCs are not client streaming.
 RPC_NO_SERVER_STREAMING           UNARY_RPC                Checks that RPCs are not server streaming.
+FIELD_DEPRECATED_COMMENT          API_SURFACE              Checks that deprecated fields have non-empty comments saying what replaces them.
+FIELD_NUMBER_GAP                  API_SURFACE              Checks that messages do not have gaps of more than 100 unused field numbers (gap is configurable).
+FIELD_NUMBER_RANGE                API_SURFACE              Checks that field numbers are not in the reserved range 19000 to 19999 and are at most 536870911 (maximum is configurable).
+MESSAGE_FIELD_COUNT               API_SURFACE              Checks that messages have at most 100 fields (maximum is configurable).
+ONEOF_NO_SINGLE_FIELD             API_SURFACE              Checks that oneofs have more than one field.
 PACKAGE_NO_IMPORT_CYCLE                                    Checks that packages do not have import cycles.
 		`
 	testRunStdout(
@@ -673,11 +678,17 @@ PACKAGE_MESSAGE_NO_DELETE                       PACKAGE
 PACKAGE_NO_DELETE                               PACKAGE                         Checks that packages are not deleted.
 PACKAGE_SERVICE_NO_DELETE                       PACKAGE                         Checks that services are not deleted from a given package.
 ENUM_VALUE_NO_DELETE_UNLESS_NAME_RESERVED       WIRE_JSON                       Checks that enum values are not deleted from a given enum unless the name is reserved.
+ENUM_VALUE_SAME_NUMBER                          WIRE_JSON                       Checks that enum values have the same number for a given name.
 FIELD_NO_DELETE_UNLESS_NAME_RESERVED            WIRE_JSON                       Checks that fields are not deleted from a given message unless the name is reserved.
+FIELD_NO_REUSE_JSON_NAME                        WIRE_JSON                       Checks that added fields do not have the JSON name of a reserved or deleted field.
 FIELD_WIRE_JSON_COMPATIBLE_TYPE                 WIRE_JSON                       Checks that fields have wire and JSON compatible types in a given message.
 ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED     WIRE_JSON, WIRE                 Checks that enum values are not deleted from a given enum unless the number is reserved.
 FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED          WIRE_JSON, WIRE                 Checks that fields are not deleted from a given message unless the number is reserved.
 FIELD_WIRE_COMPATIBLE_TYPE                      WIRE                            Checks that fields have wire-compatible types in a given message.
+FIELD_SAME_CUSTOM_OPTIONS                       CUSTOM_OPTIONS                  Checks that fields have the same values for custom options (options are configurable).
+MESSAGE_SAME_CUSTOM_OPTIONS                     CUSTOM_OPTIONS                  Checks that messages have the same values for custom options (options are configurable).
+RPC_SAME_CUSTOM_OPTIONS                         CUSTOM_OPTIONS                  Checks that rpcs have the same values for custom options (options are configurable).
+SERVICE_SAME_CUSTOM_OPTIONS                     CUSTOM_OPTIONS                  Checks that services have the same values for custom options (options are configurable).
 		`
 	testRunStdout(
 		t,
@@ -23,6 +23,8 @@ import (
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
@@ -43,6 +45,8 @@ const (
 	againstConfigFlagName     = "against-config"
 	excludePathsFlagName      = "exclude-path"
 	disableSymlinksFlagName   = "disable-symlinks"
+	baselineFlagName          = "baseline"
+	writeBaselineFlagName     = "write-baseline"
 )
 
 // NewCommand returns a new Command.
@@ -76,6 +80,8 @@ type flags struct {
 	AgainstConfig     string
 	ExcludePaths      []string
 	DisableSymlinks   bool
+	Baseline          string
+	WriteBaseline     string
 	// special
 	InputHashtag string
 }
@@ -89,6 +95,7 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
 	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	bufcli.BindBaseline(flagSet, &f.Baseline, baselineFlagName, &f.WriteBaseline, writeBaselineFlagName)
 	flagSet.StringVar(
 		&f.ErrorFormat,
 		errorFormatFlagName,
@@ -149,6 +156,10 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
+	baseline, err := bufcli.ReadBaselineFile(flags.Baseline)
+	if err != nil {
+		return err
+	}
 	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
 	if err != nil {
 		return err
@@ -240,15 +251,38 @@ func run(
 		// we're torched.
 		return fmt.Errorf("input contained %d images, whereas against contained %d images", len(imageConfigs), len(againstImageConfigs))
 	}
+	if flags.WriteBaseline != "" {
+		var allBaselineEntries []bufbaseline.Entry
+		for i, imageConfig := range imageConfigs {
+			image, againstImage := getImages(imageConfig, againstImageConfigs[i], flags.ExcludeImports)
+			baseline, err := bufbreaking.NewHandler(container.Logger()).Baseline(
+				ctx,
+				imageConfig.Config().Breaking,
+				againstImage,
+				image,
+			)
+			if err != nil {
+				return err
+			}
+			allBaselineEntries = append(allBaselineEntries, baseline.Entries()...)
+		}
+		return bufcli.WriteBaselineFile(flags.WriteBaseline, bufbaseline.NewBaseline(allBaselineEntries...))
+	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var allRules []bufcheck.Rule
 	for i, imageConfig := range imageConfigs {
+		rules, err := bufbreaking.RulesForConfig(imageConfig.Config().Breaking)
+		if err != nil {
+			return err
+		}
+		allRules = append(allRules, rules...)
 		fileAnnotations, err := breakingForImage(
 			ctx,
 			container,
 			imageConfig,
 			againstImageConfigs[i],
 			flags.ExcludeImports,
-			flags.ErrorFormat,
+			baseline,
 		)
 		if err != nil {
 			return err
@@ -260,10 +294,15 @@ func run(
 			container.Stdout(),
 			bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
 			flags.ErrorFormat,
+			bufcheck.PrintFileAnnotationsWithRules(allRules),
 		); err != nil {
 			return err
 		}
-		return bufcli.ErrFileAnnotation
+		// Violations of rules configured with a severity of
+		// warning or info are printed but do not fail the command.
+		if bufanalysis.HasErrors(allFileAnnotations) {
+			return bufcli.ErrFileAnnotation
+		}
 	}
 	return nil
 }
@@ -274,8 +313,23 @@ func breakingForImage(
 	imageConfig bufwire.ImageConfig,
 	againstImageConfig bufwire.ImageConfig,
 	excludeImports bool,
-	errorFormat string,
+	baseline *bufbaseline.Baseline,
 ) ([]bufanalysis.FileAnnotation, error) {
+	image, againstImage := getImages(imageConfig, againstImageConfig, excludeImports)
+	return bufbreaking.NewHandler(container.Logger()).Check(
+		ctx,
+		imageConfig.Config().Breaking,
+		againstImage,
+		image,
+		bufbreaking.CheckWithBaseline(baseline),
+	)
+}
+
+func getImages(
+	imageConfig bufwire.ImageConfig,
+	againstImageConfig bufwire.ImageConfig,
+	excludeImports bool,
+) (bufimage.Image, bufimage.Image) {
 	image := imageConfig.Image()
 	if excludeImports {
 		image = bufimage.ImageWithoutImports(image)
@@ -284,12 +338,7 @@ func breakingForImage(
 	if excludeImports {
 		againstImage = bufimage.ImageWithoutImports(againstImage)
 	}
-	return bufbreaking.NewHandler(container.Logger()).Check(
-		ctx,
-		imageConfig.Config().Breaking,
-		againstImage,
-		image,
-	)
+	return image, againstImage
 }
 
 func getExternalPathsForImages(imageConfigs []bufwire.ImageConfig, excludeImports bool) ([]string, error) {
@@ -0,0 +1,279 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/buf/bufwire"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName     = "error-format"
+	formatFlagName          = "format"
+	excludeImportsFlagName  = "exclude-imports"
+	pathsFlagName           = "path"
+	configFlagName          = "config"
+	againstFlagName         = "against"
+	againstConfigFlagName   = "against-config"
+	excludePathsFlagName    = "exclude-path"
+	disableSymlinksFlagName = "disable-symlinks"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input> --against <against-input>",
+		Short: "Print the semantic changes between the against location and the input location.",
+		Long: `Files, messages, fields, enums, enum values, services, RPCs, and options that were added, removed, or changed are printed.
+Each change is marked as breaking if it is detected by any of the breaking change rules configured for the input.
+
+` + bufcli.GetInputLong(`the source, module, or image to diff`),
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat     string
+	Format          string
+	ExcludeImports  bool
+	Paths           []string
+	Config          string
+	Against         string
+	AgainstConfig   string
+	ExcludePaths    []string
+	DisableSymlinks bool
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stdout. Must be one of %s.",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for the changes printed to stdout. Must be one of %s.",
+			stringutil.SliceToString(bufbreaking.AllChangeFormatStrings),
+		),
+	)
+	flagSet.BoolVar(
+		&f.ExcludeImports,
+		excludeImportsFlagName,
+		false,
+		"Exclude imports from the diff.",
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The file or data to use for configuration.`,
+	)
+	flagSet.StringVar(
+		&f.Against,
+		againstFlagName,
+		"",
+		fmt.Sprintf(
+			`Required. The source, module, or image to diff against. Must be one of format %s.`,
+			buffetch.AllFormatsString,
+		),
+	)
+	flagSet.StringVar(
+		&f.AgainstConfig,
+		againstConfigFlagName,
+		"",
+		`The file or data to use to configure the against source, module, or image.`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	if flags.Against == "" {
+		return appcmd.NewInvalidArgumentErrorf("required flag %q not set", againstFlagName)
+	}
+	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
+		return err
+	}
+	if err := validateFormatFlag(flags.Format); err != nil {
+		return err
+	}
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, input)
+	if err != nil {
+		return err
+	}
+	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
+	runner := command.NewRunner()
+	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+	if err != nil {
+		return err
+	}
+	imageConfigReader, err := bufcli.NewWireImageConfigReader(
+		container,
+		storageosProvider,
+		runner,
+		registryProvider,
+	)
+	if err != nil {
+		return err
+	}
+	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
+		ctx,
+		container,
+		ref,
+		flags.Config,
+		flags.Paths,        // we filter checks for files
+		flags.ExcludePaths, // we exclude these paths
+		false,              // files specified must exist on the main input
+		false,              // we must include source info to match the breaking changes to the changes
+	)
+	if err != nil {
+		return err
+	}
+	if len(fileAnnotations) > 0 {
+		if err := bufanalysis.PrintFileAnnotations(
+			container.Stdout(),
+			fileAnnotations,
+			flags.ErrorFormat,
+		); err != nil {
+			return err
+		}
+		return bufcli.ErrFileAnnotation
+	}
+	againstRef, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, flags.Against)
+	if err != nil {
+		return err
+	}
+	againstImageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
+		ctx,
+		container,
+		againstRef,
+		flags.AgainstConfig,
+		flags.Paths,        // we filter checks for files
+		flags.ExcludePaths, // we exclude these paths
+		true,               // files are allowed to not exist on the against input
+		false,              // we must include source info to match the breaking changes to the changes
+	)
+	if err != nil {
+		return err
+	}
+	if len(fileAnnotations) > 0 {
+		if err := bufanalysis.PrintFileAnnotations(
+			container.Stdout(),
+			fileAnnotations,
+			flags.ErrorFormat,
+		); err != nil {
+			return err
+		}
+		return bufcli.ErrFileAnnotation
+	}
+	if len(imageConfigs) != len(againstImageConfigs) {
+		// If workspaces are being used as input, the number
+		// of images MUST match. Otherwise the results will
+		// be meaningless.
+		return fmt.Errorf("input contained %d images, whereas against contained %d images", len(imageConfigs), len(againstImageConfigs))
+	}
+	var allChanges []bufbreaking.Change
+	for i, imageConfig := range imageConfigs {
+		changes, err := diffForImage(
+			ctx,
+			container,
+			imageConfig,
+			againstImageConfigs[i],
+			flags.ExcludeImports,
+		)
+		if err != nil {
+			return err
+		}
+		allChanges = append(allChanges, changes...)
+	}
+	return bufbreaking.PrintChanges(container.Stdout(), allChanges, flags.Format)
+}
+
+func diffForImage(
+	ctx context.Context,
+	container appflag.Container,
+	imageConfig bufwire.ImageConfig,
+	againstImageConfig bufwire.ImageConfig,
+	excludeImports bool,
+) ([]bufbreaking.Change, error) {
+	image := imageConfig.Image()
+	againstImage := againstImageConfig.Image()
+	if excludeImports {
+		image = bufimage.ImageWithoutImports(image)
+		againstImage = bufimage.ImageWithoutImports(againstImage)
+	}
+	return bufbreaking.NewHandler(container.Logger()).Diff(
+		ctx,
+		imageConfig.Config().Breaking,
+		againstImage,
+		image,
+	)
+}
+
+func validateFormatFlag(format string) error {
+	for _, formatString := range bufbreaking.AllChangeFormatStrings {
+		if format == formatString {
+			return nil
+		}
+	}
+	return appcmd.NewInvalidArgumentErrorf("--%s: invalid value %q, must be one of %s", formatFlagName, format, stringutil.SliceToString(bufbreaking.AllChangeFormatStrings))
+}
@@ -0,0 +1,98 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/buf/cmd/buf/internal/internaltesting"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd/appcmdtesting"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakingWithinFile(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	dirPath := filepath.Join(tempDirPath, "current")
+	againstDirPath := filepath.Join(tempDirPath, "previous")
+	testWriteModule(
+		t,
+		againstDirPath,
+		`syntax = "proto3";
+
+package a.v1;
+
+message Foo {
+  int32 one = 1;
+  string two = 2;
+}
+`,
+	)
+	testWriteModule(
+		t,
+		dirPath,
+		`syntax = "proto3";
+
+package a.v1;
+
+message Foo {
+  string one = 1;
+  bytes two = 2;
+}
+`,
+	)
+	// Both changes are checked by FIELD_WIRE_COMPATIBLE_TYPE, but only the first is breaking.
+	appcmdtesting.RunCommandExitCodeStdout(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		0,
+		`
+KIND     TYPE   NAME          BREAKING                          DESCRIPTION
+changed  field  a.v1.Foo.one  yes (FIELD_WIRE_COMPATIBLE_TYPE)  Field "1" on message "a.v1.Foo" changed type from "int32" to "string".
+changed  field  a.v1.Foo.two  no                                Field "2" on message "a.v1.Foo" changed type from "string" to "bytes".
+`,
+		internaltesting.NewEnvFunc(t),
+		nil,
+		dirPath,
+		"--against",
+		againstDirPath,
+	)
+}
+
+func testWriteModule(t *testing.T, dirPath string, data string) {
+	require.NoError(t, os.MkdirAll(dirPath, 0755))
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(dirPath, "buf.yaml"),
+			[]byte(`version: v1
+breaking:
+  use:
+    - WIRE
+`),
+			0600,
+		),
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(dirPath, "a.proto"), []byte(data), 0600))
+}
@@ -17,7 +17,9 @@ package export
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
@@ -31,6 +33,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
 	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -95,7 +98,10 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		outputFlagName,
 		outputFlagShortName,
 		"",
-		`The output directory for exported files.`,
+		fmt.Sprintf(
+			`The output directory for exported files, or an OCI reference to push the files to as an OCI artifact, for example %sregistry.local/protos/payments:v3.`,
+			buffetch.OCIPathPrefix,
+		),
 	)
 	_ = cobra.MarkFlagRequired(flagSet, outputFlagName)
 	flagSet.StringVar(
@@ -119,6 +125,15 @@ func run(
 	if err != nil {
 		return err
 	}
+	// If the output is an OCI reference, the files are written to memory and
+	// pushed as a single archive once all files are written.
+	var outputOCISourceRef buffetch.SourceRef
+	if strings.HasPrefix(flags.Output, buffetch.OCIPathPrefix) {
+		outputOCISourceRef, err = buffetch.NewSourceRefParser(container.Logger()).GetSourceRef(ctx, flags.Output)
+		if err != nil {
+			return appcmd.NewInvalidArgumentErrorf("--%s: %v", outputFlagName, err)
+		}
+	}
 	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
 	runner := command.NewRunner()
 	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
@@ -161,6 +176,7 @@ func run(
 			ctx,
 			moduleConfig.Module(),
 			bufmodulebuild.WithWorkspace(moduleConfig.Workspace()),
+			bufmodulebuild.WithModuleReadBucket(moduleConfig.ReadBucket()),
 		)
 		if err != nil {
 			return err
@@ -260,15 +276,20 @@ func run(
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(flags.Output, 0755); err != nil {
-		return err
-	}
-	readWriteBucket, err := storageosProvider.NewReadWriteBucket(
-		flags.Output,
-		storageos.ReadWriteBucketWithSymlinksIfSupported(),
-	)
-	if err != nil {
-		return err
+	var readWriteBucket storage.ReadWriteBucket
+	if outputOCISourceRef != nil {
+		readWriteBucket = storagemem.NewReadWriteBucket()
+	} else {
+		if err := os.MkdirAll(flags.Output, 0755); err != nil {
+			return err
+		}
+		readWriteBucket, err = storageosProvider.NewReadWriteBucket(
+			flags.Output,
+			storageos.ReadWriteBucketWithSymlinksIfSupported(),
+		)
+		if err != nil {
+			return err
+		}
 	}
 	fileInfosFunc := bufmodule.ModuleFileSet.AllFileInfos
 	// If we filtered on some paths, only use the targets.
@@ -309,10 +330,9 @@ func run(
 				}
 				writtenPaths[path] = struct{}{}
 			}
-			if len(writtenPaths) == 0 {
-				return errors.New("no .proto target files found")
-			}
-			return nil
+			// All files were written from the image, so there is no need to
+			// iterate over the remaining ModuleFileSets.
+			break
 		}
 		fileInfos, err := fileInfosFunc(moduleFileSet, ctx)
 		if err != nil {
@@ -352,5 +372,13 @@ func run(
 	if len(writtenPaths) == 0 {
 		return errors.New("no .proto target files found")
 	}
+	if outputOCISourceRef != nil {
+		return bufcli.NewFetchWriter(container.Logger()).PutSourceBucket(
+			ctx,
+			container,
+			outputOCISourceRef,
+			readWriteBucket,
+		)
+	}
 	return nil
 }
@@ -16,11 +16,16 @@ package generate
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/buf/bufgen"
+	"github.com/bufbuild/buf/private/buf/bufprint"
+	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
@@ -33,16 +38,20 @@ import (
 )
 
 const (
-	templateFlagName            = "template"
-	baseOutDirPathFlagName      = "output"
-	baseOutDirPathFlagShortName = "o"
-	errorFormatFlagName         = "error-format"
-	configFlagName              = "config"
-	pathsFlagName               = "path"
-	includeImportsFlagName      = "include-imports"
-	includeWKTFlagName          = "include-wkt"
-	excludePathsFlagName        = "exclude-path"
-	disableSymlinksFlagName     = "disable-symlinks"
+	templateFlagName             = "template"
+	baseOutDirPathFlagName       = "output"
+	baseOutDirPathFlagShortName  = "o"
+	errorFormatFlagName          = "error-format"
+	configFlagName               = "config"
+	pathsFlagName                = "path"
+	includeImportsFlagName       = "include-imports"
+	includeWKTFlagName           = "include-wkt"
+	excludePathsFlagName         = "exclude-path"
+	disableSymlinksFlagName      = "disable-symlinks"
+	disableCacheFlagName         = "disable-cache"
+	cleanFlagName                = "clean"
+	checkFlagName                = "check"
+	generatedFilesFormatFlagName = "generated-files-format"
 )
 
 // NewCommand returns a new Command.
@@ -75,9 +84,13 @@ plugins:
     # Optional.
     opt: paths=source_relative
     # The custom path to the plugin binary, if not protoc-gen-NAME on your $PATH.
+    # If the path has the .wasm extension, the plugin is a WebAssembly module that is
+    # executed in an embedded, sandboxed WASI runtime, with no access to the filesystem,
+    # the environment, or the network. This allows checking cross-platform plugins into
+    # a repository.
     # Optional, and exclusive with "remote".
     path: custom-gen-go
-    # The generation strategy to use. There are two options:
+    # The generation strategy to use. There are four options:
     #
     # 1. "directory"
     #
@@ -100,15 +113,83 @@ plugins:
     #
     #   This is needed for certain plugins that expect all files to be given at once.
     #
+    # 3. "package"
+    #
+    #   This will result in buf splitting the input files by Protobuf package, and making separate
+    #   plugin invocations in parallel. This is needed for certain plugins that expect all files of
+    #   a package to be given at once, and for layouts with multiple packages in a single directory.
+    #
+    # 4. "file"
+    #
+    #   This will result in buf making a separate plugin invocation for each input file, in parallel.
+    #   This is needed for certain plugins that expect to be given a single file at a time.
+    #
     # If omitted, "directory" is used. Most users should not need to set this option.
     # Optional.
     strategy: directory
+    # Whether to delete files from the output directory that were generated by a previous
+    # invocation but are no longer generated, for example because a .proto file was deleted.
+    # buf keeps track of the files it generated in a ".buf.gen.manifest.json" file in the
+    # output directory, and never deletes files that it did not generate.
+    # If omitted, false is used. This can be enabled for all plugins with --clean.
+    # Optional.
+    clean: true
+    # Only send the files within these paths to the plugin. The paths are relative to the
+    # root of the input, and may be files or directories. The input may be further narrowed
+    # with --path and --exclude-path.
+    # If omitted, all files are sent.
+    # Optional.
+    include_paths:
+      - acme/weather/v1
+    # Do not send the files within these paths to the plugin.
+    # Optional.
+    exclude_paths:
+      - acme/weather/v1/internal
+    # Only send these types to the plugin, along with the types they depend on. Each value is
+    # either a fully-qualified message, enum, or service name, or a package, in which case
+    # all types within the package and its sub-packages are sent.
+    # If omitted, all types are sent.
+    # Optional.
+    types:
+      - acme.weather.v1.WeatherService
+    # Commands to run in order once all of the generated files are written, for example to
+    # format them. Each command is run from the current directory, with the paths of the
+    # files generated by this plugin appended as arguments. Each command is either a single
+    # string that is split on whitespace, or a list of arguments.
+    # Post commands are not run with --check.
+    # Optional.
+    post:
+      - gofmt -w
+      - [goimports, -w]
   - name: java
     out: gen/java
     # Use the plugin hosted at buf.build/protocolbuffers/plugins/python at version v3.17.0-1.
     # If version is omitted, uses the latest version of the plugin.
   - remote: buf.build/protocolbuffers/plugins/python:v3.17.0-1
     out: gen/python
+# The inputs to generate from, used when no input is given on the command line.
+# Each input is built separately, and the resulting images are merged and sent to the plugins
+# as if they were a single input. A file may only belong to one input, though imports may be shared.
+# Optional.
+inputs:
+    # The source, module, or image to generate from, in the same format as the input argument.
+    # Required.
+  - input: proto
+    # Only generate for the files within these paths of the input. This is the equivalent of --path.
+    # Optional.
+    paths:
+      - proto/acme/weather/v1
+    # Do not generate for the files within these paths of the input. This is the equivalent of
+    # --exclude-path.
+    # Optional.
+    exclude_paths:
+      - proto/acme/weather/v1/internal
+    # Only generate for these types, along with the types they depend on, in the same format
+    # as the types of a plugin.
+    # Optional.
+    types:
+      - acme.weather.v1
+  - input: buf.build/acme/petapis
 
 As an example, here's a typical "buf.gen.yaml" go and grpc, assuming
 "protoc-gen-go" and "protoc-gen-go-grpc" are on your "$PATH":
@@ -127,7 +208,7 @@ By default, buf generate will look for a file of this shape named
 for the set of plugins you want to invoke.
 
 The first argument is the source, module, or image to generate from.
-If no argument is specified, defaults to ".".
+If no argument is specified, the inputs in the template are used, or if there are none, defaults to ".".
 
 Call with:
 
@@ -165,10 +246,27 @@ module in "proto", you cannot specify "--path proto", however "--path proto/foo"
 as "proto/foo" is contained within "proto".
 
 Plugins are invoked in the order they are specified in the template, but each plugin
-has a per-directory parallel invocation, with results from each invocation combined
-before writing the result.
+has a parallel invocation per directory, package, or file depending on its strategy, with results
+from each invocation combined before writing the result.
 
 Insertion points are processed in the order the plugins are specified in the template.
+
+The last response of each plugin is cached in the buf cache directory, keyed on the plugin version, the plugin
+options, the output directory, and the exact request sent to the plugin. If none of these have changed since the
+previous invocation, the cached response is written instead of invoking the plugin again. Local plugins are
+identified by the path and the sha256 digest of their binary, and remote plugins are only cached if both a
+version and a revision are specified. The cache can be bypassed with --disable-cache, and is cleared with
+buf mod clear-cache.
+
+To verify that the generated files are up to date, for example in CI, use --check. Nothing is written,
+a diff is printed for every file that would change, and buf exits with a non-zero exit code if any would:
+
+$ buf generate --check
+
+To print the paths of the files that were generated by each plugin, for example for a wrapper
+script, use --generated-files-format. The JSON format lists the files per plugin:
+
+$ buf generate --generated-files-format json
 `,
 		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
@@ -182,16 +280,20 @@ Insertion points are processed in the order the plugins are specified in the tem
 }
 
 type flags struct {
-	Template        string
-	BaseOutDirPath  string
-	ErrorFormat     string
-	Files           []string
-	Config          string
-	Paths           []string
-	IncludeImports  bool
-	IncludeWKT      bool
-	ExcludePaths    []string
-	DisableSymlinks bool
+	Template             string
+	BaseOutDirPath       string
+	ErrorFormat          string
+	Files                []string
+	Config               string
+	Paths                []string
+	IncludeImports       bool
+	IncludeWKT           bool
+	ExcludePaths         []string
+	DisableSymlinks      bool
+	DisableCache         bool
+	Clean                bool
+	Check                bool
+	GeneratedFilesFormat string
 	// special
 	InputHashtag string
 }
@@ -220,6 +322,33 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 			includeImportsFlagName,
 		),
 	)
+	flagSet.BoolVar(
+		&f.DisableCache,
+		disableCacheFlagName,
+		false,
+		"Always invoke plugins instead of using cached responses from previous invocations.",
+	)
+	flagSet.BoolVar(
+		&f.Clean,
+		cleanFlagName,
+		false,
+		`Delete files that were generated by a previous invocation but are no longer generated, as if "clean: true" was set for every plugin.`,
+	)
+	flagSet.BoolVar(
+		&f.Check,
+		checkFlagName,
+		false,
+		"Check that the generated files are up to date instead of writing them. A diff is printed for any file that would change, and buf exits with a non-zero exit code if any would.",
+	)
+	flagSet.StringVar(
+		&f.GeneratedFilesFormat,
+		generatedFilesFormatFlagName,
+		"",
+		fmt.Sprintf(
+			"Print the files generated by each plugin to stdout in this format once they are written. Must be one of %s. If not set, nothing is printed.",
+			bufprint.AllFormatsString,
+		),
+	)
 	flagSet.StringVar(
 		&f.Template,
 		templateFlagName,
@@ -266,14 +395,6 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
-	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
-	if err != nil {
-		return err
-	}
-	ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, input)
-	if err != nil {
-		return err
-	}
 	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
 	runner := command.NewRunner()
 	readWriteBucket, err := storageosProvider.NewReadWriteBucket(
@@ -305,32 +426,56 @@ func run(
 	if err != nil {
 		return err
 	}
-	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
-		ctx,
-		container,
-		ref,
-		flags.Config,
-		flags.Paths,        // we filter on files
-		flags.ExcludePaths, // we exclude these paths
-		false,              // input files must exist
-		false,              // we must include source info for generation
-	)
-	if err != nil {
-		return err
-	}
-	if len(fileAnnotations) > 0 {
-		if err := bufanalysis.PrintFileAnnotations(container.Stderr(), fileAnnotations, flags.ErrorFormat); err != nil {
+	var image bufimage.Image
+	if container.NumArgs() == 0 && flags.InputHashtag == "" && len(genConfig.InputConfigs) > 0 {
+		if len(flags.Paths) > 0 || len(flags.ExcludePaths) > 0 {
+			return appcmd.NewInvalidArgumentErrorf(
+				"Cannot set --%s or --%s when the inputs are specified in the template, set paths and exclude_paths on each input instead",
+				pathsFlagName,
+				excludePathsFlagName,
+			)
+		}
+		images := make([]bufimage.Image, 0, len(genConfig.InputConfigs))
+		for _, inputConfig := range genConfig.InputConfigs {
+			inputImage, err := getImage(
+				ctx,
+				container,
+				flags,
+				imageConfigReader,
+				inputConfig.Input,
+				inputConfig.Paths,
+				inputConfig.ExcludePaths,
+			)
+			if err != nil {
+				return err
+			}
+			inputImage, err = bufgen.FilterImageForInput(inputImage, inputConfig)
+			if err != nil {
+				return err
+			}
+			images = append(images, inputImage)
+		}
+		image, err = bufimage.MergeImages(images...)
+		if err != nil {
+			return err
+		}
+	} else {
+		input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+		if err != nil {
+			return err
+		}
+		image, err = getImage(
+			ctx,
+			container,
+			flags,
+			imageConfigReader,
+			input,
+			flags.Paths,
+			flags.ExcludePaths,
+		)
+		if err != nil {
 			return err
 		}
-		return bufcli.ErrFileAnnotation
-	}
-	images := make([]bufimage.Image, 0, len(imageConfigs))
-	for _, imageConfig := range imageConfigs {
-		images = append(images, imageConfig.Image())
-	}
-	image, err := bufimage.MergeImages(images...)
-	if err != nil {
-		return err
 	}
 	generateOptions := []bufgen.GenerateOption{
 		bufgen.GenerateWithBaseOutDirPath(flags.BaseOutDirPath),
@@ -347,7 +492,43 @@ func run(
 			bufgen.GenerateWithIncludeWellKnownTypes(),
 		)
 	}
-	return bufgen.NewGenerator(
+	if flags.Clean {
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithClean(),
+		)
+	}
+	if flags.Check {
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithCheck(),
+		)
+	}
+	if flags.GeneratedFilesFormat != "" {
+		format, err := bufprint.ParseFormat(flags.GeneratedFilesFormat)
+		if err != nil {
+			return appcmd.NewInvalidArgumentError(err.Error())
+		}
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithGeneratedFilesFunc(
+				func(generatedFiles []*bufgen.GeneratedFiles) error {
+					return printGeneratedFiles(container.Stdout(), format, generatedFiles)
+				},
+			),
+		)
+	}
+	if !flags.DisableCache {
+		cacheBucket, err := bufcli.NewGenerateCacheReadWriteBucket(container)
+		if err != nil {
+			return err
+		}
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithCacheBucket(cacheBucket),
+		)
+	}
+	if err := bufgen.NewGenerator(
 		logger,
 		storageosProvider,
 		runner,
@@ -358,5 +539,70 @@ func run(
 		genConfig,
 		image,
 		generateOptions...,
+	); err != nil {
+		if errors.Is(err, bufgen.ErrGeneratedOutOfDate) {
+			// The diff has already been printed.
+			return bufcli.ErrFileAnnotation
+		}
+		return err
+	}
+	return nil
+}
+
+// getImage builds the input into a single image, filtered on the given paths.
+func getImage(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+	imageConfigReader bufwire.ImageConfigReader,
+	input string,
+	paths []string,
+	excludePaths []string,
+) (bufimage.Image, error) {
+	ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
+		ctx,
+		container,
+		ref,
+		flags.Config,
+		paths,        // we filter on files
+		excludePaths, // we exclude these paths
+		false,        // input files must exist
+		false,        // we must include source info for generation
 	)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileAnnotations) > 0 {
+		if err := bufanalysis.PrintFileAnnotations(container.Stderr(), fileAnnotations, flags.ErrorFormat); err != nil {
+			return nil, err
+		}
+		return nil, bufcli.ErrFileAnnotation
+	}
+	images := make([]bufimage.Image, 0, len(imageConfigs))
+	for _, imageConfig := range imageConfigs {
+		images = append(images, imageConfig.Image())
+	}
+	return bufimage.MergeImages(images...)
+}
+
+func printGeneratedFiles(writer io.Writer, format bufprint.Format, generatedFiles []*bufgen.GeneratedFiles) error {
+	switch format {
+	case bufprint.FormatText:
+		for _, pluginGeneratedFiles := range generatedFiles {
+			for _, path := range pluginGeneratedFiles.Paths {
+				if _, err := fmt.Fprintln(writer, path); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case bufprint.FormatJSON:
+		return json.NewEncoder(writer).Encode(generatedFiles)
+	default:
+		return fmt.Errorf("unknown format: %v", format)
+	}
 }
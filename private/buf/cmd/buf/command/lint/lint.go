@@ -17,13 +17,17 @@ package lint
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/buf/buflintfix"
+	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
-	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
 	"github.com/bufbuild/buf/private/pkg/command"
@@ -38,6 +42,9 @@ const (
 	pathsFlagName           = "path"
 	excludePathsFlagName    = "exclude-path"
 	disableSymlinksFlagName = "disable-symlinks"
+	fixFlagName             = "fix"
+	baselineFlagName        = "baseline"
+	writeBaselineFlagName   = "write-baseline"
 )
 
 // NewCommand returns a new Command.
@@ -67,6 +74,9 @@ type flags struct {
 	Paths           []string
 	ExcludePaths    []string
 	DisableSymlinks bool
+	Fix             bool
+	Baseline        string
+	WriteBaseline   string
 	// special
 	InputHashtag string
 }
@@ -80,6 +90,7 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
 	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	bufcli.BindBaseline(flagSet, &f.Baseline, baselineFlagName, &f.WriteBaseline, writeBaselineFlagName)
 	flagSet.StringVar(
 		&f.ErrorFormat,
 		errorFormatFlagName,
@@ -95,6 +106,18 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		"",
 		`The file or data to use for configuration.`,
 	)
+	flagSet.BoolVar(
+		&f.Fix,
+		fixFlagName,
+		false,
+		fmt.Sprintf(
+			`Fix the check violations that can be fixed mechanically by rewriting the source files in place.
+Fixed files are also formatted. The remaining check violations are printed.
+Only local directory and .proto file inputs can be fixed.
+Violations of the following rules can be fixed: %s.`,
+			stringutil.SliceToHumanString(buflintfix.AllFixableIDs()),
+		),
+	)
 }
 
 func run(
@@ -113,6 +136,18 @@ func run(
 	if err != nil {
 		return err
 	}
+	// The files are rewritten based on their external paths, so fixing any other
+	// input would overwrite unrelated local files that happen to share those paths.
+	if flags.Fix && !buffetch.IsLocalRef(ref) {
+		return fmt.Errorf("--%s can only be used with local directory and .proto file inputs", fixFlagName)
+	}
+	if flags.Fix && flags.WriteBaseline != "" {
+		return appcmd.NewInvalidArgumentErrorf("cannot set both --%s and --%s", fixFlagName, writeBaselineFlagName)
+	}
+	baseline, err := bufcli.ReadBaselineFile(flags.Baseline)
+	if err != nil {
+		return err
+	}
 	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
 	runner := command.NewRunner()
 	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
@@ -128,35 +163,104 @@ func run(
 	if err != nil {
 		return err
 	}
-	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
-		ctx,
-		container,
-		ref,
-		flags.Config,
-		flags.Paths,        // we filter checks for files
-		flags.ExcludePaths, // we exclude these paths
-		false,              // input files must exist
-		false,              // we must include source info for linting
-	)
+	getImageConfigs := func() ([]bufwire.ImageConfig, error) {
+		imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
+			ctx,
+			container,
+			ref,
+			flags.Config,
+			flags.Paths,        // we filter checks for files
+			flags.ExcludePaths, // we exclude these paths
+			false,              // input files must exist
+			false,              // we must include source info for linting
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(fileAnnotations) > 0 {
+			formatString := flags.ErrorFormat
+			if formatString == "config-ignore-yaml" {
+				formatString = "text"
+			}
+			if err := bufanalysis.PrintFileAnnotations(container.Stdout(), fileAnnotations, formatString); err != nil {
+				return nil, err
+			}
+			return nil, bufcli.ErrFileAnnotation
+		}
+		return imageConfigs, nil
+	}
+	imageConfigs, err := getImageConfigs()
 	if err != nil {
 		return err
 	}
-	if len(fileAnnotations) > 0 {
-		formatString := flags.ErrorFormat
-		if formatString == "config-ignore-yaml" {
-			formatString = "text"
+	// Lint plugins are arbitrary binaries, so we only run the plugins configured
+	// by local inputs or by --config, and not those configured by the authors of
+	// archives, git repositories, or modules.
+	if !buffetch.IsLocalRef(ref) && flags.Config == "" {
+		for _, imageConfig := range imageConfigs {
+			if len(imageConfig.Config().Lint.Plugins) > 0 {
+				return fmt.Errorf(
+					"lint plugins are only run for local directory and .proto file inputs, use --%s to run the lint plugins for this input",
+					configFlagName,
+				)
+			}
 		}
-		if err := bufanalysis.PrintFileAnnotations(container.Stdout(), fileAnnotations, formatString); err != nil {
+	}
+	lintHandler := bufcli.NewLintHandler(container, runner)
+	if flags.Fix {
+		fixed, unfixedFileAnnotations, err := fixImageConfigs(ctx, lintHandler, imageConfigs, baseline)
+		if err != nil {
 			return err
 		}
-		return bufcli.ErrFileAnnotation
+		for _, unfixedFileAnnotation := range unfixedFileAnnotations {
+			// Violations of rules that cannot be fixed are printed below as usual,
+			// but violations of fixable rules are expected to be fixed.
+			if buflintfix.IsFixable(unfixedFileAnnotation.Type()) {
+				container.Logger().Sugar().Warnf(
+					"could not fix %s violation in %s: %s",
+					unfixedFileAnnotation.Type(),
+					unfixedFileAnnotation.FileInfo().ExternalPath(),
+					unfixedFileAnnotation.Message(),
+				)
+			}
+		}
+		if fixed {
+			// The fixes move the remaining violations, so we lint the
+			// rewritten files again to report up-to-date positions.
+			imageConfigs, err = getImageConfigs()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if flags.WriteBaseline != "" {
+		var allBaselineEntries []bufbaseline.Entry
+		for _, imageConfig := range imageConfigs {
+			baseline, err := lintHandler.Baseline(
+				ctx,
+				imageConfig.Config().Lint,
+				imageConfig.Image(),
+			)
+			if err != nil {
+				return err
+			}
+			allBaselineEntries = append(allBaselineEntries, baseline.Entries()...)
+		}
+		return bufcli.WriteBaselineFile(flags.WriteBaseline, bufbaseline.NewBaseline(allBaselineEntries...))
 	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var allRules []bufcheck.Rule
 	for _, imageConfig := range imageConfigs {
-		fileAnnotations, err := buflint.NewHandler(container.Logger()).Check(
+		rules, err := lintHandler.Rules(ctx, imageConfig.Config().Lint)
+		if err != nil {
+			return err
+		}
+		allRules = append(allRules, rules...)
+		fileAnnotations, err := lintHandler.Check(
 			ctx,
 			imageConfig.Config().Lint,
-			bufimage.ImageWithoutImports(imageConfig.Image()),
+			imageConfig.Image(),
+			buflint.CheckWithBaseline(baseline),
 		)
 		if err != nil {
 			return err
@@ -168,10 +272,100 @@ func run(
 			container.Stdout(),
 			bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
 			flags.ErrorFormat,
+			bufcheck.PrintFileAnnotationsWithRules(allRules),
 		); err != nil {
 			return err
 		}
-		return bufcli.ErrFileAnnotation
+		// Violations of rules configured with a severity of
+		// warning or info are printed but do not fail the command.
+		if bufanalysis.HasErrors(allFileAnnotations) {
+			return bufcli.ErrFileAnnotation
+		}
 	}
 	return nil
 }
+
+// fixImageConfigs fixes the check violations of the ImageConfigs by rewriting the files in place.
+//
+// Returns true if any file was rewritten, and the FileAnnotations with a file that could not be fixed.
+func fixImageConfigs(
+	ctx context.Context,
+	lintHandler buflint.Handler,
+	imageConfigs []bufwire.ImageConfig,
+	baseline *bufbaseline.Baseline,
+) (bool, []bufanalysis.FileAnnotation, error) {
+	var fixed bool
+	var unfixedFileAnnotations []bufanalysis.FileAnnotation
+	for _, imageConfig := range imageConfigs {
+		fileAnnotations, err := lintHandler.Check(
+			ctx,
+			imageConfig.Config().Lint,
+			imageConfig.Image(),
+			buflint.CheckWithBaseline(baseline),
+		)
+		if err != nil {
+			return false, nil, err
+		}
+		imageConfigFixed, imageConfigUnfixedFileAnnotations, err := fixFileAnnotations(imageConfig.Config().Lint, fileAnnotations)
+		if err != nil {
+			return false, nil, err
+		}
+		fixed = fixed || imageConfigFixed
+		unfixedFileAnnotations = append(unfixedFileAnnotations, imageConfigUnfixedFileAnnotations...)
+	}
+	return fixed, unfixedFileAnnotations, nil
+}
+
+// fixFileAnnotations fixes the FileAnnotations by rewriting the files in place.
+//
+// Returns true if any file was rewritten, and the FileAnnotations with a file that could not be fixed.
+func fixFileAnnotations(
+	config *buflintconfig.Config,
+	fileAnnotations []bufanalysis.FileAnnotation,
+) (bool, []bufanalysis.FileAnnotation, error) {
+	var fixed bool
+	var unfixedFileAnnotations []bufanalysis.FileAnnotation
+	var externalPaths []string
+	externalPathToFileAnnotations := make(map[string][]bufanalysis.FileAnnotation)
+	for _, fileAnnotation := range fileAnnotations {
+		fileInfo := fileAnnotation.FileInfo()
+		if fileInfo == nil {
+			// Annotations without a file are printed with the remaining violations.
+			continue
+		}
+		externalPath := fileInfo.ExternalPath()
+		if _, ok := externalPathToFileAnnotations[externalPath]; !ok {
+			externalPaths = append(externalPaths, externalPath)
+		}
+		externalPathToFileAnnotations[externalPath] = append(externalPathToFileAnnotations[externalPath], fileAnnotation)
+	}
+	for _, externalPath := range externalPaths {
+		// Like buf format --write, we rewrite the files based on their external path.
+		fileInfo, err := os.Stat(externalPath)
+		if err != nil {
+			return false, nil, err
+		}
+		data, err := os.ReadFile(externalPath)
+		if err != nil {
+			return false, nil, err
+		}
+		fixedData, fileUnfixedFileAnnotations, err := buflintfix.Fix(
+			externalPath,
+			data,
+			config,
+			externalPathToFileAnnotations[externalPath],
+		)
+		if err != nil {
+			return false, nil, err
+		}
+		unfixedFileAnnotations = append(unfixedFileAnnotations, fileUnfixedFileAnnotations...)
+		if fixedData == nil {
+			continue
+		}
+		if err := os.WriteFile(externalPath, fixedData, fileInfo.Mode().Perm()); err != nil {
+			return false, nil, err
+		}
+		fixed = true
+	}
+	return fixed, unfixedFileAnnotations, nil
+}
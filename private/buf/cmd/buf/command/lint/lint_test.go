@@ -0,0 +1,201 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/internal/internaltesting"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd/appcmdtesting"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFix(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDirPath, "buf.yaml"),
+			[]byte(`version: v1
+lint:
+  use:
+    - SYNTAX_SPECIFIED
+    - FIELD_LOWER_SNAKE_CASE
+    - MESSAGE_PASCAL_CASE
+`),
+			0600,
+		),
+	)
+	filePath := filepath.Join(tempDirPath, "a.proto")
+	require.NoError(
+		t,
+		os.WriteFile(
+			filePath,
+			[]byte(`package a.v1;
+
+message Foo {
+  optional string fooBar = 1;
+}
+
+message bar {}
+`),
+			0600,
+		),
+	)
+	fileInfo, err := os.Stat(filePath)
+	require.NoError(t, err)
+	// The syntax is added at the top of the file, so the remaining violation is
+	// reported two lines below its position before the fix.
+	testRunStdout(
+		t,
+		bufcli.ExitCodeFileAnnotation,
+		filepath.Join(tempDirPath, "a.proto")+`:9:9:Message name "bar" should be PascalCase, such as "Bar".`,
+		"--fix",
+		tempDirPath,
+	)
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		`syntax = "proto2";
+
+package a.v1;
+
+message Foo {
+  optional string foo_bar = 1;
+}
+
+message bar {}
+`,
+		string(data),
+	)
+	fixedFileInfo, err := os.Stat(filePath)
+	require.NoError(t, err)
+	require.Equal(t, fileInfo.Mode(), fixedFileInfo.Mode())
+	// Running again does not rewrite the file and reports the same violation.
+	testRunStdout(
+		t,
+		bufcli.ExitCodeFileAnnotation,
+		filepath.Join(tempDirPath, "a.proto")+`:9:9:Message name "bar" should be PascalCase, such as "Bar".`,
+		"--fix",
+		tempDirPath,
+	)
+}
+
+func TestPluginsOnlyRunForLocalInputs(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	zipFilePath := filepath.Join(tempDirPath, "module.zip")
+	testWriteZipFile(
+		t,
+		zipFilePath,
+		map[string]string{
+			"buf.yaml": `version: v1
+lint:
+  use:
+    - ACME
+  plugins:
+    - plugin: buf-check-acme
+`,
+			"a.proto": `syntax = "proto3";
+
+package a.v1;
+`,
+		},
+	)
+	appcmdtesting.RunCommandExitCodeStderr(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		1,
+		"Failure: lint plugins are only run for local directory and .proto file inputs, use --config to run the lint plugins for this input",
+		internaltesting.NewEnvFunc(t),
+		nil,
+		zipFilePath,
+	)
+}
+
+func TestFixOnlyForLocalInputs(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	zipFilePath := filepath.Join(tempDirPath, "module.zip")
+	testWriteZipFile(
+		t,
+		zipFilePath,
+		map[string]string{
+			"buf.yaml": `version: v1
+`,
+			"a.proto": `package a.v1;
+`,
+		},
+	)
+	appcmdtesting.RunCommandExitCodeStderr(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		1,
+		"Failure: --fix can only be used with local directory and .proto file inputs",
+		internaltesting.NewEnvFunc(t),
+		nil,
+		"--fix",
+		zipFilePath,
+	)
+}
+
+func testWriteZipFile(t *testing.T, zipFilePath string, pathToData map[string]string) {
+	zipFile, err := os.Create(zipFilePath)
+	require.NoError(t, err)
+	zipWriter := zip.NewWriter(zipFile)
+	for path, data := range pathToData {
+		writer, err := zipWriter.Create(path)
+		require.NoError(t, err)
+		_, err = writer.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zipWriter.Close())
+	require.NoError(t, zipFile.Close())
+}
+
+func testRunStdout(t *testing.T, expectedExitCode int, expectedStdout string, args ...string) {
+	appcmdtesting.RunCommandExitCodeStdout(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		expectedExitCode,
+		expectedStdout,
+		internaltesting.NewEnvFunc(t),
+		nil,
+		args...,
+	)
+}
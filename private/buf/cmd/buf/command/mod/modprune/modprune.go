@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufconnect"
 	"github.com/bufbuild/buf/private/bufpkg/buflock"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulelocal"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
@@ -79,17 +80,13 @@ func run(
 		return err
 	}
 	remote := bufconnect.DefaultRemote
-	if config.ModuleIdentity != nil && config.ModuleIdentity.Remote() != "" {
+	if config.ModuleIdentity != nil && config.ModuleIdentity.Remote() != "" && !bufmoduleref.IsFileRemote(config.ModuleIdentity.Remote()) {
 		remote = config.ModuleIdentity.Remote()
 	}
 	apiProvider, err := bufcli.NewRegistryProvider(ctx, container)
 	if err != nil {
 		return err
 	}
-	service, err := apiProvider.NewResolveService(ctx, remote)
-	if err != nil {
-		return err
-	}
 
 	module, err := bufmodule.NewModuleForBucket(ctx, readWriteBucket)
 	if err != nil {
@@ -100,9 +97,23 @@ func run(
 	if err != nil {
 		return err
 	}
+	// Dependencies in local registries are resolved without the resolve service.
+	var localRequestReferences []bufmoduleref.ModuleReference
+	var remoteRequestReferences []bufmoduleref.ModuleReference
+	for _, requestReference := range requestReferences {
+		if bufmoduleref.IsFileRemote(requestReference.Remote()) {
+			localRequestReferences = append(localRequestReferences, requestReference)
+		} else {
+			remoteRequestReferences = append(remoteRequestReferences, requestReference)
+		}
+	}
 	var dependencyModulePins []bufmoduleref.ModulePin
-	if len(requestReferences) > 0 {
-		protoDependencyModulePins, err := service.GetModulePins(ctx, bufmoduleref.NewProtoModuleReferencesForModuleReferences(requestReferences...), nil)
+	if len(remoteRequestReferences) > 0 {
+		service, err := apiProvider.NewResolveService(ctx, remote)
+		if err != nil {
+			return err
+		}
+		protoDependencyModulePins, err := service.GetModulePins(ctx, bufmoduleref.NewProtoModuleReferencesForModuleReferences(remoteRequestReferences...), nil)
 		if err != nil {
 			if connect.CodeOf(err) == connect.CodeUnimplemented && remote != bufconnect.DefaultRemote {
 				return bufcli.NewUnimplementedRemoteError(err, remote, config.ModuleIdentity.IdentityString())
@@ -114,7 +125,27 @@ func run(
 			return bufcli.NewInternalError(err)
 		}
 	}
-	if err := bufmoduleref.PutDependencyModulePinsToBucket(ctx, readWriteBucket, dependencyModulePins); err != nil {
+	if len(localRequestReferences) > 0 {
+		moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, apiProvider)
+		if err != nil {
+			return err
+		}
+		localDependencyModulePins, err := bufmodulelocal.GetModulePins(
+			ctx,
+			bufmodulelocal.NewModuleResolver(storageos.NewProvider(), bufmodule.NewNopModuleResolver()),
+			moduleReader,
+			localRequestReferences,
+			nil,
+		)
+		if err != nil {
+			return err
+		}
+		dependencyModulePins, err = bufmoduleref.MergeModulePins(append(dependencyModulePins, localDependencyModulePins...))
+		if err != nil {
+			return err
+		}
+	}
+	if err := bufmoduleref.PutDependencyModulePinsToReadWriteBucket(ctx, readWriteBucket, dependencyModulePins); err != nil {
 		return err
 	}
 	return nil
@@ -23,9 +23,11 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufconnect"
 	"github.com/bufbuild/buf/private/bufpkg/buflock"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulelocal"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/gen/proto/api/buf/alpha/registry/v1alpha1/registryv1alpha1api"
-	modulev1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/bufbuild/buf/private/gen/proto/apiclient/buf/alpha/registry/v1alpha1/registryv1alpha1apiclient"
 	registryv1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/registry/v1alpha1"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
@@ -113,7 +115,7 @@ func run(
 	}
 
 	remote := bufconnect.DefaultRemote
-	if moduleConfig.ModuleIdentity != nil && moduleConfig.ModuleIdentity.Remote() != "" {
+	if moduleConfig.ModuleIdentity != nil && moduleConfig.ModuleIdentity.Remote() != "" && !bufmoduleref.IsFileRemote(moduleConfig.ModuleIdentity.Remote()) {
 		remote = moduleConfig.ModuleIdentity.Remote()
 	} else {
 		for _, moduleReference := range moduleConfig.Build.DependencyModuleReferences {
@@ -148,7 +150,7 @@ func run(
 		dependencyModulePins[i] = pinnedRepositories[i].modulePin
 		modulePin := pinnedRepositories[i].modulePin
 		repository := pinnedRepositories[i].repository
-		if !repository.Deprecated {
+		if repository == nil || !repository.Deprecated {
 			continue
 		}
 		warnMsg := fmt.Sprintf(
@@ -163,7 +165,7 @@ func run(
 		container.Logger().Warn(warnMsg)
 	}
 
-	if err := bufmoduleref.PutDependencyModulePinsToBucket(ctx, readWriteBucket, dependencyModulePins); err != nil {
+	if err := bufmoduleref.PutDependencyModulePinsToReadWriteBucket(ctx, readWriteBucket, dependencyModulePins); err != nil {
 		return bufcli.NewInternalError(err)
 	}
 	return nil
@@ -184,38 +186,70 @@ func getDependencies(
 	if err != nil {
 		return nil, err
 	}
-	service, err := apiProvider.NewResolveService(ctx, remote)
-	if err != nil {
-		return nil, err
-	}
-	var protoDependencyModuleReferences []*modulev1alpha1.ModuleReference
-	var currentProtoModulePins []*modulev1alpha1.ModulePin
+	dependencyModuleReferences := moduleConfig.Build.DependencyModuleReferences
+	var currentModulePins []bufmoduleref.ModulePin
 	if len(flags.Only) > 0 {
 		referencesByIdentity := map[string]bufmoduleref.ModuleReference{}
 		for _, reference := range moduleConfig.Build.DependencyModuleReferences {
 			referencesByIdentity[reference.IdentityString()] = reference
 		}
+		dependencyModuleReferences = nil
 		for _, only := range flags.Only {
 			moduleReference, ok := referencesByIdentity[only]
 			if !ok {
 				return nil, fmt.Errorf("%q is not a valid --only input: no such dependency in current module deps", only)
 			}
-			protoDependencyModuleReferences = append(protoDependencyModuleReferences, bufmoduleref.NewProtoModuleReferenceForModuleReference(moduleReference))
+			dependencyModuleReferences = append(dependencyModuleReferences, moduleReference)
 		}
-		currentModulePins, err := bufmoduleref.DependencyModulePinsForBucket(ctx, readWriteBucket)
+		currentModulePins, err = bufmoduleref.DependencyModulePinsForBucket(ctx, readWriteBucket)
 		if err != nil {
 			return nil, fmt.Errorf("couldn't read current dependencies: %w", err)
 		}
-		currentProtoModulePins = bufmoduleref.NewProtoModulePinsForModulePins(currentModulePins...)
-	} else {
-		protoDependencyModuleReferences = bufmoduleref.NewProtoModuleReferencesForModuleReferences(
-			moduleConfig.Build.DependencyModuleReferences...,
-		)
+	}
+	// Dependencies in local registries are resolved without the resolve service.
+	var localModuleReferences []bufmoduleref.ModuleReference
+	var remoteModuleReferences []bufmoduleref.ModuleReference
+	for _, moduleReference := range dependencyModuleReferences {
+		if bufmoduleref.IsFileRemote(moduleReference.Remote()) {
+			localModuleReferences = append(localModuleReferences, moduleReference)
+		} else {
+			remoteModuleReferences = append(remoteModuleReferences, moduleReference)
+		}
+	}
+	var localCurrentModulePins []bufmoduleref.ModulePin
+	var remoteCurrentModulePins []bufmoduleref.ModulePin
+	for _, modulePin := range currentModulePins {
+		if bufmoduleref.IsFileRemote(modulePin.Remote()) {
+			localCurrentModulePins = append(localCurrentModulePins, modulePin)
+		} else {
+			remoteCurrentModulePins = append(remoteCurrentModulePins, modulePin)
+		}
+	}
+	localPinnedRepositories, err := getLocalDependencies(
+		ctx,
+		container,
+		apiProvider,
+		localModuleReferences,
+		localCurrentModulePins,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(remoteModuleReferences) == 0 {
+		// Nothing to resolve remotely, keep the current pins.
+		for _, modulePin := range remoteCurrentModulePins {
+			localPinnedRepositories = append(localPinnedRepositories, &pinnedRepository{modulePin: modulePin})
+		}
+		return mergePinnedRepositories(localPinnedRepositories)
+	}
+	service, err := apiProvider.NewResolveService(ctx, remote)
+	if err != nil {
+		return nil, err
 	}
 	protoDependencyModulePins, err := service.GetModulePins(
 		ctx,
-		protoDependencyModuleReferences,
-		currentProtoModulePins,
+		bufmoduleref.NewProtoModuleReferencesForModuleReferences(remoteModuleReferences...),
+		bufmoduleref.NewProtoModulePinsForModulePins(remoteCurrentModulePins...),
 	)
 	if err != nil {
 		if connect.CodeOf(err) == connect.CodeUnimplemented && remote != bufconnect.DefaultRemote {
@@ -263,10 +297,70 @@ func getDependencies(
 		}
 		allPinnedRepositories = append(allPinnedRepositories, pinnedRepositories...)
 	}
-	return allPinnedRepositories, nil
+	return mergePinnedRepositories(append(allPinnedRepositories, localPinnedRepositories...))
+}
+
+// getLocalDependencies resolves the dependencies in local registries.
+func getLocalDependencies(
+	ctx context.Context,
+	container appflag.Container,
+	apiProvider registryv1alpha1apiclient.Provider,
+	moduleReferences []bufmoduleref.ModuleReference,
+	currentModulePins []bufmoduleref.ModulePin,
+) ([]*pinnedRepository, error) {
+	if len(moduleReferences) == 0 && len(currentModulePins) == 0 {
+		return nil, nil
+	}
+	// The dependencies of modules in local registries may themselves
+	// be hosted on a remote, so we use the regular ModuleReader.
+	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, apiProvider)
+	if err != nil {
+		return nil, err
+	}
+	modulePins, err := bufmodulelocal.GetModulePins(
+		ctx,
+		bufmodulelocal.NewModuleResolver(storageos.NewProvider(), bufmodule.NewNopModuleResolver()),
+		moduleReader,
+		moduleReferences,
+		currentModulePins,
+	)
+	if err != nil {
+		return nil, err
+	}
+	pinnedRepositories := make([]*pinnedRepository, len(modulePins))
+	for i, modulePin := range modulePins {
+		pinnedRepositories[i] = &pinnedRepository{
+			modulePin: modulePin,
+		}
+	}
+	return pinnedRepositories, nil
+}
+
+// mergePinnedRepositories deduplicates the pinned repositories by module identity,
+// and returns an error if the same module is pinned to different commits.
+func mergePinnedRepositories(pinnedRepositories []*pinnedRepository) ([]*pinnedRepository, error) {
+	modulePins := make([]bufmoduleref.ModulePin, 0, len(pinnedRepositories))
+	identityToPinnedRepository := make(map[string]*pinnedRepository, len(pinnedRepositories))
+	for _, pinnedRepository := range pinnedRepositories {
+		modulePins = append(modulePins, pinnedRepository.modulePin)
+		identity := pinnedRepository.modulePin.IdentityString()
+		if _, ok := identityToPinnedRepository[identity]; !ok {
+			identityToPinnedRepository[identity] = pinnedRepository
+		}
+	}
+	mergedModulePins, err := bufmoduleref.MergeModulePins(modulePins)
+	if err != nil {
+		return nil, err
+	}
+	mergedPinnedRepositories := make([]*pinnedRepository, 0, len(mergedModulePins))
+	for _, modulePin := range mergedModulePins {
+		mergedPinnedRepositories = append(mergedPinnedRepositories, identityToPinnedRepository[modulePin.IdentityString()])
+	}
+	return mergedPinnedRepositories, nil
 }
 
 type pinnedRepository struct {
-	modulePin  bufmoduleref.ModulePin
+	modulePin bufmoduleref.ModulePin
+	// This is nil for dependencies resolved from a local registry.
 	repository *registryv1alpha1.Repository
 }
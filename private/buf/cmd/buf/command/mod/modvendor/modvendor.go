@@ -0,0 +1,190 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modvendor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/bufpkg/buflock"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+)
+
+const recordMissingDigestsFlagName = "record-missing-digests"
+
+// NewCommand returns a new vendor Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <directory>",
+		Short: "Vendor a module's dependencies into the " + bufmodulebuild.VendorDirPath + " directory.",
+		Long: "Download every dependency pinned in the " + buflock.ExternalConfigFilePath + " file and write it to the " +
+			bufmodulebuild.VendorDirPath + " directory of the module, replacing anything previously vendored. " +
+			"Each dependency is verified against the digest recorded in the " + buflock.ExternalConfigFilePath + " file. " +
+			"Dependencies without a recorded digest are rejected unless --" + recordMissingDigestsFlagName + " is set. " +
+			"Vendored dependencies are used by builds of the module before the module cache is consulted, " +
+			"which allows the module to be built without network access. " +
+			`The first argument is the directory of the local module to vendor. Defaults to "." if no argument is specified.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	RecordMissingDigests bool
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	flagSet.BoolVar(
+		&f.RecordMissingDigests,
+		recordMissingDigestsFlagName,
+		false,
+		"Record the digest of the dependencies that have no digest in the "+buflock.ExternalConfigFilePath+" file. "+
+			"The downloaded content is trusted as is, so only set this if you trust the source of the dependencies.",
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	directoryInput, err := bufcli.GetInputValue(container, "", ".")
+	if err != nil {
+		return err
+	}
+	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+	readWriteBucket, err := storageosProvider.NewReadWriteBucket(
+		directoryInput,
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	if err != nil {
+		return err
+	}
+	existingConfigFilePath, err := bufconfig.ExistingConfigFilePath(ctx, readWriteBucket)
+	if err != nil {
+		return err
+	}
+	if existingConfigFilePath == "" {
+		return bufcli.ErrNoConfigFile
+	}
+	lockConfig, err := buflock.ReadConfig(ctx, readWriteBucket)
+	if err != nil {
+		return err
+	}
+	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+	if err != nil {
+		return err
+	}
+	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
+	if err != nil {
+		return err
+	}
+	modulePins := make([]bufmoduleref.ModulePin, len(lockConfig.Dependencies))
+	modules := make([]bufmodule.Module, len(lockConfig.Dependencies))
+	var lockConfigUpdated bool
+	// Fetch and verify every dependency before touching the vendor
+	// directory so that a failure does not leave it half-written.
+	for i, dependency := range lockConfig.Dependencies {
+		modulePin, err := bufmoduleref.NewModulePin(
+			dependency.Remote,
+			dependency.Owner,
+			dependency.Repository,
+			"",
+			dependency.Commit,
+			time.Time{},
+		)
+		if err != nil {
+			return err
+		}
+		if dependency.Digest == "" && !flags.RecordMissingDigests {
+			return fmt.Errorf(
+				"%s has no digest for dependency %q, set --%s to record it",
+				buflock.ExternalConfigFilePath,
+				modulePin.String(),
+				recordMissingDigestsFlagName,
+			)
+		}
+		module, err := moduleReader.GetModule(ctx, modulePin)
+		if err != nil {
+			return err
+		}
+		if dependency.Digest == "" {
+			digest, err := bufmodule.ModuleDigestB3(ctx, module)
+			if err != nil {
+				return err
+			}
+			container.Logger().Debug(
+				"recording_digest",
+				zap.String("module_pin", modulePin.String()),
+				zap.String("digest", digest),
+			)
+			lockConfig.Dependencies[i].Digest = digest
+			lockConfigUpdated = true
+		} else if err := bufmodulebuild.ValidateModuleDigest(ctx, modulePin, module, dependency.Digest); err != nil {
+			return err
+		}
+		modulePins[i] = modulePin
+		modules[i] = module
+	}
+	vendorReadWriteBucket := storage.MapReadWriteBucket(
+		readWriteBucket,
+		storage.MapOnPrefix(bufmodulebuild.VendorDirPath),
+	)
+	if err := vendorReadWriteBucket.DeleteAll(ctx, ""); err != nil {
+		return err
+	}
+	for i, module := range modules {
+		if err := bufmodule.ModuleToBucket(
+			ctx,
+			module,
+			storage.MapReadWriteBucket(
+				readWriteBucket,
+				storage.MapOnPrefix(bufmodulebuild.VendorPathForModulePin(modulePins[i])),
+			),
+		); err != nil {
+			return err
+		}
+	}
+	if lockConfigUpdated {
+		return buflock.WriteConfig(ctx, readWriteBucket, lockConfig)
+	}
+	return nil
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/applog"
+	"github.com/bufbuild/buf/private/pkg/app/appname"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
 	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/encoding"
@@ -100,7 +101,12 @@ func handle(
 	}
 	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
 	runner := command.NewRunner()
-	imageReader := bufcli.NewWireImageReader(logger, storageosProvider, runner)
+	// The plugin shares the cache of buf.
+	nameContainer, err := appname.NewContainer(container, "buf")
+	if err != nil {
+		return err
+	}
+	imageReader := bufcli.NewWireImageReader(logger, nameContainer, storageosProvider, runner)
 	againstImage, err := imageReader.GetImage(
 		ctx,
 		newContainer(container),
@@ -151,6 +157,11 @@ func handle(
 		if err := bufanalysis.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
 			return err
 		}
+		if !bufanalysis.HasErrors(fileAnnotations) {
+			// Only warnings and infos, these should not fail the plugin.
+			_, err := container.Stderr().Write(buffer.Bytes())
+			return err
+		}
 		responseWriter.AddError(strings.TrimSpace(buffer.String()))
 	}
 	return nil
@@ -21,6 +21,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
@@ -28,6 +30,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/applog"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/encoding"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"google.golang.org/protobuf/types/pluginpb"
@@ -103,8 +106,15 @@ func handle(
 	if err != nil {
 		return err
 	}
-	image = bufimage.ImageWithoutImports(image)
-	fileAnnotations, err := buflint.NewHandler(logger).Check(
+	fileAnnotations, err := buflint.NewHandler(
+		logger,
+		buflint.HandlerWithPluginRunner(
+			bufcheckplugin.NewRunner(
+				command.NewRunner(),
+				bufcheckplugin.RunnerWithEnv(app.EnvironMap(container)),
+			),
+		),
+	).Check(
 		ctx,
 		config.Lint,
 		image,
@@ -117,6 +127,11 @@ func handle(
 		if err := buflintconfig.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
 			return err
 		}
+		if !bufanalysis.HasErrors(fileAnnotations) {
+			// Only warnings and infos, these should not fail the plugin.
+			_, err := container.Stderr().Write(buffer.Bytes())
+			return err
+		}
 		responseWriter.AddError(strings.TrimSpace(buffer.String()))
 	}
 	return nil
@@ -32,6 +32,46 @@ const (
 	FormatMSVS
 	// FormatJUnit is the JUnit format for FileAnnotations.
 	FormatJUnit
+	// FormatSARIF is the SARIF 2.1.0 format for FileAnnotations.
+	FormatSARIF
+	// FormatGitHubActions is the GitHub Actions workflow command format for FileAnnotations.
+	FormatGitHubActions
+	// FormatGitLab is the GitLab Code Quality format for FileAnnotations.
+	FormatGitLab
+)
+
+const (
+	// SeverityError is the error severity.
+	//
+	// This is the default severity. Only FileAnnotations with this severity
+	// should result in a failure.
+	SeverityError Severity = iota + 1
+	// SeverityWarning is the warning severity.
+	SeverityWarning
+	// SeverityInfo is the info severity.
+	SeverityInfo
+)
+
+var (
+	// AllSeverityStrings is all severity strings.
+	//
+	// Sorted in the order we want to display them.
+	AllSeverityStrings = []string{
+		"error",
+		"warning",
+		"info",
+	}
+
+	stringToSeverity = map[string]Severity{
+		"error":   SeverityError,
+		"warning": SeverityWarning,
+		"info":    SeverityInfo,
+	}
+	severityToString = map[Severity]string{
+		SeverityError:   "error",
+		SeverityWarning: "warning",
+		SeverityInfo:    "info",
+	}
 )
 
 var (
@@ -43,6 +83,9 @@ var (
 		"json",
 		"msvs",
 		"junit",
+		"sarif",
+		"github-actions",
+		"gitlab",
 	}
 	// AllFormatStringsWithAliases is all format strings with aliases.
 	//
@@ -53,21 +96,30 @@ var (
 		"json",
 		"msvs",
 		"junit",
+		"sarif",
+		"github-actions",
+		"gitlab",
 	}
 
 	stringToFormat = map[string]Format{
 		"text": FormatText,
 		// alias for text
-		"gcc":   FormatText,
-		"json":  FormatJSON,
-		"msvs":  FormatMSVS,
-		"junit": FormatJUnit,
+		"gcc":            FormatText,
+		"json":           FormatJSON,
+		"msvs":           FormatMSVS,
+		"junit":          FormatJUnit,
+		"sarif":          FormatSARIF,
+		"github-actions": FormatGitHubActions,
+		"gitlab":         FormatGitLab,
 	}
 	formatToString = map[Format]string{
-		FormatText:  "text",
-		FormatJSON:  "json",
-		FormatMSVS:  "msvs",
-		FormatJUnit: "junit",
+		FormatText:          "text",
+		FormatJSON:          "json",
+		FormatMSVS:          "msvs",
+		FormatJUnit:         "junit",
+		FormatSARIF:         "sarif",
+		FormatGitHubActions: "github-actions",
+		FormatGitLab:        "gitlab",
 	}
 )
 
@@ -98,6 +150,33 @@ func ParseFormat(s string) (Format, error) {
 	return 0, fmt.Errorf("unknown format: %q", s)
 }
 
+// Severity is the severity of a FileAnnotation.
+type Severity int
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	str, ok := severityToString[s]
+	if !ok {
+		return strconv.Itoa(int(s))
+	}
+	return str
+}
+
+// ParseSeverity parses the Severity.
+//
+// The empty string defaults to SeverityError.
+func ParseSeverity(s string) (Severity, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return SeverityError, nil
+	}
+	severity, ok := stringToSeverity[s]
+	if ok {
+		return severity, nil
+	}
+	return 0, fmt.Errorf("unknown severity: %q", s)
+}
+
 // FileInfo is a minimal FileInfo interface.
 type FileInfo interface {
 	Path() string
@@ -138,6 +217,10 @@ type FileAnnotation interface {
 	Type() string
 	// Message is the message of the annotation.
 	Message() string
+	// Severity is the severity of the annotation.
+	//
+	// This will never be 0, and defaults to SeverityError.
+	Severity() Severity
 }
 
 // NewFileAnnotation returns a new FileAnnotation.
@@ -161,6 +244,32 @@ func NewFileAnnotation(
 	)
 }
 
+// FileAnnotationWithSeverity returns a copy of the FileAnnotation with the given Severity.
+func FileAnnotationWithSeverity(fileAnnotation FileAnnotation, severity Severity) FileAnnotation {
+	return newFileAnnotationWithSeverity(
+		fileAnnotation.FileInfo(),
+		fileAnnotation.StartLine(),
+		fileAnnotation.StartColumn(),
+		fileAnnotation.EndLine(),
+		fileAnnotation.EndColumn(),
+		fileAnnotation.Type(),
+		fileAnnotation.Message(),
+		severity,
+	)
+}
+
+// HasErrors returns true if any of the FileAnnotations has SeverityError.
+//
+// Only errors should result in a failure.
+func HasErrors(fileAnnotations []FileAnnotation) bool {
+	for _, fileAnnotation := range fileAnnotations {
+		if fileAnnotation.Severity() == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
 // SortFileAnnotations sorts the FileAnnotations.
 //
 // The order of sorting is:
@@ -194,11 +303,20 @@ func DeduplicateAndSortFileAnnotations(fileAnnotations []FileAnnotation) []FileA
 }
 
 // PrintFileAnnotations prints the file annotations separated by newlines.
-func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, formatString string) error {
+func PrintFileAnnotations(
+	writer io.Writer,
+	fileAnnotations []FileAnnotation,
+	formatString string,
+	options ...PrintFileAnnotationsOption,
+) error {
 	format, err := ParseFormat(formatString)
 	if err != nil {
 		return err
 	}
+	printFileAnnotationsOptions := newPrintFileAnnotationsOptions()
+	for _, option := range options {
+		option(printFileAnnotationsOptions)
+	}
 
 	switch format {
 	case FormatText:
@@ -209,11 +327,39 @@ func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, fo
 		return printAsMSVS(writer, fileAnnotations)
 	case FormatJUnit:
 		return printAsJUnit(writer, fileAnnotations)
+	case FormatSARIF:
+		return printAsSARIF(writer, fileAnnotations, printFileAnnotationsOptions.typeToDescription)
+	case FormatGitHubActions:
+		return printAsGitHubActions(writer, fileAnnotations)
+	case FormatGitLab:
+		return printAsGitLab(writer, fileAnnotations)
 	default:
 		return fmt.Errorf("unknown FileAnnotation Format: %v", format)
 	}
 }
 
+// PrintFileAnnotationsOption is an option for PrintFileAnnotations.
+type PrintFileAnnotationsOption func(*printFileAnnotationsOptions)
+
+// PrintFileAnnotationsWithTypeDescriptions returns a new PrintFileAnnotationsOption that
+// describes each FileAnnotation type, typically a rule ID.
+//
+// Only formats that carry rule metadata, such as FormatSARIF, use the descriptions.
+// Types without a description are still printed.
+func PrintFileAnnotationsWithTypeDescriptions(typeToDescription map[string]string) PrintFileAnnotationsOption {
+	return func(printFileAnnotationsOptions *printFileAnnotationsOptions) {
+		printFileAnnotationsOptions.typeToDescription = typeToDescription
+	}
+}
+
+type printFileAnnotationsOptions struct {
+	typeToDescription map[string]string
+}
+
+func newPrintFileAnnotationsOptions() *printFileAnnotationsOptions {
+	return &printFileAnnotationsOptions{}
+}
+
 // hash returns a hash value that uniquely identifies the given FileAnnotation.
 func hash(fileAnnotation FileAnnotation) string {
 	path := ""
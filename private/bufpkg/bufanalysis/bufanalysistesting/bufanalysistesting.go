@@ -151,14 +151,17 @@ func normalizeFileAnnotations(
 			)
 			require.NoError(t, err)
 		}
-		normalizedFileAnnotations[i] = bufanalysis.NewFileAnnotation(
-			fileInfo,
-			a.StartLine(),
-			a.StartColumn(),
-			a.EndLine(),
-			a.EndColumn(),
-			a.Type(),
-			"",
+		normalizedFileAnnotations[i] = bufanalysis.FileAnnotationWithSeverity(
+			bufanalysis.NewFileAnnotation(
+				fileInfo,
+				a.StartLine(),
+				a.StartColumn(),
+				a.EndLine(),
+				a.EndColumn(),
+				a.Type(),
+				"",
+			),
+			a.Severity(),
 		)
 	}
 	return normalizedFileAnnotations
@@ -15,6 +15,7 @@
 package bufanalysistesting
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -93,4 +94,281 @@ path/to/file.proto(2,1) : error FOO : Hello.
 `,
 		sb.String(),
 	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(
+		sb,
+		fileAnnotations,
+		"sarif",
+		bufanalysis.PrintFileAnnotationsWithTypeDescriptions(
+			map[string]string{
+				"FOO": "Checks that foo.",
+			},
+		),
+	)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "buf",
+          "informationUri": "https://github.com/bufbuild/buf",
+          "rules": [
+            {
+              "id": "FOO",
+              "shortDescription": {
+                "text": "Checks that foo."
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "FOO",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "Hello."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 1,
+                  "endLine": 1
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "FOO",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "Hello."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 1,
+                  "endLine": 2,
+                  "endColumn": 1
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+		sb.String(),
+	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "github-actions")
+	require.NoError(t, err)
+	assert.Equal(t,
+		`::error file=path/to/file.proto,line=1,endLine=1,title=FOO::Hello.
+::error file=path/to/file.proto,line=2,col=1,endLine=2,endColumn=1,title=FOO::Hello.
+`,
+		sb.String(),
+	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "gitlab")
+	require.NoError(t, err)
+	assert.Equal(t,
+		`[{"description":"Hello.","check_name":"FOO","fingerprint":"4a174fb828d67accfd27ea1b361a0b281fc1cb3d98a1e6c0db161d64a7b7f57e","severity":"major","location":{"path":"path/to/file.proto","lines":{"begin":1,"end":1}}},{"description":"Hello.","check_name":"FOO","fingerprint":"4a174fb828d67accfd27ea1b361a0b281fc1cb3d98a1e6c0db161d64a7b7f57e","severity":"major","location":{"path":"path/to/file.proto","lines":{"begin":2,"end":2}}}]
+`,
+		sb.String(),
+	)
+}
+
+func TestGitLabFingerprint(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			1,
+			1,
+			1,
+			5,
+			"FOO",
+			"Hello.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			10,
+			2,
+			12,
+			3,
+			"FOO",
+			"Hello.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			1,
+			1,
+			1,
+			5,
+			"FOO",
+			"Goodbye.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/other.proto",
+			1,
+			1,
+			1,
+			5,
+			"FOO",
+			"Hello.",
+		),
+	}
+	sb := &strings.Builder{}
+	err := bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "gitlab")
+	require.NoError(t, err)
+	var issues []struct {
+		Fingerprint string `json:"fingerprint"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(sb.String()), &issues))
+	require.Len(t, issues, 4)
+	// The fingerprint does not change when the issue moves within the file.
+	assert.Equal(t, issues[0].Fingerprint, issues[1].Fingerprint)
+	assert.NotEqual(t, issues[0].Fingerprint, issues[2].Fingerprint)
+	assert.NotEqual(t, issues[0].Fingerprint, issues[3].Fingerprint)
+}
+
+func TestSeverity(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			1,
+			1,
+			1,
+			1,
+			"FOO",
+			"Hello.",
+		),
+		bufanalysis.FileAnnotationWithSeverity(
+			newFileAnnotation(
+				t,
+				"path/to/file.proto",
+				2,
+				1,
+				2,
+				1,
+				"BAR",
+				"Hello.",
+			),
+			bufanalysis.SeverityWarning,
+		),
+		bufanalysis.FileAnnotationWithSeverity(
+			newFileAnnotation(
+				t,
+				"path/to/file.proto",
+				3,
+				1,
+				3,
+				1,
+				"BAZ",
+				"Hello.",
+			),
+			bufanalysis.SeverityInfo,
+		),
+	}
+	assert.True(t, bufanalysis.HasErrors(fileAnnotations))
+	assert.False(t, bufanalysis.HasErrors(fileAnnotations[1:]))
+	sb := &strings.Builder{}
+	err := bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "text")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`path/to/file.proto:1:1:Hello.
+path/to/file.proto:2:1:warning: Hello.
+path/to/file.proto:3:1:info: Hello.
+`,
+		sb.String(),
+	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "json")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"path":"path/to/file.proto","start_line":1,"start_column":1,"end_line":1,"end_column":1,"type":"FOO","message":"Hello."}
+{"path":"path/to/file.proto","start_line":2,"start_column":1,"end_line":2,"end_column":1,"type":"BAR","message":"Hello.","severity":"warning"}
+{"path":"path/to/file.proto","start_line":3,"start_column":1,"end_line":3,"end_column":1,"type":"BAZ","message":"Hello.","severity":"info"}
+`,
+		sb.String(),
+	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "msvs")
+	require.NoError(t, err)
+	assert.Equal(t,
+		`path/to/file.proto(1,1) : error FOO : Hello.
+path/to/file.proto(2,1) : warning BAR : Hello.
+path/to/file.proto(3,1) : info BAZ : Hello.
+`,
+		sb.String(),
+	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "junit")
+	require.NoError(t, err)
+	assert.Equal(t,
+		`<testsuites>
+  <testsuite name="path/to/file" tests="3" failures="1" errors="0">
+    <testcase name="FOO_1_1">
+      <failure message="path/to/file.proto:1:1:Hello." type="FOO"></failure>
+    </testcase>
+    <testcase name="BAR_2_1">
+      <system-out>path/to/file.proto:2:1:warning: Hello.</system-out>
+    </testcase>
+    <testcase name="BAZ_3_1">
+      <system-out>path/to/file.proto:3:1:info: Hello.</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		sb.String(),
+	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "github-actions")
+	require.NoError(t, err)
+	assert.Equal(t,
+		`::error file=path/to/file.proto,line=1,col=1,endLine=1,endColumn=1,title=FOO::Hello.
+::warning file=path/to/file.proto,line=2,col=1,endLine=2,endColumn=1,title=BAR::Hello.
+::notice file=path/to/file.proto,line=3,col=1,endLine=3,endColumn=1,title=BAZ::Hello.
+`,
+		sb.String(),
+	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "sarif")
+	require.NoError(t, err)
+	assert.Contains(t, sb.String(), `"level": "error"`)
+	assert.Contains(t, sb.String(), `"level": "warning"`)
+	assert.Contains(t, sb.String(), `"level": "note"`)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "gitlab")
+	require.NoError(t, err)
+	assert.Contains(t, sb.String(), `"severity":"major"`)
+	assert.Contains(t, sb.String(), `"severity":"minor"`)
+	assert.Contains(t, sb.String(), `"severity":"info"`)
 }
@@ -27,6 +27,7 @@ type fileAnnotation struct {
 	endColumn   int
 	typeString  string
 	message     string
+	severity    Severity
 }
 
 func newFileAnnotation(
@@ -38,6 +39,31 @@ func newFileAnnotation(
 	typeString string,
 	message string,
 ) *fileAnnotation {
+	return newFileAnnotationWithSeverity(
+		fileInfo,
+		startLine,
+		startColumn,
+		endLine,
+		endColumn,
+		typeString,
+		message,
+		SeverityError,
+	)
+}
+
+func newFileAnnotationWithSeverity(
+	fileInfo FileInfo,
+	startLine int,
+	startColumn int,
+	endLine int,
+	endColumn int,
+	typeString string,
+	message string,
+	severity Severity,
+) *fileAnnotation {
+	if severity == 0 {
+		severity = SeverityError
+	}
 	return &fileAnnotation{
 		fileInfo:    fileInfo,
 		startLine:   startLine,
@@ -46,6 +72,7 @@ func newFileAnnotation(
 		endColumn:   endColumn,
 		typeString:  typeString,
 		message:     message,
+		severity:    severity,
 	}
 }
 
@@ -77,6 +104,10 @@ func (f *fileAnnotation) Message() string {
 	return f.message
 }
 
+func (f *fileAnnotation) Severity() Severity {
+	return f.severity
+}
+
 func (f *fileAnnotation) String() string {
 	if f == nil {
 		return ""
@@ -108,6 +139,11 @@ func (f *fileAnnotation) String() string {
 	_, _ = buffer.WriteRune(':')
 	_, _ = buffer.WriteString(strconv.Itoa(column))
 	_, _ = buffer.WriteRune(':')
+	// errors are not prefixed to keep the output stable
+	if f.severity != SeverityError {
+		_, _ = buffer.WriteString(f.severity.String())
+		_, _ = buffer.WriteString(": ")
+	}
 	_, _ = buffer.WriteString(message)
 	return buffer.String()
 }
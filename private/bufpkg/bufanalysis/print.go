@@ -16,14 +16,24 @@ package bufanalysis
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
+const (
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion        = "2.1.0"
+	sarifToolName       = "buf"
+	sarifInformationURI = "https://github.com/bufbuild/buf"
+)
+
 func printAsText(writer io.Writer, fileAnnotations []FileAnnotation) error {
 	return printEachAnnotationOnNewLine(
 		writer,
@@ -48,6 +58,24 @@ func printAsJSON(writer io.Writer, fileAnnotations []FileAnnotation) error {
 	)
 }
 
+func printAsGitHubActions(writer io.Writer, fileAnnotations []FileAnnotation) error {
+	return printEachAnnotationOnNewLine(
+		writer,
+		fileAnnotations,
+		printFileAnnotationAsGitHubActions,
+	)
+}
+
+func printAsGitLab(writer io.Writer, fileAnnotations []FileAnnotation) error {
+	externalGitLabIssues := make([]externalGitLabIssue, 0, len(fileAnnotations))
+	for _, fileAnnotation := range fileAnnotations {
+		externalGitLabIssues = append(externalGitLabIssues, newExternalGitLabIssue(fileAnnotation))
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(externalGitLabIssues)
+}
+
 func printAsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
 	encoder := xml.NewEncoder(writer)
 	encoder.Indent("", "  ")
@@ -68,7 +96,7 @@ func printAsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
 			Attr: []xml.Attr{
 				{Name: xml.Name{Local: "name"}, Value: path},
 				{Name: xml.Name{Local: "tests"}, Value: strconv.Itoa(len(annotations))},
-				{Name: xml.Name{Local: "failures"}, Value: strconv.Itoa(len(annotations))},
+				{Name: xml.Name{Local: "failures"}, Value: strconv.Itoa(countErrors(annotations))},
 				{Name: xml.Name{Local: "errors"}, Value: "0"},
 			},
 		}
@@ -96,6 +124,105 @@ func printAsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
 	return nil
 }
 
+func printAsSARIF(writer io.Writer, fileAnnotations []FileAnnotation, typeToDescription map[string]string) error {
+	rules := make([]*externalSARIFRule, 0)
+	typeToRuleIndex := make(map[string]int)
+	results := make([]*externalSARIFResult, 0, len(fileAnnotations))
+	for _, fileAnnotation := range fileAnnotations {
+		typeString := fileAnnotation.Type()
+		if typeString == "" {
+			// should never happen but just in case
+			typeString = "FAILURE"
+		}
+		ruleIndex, ok := typeToRuleIndex[typeString]
+		if !ok {
+			ruleIndex = len(rules)
+			typeToRuleIndex[typeString] = ruleIndex
+			rule := &externalSARIFRule{
+				ID: typeString,
+			}
+			if description := typeToDescription[typeString]; description != "" {
+				rule.ShortDescription = &externalSARIFMessage{
+					Text: description,
+				}
+			}
+			rules = append(rules, rule)
+		}
+		results = append(results, newExternalSARIFResult(fileAnnotation, typeString, ruleIndex))
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(
+		&externalSARIFLog{
+			Schema:  sarifSchema,
+			Version: sarifVersion,
+			Runs: []*externalSARIFRun{
+				{
+					Tool: &externalSARIFTool{
+						Driver: &externalSARIFDriver{
+							Name:           sarifToolName,
+							InformationURI: sarifInformationURI,
+							Rules:          rules,
+						},
+					},
+					Results: results,
+				},
+			},
+		},
+	)
+}
+
+func sarifLevelForSeverity(severity Severity) string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+func newExternalSARIFResult(f FileAnnotation, typeString string, ruleIndex int) *externalSARIFResult {
+	message := f.Message()
+	if message == "" {
+		message = typeString
+	}
+	result := &externalSARIFResult{
+		RuleID:    typeString,
+		RuleIndex: ruleIndex,
+		Level:     sarifLevelForSeverity(f.Severity()),
+		Message: &externalSARIFMessage{
+			Text: message,
+		},
+	}
+	if f.FileInfo() == nil {
+		return result
+	}
+	location := &externalSARIFPhysicalLocation{
+		ArtifactLocation: &externalSARIFArtifactLocation{
+			URI: filepath.ToSlash(f.FileInfo().ExternalPath()),
+		},
+	}
+	// SARIF requires line and column numbers to be greater than 0 if set,
+	// so we omit the region entirely if we do not know the starting line.
+	if f.StartLine() > 0 {
+		location.Region = &externalSARIFRegion{
+			StartLine:   f.StartLine(),
+			StartColumn: f.StartColumn(),
+			EndLine:     f.EndLine(),
+			EndColumn:   f.EndColumn(),
+		}
+	}
+	result.Locations = []*externalSARIFLocation{
+		{
+			PhysicalLocation: location,
+		},
+	}
+	return result
+}
+
 func printFileAnnotationAsJUnit(encoder *xml.Encoder, annotation FileAnnotation) error {
 	testcase := xml.StartElement{Name: xml.Name{Local: "testcase"}}
 	name := annotation.Type()
@@ -108,6 +235,20 @@ func printFileAnnotationAsJUnit(encoder *xml.Encoder, annotation FileAnnotation)
 	if err := encoder.EncodeToken(testcase); err != nil {
 		return err
 	}
+	if annotation.Severity() != SeverityError {
+		// Warnings and infos do not fail the test case, so they are printed as output instead.
+		systemOut := xml.StartElement{Name: xml.Name{Local: "system-out"}}
+		if err := encoder.EncodeToken(systemOut); err != nil {
+			return err
+		}
+		if err := encoder.EncodeToken(xml.CharData(annotation.String())); err != nil {
+			return err
+		}
+		if err := encoder.EncodeToken(xml.EndElement{Name: systemOut.Name}); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(xml.EndElement{Name: testcase.Name})
+	}
 	failure := xml.StartElement{
 		Name: xml.Name{Local: "failure"},
 		Attr: []xml.Attr{
@@ -127,6 +268,16 @@ func printFileAnnotationAsJUnit(encoder *xml.Encoder, annotation FileAnnotation)
 	return nil
 }
 
+func countErrors(annotations []FileAnnotation) int {
+	var count int
+	for _, annotation := range annotations {
+		if annotation.Severity() == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
 func groupAnnotationsByPath(annotations []FileAnnotation) [][]FileAnnotation {
 	pathToIndex := make(map[string]int)
 	annotationsByPath := make([][]FileAnnotation, 0)
@@ -185,13 +336,93 @@ func printFileAnnotationAsMSVS(buffer *bytes.Buffer, f FileAnnotation) error {
 		_, _ = buffer.WriteRune(',')
 		_, _ = buffer.WriteString(strconv.Itoa(column))
 	}
-	_, _ = buffer.WriteString(") : error ")
+	_, _ = buffer.WriteString(") : ")
+	_, _ = buffer.WriteString(f.Severity().String())
+	_, _ = buffer.WriteRune(' ')
 	_, _ = buffer.WriteString(typeString)
 	_, _ = buffer.WriteString(" : ")
 	_, _ = buffer.WriteString(message)
 	return nil
 }
 
+func printFileAnnotationAsGitHubActions(buffer *bytes.Buffer, f FileAnnotation) error {
+	// This will work as long as f != (*fileAnnotation)(nil)
+	if f == nil {
+		return nil
+	}
+	typeString := f.Type()
+	if typeString == "" {
+		// should never happen but just in case
+		typeString = "FAILURE"
+	}
+	message := f.Message()
+	if message == "" {
+		message = typeString
+	}
+	_, _ = buffer.WriteString("::")
+	_, _ = buffer.WriteString(gitHubActionsCommandForSeverity(f.Severity()))
+	_, _ = buffer.WriteRune(' ')
+	if f.FileInfo() != nil {
+		_, _ = buffer.WriteString("file=")
+		_, _ = buffer.WriteString(escapeGitHubActionsProperty(f.FileInfo().ExternalPath()))
+		_, _ = buffer.WriteRune(',')
+		if line := f.StartLine(); line != 0 {
+			_, _ = buffer.WriteString("line=")
+			_, _ = buffer.WriteString(strconv.Itoa(line))
+			_, _ = buffer.WriteRune(',')
+			if column := f.StartColumn(); column != 0 {
+				_, _ = buffer.WriteString("col=")
+				_, _ = buffer.WriteString(strconv.Itoa(column))
+				_, _ = buffer.WriteRune(',')
+			}
+			if endLine := f.EndLine(); endLine != 0 {
+				_, _ = buffer.WriteString("endLine=")
+				_, _ = buffer.WriteString(strconv.Itoa(endLine))
+				_, _ = buffer.WriteRune(',')
+				if endColumn := f.EndColumn(); endColumn != 0 {
+					_, _ = buffer.WriteString("endColumn=")
+					_, _ = buffer.WriteString(strconv.Itoa(endColumn))
+					_, _ = buffer.WriteRune(',')
+				}
+			}
+		}
+	}
+	_, _ = buffer.WriteString("title=")
+	_, _ = buffer.WriteString(escapeGitHubActionsProperty(typeString))
+	_, _ = buffer.WriteString("::")
+	_, _ = buffer.WriteString(escapeGitHubActionsData(message))
+	return nil
+}
+
+// gitHubActionsCommandForSeverity returns the GitHub Actions workflow command
+// that annotates files with the given Severity.
+func gitHubActionsCommandForSeverity(severity Severity) string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "notice"
+	default:
+		return "error"
+	}
+}
+
+// escapeGitHubActionsData escapes the message of a GitHub Actions workflow command.
+//
+// See https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+func escapeGitHubActionsData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeGitHubActionsProperty escapes a property value of a GitHub Actions workflow command.
+func escapeGitHubActionsProperty(s string) string {
+	s = escapeGitHubActionsData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
 func printFileAnnotationAsJSON(buffer *bytes.Buffer, f FileAnnotation) error {
 	data, err := json.Marshal(newExternalFileAnnotation(f))
 	if err != nil {
@@ -209,6 +440,8 @@ type externalFileAnnotation struct {
 	EndColumn   int    `json:"end_column,omitempty" yaml:"end_column,omitempty"`
 	Type        string `json:"type,omitempty" yaml:"type,omitempty"`
 	Message     string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Severity is omitted for errors to keep the output stable.
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
 }
 
 func newExternalFileAnnotation(f FileAnnotation) externalFileAnnotation {
@@ -216,7 +449,7 @@ func newExternalFileAnnotation(f FileAnnotation) externalFileAnnotation {
 	if f.FileInfo() != nil {
 		path = f.FileInfo().ExternalPath()
 	}
-	return externalFileAnnotation{
+	externalFileAnnotation := externalFileAnnotation{
 		Path:        path,
 		StartLine:   f.StartLine(),
 		StartColumn: f.StartColumn(),
@@ -225,6 +458,147 @@ func newExternalFileAnnotation(f FileAnnotation) externalFileAnnotation {
 		Type:        f.Type(),
 		Message:     f.Message(),
 	}
+	if severity := f.Severity(); severity != SeverityError {
+		externalFileAnnotation.Severity = severity.String()
+	}
+	return externalFileAnnotation
+}
+
+// externalGitLabIssue is an issue in the GitLab Code Quality report format, which
+// is a subset of the Code Climate issue format.
+//
+// See https://docs.gitlab.com/ee/ci/testing/code_quality.html#implement-a-custom-tool
+type externalGitLabIssue struct {
+	Description string                       `json:"description"`
+	CheckName   string                       `json:"check_name"`
+	Fingerprint string                       `json:"fingerprint"`
+	Severity    string                       `json:"severity"`
+	Location    *externalGitLabIssueLocation `json:"location"`
+}
+
+type externalGitLabIssueLocation struct {
+	Path  string                    `json:"path"`
+	Lines *externalGitLabIssueLines `json:"lines"`
+}
+
+type externalGitLabIssueLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+func gitLabSeverityForSeverity(severity Severity) string {
+	switch severity {
+	case SeverityWarning:
+		return "minor"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "major"
+	}
+}
+
+func newExternalGitLabIssue(f FileAnnotation) externalGitLabIssue {
+	path := "<input>"
+	if f.FileInfo() != nil {
+		path = filepath.ToSlash(f.FileInfo().ExternalPath())
+	}
+	typeString := f.Type()
+	if typeString == "" {
+		// should never happen but just in case
+		typeString = "FAILURE"
+	}
+	message := f.Message()
+	if message == "" {
+		message = typeString
+	}
+	// GitLab requires a line number, and lines are 1-indexed.
+	beginLine := f.StartLine()
+	if beginLine == 0 {
+		beginLine = 1
+	}
+	return externalGitLabIssue{
+		Description: message,
+		CheckName:   typeString,
+		Fingerprint: gitLabFingerprint(path, typeString, message),
+		Severity:    gitLabSeverityForSeverity(f.Severity()),
+		Location: &externalGitLabIssueLocation{
+			Path: path,
+			Lines: &externalGitLabIssueLines{
+				Begin: beginLine,
+				End:   f.EndLine(),
+			},
+		},
+	}
+}
+
+// gitLabFingerprint returns the fingerprint of an issue.
+//
+// The location within the file is not included, so that the fingerprint stays
+// the same when lines are added or removed above the issue.
+func gitLabFingerprint(path string, typeString string, message string) string {
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(path))
+	_, _ = hash.Write([]byte(typeString))
+	_, _ = hash.Write([]byte(message))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type externalSARIFLog struct {
+	Schema  string              `json:"$schema"`
+	Version string              `json:"version"`
+	Runs    []*externalSARIFRun `json:"runs"`
+}
+
+type externalSARIFRun struct {
+	Tool    *externalSARIFTool     `json:"tool"`
+	Results []*externalSARIFResult `json:"results"`
+}
+
+type externalSARIFTool struct {
+	Driver *externalSARIFDriver `json:"driver"`
+}
+
+type externalSARIFDriver struct {
+	Name           string               `json:"name"`
+	InformationURI string               `json:"informationUri,omitempty"`
+	Rules          []*externalSARIFRule `json:"rules"`
+}
+
+type externalSARIFRule struct {
+	ID               string                `json:"id"`
+	ShortDescription *externalSARIFMessage `json:"shortDescription,omitempty"`
+}
+
+type externalSARIFResult struct {
+	RuleID    string                   `json:"ruleId"`
+	RuleIndex int                      `json:"ruleIndex"`
+	Level     string                   `json:"level"`
+	Message   *externalSARIFMessage    `json:"message"`
+	Locations []*externalSARIFLocation `json:"locations,omitempty"`
+}
+
+type externalSARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type externalSARIFLocation struct {
+	PhysicalLocation *externalSARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type externalSARIFPhysicalLocation struct {
+	ArtifactLocation *externalSARIFArtifactLocation `json:"artifactLocation"`
+	Region           *externalSARIFRegion           `json:"region,omitempty"`
+}
+
+type externalSARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type externalSARIFRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
 }
 
 func printEachAnnotationOnNewLine(
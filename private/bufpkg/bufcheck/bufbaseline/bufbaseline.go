@@ -0,0 +1,205 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufbaseline contains baselines of known lint and breaking change violations.
+//
+// A Baseline is used to only report violations that were introduced after the Baseline
+// was written, which allows adopting new rules on existing modules incrementally.
+package bufbaseline
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/bufbuild/buf/private/pkg/encoding"
+)
+
+const (
+	// V1Version is the v1 version.
+	V1Version = "v1"
+	// Header is the header prepended to any baseline files.
+	Header = "# Generated by buf. DO NOT EDIT.\n"
+)
+
+// Entry is a single known violation.
+//
+// Entries are keyed on the rule ID, the file path, and the element path instead of
+// on line numbers, so that unrelated edits to a file do not invalidate them.
+type Entry struct {
+	// ID is the ID of the rule that reported the violation.
+	ID string
+	// Path is the path of the file the violation was reported on.
+	Path string
+	// ElementPath is the fully-qualified name of the element the violation was reported on.
+	//
+	// This is the import path for imports, and empty for violations on files.
+	ElementPath string
+}
+
+// Baseline is a set of known violations.
+//
+// The same Entry may be contained multiple times if multiple violations were
+// reported for the same element.
+type Baseline struct {
+	entryToCount map[Entry]int
+}
+
+// NewBaseline returns a new Baseline for the Entries.
+func NewBaseline(entries ...Entry) *Baseline {
+	entryToCount := make(map[Entry]int, len(entries))
+	for _, entry := range entries {
+		entryToCount[entry]++
+	}
+	return &Baseline{
+		entryToCount: entryToCount,
+	}
+}
+
+// Entries returns the Entries in the Baseline.
+//
+// Sorted by path, element path, and ID.
+func (b *Baseline) Entries() []Entry {
+	var entries []Entry
+	for entry, count := range b.entryToCount {
+		for i := 0; i < count; i++ {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(
+		entries,
+		func(i int, j int) bool {
+			one := entries[i]
+			two := entries[j]
+			if one.Path != two.Path {
+				return one.Path < two.Path
+			}
+			if one.ElementPath != two.ElementPath {
+				return one.ElementPath < two.ElementPath
+			}
+			return one.ID < two.ID
+		},
+	)
+	return entries
+}
+
+// NewMatcher returns a new Matcher for the Baseline.
+func (b *Baseline) NewMatcher() *Matcher {
+	entryToCount := make(map[Entry]int, len(b.entryToCount))
+	for entry, count := range b.entryToCount {
+		entryToCount[entry] = count
+	}
+	return &Matcher{
+		entryToCount: entryToCount,
+	}
+}
+
+// Matcher matches violations against a Baseline.
+//
+// Each Entry in the Baseline matches at most as many violations as it is contained
+// in the Baseline, so that new violations on an element with known violations are
+// still reported.
+//
+// Safe for concurrent use.
+type Matcher struct {
+	entryToCount map[Entry]int
+	lock         sync.Mutex
+}
+
+// Match returns true if the Entry is in the Baseline and has not been matched yet.
+func (m *Matcher) Match(entry Entry) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.entryToCount[entry] == 0 {
+		return false
+	}
+	m.entryToCount[entry]--
+	return true
+}
+
+// ReadBaseline reads a Baseline from the Reader.
+func ReadBaseline(reader io.Reader) (*Baseline, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var externalBaselineVersion externalBaselineVersion
+	if err := encoding.UnmarshalYAMLNonStrict(data, &externalBaselineVersion); err != nil {
+		return nil, fmt.Errorf("failed to decode baseline as YAML: %w", err)
+	}
+	switch externalBaselineVersion.Version {
+	case V1Version:
+		var externalBaseline externalBaselineV1
+		if err := encoding.UnmarshalYAMLStrict(data, &externalBaseline); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal baseline at %s: %w", V1Version, err)
+		}
+		entries := make([]Entry, len(externalBaseline.Entries))
+		for i, externalEntry := range externalBaseline.Entries {
+			if externalEntry.ID == "" {
+				return nil, fmt.Errorf("baseline entry %d has no id", i)
+			}
+			if externalEntry.Path == "" {
+				return nil, fmt.Errorf("baseline entry %d has no path", i)
+			}
+			entries[i] = Entry{
+				ID:          externalEntry.ID,
+				Path:        externalEntry.Path,
+				ElementPath: externalEntry.Element,
+			}
+		}
+		return NewBaseline(entries...), nil
+	default:
+		return nil, fmt.Errorf("unknown baseline version %q", externalBaselineVersion.Version)
+	}
+}
+
+// WriteBaseline writes the Baseline to the Writer.
+func WriteBaseline(writer io.Writer, baseline *Baseline) error {
+	entries := baseline.Entries()
+	externalBaseline := externalBaselineV1{
+		Version: V1Version,
+		Entries: make([]externalEntryV1, len(entries)),
+	}
+	for i, entry := range entries {
+		externalBaseline.Entries[i] = externalEntryV1{
+			ID:      entry.ID,
+			Path:    entry.Path,
+			Element: entry.ElementPath,
+		}
+	}
+	data, err := encoding.MarshalYAML(&externalBaseline)
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+	if _, err := writer.Write(append([]byte(Header), data...)); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+type externalBaselineVersion struct {
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+type externalBaselineV1 struct {
+	Version string            `json:"version,omitempty" yaml:"version,omitempty"`
+	Entries []externalEntryV1 `json:"entries,omitempty" yaml:"entries,omitempty"`
+}
+
+type externalEntryV1 struct {
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+	Element string `json:"element,omitempty" yaml:"element,omitempty"`
+}
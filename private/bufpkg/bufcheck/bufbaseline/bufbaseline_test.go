@@ -0,0 +1,81 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbaseline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	baseline := NewBaseline(
+		Entry{ID: "FIELD_LOWER_SNAKE_CASE", Path: "b.proto", ElementPath: "a.Foo.barBaz"},
+		Entry{ID: "PACKAGE_VERSION_SUFFIX", Path: "a.proto"},
+		Entry{ID: "FIELD_NO_DELETE", Path: "a.proto", ElementPath: "a.Foo"},
+		Entry{ID: "FIELD_NO_DELETE", Path: "a.proto", ElementPath: "a.Foo"},
+	)
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, WriteBaseline(buffer, baseline))
+	assert.Equal(
+		t,
+		`# Generated by buf. DO NOT EDIT.
+version: v1
+entries:
+  - id: PACKAGE_VERSION_SUFFIX
+    path: a.proto
+  - id: FIELD_NO_DELETE
+    path: a.proto
+    element: a.Foo
+  - id: FIELD_NO_DELETE
+    path: a.proto
+    element: a.Foo
+  - id: FIELD_LOWER_SNAKE_CASE
+    path: b.proto
+    element: a.Foo.barBaz
+`,
+		buffer.String(),
+	)
+	readBaseline, err := ReadBaseline(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, baseline.Entries(), readBaseline.Entries())
+}
+
+func TestReadBaselineError(t *testing.T) {
+	t.Parallel()
+	_, err := ReadBaseline(strings.NewReader("version: v2\n"))
+	assert.Error(t, err)
+	_, err = ReadBaseline(strings.NewReader("version: v1\nentries:\n  - path: a.proto\n"))
+	assert.Error(t, err)
+	_, err = ReadBaseline(strings.NewReader("version: v1\nunknown: true\n"))
+	assert.Error(t, err)
+}
+
+func TestMatcher(t *testing.T) {
+	t.Parallel()
+	entry := Entry{ID: "FIELD_NO_DELETE", Path: "a.proto", ElementPath: "a.Foo"}
+	baseline := NewBaseline(entry, entry)
+	matcher := baseline.NewMatcher()
+	assert.True(t, matcher.Match(entry))
+	assert.True(t, matcher.Match(entry))
+	assert.False(t, matcher.Match(entry))
+	assert.False(t, matcher.Match(Entry{ID: "FIELD_NO_DELETE", Path: "a.proto", ElementPath: "a.Bar"}))
+	// matching does not modify the Baseline
+	assert.True(t, baseline.NewMatcher().Match(entry))
+}
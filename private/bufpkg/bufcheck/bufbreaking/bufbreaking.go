@@ -19,9 +19,12 @@ package bufbreaking
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1beta1"
@@ -44,7 +47,80 @@ type Handler interface {
 		config *bufbreakingconfig.Config,
 		previousImage bufimage.Image,
 		image bufimage.Image,
+		options ...CheckOption,
 	) ([]bufanalysis.FileAnnotation, error)
+	// Baseline runs the breaking checks and returns a Baseline containing all violations.
+	//
+	// Passing the Baseline to Check with CheckWithBaseline results in only new violations being reported.
+	Baseline(
+		ctx context.Context,
+		config *bufbreakingconfig.Config,
+		previousImage bufimage.Image,
+		image bufimage.Image,
+	) (*bufbaseline.Baseline, error)
+	// Diff returns the changes between the previousImage and the image.
+	//
+	// A Change is breaking if it is detected by a rule that is enabled
+	// and not ignored by the config.
+	//
+	// Images should be filtered with regards to imports before passing to this function.
+	Diff(
+		ctx context.Context,
+		config *bufbreakingconfig.Config,
+		previousImage bufimage.Image,
+		image bufimage.Image,
+	) ([]Change, error)
+}
+
+// Change is a semantic change between two Images.
+type Change interface {
+	json.Marshaler
+
+	// Kind is one of "added", "removed", or "changed".
+	Kind() string
+	// ElementType is one of "file", "message", "field", "enum", "enum_value",
+	// "service", "rpc", or "option".
+	ElementType() string
+	// Name is the fully-qualified name of the element, or the file path for files.
+	//
+	// For options, this is the name of the element the option is set on.
+	Name() string
+	// Path is the path of the file that contains the element.
+	Path() string
+	// Description is a full sentence describing the change.
+	Description() string
+	// Breaking returns true if any enabled rule detects this change as breaking.
+	Breaking() bool
+	// RuleIDs are the IDs of all enabled rules that detect this change as breaking.
+	//
+	// Sorted.
+	RuleIDs() []string
+}
+
+// AllChangeFormatStrings are all the format strings for PrintChanges.
+var AllChangeFormatStrings = []string{
+	"text",
+	"json",
+	"markdown",
+}
+
+// PrintChanges prints the Changes to the Writer in the given format.
+//
+// The format string must be one of AllChangeFormatStrings, or empty, in which
+// case the text format is used.
+func PrintChanges(writer io.Writer, changes []Change, formatString string) error {
+	return printChanges(writer, changes, formatString)
+}
+
+// CheckOption is an option for Check.
+type CheckOption func(*checkOptions)
+
+// CheckWithBaseline returns a new CheckOption that does not report the violations
+// contained in the Baseline.
+func CheckWithBaseline(baseline *bufbaseline.Baseline) CheckOption {
+	return func(checkOptions *checkOptions) {
+		checkOptions.baseline = baseline
+	}
 }
 
 // NewHandler returns a new Handler.
@@ -122,7 +198,9 @@ func internalConfigForConfig(config *bufbreakingconfig.Config) (*internal.Config
 		Except:                        config.Except,
 		IgnoreRootPaths:               config.IgnoreRootPaths,
 		IgnoreIDOrCategoryToRootPaths: config.IgnoreIDOrCategoryToRootPaths,
+		IDOrCategoryToSeverity:        config.IDOrCategoryToSeverity,
 		IgnoreUnstablePackages:        config.IgnoreUnstablePackages,
+		CustomOptions:                 config.CustomOptions,
 	}.NewConfig(
 		versionSpec,
 	)
@@ -138,3 +216,11 @@ func rulesForInternalRules(rules []*internal.Rule) []bufcheck.Rule {
 	}
 	return s
 }
+
+type checkOptions struct {
+	baseline *bufbaseline.Baseline
+}
+
+func newCheckOptions() *checkOptions {
+	return &checkOptions{}
+}
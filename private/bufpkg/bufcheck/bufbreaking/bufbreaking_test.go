@@ -15,6 +15,7 @@
 package bufbreaking_test
 
 import (
+	"bytes"
 	"context"
 	"path/filepath"
 	"testing"
@@ -22,6 +23,7 @@ import (
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
@@ -35,6 +37,27 @@ import (
 	"go.uber.org/zap"
 )
 
+func TestRunBreakingCustomOptions(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_custom_options",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 10, 3, 10, 35, "FIELD_SAME_CUSTOM_OPTIONS"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 14, 1, 19, 2, "MESSAGE_SAME_CUSTOM_OPTIONS"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 15, 3, 18, 5, "FIELD_SAME_CUSTOM_OPTIONS"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 21, 1, 29, 2, "SERVICE_SAME_CUSTOM_OPTIONS"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 23, 3, 25, 4, "RPC_SAME_CUSTOM_OPTIONS"),
+	)
+}
+
+func TestRunBreakingCustomOptionsConfigured(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_custom_options_configured",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 10, 3, 10, 35, "FIELD_SAME_CUSTOM_OPTIONS"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 23, 3, 25, 4, "RPC_SAME_CUSTOM_OPTIONS"),
+	)
+}
+
 func TestRunBreakingEnumNoDelete(t *testing.T) {
 	testBreaking(
 		t,
@@ -94,6 +117,15 @@ func TestRunBreakingEnumValueSameName(t *testing.T) {
 	)
 }
 
+func TestRunBreakingEnumValueSameNumber(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_enum_value_same_number",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 15, 15, 15, 16, "ENUM_VALUE_SAME_NUMBER"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 22, 16, 22, 17, "ENUM_VALUE_SAME_NUMBER"),
+	)
+}
+
 func TestRunBreakingExtensionMessageNoDelete(t *testing.T) {
 	testBreaking(
 		t,
@@ -151,6 +183,16 @@ func TestRunBreakingFieldNoDeleteUnlessNumberReserved(t *testing.T) {
 	)
 }
 
+func TestRunBreakingFieldNoReuseJSONName(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_no_reuse_json_name",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 10, 3, 10, 21, "FIELD_NO_REUSE_JSON_NAME"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 11, 20, 11, 39, "FIELD_NO_REUSE_JSON_NAME"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 17, 3, 17, 21, "FIELD_NO_REUSE_JSON_NAME"),
+	)
+}
+
 func TestRunBreakingFieldSameCType(t *testing.T) {
 	testBreaking(
 		t,
@@ -670,6 +712,106 @@ func TestRunBreakingIgnoreUnstablePackagesDeleteFile(t *testing.T) {
 	)
 }
 
+func TestRunBreakingBaselineFileNoDelete(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Deleted files are only reported against the previous files.
+	config, previousImage, image := testGetConfigAndImages(ctx, t, "breaking_file_no_delete")
+	handler := bufbreaking.NewHandler(zap.NewNop())
+	baseline, err := handler.Baseline(ctx, config.Breaking, previousImage, image)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]bufbaseline.Entry{
+			{
+				ID:   "FILE_NO_DELETE",
+				Path: "a/a.proto",
+			},
+			{
+				ID:   "FILE_NO_DELETE",
+				Path: "no_package.proto",
+			},
+		},
+		baseline.Entries(),
+	)
+	fileAnnotations, err := handler.Check(ctx, config.Breaking, previousImage, image, bufbreaking.CheckWithBaseline(baseline))
+	require.NoError(t, err)
+	assert.Empty(t, fileAnnotations)
+
+	fileAnnotations, err = handler.Check(
+		ctx,
+		config.Breaking,
+		previousImage,
+		image,
+		bufbreaking.CheckWithBaseline(bufbaseline.NewBaseline(baseline.Entries()[0])),
+	)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotationNoLocationOrPath(t, "FILE_NO_DELETE"),
+		},
+		fileAnnotations,
+	)
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config, previousImage, image := testGetConfigAndImages(ctx, t, "diff")
+	changes, err := bufbreaking.NewHandler(zap.NewNop()).Diff(
+		ctx,
+		config.Breaking,
+		previousImage,
+		image,
+	)
+	require.NoError(t, err)
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, bufbreaking.PrintChanges(buffer, changes, "markdown"))
+	assert.Equal(
+		t,
+		`## Breaking changes
+
+- `+"`1.proto`"+` Option "go_package" on "1.proto" changed from "a/v1" to "a/v2". (FILE_SAME_GO_PACKAGE)
+- `+"`1.proto`"+` Enum value "1" on enum "a.Enum" changed name from "ENUM_ONE" to "ENUM_UNO". (ENUM_VALUE_SAME_NAME)
+- `+"`1.proto`"+` Field "5" on message "a.One" changed type from "a.Status" to "a.One.Status". (FIELD_SAME_TYPE)
+- `+"`1.proto`"+` Field "4" on message "a.One" changed type from "string" to "bytes". (FIELD_SAME_TYPE, FIELD_WIRE_JSON_COMPATIBLE_TYPE)
+- `+"`1.proto`"+` Field "3" with name "three" on message "a.One" was removed. (FIELD_NO_DELETE, FIELD_NO_DELETE_UNLESS_NAME_RESERVED)
+- `+"`1.proto`"+` Field "2" on message "a.One" changed type from "int32" to "int64". (FIELD_SAME_TYPE, FIELD_WIRE_JSON_COMPATIBLE_TYPE)
+- `+"`1.proto`"+` RPC "Get" on service "a.Service" changed response type from "a.One" to "a.Two". (RPC_SAME_RESPONSE_TYPE)
+- `+"`2.proto`"+` File "2.proto" was removed. (FILE_NO_DELETE)
+
+## Added
+
+- `+"`1.proto`"+` Enum value "2" with name "ENUM_TWO" on enum "a.Enum" was added.
+- `+"`1.proto`"+` Enum "a.One.Status" was added.
+- `+"`1.proto`"+` RPC "List" on service "a.Service" was added.
+- `+"`1.proto`"+` Message "a.Two" was added.
+
+## Removed
+
+- `+"`1.proto`"+` Field "3" with name "three" on message "a.One" was removed. (FIELD_NO_DELETE, FIELD_NO_DELETE_UNLESS_NAME_RESERVED)
+- `+"`2.proto`"+` File "2.proto" was removed. (FILE_NO_DELETE)
+- `+"`2.proto`"+` Message "a.Removed" was removed.
+
+## Changed
+
+- `+"`1.proto`"+` Option "go_package" on "1.proto" changed from "a/v1" to "a/v2". (FILE_SAME_GO_PACKAGE)
+- `+"`1.proto`"+` Enum value "1" on enum "a.Enum" changed name from "ENUM_ONE" to "ENUM_UNO". (ENUM_VALUE_SAME_NAME)
+- `+"`1.proto`"+` Field "5" on message "a.One" changed type from "a.Status" to "a.One.Status". (FIELD_SAME_TYPE)
+- `+"`1.proto`"+` Field "4" on message "a.One" changed type from "string" to "bytes". (FIELD_SAME_TYPE, FIELD_WIRE_JSON_COMPATIBLE_TYPE)
+- `+"`1.proto`"+` Option "deprecated" on "a.One.six" changed from "true" to "false".
+- `+"`1.proto`"+` Field "2" on message "a.One" changed type from "int32" to "int64". (FIELD_SAME_TYPE, FIELD_WIRE_JSON_COMPATIBLE_TYPE)
+- `+"`1.proto`"+` RPC "Get" on service "a.Service" changed response type from "a.One" to "a.Two". (RPC_SAME_RESPONSE_TYPE)
+`,
+		buffer.String(),
+	)
+}
+
 func testBreaking(
 	t *testing.T,
 	relDirPath string,
@@ -680,6 +822,27 @@ func testBreaking(
 	defer cancel()
 	logger := zap.NewNop()
 
+	config, previousImage, image := testGetConfigAndImages(ctx, t, relDirPath)
+	handler := bufbreaking.NewHandler(logger)
+	fileAnnotations, err := handler.Check(
+		ctx,
+		config.Breaking,
+		previousImage,
+		image,
+	)
+	assert.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
+
+func testGetConfigAndImages(
+	ctx context.Context,
+	t *testing.T,
+	relDirPath string,
+) (*bufconfig.Config, bufimage.Image, bufimage.Image) {
 	previousDirPath := filepath.Join("testdata_previous", relDirPath)
 	dirPath := filepath.Join("testdata", relDirPath)
 
@@ -742,20 +905,7 @@ func testBreaking(
 	require.NoError(t, err)
 	require.Empty(t, fileAnnotations)
 	image = bufimage.ImageWithoutImports(image)
-
-	handler := bufbreaking.NewHandler(logger)
-	fileAnnotations, err = handler.Check(
-		ctx,
-		config.Breaking,
-		previousImage,
-		image,
-	)
-	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+	return config, previousImage, image
 }
 
 func testGetConfig(
@@ -40,12 +40,23 @@ type Config struct {
 	// IgnoreIDOrCategoryToRootPaths is a map of rule and/or category IDs to directory and/or file paths to exclude from the
 	// breaking change check.
 	IgnoreIDOrCategoryToRootPaths map[string][]string
+	// IDOrCategoryToSeverity is a map of rule and/or category IDs to the severity of their violations,
+	// one of "error", "warning", or "info". Violations of rules that are not in the map are errors.
+	//
+	// Only errors result in a non-zero exit code.
+	IDOrCategoryToSeverity map[string]string
 	// IgnoreUnstablePackages ignores packages with a last component that is one of the unstable forms recognised
 	// by the PACKAGE_VERSION_SUFFIX:
 	//   v\d+test.*
 	//   v\d+(alpha|beta)\d+
 	//   v\d+p\d+(alpha|beta)\d+
 	IgnoreUnstablePackages bool
+	// CustomOptions is a list of the full names of the custom options that are compared by the
+	// MESSAGE_SAME_CUSTOM_OPTIONS, FIELD_SAME_CUSTOM_OPTIONS, SERVICE_SAME_CUSTOM_OPTIONS, and
+	// RPC_SAME_CUSTOM_OPTIONS rule IDs, for example "google.api.http".
+	//
+	// If empty, all custom options are compared.
+	CustomOptions []string
 	// Version represents the version of the breaking change rule and category IDs that should be used with this config.
 	Version string
 }
@@ -69,7 +80,9 @@ func NewConfigV1(externalConfig ExternalConfigV1) *Config {
 		Except:                        externalConfig.Except,
 		IgnoreRootPaths:               externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
+		IDOrCategoryToSeverity:        externalConfig.Severity,
 		IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
+		CustomOptions:                 externalConfig.CustomOptions,
 		Version:                       v1Version,
 	}
 }
@@ -118,6 +131,9 @@ type ExternalConfigV1 struct {
 	// IgnoreIDOrCategoryToRootPaths
 	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
 	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	// IDOrCategoryToSeverity
+	Severity      map[string]string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	CustomOptions []string          `json:"custom_options,omitempty" yaml:"custom_options,omitempty"`
 }
 
 // ExternalConfigV1Beta1ForConfig takes a *Config and returns the v1beta1 external config representation.
@@ -138,7 +154,9 @@ func ExternalConfigV1ForConfig(config *Config) ExternalConfigV1 {
 		Except:                 config.Except,
 		Ignore:                 config.IgnoreRootPaths,
 		IgnoreOnly:             config.IgnoreIDOrCategoryToRootPaths,
+		Severity:               config.IDOrCategoryToSeverity,
 		IgnoreUnstablePackages: config.IgnoreUnstablePackages,
+		CustomOptions:          config.CustomOptions,
 	}
 }
 
@@ -153,12 +171,14 @@ func BytesForConfig(config *Config) ([]byte, error) {
 }
 
 type configJSON struct {
-	Use                           []string      `json:"use,omitempty"`
-	Except                        []string      `json:"except,omitempty"`
-	IgnoreRootPaths               []string      `json:"ignore_root_paths,omitempty"`
-	IgnoreIDOrCategoryToRootPaths []idPathsJSON `json:"ignore_id_to_root_paths,omitempty"`
-	IgnoreUnstablePackages        bool          `json:"ignore_unstable_packages,omitempty"`
-	Version                       string        `json:"version,omitempty"`
+	Use                           []string          `json:"use,omitempty"`
+	Except                        []string          `json:"except,omitempty"`
+	IgnoreRootPaths               []string          `json:"ignore_root_paths,omitempty"`
+	IgnoreIDOrCategoryToRootPaths []idPathsJSON     `json:"ignore_id_to_root_paths,omitempty"`
+	IDOrCategoryToSeverity        map[string]string `json:"id_to_severity,omitempty"`
+	IgnoreUnstablePackages        bool              `json:"ignore_unstable_packages,omitempty"`
+	CustomOptions                 []string          `json:"custom_options,omitempty"`
+	Version                       string            `json:"version,omitempty"`
 }
 
 type idPathsJSON struct {
@@ -189,12 +209,17 @@ func configToJSON(config *Config) *configJSON {
 	sort.Strings(use)
 	sort.Strings(except)
 	sort.Strings(ignoreRootPaths)
+	customOptions := make([]string, len(config.CustomOptions))
+	copy(customOptions, config.CustomOptions)
+	sort.Strings(customOptions)
 	return &configJSON{
 		Use:                           use,
 		Except:                        except,
 		IgnoreRootPaths:               ignoreRootPaths,
 		IgnoreIDOrCategoryToRootPaths: ignoreIDPathsJSON,
+		IDOrCategoryToSeverity:        config.IDOrCategoryToSeverity,
 		IgnoreUnstablePackages:        config.IgnoreUnstablePackages,
+		CustomOptions:                 customOptions,
 		Version:                       config.Version,
 	}
 }
@@ -0,0 +1,204 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreaking
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcheck"
+	"go.uber.org/multierr"
+)
+
+type change struct {
+	kind        string
+	elementType string
+	name        string
+	path        string
+	description string
+	ruleIDs     []string
+}
+
+func newChange(internalChange *bufbreakingcheck.Change, ruleIDs []string) *change {
+	return &change{
+		kind:        internalChange.Kind,
+		elementType: internalChange.ElementType,
+		name:        internalChange.Name,
+		path:        internalChange.Path,
+		description: internalChange.Description,
+		ruleIDs:     ruleIDs,
+	}
+}
+
+func (c *change) Kind() string {
+	return c.kind
+}
+
+func (c *change) ElementType() string {
+	return c.elementType
+}
+
+func (c *change) Name() string {
+	return c.name
+}
+
+func (c *change) Path() string {
+	return c.path
+}
+
+func (c *change) Description() string {
+	return c.description
+}
+
+func (c *change) Breaking() bool {
+	return len(c.ruleIDs) > 0
+}
+
+func (c *change) RuleIDs() []string {
+	return c.ruleIDs
+}
+
+func (c *change) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		changeJSON{
+			Kind:        c.kind,
+			ElementType: c.elementType,
+			Name:        c.name,
+			Path:        c.path,
+			Description: c.description,
+			Breaking:    c.Breaking(),
+			RuleIDs:     c.ruleIDs,
+		},
+	)
+}
+
+type changeJSON struct {
+	Kind        string   `json:"kind" yaml:"kind"`
+	ElementType string   `json:"element_type" yaml:"element_type"`
+	Name        string   `json:"name" yaml:"name"`
+	Path        string   `json:"path" yaml:"path"`
+	Description string   `json:"description" yaml:"description"`
+	Breaking    bool     `json:"breaking" yaml:"breaking"`
+	RuleIDs     []string `json:"rule_ids,omitempty" yaml:"rule_ids,omitempty"`
+}
+
+func printChanges(writer io.Writer, changes []Change, formatString string) (retErr error) {
+	switch s := strings.ToLower(strings.TrimSpace(formatString)); s {
+	case "", "text":
+		if len(changes) == 0 {
+			return nil
+		}
+		tabWriter := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		defer func() {
+			retErr = multierr.Append(retErr, tabWriter.Flush())
+		}()
+		return printChangesAsText(tabWriter, changes)
+	case "json":
+		return printChangesAsJSON(writer, changes)
+	case "markdown":
+		return printChangesAsMarkdown(writer, changes)
+	default:
+		return fmt.Errorf("unknown format: %q", s)
+	}
+}
+
+func printChangesAsText(writer io.Writer, changes []Change) error {
+	if _, err := fmt.Fprintln(writer, "KIND\tTYPE\tNAME\tBREAKING\tDESCRIPTION"); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		breaking := "no"
+		if change.Breaking() {
+			breaking = "yes (" + strings.Join(change.RuleIDs(), ", ") + ")"
+		}
+		if _, err := fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			change.Kind(),
+			change.ElementType(),
+			change.Name(),
+			breaking,
+			change.Description(),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printChangesAsJSON(writer io.Writer, changes []Change) error {
+	for _, change := range changes {
+		data, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(writer, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printChangesAsMarkdown(writer io.Writer, changes []Change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(writer, "No changes.")
+		return err
+	}
+	var breakingChanges []Change
+	kindToChanges := make(map[string][]Change)
+	for _, change := range changes {
+		if change.Breaking() {
+			breakingChanges = append(breakingChanges, change)
+		}
+		kindToChanges[change.Kind()] = append(kindToChanges[change.Kind()], change)
+	}
+	sections := []struct {
+		title   string
+		changes []Change
+	}{
+		{title: "Breaking changes", changes: breakingChanges},
+		{title: "Added", changes: kindToChanges[bufbreakingcheck.ChangeKindAdded]},
+		{title: "Removed", changes: kindToChanges[bufbreakingcheck.ChangeKindRemoved]},
+		{title: "Changed", changes: kindToChanges[bufbreakingcheck.ChangeKindChanged]},
+	}
+	printed := false
+	for _, section := range sections {
+		if len(section.changes) == 0 {
+			continue
+		}
+		if printed {
+			if _, err := fmt.Fprintln(writer); err != nil {
+				return err
+			}
+		}
+		printed = true
+		if _, err := fmt.Fprintf(writer, "## %s\n\n", section.title); err != nil {
+			return err
+		}
+		for _, change := range section.changes {
+			line := fmt.Sprintf("- `%s` %s", change.Path(), change.Description())
+			if change.Breaking() {
+				line += " (" + strings.Join(change.RuleIDs(), ", ") + ")"
+			}
+			if _, err := fmt.Fprintln(writer, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
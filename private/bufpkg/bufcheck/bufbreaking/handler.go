@@ -18,11 +18,14 @@ import (
 	"context"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"go.uber.org/zap"
 )
 
@@ -47,18 +50,83 @@ func (h *handler) Check(
 	config *bufbreakingconfig.Config,
 	previousImage bufimage.Image,
 	image bufimage.Image,
+	options ...CheckOption,
 ) ([]bufanalysis.FileAnnotation, error) {
-	previousFiles, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(previousImage.Files())...)
+	checkOptions := newCheckOptions()
+	for _, option := range options {
+		option(checkOptions)
+	}
+	internalConfig, previousFiles, files, err := h.getInternalConfigAndFiles(ctx, config, previousImage, image)
 	if err != nil {
 		return nil, err
 	}
-	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	internalConfig.Baseline = checkOptions.baseline
+	return h.runner.Check(ctx, internalConfig, previousFiles, files)
+}
+
+func (h *handler) Baseline(
+	ctx context.Context,
+	config *bufbreakingconfig.Config,
+	previousImage bufimage.Image,
+	image bufimage.Image,
+) (*bufbaseline.Baseline, error) {
+	internalConfig, previousFiles, files, err := h.getInternalConfigAndFiles(ctx, config, previousImage, image)
 	if err != nil {
 		return nil, err
 	}
-	internalConfig, err := internalConfigForConfig(config)
+	return h.runner.Baseline(ctx, internalConfig, previousFiles, files)
+}
+
+func (h *handler) Diff(
+	ctx context.Context,
+	config *bufbreakingconfig.Config,
+	previousImage bufimage.Image,
+	image bufimage.Image,
+) ([]Change, error) {
+	internalConfig, previousFiles, files, err := h.getInternalConfigAndFiles(ctx, config, previousImage, image)
 	if err != nil {
 		return nil, err
 	}
-	return h.runner.Check(ctx, internalConfig, previousFiles, files)
+	internalChanges, err := bufbreakingcheck.Diff(previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	// A change is breaking if the configured rules report it, so we derive
+	// the breaking status from the same FileAnnotations as Check.
+	fileAnnotations, err := h.runner.Check(ctx, internalConfig, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]Change, len(internalChanges))
+	for i, internalChange := range internalChanges {
+		ruleIDMap := make(map[string]struct{})
+		for _, fileAnnotation := range fileAnnotations {
+			if internalChange.Matches(fileAnnotation) {
+				ruleIDMap[fileAnnotation.Type()] = struct{}{}
+			}
+		}
+		changes[i] = newChange(internalChange, stringutil.MapToSortedSlice(ruleIDMap))
+	}
+	return changes, nil
+}
+
+func (h *handler) getInternalConfigAndFiles(
+	ctx context.Context,
+	config *bufbreakingconfig.Config,
+	previousImage bufimage.Image,
+	image bufimage.Image,
+) (*internal.Config, []protosource.File, []protosource.File, error) {
+	previousFiles, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(previousImage.Files())...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	internalConfig, err := internalConfigForConfig(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return internalConfig, previousFiles, files, nil
 }
@@ -21,8 +21,13 @@
 package bufbreakingbuild
 
 import (
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
 )
 
 var (
@@ -56,6 +61,12 @@ var (
 		"enum values have the same name",
 		bufbreakingcheck.CheckEnumValueSameName,
 	)
+	// EnumValueSameNumberRuleBuilder is a rule builder.
+	EnumValueSameNumberRuleBuilder = internal.NewNopRuleBuilder(
+		"ENUM_VALUE_SAME_NUMBER",
+		"enum values have the same number for a given name",
+		bufbreakingcheck.CheckEnumValueSameNumber,
+	)
 	// ExtensionMessageNoDeleteRuleBuilder is a rule builder.
 	ExtensionMessageNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
 		"EXTENSION_MESSAGE_NO_DELETE",
@@ -80,12 +91,24 @@ var (
 		"fields are not deleted from a given message unless the number is reserved",
 		bufbreakingcheck.CheckFieldNoDeleteUnlessNumberReserved,
 	)
+	// FieldNoReuseJSONNameRuleBuilder is a rule builder.
+	FieldNoReuseJSONNameRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_NO_REUSE_JSON_NAME",
+		"added fields do not have the JSON name of a reserved or deleted field",
+		bufbreakingcheck.CheckFieldNoReuseJSONName,
+	)
 	// FieldSameCTypeRuleBuilder is a rule builder.
 	FieldSameCTypeRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_SAME_CTYPE",
 		"fields have the same value for the ctype option",
 		bufbreakingcheck.CheckFieldSameCType,
 	)
+	// FieldSameCustomOptionsRuleBuilder is a rule builder.
+	FieldSameCustomOptionsRuleBuilder = internal.NewRuleBuilder(
+		"FIELD_SAME_CUSTOM_OPTIONS",
+		newCustomOptionsPurposeFunc("fields have the same values for"),
+		newCustomOptionsCheckFuncBuilder(bufbreakingcheck.CheckFieldSameCustomOptions),
+	)
 	// FieldSameJSONNameRuleBuilder is a rule builder.
 	FieldSameJSONNameRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_SAME_JSON_NAME",
@@ -272,6 +295,12 @@ var (
 		"messages do not change the no_standard_descriptor_accessor option from false or unset to true",
 		bufbreakingcheck.CheckMessageNoRemoveStandardDescriptorAccessor,
 	)
+	// MessageSameCustomOptionsRuleBuilder is a rule builder.
+	MessageSameCustomOptionsRuleBuilder = internal.NewRuleBuilder(
+		"MESSAGE_SAME_CUSTOM_OPTIONS",
+		newCustomOptionsPurposeFunc("messages have the same values for"),
+		newCustomOptionsCheckFuncBuilder(bufbreakingcheck.CheckMessageSameCustomOptions),
+	)
 	// MessageSameMessageSetWireFormatRuleBuilder is a rule builder.
 	MessageSameMessageSetWireFormatRuleBuilder = internal.NewNopRuleBuilder(
 		"MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT",
@@ -338,6 +367,12 @@ var (
 		"rpcs have the same client streaming value",
 		bufbreakingcheck.CheckRPCSameClientStreaming,
 	)
+	// RPCSameCustomOptionsRuleBuilder is a rule builder.
+	RPCSameCustomOptionsRuleBuilder = internal.NewRuleBuilder(
+		"RPC_SAME_CUSTOM_OPTIONS",
+		newCustomOptionsPurposeFunc("rpcs have the same values for"),
+		newCustomOptionsCheckFuncBuilder(bufbreakingcheck.CheckRPCSameCustomOptions),
+	)
 	// RPCSameIdempotencyLevelRuleBuilder is a rule builder.
 	RPCSameIdempotencyLevelRuleBuilder = internal.NewNopRuleBuilder(
 		"RPC_SAME_IDEMPOTENCY_LEVEL",
@@ -368,4 +403,40 @@ var (
 		"services are not deleted from a given file",
 		bufbreakingcheck.CheckServiceNoDelete,
 	)
+	// ServiceSameCustomOptionsRuleBuilder is a rule builder.
+	ServiceSameCustomOptionsRuleBuilder = internal.NewRuleBuilder(
+		"SERVICE_SAME_CUSTOM_OPTIONS",
+		newCustomOptionsPurposeFunc("services have the same values for"),
+		newCustomOptionsCheckFuncBuilder(bufbreakingcheck.CheckServiceSameCustomOptions),
+	)
 )
+
+func newCustomOptionsPurposeFunc(purpose string) func(internal.ConfigBuilder) (string, error) {
+	return func(configBuilder internal.ConfigBuilder) (string, error) {
+		if len(configBuilder.CustomOptions) > 0 {
+			return purpose + " the custom options " + strings.Join(getCustomOptionFullNames(configBuilder.CustomOptions), ", "), nil
+		}
+		return purpose + " custom options (options are configurable)", nil
+	}
+}
+
+func newCustomOptionsCheckFuncBuilder(
+	f func(string, internal.IgnoreFunc, []protosource.File, []protosource.File, []string) ([]bufanalysis.FileAnnotation, error),
+) func(internal.ConfigBuilder) (internal.CheckFunc, error) {
+	return func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+		customOptions := getCustomOptionFullNames(configBuilder.CustomOptions)
+		return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, previousFiles []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+			return f(id, ignoreFunc, previousFiles, files, customOptions)
+		}), nil
+	}
+}
+
+// getCustomOptionFullNames accepts the names of custom options as they are written in
+// .proto files, that is with surrounding parentheses and optionally a leading dot.
+func getCustomOptionFullNames(customOptions []string) []string {
+	fullNames := make([]string, len(customOptions))
+	for i, customOption := range customOptions {
+		fullNames[i] = strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(customOption, "("), ")"), ".")
+	}
+	return stringutil.SliceToUniqueSortedSliceFilterEmptyStrings(fullNames)
+}
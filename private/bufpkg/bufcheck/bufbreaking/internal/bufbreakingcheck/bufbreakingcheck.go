@@ -23,6 +23,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/pkg/protosource"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 )
@@ -146,6 +148,28 @@ func checkEnumValueSameName(add addFunc, corpus *corpus, previousNameToEnumValue
 	return nil
 }
 
+// CheckEnumValueSameNumber is a check function.
+var CheckEnumValueSameNumber = newEnumPairCheckFunc(checkEnumValueSameNumber)
+
+func checkEnumValueSameNumber(add addFunc, corpus *corpus, previousEnum protosource.Enum, enum protosource.Enum) error {
+	previousNameToEnumValue, err := protosource.NameToEnumValue(previousEnum)
+	if err != nil {
+		return err
+	}
+	nameToEnumValue, err := protosource.NameToEnumValue(enum)
+	if err != nil {
+		return err
+	}
+	// JSON uses the names of enum values, so a name that now refers to a different
+	// number is breaking for JSON even if the number still has one of its previous names
+	for name, enumValue := range nameToEnumValue {
+		if previousEnumValue, ok := previousNameToEnumValue[name]; ok && previousEnumValue.Number() != enumValue.Number() {
+			add(enumValue, nil, enumValue.NumberLocation(), `Enum value %q on enum %q changed number from "%d" to "%d".`, name, enum.Name(), previousEnumValue.Number(), enumValue.Number())
+		}
+	}
+	return nil
+}
+
 // CheckExtensionMessageNoDelete is a check function.
 var CheckExtensionMessageNoDelete = newMessagePairCheckFunc(checkExtensionMessageNoDelete)
 
@@ -217,6 +241,43 @@ func isDeletedFieldAllowedWithRules(previousField protosource.Field, message pro
 		(allowIfNameReserved && protosource.NameInReservedNames(previousField.Name(), message.ReservedNames()...))
 }
 
+// CheckFieldNoReuseJSONName is a check function.
+var CheckFieldNoReuseJSONName = newMessagePairCheckFunc(checkFieldNoReuseJSONName)
+
+func checkFieldNoReuseJSONName(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	// JSON clients may still send the JSON names of reserved and deleted fields,
+	// which would then be parsed as the values of newly added fields
+	jsonNameToDescription := make(map[string]string)
+	for _, reservedName := range message.ReservedNames() {
+		jsonNameToDescription[defaultJSONName(reservedName.Value())] = fmt.Sprintf("reserved name %q", reservedName.Value())
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		if _, ok := numberToField[previousNumber]; ok {
+			continue
+		}
+		if _, ok := jsonNameToDescription[previousField.JSONName()]; !ok {
+			jsonNameToDescription[previousField.JSONName()] = fmt.Sprintf("previously present field %q with name %q", strconv.Itoa(previousNumber), previousField.Name())
+		}
+	}
+	for number, field := range numberToField {
+		if _, ok := previousNumberToField[number]; ok {
+			continue
+		}
+		if description, ok := jsonNameToDescription[field.JSONName()]; ok {
+			add(field, nil, withBackupLocation(field.JSONNameLocation(), field.Location()), `Field %q with name %q on message %q has the same JSON name %q as the %s.`, strconv.Itoa(number), field.Name(), message.Name(), field.JSONName(), description)
+		}
+	}
+	return nil
+}
+
 // CheckFieldSameCType is a check function.
 var CheckFieldSameCType = newFieldPairCheckFunc(checkFieldSameCType)
 
@@ -229,6 +290,38 @@ func checkFieldSameCType(add addFunc, corpus *corpus, previousField protosource.
 	return nil
 }
 
+// CheckFieldSameCustomOptions is a check function.
+var CheckFieldSameCustomOptions = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionsMap := stringutil.SliceToMap(customOptions)
+	return newFieldPairCheckFunc(
+		func(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+			return checkFieldSameCustomOptions(add, corpus, previousField, field, customOptionsMap)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkFieldSameCustomOptions(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field, customOptions map[string]struct{}) error {
+	// otherwise prints as hex
+	numberString := strconv.FormatInt(int64(field.Number()), 10)
+	return checkSameCustomOptions(
+		add,
+		corpus,
+		customOptions,
+		fieldOptionsFullName,
+		previousField,
+		field,
+		field,
+		field.Location(),
+		fmt.Sprintf(`Field %q with name %q on message %q`, numberString, field.Name(), field.Message().Name()),
+	)
+}
+
 // CheckFieldSameJSONName is a check function.
 var CheckFieldSameJSONName = newFieldPairCheckFunc(checkFieldSameJSONName)
 
@@ -715,6 +808,36 @@ func checkMessageNoRemoveStandardDescriptorAccessor(add addFunc, corpus *corpus,
 	return nil
 }
 
+// CheckMessageSameCustomOptions is a check function.
+var CheckMessageSameCustomOptions = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionsMap := stringutil.SliceToMap(customOptions)
+	return newMessagePairCheckFunc(
+		func(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+			return checkMessageSameCustomOptions(add, corpus, previousMessage, message, customOptionsMap)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkMessageSameCustomOptions(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message, customOptions map[string]struct{}) error {
+	return checkSameCustomOptions(
+		add,
+		corpus,
+		customOptions,
+		messageOptionsFullName,
+		previousMessage,
+		message,
+		message,
+		message.Location(),
+		fmt.Sprintf(`Message %q`, message.Name()),
+	)
+}
+
 // CheckMessageSameMessageSetWireFormat is a check function.
 var CheckMessageSameMessageSetWireFormat = newMessagePairCheckFunc(checkMessageSameMessageSetWireFormat)
 
@@ -1025,6 +1148,36 @@ func checkRPCSameClientStreaming(add addFunc, corpus *corpus, previousMethod pro
 	return nil
 }
 
+// CheckRPCSameCustomOptions is a check function.
+var CheckRPCSameCustomOptions = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionsMap := stringutil.SliceToMap(customOptions)
+	return newMethodPairCheckFunc(
+		func(add addFunc, corpus *corpus, previousMethod protosource.Method, method protosource.Method) error {
+			return checkRPCSameCustomOptions(add, corpus, previousMethod, method, customOptionsMap)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkRPCSameCustomOptions(add addFunc, corpus *corpus, previousMethod protosource.Method, method protosource.Method, customOptions map[string]struct{}) error {
+	return checkSameCustomOptions(
+		add,
+		corpus,
+		customOptions,
+		methodOptionsFullName,
+		previousMethod,
+		method,
+		method,
+		method.Location(),
+		fmt.Sprintf(`RPC %q on service %q`, method.Name(), method.Service().Name()),
+	)
+}
+
 // CheckRPCSameIdempotencyLevel is a check function.
 var CheckRPCSameIdempotencyLevel = newMethodPairCheckFunc(checkRPCSameIdempotencyLevel)
 
@@ -1092,3 +1245,33 @@ func checkServiceNoDelete(add addFunc, corpus *corpus, previousFile protosource.
 	}
 	return nil
 }
+
+// CheckServiceSameCustomOptions is a check function.
+var CheckServiceSameCustomOptions = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionsMap := stringutil.SliceToMap(customOptions)
+	return newServicePairCheckFunc(
+		func(add addFunc, corpus *corpus, previousService protosource.Service, service protosource.Service) error {
+			return checkServiceSameCustomOptions(add, corpus, previousService, service, customOptionsMap)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkServiceSameCustomOptions(add addFunc, corpus *corpus, previousService protosource.Service, service protosource.Service, customOptions map[string]struct{}) error {
+	return checkSameCustomOptions(
+		add,
+		corpus,
+		customOptions,
+		serviceOptionsFullName,
+		previousService,
+		service,
+		service,
+		service.Location(),
+		fmt.Sprintf(`Service %q`, service.Name()),
+	)
+}
@@ -0,0 +1,848 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingcheck
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+const (
+	// ChangeKindAdded is the kind for added elements.
+	ChangeKindAdded = "added"
+	// ChangeKindRemoved is the kind for removed elements.
+	ChangeKindRemoved = "removed"
+	// ChangeKindChanged is the kind for changed elements.
+	ChangeKindChanged = "changed"
+
+	// ElementTypeFile is the element type for files.
+	ElementTypeFile = "file"
+	// ElementTypeMessage is the element type for messages.
+	ElementTypeMessage = "message"
+	// ElementTypeField is the element type for fields.
+	ElementTypeField = "field"
+	// ElementTypeEnum is the element type for enums.
+	ElementTypeEnum = "enum"
+	// ElementTypeEnumValue is the element type for enum values.
+	ElementTypeEnumValue = "enum_value"
+	// ElementTypeService is the element type for services.
+	ElementTypeService = "service"
+	// ElementTypeRPC is the element type for RPCs.
+	ElementTypeRPC = "rpc"
+	// ElementTypeOption is the element type for options.
+	ElementTypeOption = "option"
+)
+
+// Change is a change between the previous files and the files.
+type Change struct {
+	// Kind is one of ChangeKindAdded, ChangeKindRemoved, or ChangeKindChanged.
+	Kind string
+	// ElementType is the type of the element that changed.
+	ElementType string
+	// Name is the fully-qualified name of the element, or the file path for files.
+	//
+	// For options, this is the name of the element the option is set on.
+	Name string
+	// Path is the path of the file that contains the element.
+	//
+	// For removed elements, this is the path in the previous files.
+	Path string
+	// Description is a full sentence describing the change.
+	Description string
+	// RuleIDs are the IDs of all rules that check this kind of change.
+	//
+	// Whether a rule detects the change as breaking is determined by the
+	// FileAnnotations the rule produces, see Matches.
+	//
+	// Sorted.
+	RuleIDs []string
+
+	anchor *anchor
+}
+
+// Matches returns true if the FileAnnotation was produced by one of the rules
+// of the Change for this Change.
+func (c *Change) Matches(fileAnnotation bufanalysis.FileAnnotation) bool {
+	if c.anchor == nil {
+		return false
+	}
+	for _, ruleID := range c.RuleIDs {
+		if ruleID == fileAnnotation.Type() {
+			return c.anchor.matches(fileAnnotation)
+		}
+	}
+	return false
+}
+
+// anchor is where the rules report the FileAnnotations for a Change.
+type anchor struct {
+	// path is the path of the file the FileAnnotations are reported in.
+	//
+	// FileAnnotations without a file, for example for deleted files, match any path.
+	path string
+	// locations are the locations of the elements the FileAnnotations are reported on.
+	//
+	// If empty, FileAnnotations anywhere in the file match.
+	locations []protosource.Location
+	// exact is true if the FileAnnotations are reported at the start of the
+	// locations instead of anywhere within them. This is the case for elements
+	// that were deleted, which are reported on their parent.
+	exact bool
+	// tokens are the quoted strings that the FileAnnotation messages contain,
+	// for example the number of a deleted field.
+	tokens []string
+}
+
+// newFileAnchor returns a new anchor for FileAnnotations anywhere in the file.
+func newFileAnchor(path string, tokens ...string) *anchor {
+	return &anchor{
+		path:   path,
+		tokens: quoteTokens(tokens),
+	}
+}
+
+// newElementAnchor returns a new anchor for FileAnnotations within the elements.
+//
+// All elements must be in the same file.
+func newElementAnchor(locationDescriptors ...protosource.LocationDescriptor) *anchor {
+	anchor := &anchor{
+		path: locationDescriptors[0].File().Path(),
+	}
+	for _, locationDescriptor := range locationDescriptors {
+		if location := locationDescriptor.Location(); location != nil {
+			anchor.locations = append(anchor.locations, location)
+		}
+	}
+	return anchor
+}
+
+// newParentAnchor returns a new anchor for FileAnnotations for a deleted element
+// that are reported on its parent.
+func newParentAnchor(parent protosource.LocationDescriptor, tokens ...string) *anchor {
+	anchor := newElementAnchor(parent)
+	anchor.exact = true
+	anchor.tokens = quoteTokens(tokens)
+	return anchor
+}
+
+func (a *anchor) matches(fileAnnotation bufanalysis.FileAnnotation) bool {
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil && fileInfo.Path() != a.path {
+		return false
+	}
+	for _, token := range a.tokens {
+		if !strings.Contains(fileAnnotation.Message(), token) {
+			return false
+		}
+	}
+	if len(a.locations) == 0 || fileAnnotation.StartLine() == 0 {
+		return true
+	}
+	for _, location := range a.locations {
+		if a.exact {
+			if fileAnnotation.StartLine() == location.StartLine() && fileAnnotation.StartColumn() == location.StartColumn() {
+				return true
+			}
+			continue
+		}
+		if comparePositions(fileAnnotation.StartLine(), fileAnnotation.StartColumn(), location.StartLine(), location.StartColumn()) >= 0 &&
+			comparePositions(fileAnnotation.StartLine(), fileAnnotation.StartColumn(), location.EndLine(), location.EndColumn()) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the changes between the previous files and the files.
+//
+// Messages, enums and services are paired by full name, fields and enum values by number,
+// and RPCs by name, the same way the breaking change rules pair them. Whether a change
+// is breaking is not decided here, see Change.Matches.
+//
+// The changes are sorted by path, name, kind and description.
+func Diff(previousFiles []protosource.File, files []protosource.File) ([]*Change, error) {
+	differ := newDiffer()
+	diffCorpus := newCorpus(previousFiles, files)
+	for _, diffFunc := range []func(*corpus) error{
+		differ.diffFiles,
+		differ.diffMessages,
+		differ.diffEnums,
+		differ.diffServices,
+	} {
+		if err := diffFunc(diffCorpus); err != nil {
+			return nil, err
+		}
+	}
+	changes := differ.changes
+	sort.SliceStable(
+		changes,
+		func(i int, j int) bool {
+			one := changes[i]
+			two := changes[j]
+			if one.Path != two.Path {
+				return one.Path < two.Path
+			}
+			if one.Name != two.Name {
+				return one.Name < two.Name
+			}
+			if one.Kind != two.Kind {
+				return one.Kind < two.Kind
+			}
+			return one.Description < two.Description
+		},
+	)
+	return changes, nil
+}
+
+type differ struct {
+	changes []*Change
+}
+
+func newDiffer() *differ {
+	return &differ{}
+}
+
+func (d *differ) add(
+	kind string,
+	elementType string,
+	name string,
+	path string,
+	anchor *anchor,
+	ruleIDs []string,
+	format string,
+	args ...interface{},
+) {
+	sortedRuleIDs := make([]string, len(ruleIDs))
+	copy(sortedRuleIDs, ruleIDs)
+	sort.Strings(sortedRuleIDs)
+	d.changes = append(
+		d.changes,
+		&Change{
+			Kind:        kind,
+			ElementType: elementType,
+			Name:        name,
+			Path:        path,
+			Description: fmt.Sprintf(format, args...),
+			RuleIDs:     sortedRuleIDs,
+			anchor:      anchor,
+		},
+	)
+}
+
+func (d *differ) addOption(
+	name string,
+	path string,
+	anchor *anchor,
+	ruleID string,
+	optionName string,
+	previousValue string,
+	value string,
+) {
+	if previousValue == value {
+		return
+	}
+	d.add(
+		ChangeKindChanged,
+		ElementTypeOption,
+		name,
+		path,
+		anchor,
+		ruleIDsForRuleID(ruleID),
+		`Option %q on %q changed from %q to %q.`,
+		optionName,
+		name,
+		previousValue,
+		value,
+	)
+}
+
+// addCustomOptions adds the custom options that were added, removed, or changed.
+//
+// Options are compared by their wire format encoding, like checkSameCustomOptions.
+func (d *differ) addCustomOptions(
+	corpus *corpus,
+	name string,
+	path string,
+	anchor *anchor,
+	ruleID string,
+	optionsFullName string,
+	previousOptionExtensionDescriptor protosource.OptionExtensionDescriptor,
+	optionExtensionDescriptor protosource.OptionExtensionDescriptor,
+) error {
+	numberMap := make(map[int32]struct{})
+	for _, number := range previousOptionExtensionDescriptor.PresentExtensionNumbers() {
+		numberMap[number] = struct{}{}
+	}
+	for _, number := range optionExtensionDescriptor.PresentExtensionNumbers() {
+		numberMap[number] = struct{}{}
+	}
+	for number := range numberMap {
+		previousValue, err := previousOptionExtensionDescriptor.OptionExtensionBytes(number)
+		if err != nil {
+			return err
+		}
+		value, err := optionExtensionDescriptor.OptionExtensionBytes(number)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(previousValue, value) {
+			continue
+		}
+		fullName, ok, err := corpus.getExtensionFullName(optionsFullName, int(number))
+		if err != nil {
+			return err
+		}
+		optionName := strconv.FormatInt(int64(number), 10)
+		if ok {
+			optionName = "(" + fullName + ")"
+		}
+		kind := ChangeKindChanged
+		verb := "changed"
+		switch {
+		case len(previousValue) == 0:
+			kind = ChangeKindAdded
+			verb = "was added"
+		case len(value) == 0:
+			kind = ChangeKindRemoved
+			verb = "was removed"
+		}
+		d.add(kind, ElementTypeOption, name, path, anchor, ruleIDsForRuleID(ruleID), `Option %q on %q %s.`, optionName, name, verb)
+	}
+	return nil
+}
+
+func (d *differ) diffFiles(corpus *corpus) error {
+	previousFilePathToFile, err := protosource.FilePathToFile(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	filePathToFile, err := protosource.FilePathToFile(corpus.files...)
+	if err != nil {
+		return err
+	}
+	for previousFilePath := range previousFilePathToFile {
+		if _, ok := filePathToFile[previousFilePath]; !ok {
+			d.add(
+				ChangeKindRemoved,
+				ElementTypeFile,
+				previousFilePath,
+				previousFilePath,
+				newFileAnchor(previousFilePath, previousFilePath),
+				[]string{"FILE_NO_DELETE"},
+				`File %q was removed.`,
+				previousFilePath,
+			)
+		}
+	}
+	for filePath, file := range filePathToFile {
+		previousFile, ok := previousFilePathToFile[filePath]
+		if !ok {
+			d.add(ChangeKindAdded, ElementTypeFile, filePath, filePath, nil, nil, `File %q was added.`, filePath)
+			continue
+		}
+		d.diffFilePair(previousFile, file)
+	}
+	return nil
+}
+
+func (d *differ) diffFilePair(previousFile protosource.File, file protosource.File) {
+	path := file.Path()
+	anchor := newFileAnchor(path)
+	if previousFile.Package() != file.Package() {
+		d.add(ChangeKindChanged, ElementTypeFile, path, path, anchor, []string{"FILE_SAME_PACKAGE"}, `File %q changed package from %q to %q.`, path, previousFile.Package(), file.Package())
+	}
+	if previousFile.Syntax() != file.Syntax() {
+		d.add(ChangeKindChanged, ElementTypeFile, path, path, anchor, []string{"FILE_SAME_SYNTAX"}, `File %q changed syntax from %q to %q.`, path, previousFile.Syntax().String(), file.Syntax().String())
+	}
+	d.addOption(path, path, anchor, "FILE_SAME_CSHARP_NAMESPACE", "csharp_namespace", previousFile.CsharpNamespace(), file.CsharpNamespace())
+	d.addOption(path, path, anchor, "FILE_SAME_GO_PACKAGE", "go_package", previousFile.GoPackage(), file.GoPackage())
+	d.addOption(path, path, anchor, "FILE_SAME_JAVA_MULTIPLE_FILES", "java_multiple_files", strconv.FormatBool(previousFile.JavaMultipleFiles()), strconv.FormatBool(file.JavaMultipleFiles()))
+	d.addOption(path, path, anchor, "FILE_SAME_JAVA_OUTER_CLASSNAME", "java_outer_classname", previousFile.JavaOuterClassname(), file.JavaOuterClassname())
+	d.addOption(path, path, anchor, "FILE_SAME_JAVA_PACKAGE", "java_package", previousFile.JavaPackage(), file.JavaPackage())
+	d.addOption(path, path, anchor, "FILE_SAME_JAVA_STRING_CHECK_UTF8", "java_string_check_utf8", strconv.FormatBool(previousFile.JavaStringCheckUtf8()), strconv.FormatBool(file.JavaStringCheckUtf8()))
+	d.addOption(path, path, anchor, "FILE_SAME_OBJC_CLASS_PREFIX", "objc_class_prefix", previousFile.ObjcClassPrefix(), file.ObjcClassPrefix())
+	d.addOption(path, path, anchor, "FILE_SAME_PHP_CLASS_PREFIX", "php_class_prefix", previousFile.PhpClassPrefix(), file.PhpClassPrefix())
+	d.addOption(path, path, anchor, "FILE_SAME_PHP_NAMESPACE", "php_namespace", previousFile.PhpNamespace(), file.PhpNamespace())
+	d.addOption(path, path, anchor, "FILE_SAME_PHP_METADATA_NAMESPACE", "php_metadata_namespace", previousFile.PhpMetadataNamespace(), file.PhpMetadataNamespace())
+	d.addOption(path, path, anchor, "FILE_SAME_RUBY_PACKAGE", "ruby_package", previousFile.RubyPackage(), file.RubyPackage())
+	d.addOption(path, path, anchor, "FILE_SAME_SWIFT_PREFIX", "swift_prefix", previousFile.SwiftPrefix(), file.SwiftPrefix())
+	d.addOption(path, path, anchor, "FILE_SAME_OPTIMIZE_FOR", "optimize_for", previousFile.OptimizeFor().String(), file.OptimizeFor().String())
+	d.addOption(path, path, anchor, "FILE_SAME_CC_GENERIC_SERVICES", "cc_generic_services", strconv.FormatBool(previousFile.CcGenericServices()), strconv.FormatBool(file.CcGenericServices()))
+	d.addOption(path, path, anchor, "FILE_SAME_JAVA_GENERIC_SERVICES", "java_generic_services", strconv.FormatBool(previousFile.JavaGenericServices()), strconv.FormatBool(file.JavaGenericServices()))
+	d.addOption(path, path, anchor, "FILE_SAME_PY_GENERIC_SERVICES", "py_generic_services", strconv.FormatBool(previousFile.PyGenericServices()), strconv.FormatBool(file.PyGenericServices()))
+	d.addOption(path, path, anchor, "FILE_SAME_PHP_GENERIC_SERVICES", "php_generic_services", strconv.FormatBool(previousFile.PhpGenericServices()), strconv.FormatBool(file.PhpGenericServices()))
+	d.addOption(path, path, anchor, "FILE_SAME_CC_ENABLE_ARENAS", "cc_enable_arenas", strconv.FormatBool(previousFile.CcEnableArenas()), strconv.FormatBool(file.CcEnableArenas()))
+}
+
+func (d *differ) diffMessages(corpus *corpus) error {
+	previousFullNameToMessage, err := protosource.FullNameToMessage(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToMessage, err := protosource.FullNameToMessage(corpus.files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousMessage := range previousFullNameToMessage {
+		// Map entries are represented by the changes to their map fields.
+		if _, ok := fullNameToMessage[previousFullName]; !ok && !previousMessage.IsMapEntry() {
+			previousPath := previousMessage.File().Path()
+			d.add(
+				ChangeKindRemoved,
+				ElementTypeMessage,
+				previousFullName,
+				previousPath,
+				newFileAnchor(previousPath, previousMessage.NestedName()),
+				[]string{"MESSAGE_NO_DELETE", "PACKAGE_MESSAGE_NO_DELETE"},
+				`Message %q was removed.`,
+				previousFullName,
+			)
+		}
+	}
+	for fullName, message := range fullNameToMessage {
+		previousMessage, ok := previousFullNameToMessage[fullName]
+		if !ok {
+			if !message.IsMapEntry() {
+				d.add(ChangeKindAdded, ElementTypeMessage, fullName, message.File().Path(), nil, nil, `Message %q was added.`, fullName)
+			}
+			continue
+		}
+		if err := d.diffMessagePair(corpus, previousMessage, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffMessagePair(corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+	fullName := message.FullName()
+	path := message.File().Path()
+	if previousPath := previousMessage.File().Path(); previousPath != path {
+		d.add(
+			ChangeKindChanged,
+			ElementTypeMessage,
+			fullName,
+			path,
+			newFileAnchor(previousPath, previousMessage.NestedName()),
+			[]string{"MESSAGE_NO_DELETE"},
+			`Message %q moved from file %q to file %q.`,
+			fullName,
+			previousPath,
+			path,
+		)
+	}
+	anchor := newElementAnchor(message)
+	d.addOption(fullName, path, anchor, "MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT", "message_set_wire_format", strconv.FormatBool(previousMessage.MessageSetWireFormat()), strconv.FormatBool(message.MessageSetWireFormat()))
+	d.addOption(fullName, path, anchor, "MESSAGE_NO_REMOVE_STANDARD_DESCRIPTOR_ACCESSOR", "no_standard_descriptor_accessor", strconv.FormatBool(previousMessage.NoStandardDescriptorAccessor()), strconv.FormatBool(message.NoStandardDescriptorAccessor()))
+	if err := d.addCustomOptions(corpus, fullName, path, anchor, "MESSAGE_SAME_CUSTOM_OPTIONS", messageOptionsFullName, previousMessage, message); err != nil {
+		return err
+	}
+	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		if _, ok := numberToField[previousNumber]; !ok {
+			numberString := strconv.Itoa(previousNumber)
+			d.add(
+				ChangeKindRemoved,
+				ElementTypeField,
+				previousField.FullName(),
+				path,
+				newParentAnchor(message, numberString),
+				[]string{
+					"FIELD_NO_DELETE",
+					"FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED",
+					"FIELD_NO_DELETE_UNLESS_NAME_RESERVED",
+					"MESSAGE_SAME_REQUIRED_FIELDS",
+				},
+				`Field %q with name %q on message %q was removed.`,
+				numberString,
+				previousField.Name(),
+				fullName,
+			)
+		}
+	}
+	for number, field := range numberToField {
+		previousField, ok := previousNumberToField[number]
+		if !ok {
+			d.add(
+				ChangeKindAdded,
+				ElementTypeField,
+				field.FullName(),
+				path,
+				newElementAnchor(field),
+				[]string{"FIELD_NO_REUSE_JSON_NAME", "MESSAGE_SAME_REQUIRED_FIELDS"},
+				`Field %q with name %q on message %q was added.`,
+				strconv.Itoa(number),
+				field.Name(),
+				fullName,
+			)
+			continue
+		}
+		if err := d.diffFieldPair(corpus, previousField, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffFieldPair(corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+	fullName := field.FullName()
+	path := field.File().Path()
+	anchor := newElementAnchor(field)
+	numberString := strconv.Itoa(field.Number())
+	messageName := field.Message().FullName()
+	if previousField.Name() != field.Name() {
+		d.add(ChangeKindChanged, ElementTypeField, fullName, path, anchor, []string{"FIELD_SAME_NAME"}, `Field %q on message %q changed name from %q to %q.`, numberString, messageName, previousField.Name(), field.Name())
+	}
+	if previousField.JSONName() != field.JSONName() {
+		d.add(ChangeKindChanged, ElementTypeField, fullName, path, anchor, []string{"FIELD_SAME_JSON_NAME"}, `Field %q on message %q changed option "json_name" from %q to %q.`, numberString, messageName, previousField.JSONName(), field.JSONName())
+	}
+	if previousField.Label() != field.Label() {
+		d.add(ChangeKindChanged, ElementTypeField, fullName, path, anchor, []string{"FIELD_SAME_LABEL", "MESSAGE_SAME_REQUIRED_FIELDS"}, `Field %q on message %q changed label from %q to %q.`, numberString, messageName, previousField.Label().String(), field.Label().String())
+	}
+	if previousTypeString, typeString := fieldTypeString(previousField), fieldTypeString(field); previousTypeString != typeString {
+		d.add(
+			ChangeKindChanged,
+			ElementTypeField,
+			fullName,
+			path,
+			anchor,
+			[]string{"FIELD_SAME_TYPE", "FIELD_WIRE_COMPATIBLE_TYPE", "FIELD_WIRE_JSON_COMPATIBLE_TYPE"},
+			`Field %q on message %q changed type from %q to %q.`,
+			numberString,
+			messageName,
+			previousTypeString,
+			typeString,
+		)
+	}
+	if previousOneofName, oneofName := oneofName(previousField), oneofName(field); previousOneofName != oneofName {
+		d.add(ChangeKindChanged, ElementTypeField, fullName, path, anchor, []string{"FIELD_SAME_ONEOF"}, `Field %q on message %q changed oneof from %q to %q.`, numberString, messageName, previousOneofName, oneofName)
+	}
+	d.addOption(fullName, path, anchor, "FIELD_SAME_CTYPE", "ctype", previousField.CType().String(), field.CType().String())
+	d.addOption(fullName, path, anchor, "FIELD_SAME_JSTYPE", "jstype", previousField.JSType().String(), field.JSType().String())
+	d.addOption(fullName, path, anchor, "", "packed", optionalBoolString(previousField.Packed()), optionalBoolString(field.Packed()))
+	d.addOption(fullName, path, anchor, "", "deprecated", strconv.FormatBool(previousField.Deprecated()), strconv.FormatBool(field.Deprecated()))
+	return d.addCustomOptions(corpus, fullName, path, anchor, "FIELD_SAME_CUSTOM_OPTIONS", fieldOptionsFullName, previousField, field)
+}
+
+func (d *differ) diffEnums(corpus *corpus) error {
+	previousFullNameToEnum, err := protosource.FullNameToEnum(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToEnum, err := protosource.FullNameToEnum(corpus.files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousEnum := range previousFullNameToEnum {
+		if _, ok := fullNameToEnum[previousFullName]; !ok {
+			previousPath := previousEnum.File().Path()
+			d.add(
+				ChangeKindRemoved,
+				ElementTypeEnum,
+				previousFullName,
+				previousPath,
+				newFileAnchor(previousPath, previousEnum.NestedName()),
+				[]string{"ENUM_NO_DELETE", "PACKAGE_ENUM_NO_DELETE"},
+				`Enum %q was removed.`,
+				previousFullName,
+			)
+		}
+	}
+	for fullName, enum := range fullNameToEnum {
+		previousEnum, ok := previousFullNameToEnum[fullName]
+		if !ok {
+			d.add(ChangeKindAdded, ElementTypeEnum, fullName, enum.File().Path(), nil, nil, `Enum %q was added.`, fullName)
+			continue
+		}
+		if err := d.diffEnumPair(previousEnum, enum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffEnumPair(previousEnum protosource.Enum, enum protosource.Enum) error {
+	fullName := enum.FullName()
+	path := enum.File().Path()
+	if previousPath := previousEnum.File().Path(); previousPath != path {
+		d.add(
+			ChangeKindChanged,
+			ElementTypeEnum,
+			fullName,
+			path,
+			newFileAnchor(previousPath, previousEnum.NestedName()),
+			[]string{"ENUM_NO_DELETE"},
+			`Enum %q moved from file %q to file %q.`,
+			fullName,
+			previousPath,
+			path,
+		)
+	}
+	d.addOption(fullName, path, newElementAnchor(enum), "", "allow_alias", strconv.FormatBool(previousEnum.AllowAlias()), strconv.FormatBool(enum.AllowAlias()))
+	previousNumberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(previousEnum)
+	if err != nil {
+		return err
+	}
+	numberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(enum)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousNameToEnumValue := range previousNumberToNameToEnumValue {
+		if _, ok := numberToNameToEnumValue[previousNumber]; !ok {
+			previousNames := getSortedEnumValueNames(previousNameToEnumValue)
+			numberString := strconv.Itoa(previousNumber)
+			d.add(
+				ChangeKindRemoved,
+				ElementTypeEnumValue,
+				enumValueFullName(enum, previousNames),
+				path,
+				newParentAnchor(enum, numberString),
+				[]string{
+					"ENUM_VALUE_NO_DELETE",
+					"ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED",
+					"ENUM_VALUE_NO_DELETE_UNLESS_NAME_RESERVED",
+				},
+				`Enum value %q with name %q on enum %q was removed.`,
+				numberString,
+				strings.Join(previousNames, ", "),
+				fullName,
+			)
+		}
+	}
+	for number, nameToEnumValue := range numberToNameToEnumValue {
+		names := getSortedEnumValueNames(nameToEnumValue)
+		enumValues := make([]protosource.LocationDescriptor, 0, len(names))
+		for _, name := range names {
+			enumValues = append(enumValues, nameToEnumValue[name])
+		}
+		anchor := newElementAnchor(enumValues...)
+		previousNameToEnumValue, ok := previousNumberToNameToEnumValue[number]
+		if !ok {
+			d.add(
+				ChangeKindAdded,
+				ElementTypeEnumValue,
+				enumValueFullName(enum, names),
+				path,
+				anchor,
+				[]string{"ENUM_VALUE_SAME_NUMBER"},
+				`Enum value %q with name %q on enum %q was added.`,
+				strconv.Itoa(number),
+				strings.Join(names, ", "),
+				fullName,
+			)
+			continue
+		}
+		if previousNames := getSortedEnumValueNames(previousNameToEnumValue); strings.Join(previousNames, ",") != strings.Join(names, ",") {
+			d.add(
+				ChangeKindChanged,
+				ElementTypeEnumValue,
+				enumValueFullName(enum, names),
+				path,
+				anchor,
+				[]string{"ENUM_VALUE_SAME_NAME", "ENUM_VALUE_SAME_NUMBER"},
+				`Enum value %q on enum %q changed name from %q to %q.`,
+				strconv.Itoa(number),
+				fullName,
+				strings.Join(previousNames, ", "),
+				strings.Join(names, ", "),
+			)
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffServices(corpus *corpus) error {
+	previousFullNameToService, err := protosource.FullNameToService(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToService, err := protosource.FullNameToService(corpus.files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousService := range previousFullNameToService {
+		if _, ok := fullNameToService[previousFullName]; !ok {
+			previousPath := previousService.File().Path()
+			d.add(
+				ChangeKindRemoved,
+				ElementTypeService,
+				previousFullName,
+				previousPath,
+				newFileAnchor(previousPath, previousService.Name()),
+				[]string{"SERVICE_NO_DELETE", "PACKAGE_SERVICE_NO_DELETE"},
+				`Service %q was removed.`,
+				previousFullName,
+			)
+		}
+	}
+	for fullName, service := range fullNameToService {
+		previousService, ok := previousFullNameToService[fullName]
+		if !ok {
+			d.add(ChangeKindAdded, ElementTypeService, fullName, service.File().Path(), nil, nil, `Service %q was added.`, fullName)
+			continue
+		}
+		if err := d.diffServicePair(corpus, previousService, service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffServicePair(corpus *corpus, previousService protosource.Service, service protosource.Service) error {
+	fullName := service.FullName()
+	path := service.File().Path()
+	if previousPath := previousService.File().Path(); previousPath != path {
+		d.add(
+			ChangeKindChanged,
+			ElementTypeService,
+			fullName,
+			path,
+			newFileAnchor(previousPath, previousService.Name()),
+			[]string{"SERVICE_NO_DELETE"},
+			`Service %q moved from file %q to file %q.`,
+			fullName,
+			previousPath,
+			path,
+		)
+	}
+	if err := d.addCustomOptions(corpus, fullName, path, newElementAnchor(service), "SERVICE_SAME_CUSTOM_OPTIONS", serviceOptionsFullName, previousService, service); err != nil {
+		return err
+	}
+	previousNameToMethod, err := protosource.NameToMethod(previousService)
+	if err != nil {
+		return err
+	}
+	nameToMethod, err := protosource.NameToMethod(service)
+	if err != nil {
+		return err
+	}
+	for previousName, previousMethod := range previousNameToMethod {
+		if _, ok := nameToMethod[previousName]; !ok {
+			d.add(
+				ChangeKindRemoved,
+				ElementTypeRPC,
+				previousMethod.FullName(),
+				path,
+				newParentAnchor(service, previousName),
+				[]string{"RPC_NO_DELETE"},
+				`RPC %q on service %q was removed.`,
+				previousName,
+				fullName,
+			)
+		}
+	}
+	for name, method := range nameToMethod {
+		previousMethod, ok := previousNameToMethod[name]
+		if !ok {
+			d.add(ChangeKindAdded, ElementTypeRPC, method.FullName(), path, nil, nil, `RPC %q on service %q was added.`, name, fullName)
+			continue
+		}
+		if err := d.diffMethodPair(corpus, previousMethod, method); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffMethodPair(corpus *corpus, previousMethod protosource.Method, method protosource.Method) error {
+	fullName := method.FullName()
+	path := method.File().Path()
+	anchor := newElementAnchor(method)
+	serviceName := method.Service().FullName()
+	if previousMethod.InputTypeName() != method.InputTypeName() {
+		d.add(ChangeKindChanged, ElementTypeRPC, fullName, path, anchor, []string{"RPC_SAME_REQUEST_TYPE"}, `RPC %q on service %q changed request type from %q to %q.`, method.Name(), serviceName, previousMethod.InputTypeName(), method.InputTypeName())
+	}
+	if previousMethod.OutputTypeName() != method.OutputTypeName() {
+		d.add(ChangeKindChanged, ElementTypeRPC, fullName, path, anchor, []string{"RPC_SAME_RESPONSE_TYPE"}, `RPC %q on service %q changed response type from %q to %q.`, method.Name(), serviceName, previousMethod.OutputTypeName(), method.OutputTypeName())
+	}
+	if previousMethod.ClientStreaming() != method.ClientStreaming() {
+		d.add(ChangeKindChanged, ElementTypeRPC, fullName, path, anchor, []string{"RPC_SAME_CLIENT_STREAMING"}, `RPC %q on service %q changed client streaming from %q to %q.`, method.Name(), serviceName, strconv.FormatBool(previousMethod.ClientStreaming()), strconv.FormatBool(method.ClientStreaming()))
+	}
+	if previousMethod.ServerStreaming() != method.ServerStreaming() {
+		d.add(ChangeKindChanged, ElementTypeRPC, fullName, path, anchor, []string{"RPC_SAME_SERVER_STREAMING"}, `RPC %q on service %q changed server streaming from %q to %q.`, method.Name(), serviceName, strconv.FormatBool(previousMethod.ServerStreaming()), strconv.FormatBool(method.ServerStreaming()))
+	}
+	d.addOption(fullName, path, anchor, "RPC_SAME_IDEMPOTENCY_LEVEL", "idempotency_level", previousMethod.IdempotencyLevel().String(), method.IdempotencyLevel().String())
+	return d.addCustomOptions(corpus, fullName, path, anchor, "RPC_SAME_CUSTOM_OPTIONS", methodOptionsFullName, previousMethod, method)
+}
+
+// fieldTypeString returns the type name for message and enum fields, and the
+// type otherwise.
+func fieldTypeString(field protosource.Field) string {
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeMessage, protosource.FieldDescriptorProtoTypeEnum, protosource.FieldDescriptorProtoTypeGroup:
+		return strings.TrimPrefix(field.TypeName(), ".")
+	default:
+		return field.Type().String()
+	}
+}
+
+func oneofName(field protosource.Field) string {
+	if oneof := field.Oneof(); oneof != nil {
+		return oneof.Name()
+	}
+	return ""
+}
+
+func optionalBoolString(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*value)
+}
+
+func enumValueFullName(enum protosource.Enum, names []string) string {
+	// Enum values are siblings of their enum.
+	prefix := strings.TrimSuffix(enum.FullName(), enum.Name())
+	if len(names) == 0 {
+		return prefix
+	}
+	return prefix + names[0]
+}
+
+func ruleIDsForRuleID(ruleID string) []string {
+	if ruleID == "" {
+		return nil
+	}
+	return []string{ruleID}
+}
+
+// quoteTokens quotes the tokens the way the rules quote them in FileAnnotation messages.
+func quoteTokens(tokens []string) []string {
+	quotedTokens := make([]string, len(tokens))
+	for i, token := range tokens {
+		quotedTokens[i] = strconv.Quote(token)
+	}
+	return quotedTokens
+}
+
+// comparePositions compares two line and column positions.
+func comparePositions(line int, column int, otherLine int, otherColumn int) int {
+	if line != otherLine {
+		return line - otherLine
+	}
+	return column - otherColumn
+}
@@ -15,8 +15,10 @@
 package bufbreakingcheck
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
@@ -24,6 +26,13 @@ import (
 	"github.com/bufbuild/buf/private/pkg/protosource"
 )
 
+const (
+	messageOptionsFullName = "google.protobuf.MessageOptions"
+	fieldOptionsFullName   = "google.protobuf.FieldOptions"
+	serviceOptionsFullName = "google.protobuf.ServiceOptions"
+	methodOptionsFullName  = "google.protobuf.MethodOptions"
+)
+
 var (
 	// https://developers.google.com/protocol-buffers/docs/proto3#updating
 	fieldDescriptorProtoTypeToWireCompatiblityGroup = map[protosource.FieldDescriptorProtoType]int{
@@ -94,6 +103,9 @@ type addFunc func(protosource.Descriptor, []protosource.Descriptor, protosource.
 type corpus struct {
 	previousFiles []protosource.File
 	files         []protosource.File
+
+	// lazily computed by getExtensionFullName
+	extendeeToNumberToExtensionFullName map[string]map[int]string
 }
 
 func newCorpus(
@@ -106,6 +118,45 @@ func newCorpus(
 	}
 }
 
+// getExtensionFullName gets the full name of the extension of the given extendee with the given number.
+//
+// The files take precedence over the previous files. Returns false if the extension is not
+// defined in either, for example if imports were excluded.
+func (c *corpus) getExtensionFullName(extendee string, number int) (string, bool, error) {
+	if c.extendeeToNumberToExtensionFullName == nil {
+		extendeeToNumberToExtensionFullName := make(map[string]map[int]string)
+		addExtension := func(extension protosource.Field) {
+			numberToExtensionFullName, ok := extendeeToNumberToExtensionFullName[extension.Extendee()]
+			if !ok {
+				numberToExtensionFullName = make(map[int]string)
+				extendeeToNumberToExtensionFullName[extension.Extendee()] = numberToExtensionFullName
+			}
+			if _, ok := numberToExtensionFullName[extension.Number()]; !ok {
+				numberToExtensionFullName[extension.Number()] = extension.FullName()
+			}
+		}
+		for _, file := range append(append([]protosource.File{}, c.files...), c.previousFiles...) {
+			for _, extension := range file.Extensions() {
+				addExtension(extension)
+			}
+			if err := protosource.ForEachMessage(
+				func(message protosource.Message) error {
+					for _, extension := range message.Extensions() {
+						addExtension(extension)
+					}
+					return nil
+				},
+				file,
+			); err != nil {
+				return "", false, err
+			}
+		}
+		c.extendeeToNumberToExtensionFullName = extendeeToNumberToExtensionFullName
+	}
+	fullName, ok := c.extendeeToNumberToExtensionFullName[extendee][number]
+	return fullName, ok, nil
+}
+
 func newFilesCheckFunc(
 	f func(addFunc, *corpus) error,
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
@@ -350,3 +401,88 @@ func withBackupLocation(primary protosource.Location, secondary protosource.Loca
 	}
 	return secondary
 }
+
+// checkSameCustomOptions checks that the custom options set on an element were not added,
+// removed, or changed.
+//
+// Options are compared by their wire format encoding. If customOptions is not empty, only
+// the options with the given full names are compared.
+func checkSameCustomOptions(
+	add addFunc,
+	corpus *corpus,
+	customOptions map[string]struct{},
+	optionsFullName string,
+	previousOptionExtensionDescriptor protosource.OptionExtensionDescriptor,
+	optionExtensionDescriptor protosource.OptionExtensionDescriptor,
+	descriptor protosource.Descriptor,
+	location protosource.Location,
+	elementDescription string,
+) error {
+	numberMap := make(map[int32]struct{})
+	for _, number := range previousOptionExtensionDescriptor.PresentExtensionNumbers() {
+		numberMap[number] = struct{}{}
+	}
+	for _, number := range optionExtensionDescriptor.PresentExtensionNumbers() {
+		numberMap[number] = struct{}{}
+	}
+	numbers := make([]int32, 0, len(numberMap))
+	for number := range numberMap {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i int, j int) bool { return numbers[i] < numbers[j] })
+	for _, number := range numbers {
+		fullName, ok, err := corpus.getExtensionFullName(optionsFullName, int(number))
+		if err != nil {
+			return err
+		}
+		var optionName string
+		if ok {
+			optionName = "(" + fullName + ")"
+		} else {
+			// the extension is not defined in the files, we can only refer to it by number
+			optionName = strconv.FormatInt(int64(number), 10)
+		}
+		if len(customOptions) > 0 {
+			if _, ok := customOptions[fullName]; !ok {
+				continue
+			}
+		}
+		previousValue, err := previousOptionExtensionDescriptor.OptionExtensionBytes(number)
+		if err != nil {
+			return err
+		}
+		value, err := optionExtensionDescriptor.OptionExtensionBytes(number)
+		if err != nil {
+			return err
+		}
+		switch {
+		case bytes.Equal(previousValue, value):
+		case len(previousValue) == 0:
+			add(descriptor, nil, location, `%s added option %q.`, elementDescription, optionName)
+		case len(value) == 0:
+			add(descriptor, nil, location, `%s removed option %q.`, elementDescription, optionName)
+		default:
+			add(descriptor, nil, location, `%s changed the value of option %q.`, elementDescription, optionName)
+		}
+	}
+	return nil
+}
+
+// defaultJSONName returns the JSON name that protoc assigns to a field with the given name
+// if the json_name option is not set.
+func defaultJSONName(name string) string {
+	var builder strings.Builder
+	afterUnderscore := false
+	for _, r := range name {
+		if r == '_' {
+			afterUnderscore = true
+			continue
+		}
+		if afterUnderscore && 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		afterUnderscore = false
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
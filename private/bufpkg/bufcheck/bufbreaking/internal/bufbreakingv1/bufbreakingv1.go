@@ -30,6 +30,12 @@ import "github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 // Splits FIELD_SAME_TYPE into FIELD_SAME_TYPE for FILE AND PACKAGE,
 // FIRE_WIRE_JSON_COMPATIBLE_TYPE for WIRE_JSON, and
 // FIELD_WIRE_COMPATIBLE_TYPE for WIRE.
+//
+// Adds ENUM_VALUE_SAME_NUMBER and FIELD_NO_REUSE_JSON_NAME to WIRE_JSON only, so that
+// the default FILE category does not become stricter for existing configurations.
+//
+// Adds MESSAGE_SAME_CUSTOM_OPTIONS, FIELD_SAME_CUSTOM_OPTIONS, SERVICE_SAME_CUSTOM_OPTIONS,
+// and RPC_SAME_CUSTOM_OPTIONS to the new CUSTOM_OPTIONS category, which is not used by default.
 var VersionSpec = &internal.VersionSpec{
 	RuleBuilders:      v1RuleBuilders,
 	DefaultCategories: v1DefaultCategories,
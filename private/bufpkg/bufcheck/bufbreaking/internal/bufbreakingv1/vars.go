@@ -27,11 +27,14 @@ var (
 		bufbreakingbuild.EnumValueNoDeleteUnlessNameReservedRuleBuilder,
 		bufbreakingbuild.EnumValueNoDeleteUnlessNumberReservedRuleBuilder,
 		bufbreakingbuild.EnumValueSameNameRuleBuilder,
+		bufbreakingbuild.EnumValueSameNumberRuleBuilder,
 		bufbreakingbuild.ExtensionMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNameReservedRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNumberReservedRuleBuilder,
+		bufbreakingbuild.FieldNoReuseJSONNameRuleBuilder,
 		bufbreakingbuild.FieldSameCTypeRuleBuilder,
+		bufbreakingbuild.FieldSameCustomOptionsRuleBuilder,
 		bufbreakingbuild.FieldSameJSONNameRuleBuilder,
 		bufbreakingbuild.FieldSameJSTypeRuleBuilder,
 		bufbreakingbuild.FieldSameLabelRuleBuilder,
@@ -63,6 +66,7 @@ var (
 		bufbreakingbuild.FileSameSyntaxRuleBuilder,
 		bufbreakingbuild.MessageNoDeleteRuleBuilder,
 		bufbreakingbuild.MessageNoRemoveStandardDescriptorAccessorRuleBuilder,
+		bufbreakingbuild.MessageSameCustomOptionsRuleBuilder,
 		bufbreakingbuild.MessageSameMessageSetWireFormatRuleBuilder,
 		bufbreakingbuild.MessageSameRequiredFieldsRuleBuilder,
 		bufbreakingbuild.OneofNoDeleteRuleBuilder,
@@ -74,11 +78,13 @@ var (
 		bufbreakingbuild.ReservedMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.RPCNoDeleteRuleBuilder,
 		bufbreakingbuild.RPCSameClientStreamingRuleBuilder,
+		bufbreakingbuild.RPCSameCustomOptionsRuleBuilder,
 		bufbreakingbuild.RPCSameIdempotencyLevelRuleBuilder,
 		bufbreakingbuild.RPCSameRequestTypeRuleBuilder,
 		bufbreakingbuild.RPCSameResponseTypeRuleBuilder,
 		bufbreakingbuild.RPCSameServerStreamingRuleBuilder,
 		bufbreakingbuild.ServiceNoDeleteRuleBuilder,
+		bufbreakingbuild.ServiceSameCustomOptionsRuleBuilder,
 	}
 
 	// v1DefaultCategories are the default categories.
@@ -106,6 +112,9 @@ var (
 			"PACKAGE",
 			"WIRE_JSON",
 		},
+		"ENUM_VALUE_SAME_NUMBER": {
+			"WIRE_JSON",
+		},
 		"EXTENSION_MESSAGE_NO_DELETE": {
 			"FILE",
 			"PACKAGE",
@@ -121,10 +130,16 @@ var (
 			"WIRE_JSON",
 			"WIRE",
 		},
+		"FIELD_NO_REUSE_JSON_NAME": {
+			"WIRE_JSON",
+		},
 		"FIELD_SAME_CTYPE": {
 			"FILE",
 			"PACKAGE",
 		},
+		"FIELD_SAME_CUSTOM_OPTIONS": {
+			"CUSTOM_OPTIONS",
+		},
 		"FIELD_SAME_JSON_NAME": {
 			"FILE",
 			"PACKAGE",
@@ -253,6 +268,9 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"MESSAGE_SAME_CUSTOM_OPTIONS": {
+			"CUSTOM_OPTIONS",
+		},
 		"MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT": {
 			"FILE",
 			"PACKAGE",
@@ -303,6 +321,9 @@ var (
 			"WIRE_JSON",
 			"WIRE",
 		},
+		"RPC_SAME_CUSTOM_OPTIONS": {
+			"CUSTOM_OPTIONS",
+		},
 		"RPC_SAME_IDEMPOTENCY_LEVEL": {
 			"FILE",
 			"PACKAGE",
@@ -330,5 +351,8 @@ var (
 		"SERVICE_NO_DELETE": {
 			"FILE",
 		},
+		"SERVICE_SAME_CUSTOM_OPTIONS": {
+			"CUSTOM_OPTIONS",
+		},
 	}
 )
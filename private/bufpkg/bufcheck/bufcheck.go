@@ -26,6 +26,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"go.uber.org/multierr"
 )
 
@@ -89,6 +90,18 @@ func PrintRules(writer io.Writer, rules []Rule, formatString string) (retErr err
 	return nil
 }
 
+// PrintFileAnnotationsWithRules returns a new bufanalysis.PrintFileAnnotationsOption
+// that describes FileAnnotations produced by the given rules.
+//
+// The rule purposes are used as the type descriptions.
+func PrintFileAnnotationsWithRules(rules []Rule) bufanalysis.PrintFileAnnotationsOption {
+	typeToDescription := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		typeToDescription[rule.ID()] = rule.Purpose()
+	}
+	return bufanalysis.PrintFileAnnotationsWithTypeDescriptions(typeToDescription)
+}
+
 func printRule(writer io.Writer, rule Rule, asJSON bool) error {
 	if asJSON {
 		data, err := json.Marshal(rule)
@@ -0,0 +1,167 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufcheckplugin implements the protocol for check plugins.
+//
+// A check plugin is a binary that provides lint rules in addition to the
+// rules that are built into buf. Similar to protoc plugins, a check plugin
+// reads a single JSON-encoded Request from stdin and writes a single
+// JSON-encoded Response to stdout.
+//
+// If ListRules is set on the Request, the plugin responds with the Rules
+// it provides. Otherwise, the plugin checks the Image on the Request with
+// the Rules with the given IDs, and responds with the resulting Annotations.
+//
+// A plugin that fails should print an error to stderr and exit with a
+// non-zero exit code.
+//
+// Plugins written in Go can use Main with a Handler to implement the protocol.
+package bufcheckplugin
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+)
+
+// Request is a request to a check plugin.
+type Request struct {
+	// ListRules says to list the Rules provided by the plugin.
+	//
+	// If set, Image and RuleIDs are empty.
+	ListRules bool `json:"list_rules,omitempty"`
+	// Image is the binary-encoded buf.alpha.image.v1.Image to check.
+	//
+	// The Image includes the imports so that the types and options defined in them
+	// can be resolved. Files that are imports are marked as such, and should not be checked.
+	Image []byte `json:"image,omitempty"`
+	// RuleIDs are the IDs of the Rules to check.
+	RuleIDs []string `json:"rule_ids,omitempty"`
+	// Options are the options for the plugin from the configuration.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// Response is a response from a check plugin.
+type Response struct {
+	// Rules are the Rules provided by the plugin, if ListRules was set on the Request.
+	Rules []*Rule `json:"rules,omitempty"`
+	// Annotations are the violations found by the plugin.
+	Annotations []*Annotation `json:"annotations,omitempty"`
+}
+
+// Rule is a rule provided by a check plugin.
+type Rule struct {
+	// ID is the ID of the rule.
+	//
+	// Must not be the ID of a rule built into buf.
+	ID string `json:"id,omitempty"`
+	// Categories are the categories of the rule.
+	//
+	// May be empty.
+	Categories []string `json:"categories,omitempty"`
+	// Purpose is the purpose of the rule.
+	//
+	// Like the purposes of the rules built into buf, this should complete
+	// the sentence "Checks that ", for example "all RPCs set the auth option".
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// Annotation is a violation found by a check plugin.
+type Annotation struct {
+	// Path is the path of the file within the Image.
+	Path string `json:"path,omitempty"`
+	// StartLine is the starting line, or 0 if not known.
+	StartLine int `json:"start_line,omitempty"`
+	// StartColumn is the starting column, or 0 if not known.
+	StartColumn int `json:"start_column,omitempty"`
+	// EndLine is the ending line, or 0 if not known.
+	EndLine int `json:"end_line,omitempty"`
+	// EndColumn is the ending column, or 0 if not known.
+	EndColumn int `json:"end_column,omitempty"`
+	// Type is the ID of the Rule that was violated.
+	Type string `json:"type,omitempty"`
+	// Message is the message of the violation.
+	Message string `json:"message,omitempty"`
+}
+
+// Runner runs check plugins.
+type Runner interface {
+	// ListRules lists the Rules provided by the plugin.
+	ListRules(
+		ctx context.Context,
+		plugin string,
+		options map[string]string,
+	) ([]*Rule, error)
+	// Check checks the Image with the Rules with the given IDs.
+	//
+	// The returned FileAnnotations reference the ImageFiles of the Image.
+	// FileAnnotations returned by the plugin for imports are dropped.
+	Check(
+		ctx context.Context,
+		plugin string,
+		options map[string]string,
+		image bufimage.Image,
+		ruleIDs []string,
+	) ([]bufanalysis.FileAnnotation, error)
+}
+
+// NewRunner returns a new Runner that executes plugins as binaries.
+//
+// The plugin is either the name of a binary on the PATH, or a path to a binary.
+func NewRunner(commandRunner command.Runner, options ...RunnerOption) Runner {
+	return newRunner(commandRunner, options...)
+}
+
+// RunnerOption is an option for a new Runner.
+type RunnerOption func(*runner)
+
+// RunnerWithEnv returns a new RunnerOption that sets the environment
+// variables of the plugins.
+//
+// The default is to use no environment variables.
+func RunnerWithEnv(env map[string]string) RunnerOption {
+	return func(runner *runner) {
+		runner.env = env
+	}
+}
+
+// Handler handles the requests of a check plugin.
+type Handler interface {
+	// Rules returns the Rules provided by the plugin.
+	Rules(ctx context.Context, options map[string]string) ([]*Rule, error)
+	// Check checks the Image with the Rules with the given IDs.
+	//
+	// The Image includes the imports, which have IsImport set and should not be checked.
+	Check(
+		ctx context.Context,
+		image bufimage.Image,
+		ruleIDs []string,
+		options map[string]string,
+	) ([]bufanalysis.FileAnnotation, error)
+}
+
+// Main runs the plugin using app.Main and the Handler.
+func Main(ctx context.Context, handler Handler) {
+	app.Main(ctx, newRunFunc(handler))
+}
+
+// Run runs the plugin using app.Run and the Handler.
+//
+// The exit code can be determined using app.GetExitCode.
+func Run(ctx context.Context, container app.Container, handler Handler) error {
+	return app.Run(ctx, container, newRunFunc(handler))
+}
@@ -0,0 +1,211 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcheckplugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestListRules(t *testing.T) {
+	t.Parallel()
+	rules, err := newTestRunner(&testHandler{}).ListRules(context.Background(), "test", nil)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]*Rule{
+			{
+				ID:         "FILE_HAS_PACKAGE",
+				Categories: []string{"ACME"},
+				Purpose:    "all files have a package",
+			},
+		},
+		rules,
+	)
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	image := testNewImage(t)
+	handler := &testHandler{}
+	fileAnnotations, err := newTestRunner(handler).Check(
+		context.Background(),
+		"test",
+		map[string]string{"message": "no package"},
+		image,
+		[]string{"FILE_HAS_PACKAGE"},
+	)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "b.proto", 1, 1, 1, 1, "FILE_HAS_PACKAGE"),
+		},
+		fileAnnotations,
+	)
+	assert.Equal(t, "no package", fileAnnotations[0].Message())
+	// the annotation references the ImageFile of the Image
+	assert.Equal(t, image.GetFile("b.proto"), fileAnnotations[0].FileInfo())
+	// the plugin gets the imports
+	assert.Equal(t, []string{"c.proto"}, handler.importPaths)
+}
+
+func TestCheckImportAnnotationsDropped(t *testing.T) {
+	t.Parallel()
+	fileAnnotations, err := newTestRunner(&testHandler{checkImports: true}).Check(
+		context.Background(),
+		"test",
+		nil,
+		testNewImage(t),
+		[]string{"FILE_HAS_PACKAGE"},
+	)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "b.proto", 1, 1, 1, 1, "FILE_HAS_PACKAGE"),
+		},
+		fileAnnotations,
+	)
+}
+
+func TestCheckUnrequestedRule(t *testing.T) {
+	t.Parallel()
+	_, err := newTestRunner(&testHandler{annotationType: "OTHER"}).Check(
+		context.Background(),
+		"test",
+		nil,
+		testNewImage(t),
+		[]string{"FILE_HAS_PACKAGE"},
+	)
+	assert.Error(t, err)
+}
+
+func TestCheckError(t *testing.T) {
+	t.Parallel()
+	_, err := newTestRunner(&testHandler{err: errors.New("broken")}).Check(
+		context.Background(),
+		"test",
+		nil,
+		testNewImage(t),
+		[]string{"FILE_HAS_PACKAGE"},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+}
+
+type testHandler struct {
+	// annotationType overrides the type of the returned annotations if set.
+	annotationType string
+	// checkImports says to also check the imports, which a plugin should not do.
+	checkImports bool
+	err          error
+
+	// importPaths are the paths of the imports of the last checked Image.
+	importPaths []string
+}
+
+func (h *testHandler) Rules(context.Context, map[string]string) ([]*Rule, error) {
+	return []*Rule{
+		{
+			ID:         "FILE_HAS_PACKAGE",
+			Categories: []string{"ACME"},
+			Purpose:    "all files have a package",
+		},
+	}, nil
+}
+
+func (h *testHandler) Check(
+	_ context.Context,
+	image bufimage.Image,
+	ruleIDs []string,
+	options map[string]string,
+) ([]bufanalysis.FileAnnotation, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	h.importPaths = nil
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			h.importPaths = append(h.importPaths, imageFile.Path())
+		}
+	}
+	var fileAnnotations []bufanalysis.FileAnnotation
+	for _, ruleID := range ruleIDs {
+		if h.annotationType != "" {
+			ruleID = h.annotationType
+		}
+		for _, imageFile := range image.Files() {
+			if imageFile.IsImport() && !h.checkImports {
+				continue
+			}
+			if imageFile.Proto().GetPackage() == "" {
+				fileAnnotations = append(
+					fileAnnotations,
+					bufanalysis.NewFileAnnotation(imageFile, 1, 1, 1, 1, ruleID, options["message"]),
+				)
+			}
+		}
+	}
+	return fileAnnotations, nil
+}
+
+// newTestRunner returns a new runner that runs the Handler in-process.
+func newTestRunner(handler Handler) *runner {
+	return &runner{
+		runFunc: func(ctx context.Context, plugin string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+			return Run(ctx, app.NewContainer(nil, stdin, stdout, stderr, plugin), handler)
+		},
+	}
+}
+
+func testNewImage(t *testing.T) bufimage.Image {
+	var imageFiles []bufimage.ImageFile
+	for _, fileDescriptorProto := range []*descriptorpb.FileDescriptorProto{
+		{
+			Name:   proto.String("c.proto"),
+			Syntax: proto.String("proto3"),
+		},
+		{
+			Name:       proto.String("a.proto"),
+			Package:    proto.String("a"),
+			Dependency: []string{"c.proto"},
+			Syntax:     proto.String("proto3"),
+		},
+		{
+			Name:   proto.String("b.proto"),
+			Syntax: proto.String("proto3"),
+		},
+	} {
+		isImport := fileDescriptorProto.GetName() == "c.proto"
+		imageFile, err := bufimage.NewImageFile(fileDescriptorProto, nil, "", "", isImport, false, nil)
+		require.NoError(t, err)
+		imageFiles = append(imageFiles, imageFile)
+	}
+	image, err := bufimage.NewImage(imageFiles)
+	require.NoError(t, err)
+	return image
+}
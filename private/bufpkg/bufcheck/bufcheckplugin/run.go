@@ -0,0 +1,103 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcheckplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+)
+
+func newRunFunc(handler Handler) func(context.Context, app.Container) error {
+	return func(ctx context.Context, container app.Container) error {
+		requestData, err := io.ReadAll(container.Stdin())
+		if err != nil {
+			return err
+		}
+		request := &Request{}
+		if err := json.Unmarshal(requestData, request); err != nil {
+			return fmt.Errorf("invalid request: %w", err)
+		}
+		response, err := handle(ctx, handler, request)
+		if err != nil {
+			return err
+		}
+		responseData, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+		_, err = container.Stdout().Write(responseData)
+		return err
+	}
+}
+
+func handle(ctx context.Context, handler Handler, request *Request) (*Response, error) {
+	if request.ListRules {
+		rules, err := handler.Rules(ctx, request.Options)
+		if err != nil {
+			return nil, err
+		}
+		return &Response{
+			Rules: rules,
+		}, nil
+	}
+	protoImage := &imagev1.Image{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(request.Image, protoImage); err != nil {
+		return nil, err
+	}
+	image, err := bufimage.NewImageForProto(protoImage)
+	if err != nil {
+		return nil, err
+	}
+	fileAnnotations, err := handler.Check(
+		ctx,
+		image,
+		request.RuleIDs,
+		request.Options,
+	)
+	if err != nil {
+		return nil, err
+	}
+	annotations := make([]*Annotation, len(fileAnnotations))
+	for i, fileAnnotation := range fileAnnotations {
+		annotations[i] = annotationForFileAnnotation(fileAnnotation)
+	}
+	return &Response{
+		Annotations: annotations,
+	}, nil
+}
+
+func annotationForFileAnnotation(fileAnnotation bufanalysis.FileAnnotation) *Annotation {
+	var path string
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+		path = fileInfo.Path()
+	}
+	return &Annotation{
+		Path:        path,
+		StartLine:   fileAnnotation.StartLine(),
+		StartColumn: fileAnnotation.StartColumn(),
+		EndLine:     fileAnnotation.EndLine(),
+		EndColumn:   fileAnnotation.EndColumn(),
+		Type:        fileAnnotation.Type(),
+		Message:     fileAnnotation.Message(),
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcheckplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"go.opencensus.io/trace"
+)
+
+type runner struct {
+	env map[string]string
+	// runFunc runs the plugin with the given stdio.
+	runFunc func(ctx context.Context, plugin string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error
+}
+
+func newRunner(commandRunner command.Runner, options ...RunnerOption) *runner {
+	runner := &runner{}
+	for _, option := range options {
+		option(runner)
+	}
+	runner.runFunc = func(ctx context.Context, plugin string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+		return commandRunner.Run(
+			ctx,
+			plugin,
+			command.RunWithEnv(runner.env),
+			command.RunWithStdin(stdin),
+			command.RunWithStdout(stdout),
+			command.RunWithStderr(stderr),
+		)
+	}
+	return runner
+}
+
+func (r *runner) ListRules(
+	ctx context.Context,
+	plugin string,
+	options map[string]string,
+) ([]*Rule, error) {
+	response, err := r.run(
+		ctx,
+		plugin,
+		&Request{
+			ListRules: true,
+			Options:   options,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	seenIDs := make(map[string]struct{}, len(response.Rules))
+	for _, rule := range response.Rules {
+		if rule == nil || rule.ID == "" {
+			return nil, fmt.Errorf("plugin %q returned a rule without an id", plugin)
+		}
+		if _, ok := seenIDs[rule.ID]; ok {
+			return nil, fmt.Errorf("plugin %q returned duplicate rule %q", plugin, rule.ID)
+		}
+		seenIDs[rule.ID] = struct{}{}
+	}
+	return response.Rules, nil
+}
+
+func (r *runner) Check(
+	ctx context.Context,
+	plugin string,
+	options map[string]string,
+	image bufimage.Image,
+	ruleIDs []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	if len(ruleIDs) == 0 {
+		return nil, nil
+	}
+	imageData, err := protoencoding.NewWireMarshaler().Marshal(bufimage.ImageToProtoImage(image))
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.run(
+		ctx,
+		plugin,
+		&Request{
+			Image:   imageData,
+			RuleIDs: ruleIDs,
+			Options: options,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	ruleIDMap := stringutil.SliceToMap(ruleIDs)
+	fileAnnotations := make([]bufanalysis.FileAnnotation, 0, len(response.Annotations))
+	for _, annotation := range response.Annotations {
+		if annotation == nil {
+			continue
+		}
+		if _, ok := ruleIDMap[annotation.Type]; !ok {
+			return nil, fmt.Errorf("plugin %q returned an annotation for rule %q which was not requested", plugin, annotation.Type)
+		}
+		imageFile := image.GetFile(annotation.Path)
+		if imageFile == nil {
+			return nil, fmt.Errorf("plugin %q returned an annotation for unknown file %q", plugin, annotation.Path)
+		}
+		if imageFile.IsImport() {
+			continue
+		}
+		fileAnnotations = append(
+			fileAnnotations,
+			bufanalysis.NewFileAnnotation(
+				imageFile,
+				annotation.StartLine,
+				annotation.StartColumn,
+				annotation.EndLine,
+				annotation.EndColumn,
+				annotation.Type,
+				annotation.Message,
+			),
+		)
+	}
+	return fileAnnotations, nil
+}
+
+func (r *runner) run(ctx context.Context, plugin string, request *Request) (*Response, error) {
+	ctx, span := trace.StartSpan(ctx, "check_plugin")
+	span.AddAttributes(trace.StringAttribute("plugin", filepath.Base(plugin)))
+	defer span.End()
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	if err := r.runFunc(ctx, plugin, bytes.NewReader(requestData), stdout, stderr); err != nil {
+		if stderrString := strings.TrimSpace(stderr.String()); stderrString != "" {
+			return nil, fmt.Errorf("plugin %q failed: %v: %s", plugin, err, stderrString)
+		}
+		return nil, fmt.Errorf("plugin %q failed: %w", plugin, err)
+	}
+	response := &Response{}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return nil, fmt.Errorf("plugin %q returned an invalid response: %w", plugin, err)
+	}
+	return response, nil
+}
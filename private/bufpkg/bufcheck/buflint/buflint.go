@@ -19,9 +19,12 @@ package buflint
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintv1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintv1beta1"
@@ -43,23 +46,71 @@ type Handler interface {
 	//
 	// The image should have source code info for this to work properly.
 	//
-	// Images should be filtered with regards to imports before passing to this function.
+	// Files that are imports are not checked, but are passed to the plugins of the config.
 	Check(
 		ctx context.Context,
 		config *buflintconfig.Config,
 		image bufimage.Image,
+		options ...CheckOption,
 	) ([]bufanalysis.FileAnnotation, error)
+	// Baseline runs the lint checks and returns a Baseline containing all violations.
+	//
+	// Passing the Baseline to Check with CheckWithBaseline results in only new violations being reported.
+	Baseline(
+		ctx context.Context,
+		config *buflintconfig.Config,
+		image bufimage.Image,
+	) (*bufbaseline.Baseline, error)
+	// Rules returns the rules for the given config, including the rules
+	// provided by the plugins of the config.
+	//
+	// Should only be used for printing.
+	Rules(
+		ctx context.Context,
+		config *buflintconfig.Config,
+	) ([]bufcheck.Rule, error)
+}
+
+// CheckOption is an option for Check.
+type CheckOption func(*checkOptions)
+
+// CheckWithBaseline returns a new CheckOption that does not report the violations
+// contained in the Baseline.
+func CheckWithBaseline(baseline *bufbaseline.Baseline) CheckOption {
+	return func(checkOptions *checkOptions) {
+		checkOptions.baseline = baseline
+	}
 }
 
 // NewHandler returns a new Handler.
-func NewHandler(logger *zap.Logger) Handler {
-	return newHandler(logger)
+func NewHandler(logger *zap.Logger, options ...HandlerOption) Handler {
+	return newHandler(logger, options...)
+}
+
+// HandlerOption is an option for a new Handler.
+type HandlerOption func(*handler)
+
+// HandlerWithPluginRunner returns a new HandlerOption that uses the given
+// Runner to run the plugins of a config.
+//
+// The rules of every plugin are listed at most once per Handler.
+//
+// If not set, configs with plugins result in an error.
+func HandlerWithPluginRunner(pluginRunner bufcheckplugin.Runner) HandlerOption {
+	return func(handler *handler) {
+		handler.pluginRunner = newListRulesCachingPluginRunner(pluginRunner)
+	}
 }
 
 // RulesForConfig returns the rules for a given config.
 //
+// Rules provided by plugins are not supported, use Handler.Rules instead.
+//
 // Should only be used for printing.
 func RulesForConfig(config *buflintconfig.Config) ([]bufcheck.Rule, error) {
+	if len(config.Plugins) > 0 {
+		return nil, errors.New("lint plugins are not supported here")
+	}
 	internalConfig, err := internalConfigForConfig(config)
 	if err != nil {
 		return nil, err
@@ -114,24 +165,39 @@ func GetAllRulesAndCategoriesV1() []string {
 }
 
 func internalConfigForConfig(config *buflintconfig.Config) (*internal.Config, error) {
-	var versionSpec *internal.VersionSpec
+	return internalConfigForConfigAndVersionSpec(config, versionSpecForConfig(config))
+}
+
+func versionSpecForConfig(config *buflintconfig.Config) *internal.VersionSpec {
 	switch config.Version {
 	case bufconfig.V1Beta1Version:
-		versionSpec = buflintv1beta1.VersionSpec
+		return buflintv1beta1.VersionSpec
 	case bufconfig.V1Version:
-		versionSpec = buflintv1.VersionSpec
+		return buflintv1.VersionSpec
+	default:
+		return nil
 	}
+}
+
+func internalConfigForConfigAndVersionSpec(
+	config *buflintconfig.Config,
+	versionSpec *internal.VersionSpec,
+) (*internal.Config, error) {
 	return internal.ConfigBuilder{
 		Use:                                  config.Use,
 		Except:                               config.Except,
 		IgnoreRootPaths:                      config.IgnoreRootPaths,
 		IgnoreIDOrCategoryToRootPaths:        config.IgnoreIDOrCategoryToRootPaths,
+		IDOrCategoryToSeverity:               config.IDOrCategoryToSeverity,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
 		EnumZeroValueSuffix:                  config.EnumZeroValueSuffix,
 		RPCAllowSameRequestResponse:          config.RPCAllowSameRequestResponse,
 		RPCAllowGoogleProtobufEmptyRequests:  config.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
+		FieldNumberMax:                       config.FieldNumberMax,
+		FieldNumberMaxGap:                    config.FieldNumberMaxGap,
+		MessageMaxFields:                     config.MessageMaxFields,
 	}.NewConfig(
 		versionSpec,
 	)
@@ -147,3 +213,11 @@ func rulesForInternalRules(rules []*internal.Rule) []bufcheck.Rule {
 	}
 	return s
 }
+
+type checkOptions struct {
+	baseline *bufbaseline.Baseline
+}
+
+func newCheckOptions() *checkOptions {
+	return &checkOptions{}
+}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
@@ -296,6 +298,17 @@ func TestRunEnumZeroValueSuffixCustom(t *testing.T) {
 	)
 }
 
+func TestRunFieldDeprecatedComment(t *testing.T) {
+	testLint(
+		t,
+		"field_deprecated_comment",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 10, 3, 10, 38, "FIELD_DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 12, 3, 12, 38, "FIELD_DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 15, 5, 15, 39, "FIELD_DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 18, 5, 18, 41, "FIELD_DEPRECATED_COMMENT"),
+	)
+}
+
 func TestRunFieldLowerSnakeCase(t *testing.T) {
 	testLint(
 		t,
@@ -357,6 +370,68 @@ func TestRunFieldNoDescriptor(t *testing.T) {
 	)
 }
 
+func TestRunFieldNumberGap(t *testing.T) {
+	testLint(
+		t,
+		"field_number_gap",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 14, 1, 16, 2, "FIELD_NUMBER_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 18, 1, 26, 2, "FIELD_NUMBER_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 18, 1, 26, 2, "FIELD_NUMBER_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 23, 3, 25, 4, "FIELD_NUMBER_GAP"),
+	)
+}
+
+func TestRunFieldNumberGapCustom(t *testing.T) {
+	testLint(
+		t,
+		"field_number_gap_custom",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 12, 1, 15, 2, "FIELD_NUMBER_GAP"),
+	)
+}
+
+func TestRunFieldNumberGapZero(t *testing.T) {
+	testLintConfigModifier(
+		t,
+		"field_number_gap_custom",
+		func(config *bufconfig.Config) {
+			fieldNumberMaxGap := 0
+			config.Lint.FieldNumberMaxGap = &fieldNumberMaxGap
+		},
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 1, 10, 2, "FIELD_NUMBER_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 1, 10, 2, "FIELD_NUMBER_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 12, 1, 15, 2, "FIELD_NUMBER_GAP"),
+	)
+}
+
+func TestRunFieldNumberGapNegative(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config, image := testGetConfigAndImage(ctx, t, "field_number_gap_custom", nil)
+	fieldNumberMaxGap := -1
+	config.Lint.FieldNumberMaxGap = &fieldNumberMaxGap
+	_, err := buflint.NewHandler(zap.NewNop()).Check(ctx, config.Lint, image)
+	assert.Error(t, err)
+}
+
+func TestRunFieldNumberRange(t *testing.T) {
+	testLint(
+		t,
+		"field_number_range",
+	)
+}
+
+func TestRunFieldNumberRangeCustom(t *testing.T) {
+	testLint(
+		t,
+		"field_number_range_custom",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 17, 8, 21, "FIELD_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 16, 9, 21, "FIELD_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 11, 17, 11, 26, "FIELD_NUMBER_RANGE"),
+	)
+}
+
 func TestRunFileLowerSnakeCase(t *testing.T) {
 	testLint(
 		t,
@@ -369,6 +444,23 @@ func TestRunFileLowerSnakeCase(t *testing.T) {
 	)
 }
 
+func TestRunMessageFieldCount(t *testing.T) {
+	testLint(
+		t,
+		"message_field_count",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 108, 9, 108, 13, "MESSAGE_FIELD_COUNT"),
+	)
+}
+
+func TestRunMessageFieldCountCustom(t *testing.T) {
+	testLint(
+		t,
+		"message_field_count_custom",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 16, "MESSAGE_FIELD_COUNT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 11, 9, 15, "MESSAGE_FIELD_COUNT"),
+	)
+}
+
 func TestRunMessagePascalCase(t *testing.T) {
 	testLint(
 		t,
@@ -408,6 +500,15 @@ func TestRunOneofLowerSnakeCase(t *testing.T) {
 	)
 }
 
+func TestRunOneofNoSingleField(t *testing.T) {
+	testLint(
+		t,
+		"oneof_no_single_field",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 10, 3, 12, 4, "ONEOF_NO_SINGLE_FIELD"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 15, 5, 17, 6, "ONEOF_NO_SINGLE_FIELD"),
+	)
+}
+
 func TestRunPackageDefined(t *testing.T) {
 	testLint(
 		t,
@@ -887,6 +988,187 @@ func TestCommentIgnoresCascadeOn(t *testing.T) {
 	)
 }
 
+func TestRunBaseline(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config, image := testGetConfigAndImage(ctx, t, "field_lower_snake_case", nil)
+	handler := buflint.NewHandler(zap.NewNop())
+	fileAnnotations, err := handler.Check(ctx, config.Lint, image)
+	require.NoError(t, err)
+	baseline, err := handler.Baseline(ctx, config.Lint, image)
+	require.NoError(t, err)
+	entries := baseline.Entries()
+	require.Len(t, entries, len(fileAnnotations))
+	assert.Contains(
+		t,
+		entries,
+		bufbaseline.Entry{
+			ID:          "FIELD_LOWER_SNAKE_CASE",
+			Path:        "a.proto",
+			ElementPath: "a.Two.Three.Four.FailTwo",
+		},
+	)
+
+	baselineFileAnnotations, err := handler.Check(ctx, config.Lint, image, buflint.CheckWithBaseline(baseline))
+	require.NoError(t, err)
+	assert.Empty(t, baselineFileAnnotations)
+
+	baselineFileAnnotations, err = handler.Check(
+		ctx,
+		config.Lint,
+		image,
+		buflint.CheckWithBaseline(
+			bufbaseline.NewBaseline(
+				bufbaseline.Entry{
+					ID:          "FIELD_LOWER_SNAKE_CASE",
+					Path:        "a.proto",
+					ElementPath: "a.One.Fail",
+				},
+			),
+		),
+	)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		fileAnnotations[1:],
+		baselineFileAnnotations,
+	)
+}
+
+func TestRunSeverity(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config, image := testGetConfigAndImage(
+		ctx,
+		t,
+		"field_lower_snake_case",
+		func(config *bufconfig.Config) {
+			config.Lint.IDOrCategoryToSeverity = map[string]string{
+				"BASIC":                  "info",
+				"FIELD_LOWER_SNAKE_CASE": "warning",
+			}
+		},
+	)
+	handler := buflint.NewHandler(zap.NewNop())
+	fileAnnotations, err := handler.Check(ctx, config.Lint, image)
+	require.NoError(t, err)
+	require.NotEmpty(t, fileAnnotations)
+	for _, fileAnnotation := range fileAnnotations {
+		assert.Equal(t, "FIELD_LOWER_SNAKE_CASE", fileAnnotation.Type())
+		assert.Equal(t, bufanalysis.SeverityWarning, fileAnnotation.Severity())
+	}
+	assert.False(t, bufanalysis.HasErrors(fileAnnotations))
+
+	config.Lint.IDOrCategoryToSeverity = map[string]string{
+		"NOT_A_RULE": "warning",
+	}
+	_, err = handler.Check(ctx, config.Lint, image)
+	assert.Error(t, err)
+
+	config.Lint.IDOrCategoryToSeverity = map[string]string{
+		"FIELD_LOWER_SNAKE_CASE": "critical",
+	}
+	_, err = handler.Check(ctx, config.Lint, image)
+	assert.Error(t, err)
+}
+
+func TestRunPlugin(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config, image := testGetConfigAndImage(ctx, t, "plugin", nil)
+	// The plugin gets the imports, which are not checked.
+	image = testImageWithImports(t, image, "b/b.proto")
+	pluginRunner := &testPluginRunner{t: t}
+	handler := buflint.NewHandler(
+		zap.NewNop(),
+		buflint.HandlerWithPluginRunner(pluginRunner),
+	)
+	rules, err := handler.Rules(ctx, config.Lint)
+	require.NoError(t, err)
+	ruleIDs := make([]string, len(rules))
+	for i, rule := range rules {
+		ruleIDs[i] = rule.ID()
+	}
+	assert.Equal(t, []string{"PACKAGE_DEFINED", "ACME_ONE"}, ruleIDs)
+	assert.Equal(t, "Checks that all messages have the suffix.", rules[1].Purpose())
+
+	fileAnnotations, err := handler.Check(ctx, config.Lint, image)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 1, 5, 15, "ACME_ONE"),
+		},
+		fileAnnotations,
+	)
+	_, err = handler.Baseline(ctx, config.Lint, image)
+	require.NoError(t, err)
+	// The rules of the plugin are only listed once per handler.
+	assert.Equal(t, 1, pluginRunner.listRulesCount)
+	assert.Equal(t, []string{"b/b.proto"}, pluginRunner.importPaths)
+
+	_, err = buflint.NewHandler(zap.NewNop()).Check(ctx, config.Lint, image)
+	assert.Error(t, err)
+}
+
+type testPluginRunner struct {
+	t              *testing.T
+	listRulesCount int
+	importPaths    []string
+}
+
+func (r *testPluginRunner) ListRules(
+	_ context.Context,
+	plugin string,
+	_ map[string]string,
+) ([]*bufcheckplugin.Rule, error) {
+	assert.Equal(r.t, "buf-check-acme", plugin)
+	r.listRulesCount++
+	return []*bufcheckplugin.Rule{
+		{
+			ID:         "ACME_ONE",
+			Categories: []string{"ACME"},
+			Purpose:    "all messages have the suffix",
+		},
+		{
+			ID:         "ACME_TWO",
+			Categories: []string{"ACME"},
+			Purpose:    "nothing",
+		},
+	}, nil
+}
+
+func (r *testPluginRunner) Check(
+	_ context.Context,
+	plugin string,
+	options map[string]string,
+	image bufimage.Image,
+	ruleIDs []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	assert.Equal(r.t, "buf-check-acme", plugin)
+	assert.Equal(r.t, map[string]string{"suffix": "Acme"}, options)
+	assert.Equal(r.t, []string{"ACME_ONE"}, ruleIDs)
+	r.importPaths = nil
+	var fileAnnotations []bufanalysis.FileAnnotation
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			r.importPaths = append(r.importPaths, imageFile.Path())
+			continue
+		}
+		fileAnnotations = append(
+			fileAnnotations,
+			bufanalysis.NewFileAnnotation(imageFile, 5, 1, 5, 15, "ACME_ONE", "Message must end in Acme."),
+		)
+	}
+	return fileAnnotations, nil
+}
+
 func testLint(
 	t *testing.T,
 	relDirPath string,
@@ -911,6 +1193,27 @@ func testLintConfigModifier(
 	defer cancel()
 	logger := zap.NewNop()
 
+	config, image := testGetConfigAndImage(ctx, t, relDirPath, configModifier)
+	handler := buflint.NewHandler(logger)
+	fileAnnotations, err := handler.Check(
+		ctx,
+		config.Lint,
+		image,
+	)
+	assert.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
+
+func testGetConfigAndImage(
+	ctx context.Context,
+	t *testing.T,
+	relDirPath string,
+	configModifier func(*bufconfig.Config),
+) (*bufconfig.Config, bufimage.Image) {
 	dirPath := filepath.Join("testdata", relDirPath)
 
 	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
@@ -946,19 +1249,33 @@ func testLintConfigModifier(
 	require.NoError(t, err)
 	require.Empty(t, fileAnnotations)
 	image = bufimage.ImageWithoutImports(image)
+	return config, image
+}
 
-	handler := buflint.NewHandler(logger)
-	fileAnnotations, err = handler.Check(
-		ctx,
-		config.Lint,
-		image,
-	)
-	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+// testImageWithImports returns the Image with the files at the given paths marked as imports.
+func testImageWithImports(t *testing.T, image bufimage.Image, importPaths ...string) bufimage.Image {
+	importPathMap := make(map[string]struct{}, len(importPaths))
+	for _, importPath := range importPaths {
+		importPathMap[importPath] = struct{}{}
+	}
+	imageFiles := make([]bufimage.ImageFile, 0, len(image.Files()))
+	for _, imageFile := range image.Files() {
+		_, isImport := importPathMap[imageFile.Path()]
+		newImageFile, err := bufimage.NewImageFile(
+			imageFile.Proto(),
+			imageFile.ModuleIdentity(),
+			imageFile.Commit(),
+			imageFile.ExternalPath(),
+			isImport,
+			imageFile.IsSyntaxUnspecified(),
+			imageFile.UnusedDependencyIndexes(),
+		)
+		require.NoError(t, err)
+		imageFiles = append(imageFiles, newImageFile)
+	}
+	newImage, err := bufimage.NewImage(imageFiles)
+	require.NoError(t, err)
+	return newImage
 }
 
 func testGetConfig(
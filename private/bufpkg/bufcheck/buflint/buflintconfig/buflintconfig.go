@@ -44,6 +44,11 @@ type Config struct {
 	// IgnoreIDOrCategoryToRootPaths is a map of rule and/or category IDs to directory and/or file paths to exclude from the
 	// lint check.
 	IgnoreIDOrCategoryToRootPaths map[string][]string
+	// IDOrCategoryToSeverity is a map of rule and/or category IDs to the severity of their violations,
+	// one of "error", "warning", or "info". Violations of rules that are not in the map are errors.
+	//
+	// Only errors result in a non-zero exit code.
+	IDOrCategoryToSeverity map[string]string
 	// EnumZeroValueSuffix controls the behavior of the ENUM_ZERO_VALUE lint rule ID. By default, this rule
 	// verifies that the zero value of all enums ends in _UNSPECIFIED. This config allows the user to override
 	// this value with the given string.
@@ -57,8 +62,24 @@ type Config struct {
 	// ServiceSuffix applies to the SERVICE_SUFFIX rule ID. By default, the rule verifies that all service names
 	// end with the suffix Service. This allows users to override the value with the given string.
 	ServiceSuffix string
+	// FieldNumberMax applies to the FIELD_NUMBER_RANGE rule ID. By default, the rule verifies that all field
+	// numbers are valid and not in the reserved range 19000 to 19999. This allows users to set a lower maximum.
+	FieldNumberMax int
+	// FieldNumberMaxGap applies to the FIELD_NUMBER_GAP rule ID. By default, the rule verifies that messages do
+	// not skip more than 100 field numbers. This allows users to override the value, including with 0.
+	//
+	// Nil if not set.
+	FieldNumberMaxGap *int
+	// MessageMaxFields applies to the MESSAGE_FIELD_COUNT rule ID. By default, the rule verifies that messages
+	// have at most 100 fields. This allows users to override the value.
+	MessageMaxFields int
 	// AllowCommentIgnores turns on comment-driven ignores.
 	AllowCommentIgnores bool
+	// Plugins are the check plugins that provide additional lint rules.
+	//
+	// The rules of the plugins are configured with Use, Except, and IgnoreIDOrCategoryToRootPaths
+	// like the rules built into buf.
+	Plugins []*PluginConfig
 	// Version represents the version of the lint rule and category IDs that should be used with this config.
 	Version string
 }
@@ -87,16 +108,29 @@ func NewConfigV1(externalConfig ExternalConfigV1) *Config {
 		Except:                               externalConfig.Except,
 		IgnoreRootPaths:                      externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths:        externalConfig.IgnoreOnly,
+		IDOrCategoryToSeverity:               externalConfig.Severity,
 		EnumZeroValueSuffix:                  externalConfig.EnumZeroValueSuffix,
 		RPCAllowSameRequestResponse:          externalConfig.RPCAllowSameRequestResponse,
 		RPCAllowGoogleProtobufEmptyRequests:  externalConfig.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
+		FieldNumberMax:                       externalConfig.FieldNumberMax,
+		FieldNumberMaxGap:                    externalConfig.FieldNumberMaxGap,
+		MessageMaxFields:                     externalConfig.MessageMaxFields,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		Plugins:                              pluginConfigsForExternalPluginConfigsV1(externalConfig.Plugins),
 		Version:                              v1Version,
 	}
 }
 
+// PluginConfig is the configuration for a check plugin.
+type PluginConfig struct {
+	// Plugin is the name of the plugin binary on the PATH, or the path to the plugin binary.
+	Plugin string
+	// Options are the options passed to the plugin.
+	Options map[string]string
+}
+
 // ConfigForProto returns the Config given the proto.
 func ConfigForProto(protoConfig *lintv1.Config) *Config {
 	return &Config{
@@ -161,6 +195,18 @@ type ExternalConfigV1 struct {
 	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
 	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
 	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	// IDOrCategoryToSeverity
+	Severity          map[string]string        `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Plugins           []ExternalPluginConfigV1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	FieldNumberMax    int                      `json:"field_number_max,omitempty" yaml:"field_number_max,omitempty"`
+	FieldNumberMaxGap *int                     `json:"field_number_max_gap,omitempty" yaml:"field_number_max_gap,omitempty"`
+	MessageMaxFields  int                      `json:"message_max_fields,omitempty" yaml:"message_max_fields,omitempty"`
+}
+
+// ExternalPluginConfigV1 is an external check plugin config.
+type ExternalPluginConfigV1 struct {
+	Plugin  string            `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 // ExternalConfigV1Beta1ForConfig takes a *Config and returns the v1beta1 externalconfig representation.
@@ -186,12 +232,17 @@ func ExternalConfigV1ForConfig(config *Config) ExternalConfigV1 {
 		Except:                               config.Except,
 		Ignore:                               config.IgnoreRootPaths,
 		IgnoreOnly:                           config.IgnoreIDOrCategoryToRootPaths,
+		Severity:                             config.IDOrCategoryToSeverity,
 		EnumZeroValueSuffix:                  config.EnumZeroValueSuffix,
 		RPCAllowSameRequestResponse:          config.RPCAllowSameRequestResponse,
 		RPCAllowGoogleProtobufEmptyRequests:  config.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Plugins:                              externalPluginConfigsV1ForPluginConfigs(config.Plugins),
+		FieldNumberMax:                       config.FieldNumberMax,
+		FieldNumberMaxGap:                    config.FieldNumberMaxGap,
+		MessageMaxFields:                     config.MessageMaxFields,
 	}
 }
 
@@ -206,17 +257,27 @@ func BytesForConfig(config *Config) ([]byte, error) {
 }
 
 type configJSON struct {
-	Use                                  []string      `json:"use,omitempty"`
-	Except                               []string      `json:"except,omitempty"`
-	IgnoreRootPaths                      []string      `json:"ignore_root_paths,omitempty"`
-	IgnoreIDOrCategoryToRootPaths        []idPathsJSON `json:"ignore_id_to_root_paths,omitempty"`
-	EnumZeroValueSuffix                  string        `json:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool          `json:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool          `json:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool          `json:"rpc_allow_google_protobuf_empty_response,omitempty"`
-	ServiceSuffix                        string        `json:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool          `json:"allow_comment_ignores,omitempty"`
-	Version                              string        `json:"version,omitempty"`
+	Use                                  []string          `json:"use,omitempty"`
+	Except                               []string          `json:"except,omitempty"`
+	IgnoreRootPaths                      []string          `json:"ignore_root_paths,omitempty"`
+	IgnoreIDOrCategoryToRootPaths        []idPathsJSON     `json:"ignore_id_to_root_paths,omitempty"`
+	IDOrCategoryToSeverity               map[string]string `json:"id_to_severity,omitempty"`
+	EnumZeroValueSuffix                  string            `json:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool              `json:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool              `json:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool              `json:"rpc_allow_google_protobuf_empty_response,omitempty"`
+	ServiceSuffix                        string            `json:"service_suffix,omitempty"`
+	FieldNumberMax                       int               `json:"field_number_max,omitempty"`
+	FieldNumberMaxGap                    *int              `json:"field_number_max_gap,omitempty"`
+	MessageMaxFields                     int               `json:"message_max_fields,omitempty"`
+	AllowCommentIgnores                  bool              `json:"allow_comment_ignores,omitempty"`
+	Plugins                              []pluginJSON      `json:"plugins,omitempty"`
+	Version                              string            `json:"version,omitempty"`
+}
+
+type pluginJSON struct {
+	Plugin  string            `json:"plugin,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
 }
 
 type idPathsJSON struct {
@@ -247,21 +308,62 @@ func configToJSON(config *Config) *configJSON {
 	sort.Strings(use)
 	sort.Strings(except)
 	sort.Strings(ignoreRootPaths)
+	// The plugins are kept in the order they were configured in.
+	var pluginsJSON []pluginJSON
+	for _, pluginConfig := range config.Plugins {
+		pluginsJSON = append(pluginsJSON, pluginJSON{
+			Plugin:  pluginConfig.Plugin,
+			Options: pluginConfig.Options,
+		})
+	}
 	return &configJSON{
 		Use:                                  use,
 		Except:                               except,
 		IgnoreRootPaths:                      ignoreRootPaths,
 		IgnoreIDOrCategoryToRootPaths:        ignoreIDPathsJSON,
+		IDOrCategoryToSeverity:               config.IDOrCategoryToSeverity,
 		EnumZeroValueSuffix:                  config.EnumZeroValueSuffix,
 		RPCAllowSameRequestResponse:          config.RPCAllowSameRequestResponse,
 		RPCAllowGoogleProtobufEmptyRequests:  config.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
+		FieldNumberMax:                       config.FieldNumberMax,
+		FieldNumberMaxGap:                    config.FieldNumberMaxGap,
+		MessageMaxFields:                     config.MessageMaxFields,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Plugins:                              pluginsJSON,
 		Version:                              config.Version,
 	}
 }
 
+func pluginConfigsForExternalPluginConfigsV1(externalPluginConfigs []ExternalPluginConfigV1) []*PluginConfig {
+	if len(externalPluginConfigs) == 0 {
+		return nil
+	}
+	pluginConfigs := make([]*PluginConfig, len(externalPluginConfigs))
+	for i, externalPluginConfig := range externalPluginConfigs {
+		pluginConfigs[i] = &PluginConfig{
+			Plugin:  externalPluginConfig.Plugin,
+			Options: externalPluginConfig.Options,
+		}
+	}
+	return pluginConfigs
+}
+
+func externalPluginConfigsV1ForPluginConfigs(pluginConfigs []*PluginConfig) []ExternalPluginConfigV1 {
+	if len(pluginConfigs) == 0 {
+		return nil
+	}
+	externalPluginConfigs := make([]ExternalPluginConfigV1, len(pluginConfigs))
+	for i, pluginConfig := range pluginConfigs {
+		externalPluginConfigs[i] = ExternalPluginConfigV1{
+			Plugin:  pluginConfig.Plugin,
+			Options: pluginConfig.Options,
+		}
+	}
+	return externalPluginConfigs
+}
+
 // PrintFileAnnotations prints the FileAnnotations to the Writer.
 //
 // Also accepts config-ignore-yaml.
@@ -269,12 +371,13 @@ func PrintFileAnnotations(
 	writer io.Writer,
 	fileAnnotations []bufanalysis.FileAnnotation,
 	formatString string,
+	options ...bufanalysis.PrintFileAnnotationsOption,
 ) error {
 	switch s := strings.ToLower(strings.TrimSpace(formatString)); s {
 	case "config-ignore-yaml":
 		return printFileAnnotationsConfigIgnoreYAML(writer, fileAnnotations)
 	default:
-		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s)
+		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s, options...)
 	}
 }
 
@@ -16,8 +16,12 @@ package buflint
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
@@ -28,12 +32,13 @@ import (
 )
 
 type handler struct {
-	logger *zap.Logger
-	runner *internal.Runner
+	logger       *zap.Logger
+	runner       *internal.Runner
+	pluginRunner bufcheckplugin.Runner
 }
 
-func newHandler(logger *zap.Logger) *handler {
-	return &handler{
+func newHandler(logger *zap.Logger, options ...HandlerOption) *handler {
+	handler := &handler{
 		logger: logger,
 		// linting allows for comment ignores
 		// note that comment ignores still need to be enabled within the config
@@ -44,20 +49,95 @@ func newHandler(logger *zap.Logger) *handler {
 			internal.RunnerWithIgnorePrefix(buflintcheck.CommentIgnorePrefix),
 		),
 	}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
 }
 
 func (h *handler) Check(
 	ctx context.Context,
 	config *buflintconfig.Config,
 	image bufimage.Image,
+	options ...CheckOption,
 ) ([]bufanalysis.FileAnnotation, error) {
-	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	checkOptions := newCheckOptions()
+	for _, option := range options {
+		option(checkOptions)
+	}
+	internalConfig, files, err := h.getInternalConfigAndFiles(ctx, config, image)
 	if err != nil {
 		return nil, err
 	}
-	internalConfig, err := internalConfigForConfig(config)
+	internalConfig.Baseline = checkOptions.baseline
+	return h.runner.Check(ctx, internalConfig, nil, files)
+}
+
+func (h *handler) Baseline(
+	ctx context.Context,
+	config *buflintconfig.Config,
+	image bufimage.Image,
+) (*bufbaseline.Baseline, error) {
+	internalConfig, files, err := h.getInternalConfigAndFiles(ctx, config, image)
 	if err != nil {
 		return nil, err
 	}
-	return h.runner.Check(ctx, internalConfig, nil, files)
+	return h.runner.Baseline(ctx, internalConfig, nil, files)
+}
+
+func (h *handler) Rules(
+	ctx context.Context,
+	config *buflintconfig.Config,
+) ([]bufcheck.Rule, error) {
+	internalConfig, err := h.getInternalConfig(ctx, config, nil)
+	if err != nil {
+		return nil, err
+	}
+	return rulesForInternalRules(internalConfig.Rules), nil
+}
+
+func (h *handler) getInternalConfigAndFiles(
+	ctx context.Context,
+	config *buflintconfig.Config,
+	image bufimage.Image,
+) (*internal.Config, []protosource.File, error) {
+	// Imports are not checked, but the plugins get the full Image so that they
+	// can resolve the types and options defined in the imports.
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(bufimage.ImageWithoutImports(image).Files())...)
+	if err != nil {
+		return nil, nil, err
+	}
+	internalConfig, err := h.getInternalConfig(ctx, config, image)
+	if err != nil {
+		return nil, nil, err
+	}
+	return internalConfig, files, nil
+}
+
+// getInternalConfig gets the internal.Config for the config, including the rules of its plugins.
+//
+// The image is the Image the plugins check, and may be nil if the rules are not checked.
+func (h *handler) getInternalConfig(
+	ctx context.Context,
+	config *buflintconfig.Config,
+	image bufimage.Image,
+) (*internal.Config, error) {
+	if len(config.Plugins) == 0 {
+		return internalConfigForConfig(config)
+	}
+	if h.pluginRunner == nil {
+		return nil, errors.New("lint plugins are not supported here")
+	}
+	versionSpec, pluginCheckers, err := versionSpecWithPlugins(ctx, h.pluginRunner, versionSpecForConfig(config), config.Plugins, image)
+	if err != nil {
+		return nil, err
+	}
+	internalConfig, err := internalConfigForConfigAndVersionSpec(config, versionSpec)
+	if err != nil {
+		return nil, err
+	}
+	for _, pluginChecker := range pluginCheckers {
+		pluginChecker.setRules(internalConfig.Rules)
+	}
+	return internalConfig, nil
 }
@@ -17,6 +17,7 @@ package buflintbuild
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintcheck"
@@ -121,6 +122,12 @@ var (
 			}), nil
 		},
 	)
+	// FieldDeprecatedCommentRuleBuilder is a rule builder.
+	FieldDeprecatedCommentRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_DEPRECATED_COMMENT",
+		"deprecated fields have non-empty comments saying what replaces them",
+		newAdapter(buflintcheck.CheckFieldDeprecatedComment),
+	)
 	// FieldLowerSnakeCaseRuleBuilder is a rule builder.
 	FieldLowerSnakeCaseRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_LOWER_SNAKE_CASE",
@@ -133,6 +140,36 @@ var (
 		`field names are not name capitalization of "descriptor" with any number of prefix or suffix underscores`,
 		newAdapter(buflintcheck.CheckFieldNoDescriptor),
 	)
+	// FieldNumberGapRuleBuilder is a rule builder.
+	FieldNumberGapRuleBuilder = internal.NewRuleBuilder(
+		"FIELD_NUMBER_GAP",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			return fmt.Sprintf("messages do not have gaps of more than %d unused field numbers (gap is configurable)", *configBuilder.FieldNumberMaxGap), nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckFieldNumberGap(id, ignoreFunc, files, *configBuilder.FieldNumberMaxGap)
+			}), nil
+		},
+	)
+	// FieldNumberRangeRuleBuilder is a rule builder.
+	FieldNumberRangeRuleBuilder = internal.NewRuleBuilder(
+		"FIELD_NUMBER_RANGE",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			if configBuilder.FieldNumberMax <= 0 {
+				return "", errors.New("field_number_max is not positive")
+			}
+			return fmt.Sprintf("field numbers are not in the reserved range 19000 to 19999 and are at most %d (maximum is configurable)", configBuilder.FieldNumberMax), nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			if configBuilder.FieldNumberMax <= 0 {
+				return nil, errors.New("field_number_max is not positive")
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckFieldNumberRange(id, ignoreFunc, files, configBuilder.FieldNumberMax)
+			}), nil
+		},
+	)
 	// FileLowerSnakeCaseRuleBuilder is a rule builder.
 	FileLowerSnakeCaseRuleBuilder = internal.NewNopRuleBuilder(
 		"FILE_LOWER_SNAKE_CASE",
@@ -157,6 +194,24 @@ var (
 		"imports are used",
 		newAdapter(buflintcheck.CheckImportUsed),
 	)
+	// MessageFieldCountRuleBuilder is a rule builder.
+	MessageFieldCountRuleBuilder = internal.NewRuleBuilder(
+		"MESSAGE_FIELD_COUNT",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			if configBuilder.MessageMaxFields <= 0 {
+				return "", errors.New("message_max_fields is not positive")
+			}
+			return fmt.Sprintf("messages have at most %d fields (maximum is configurable)", configBuilder.MessageMaxFields), nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			if configBuilder.MessageMaxFields <= 0 {
+				return nil, errors.New("message_max_fields is not positive")
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckMessageFieldCount(id, ignoreFunc, files, configBuilder.MessageMaxFields)
+			}), nil
+		},
+	)
 	// MessagePascalCaseRuleBuilder is a rule builder.
 	MessagePascalCaseRuleBuilder = internal.NewNopRuleBuilder(
 		"MESSAGE_PASCAL_CASE",
@@ -169,6 +224,12 @@ var (
 		"oneof names are lower_snake_case",
 		newAdapter(buflintcheck.CheckOneofLowerSnakeCase),
 	)
+	// OneofNoSingleFieldRuleBuilder is a rule builder.
+	OneofNoSingleFieldRuleBuilder = internal.NewNopRuleBuilder(
+		"ONEOF_NO_SINGLE_FIELD",
+		"oneofs have more than one field",
+		newAdapter(buflintcheck.CheckOneofNoSingleField),
+	)
 	// PackageDefinedRuleBuilder is a rule builder.
 	PackageDefinedRuleBuilder = internal.NewNopRuleBuilder(
 		"PACKAGE_DEFINED",
@@ -20,6 +20,7 @@ package buflintcheck
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -38,6 +39,13 @@ const (
 	// This is also used in buflint when constructing a new Runner, and is passed to the
 	// RunnerWithIgnorePrefix option.
 	CommentIgnorePrefix = "buf:lint:ignore"
+
+	// reservedFieldNumberStart is the start of the field numbers reserved for the
+	// Protocol Buffers implementation.
+	reservedFieldNumberStart = 19000
+	// reservedFieldNumberEnd is the inclusive end of the field numbers reserved for the
+	// Protocol Buffers implementation.
+	reservedFieldNumberEnd = 19999
 )
 
 var (
@@ -262,6 +270,29 @@ func checkEnumZeroValueSuffix(add addFunc, enumValue protosource.EnumValue, suff
 	return nil
 }
 
+// CheckFieldDeprecatedComment is a check function.
+var CheckFieldDeprecatedComment = newFieldCheckFunc(checkFieldDeprecatedComment)
+
+func checkFieldDeprecatedComment(add addFunc, field protosource.Field) error {
+	if !field.Deprecated() {
+		return nil
+	}
+	location := field.Location()
+	if location == nil {
+		return nil
+	}
+	if !validLeadingComment(location.LeadingComments()) && !validLeadingComment(location.TrailingComments()) {
+		add(
+			field,
+			location,
+			nil,
+			"Field %q is deprecated and should have a non-empty comment saying what replaces it.",
+			field.Name(),
+		)
+	}
+	return nil
+}
+
 // CheckFieldLowerSnakeCase is a check function.
 var CheckFieldLowerSnakeCase = newFieldCheckFunc(checkFieldLowerSnakeCase)
 
@@ -315,6 +346,108 @@ func checkFieldNoDescriptor(add addFunc, field protosource.Field) error {
 	return nil
 }
 
+// CheckFieldNumberGap is a check function.
+var CheckFieldNumberGap = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	maxGap int,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newMessageCheckFunc(
+		func(add addFunc, message protosource.Message) error {
+			return checkFieldNumberGap(add, message, maxGap)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkFieldNumberGap(add addFunc, message protosource.Message, maxGap int) error {
+	if message.IsMapEntry() {
+		return nil
+	}
+	// the numbers of fields as well as reserved and extension ranges are in use,
+	// so that removing a field and reserving its number does not result in a gap
+	var tagRanges []tagRange
+	for _, field := range message.Fields() {
+		tagRanges = append(tagRanges, tagRange{start: field.Number(), end: field.Number()})
+	}
+	for _, reservedTagRange := range message.ReservedTagRanges() {
+		tagRanges = append(tagRanges, tagRange{start: reservedTagRange.Start(), end: reservedTagRange.End()})
+	}
+	for _, extensionRange := range message.ExtensionMessageRanges() {
+		tagRanges = append(tagRanges, tagRange{start: extensionRange.Start(), end: extensionRange.End()})
+	}
+	sort.Slice(
+		tagRanges,
+		func(i int, j int) bool {
+			return tagRanges[i].start < tagRanges[j].start
+		},
+	)
+	// field numbers start at 1
+	previousEnd := 0
+	for _, tagRange := range tagRanges {
+		if gap := tagRange.start - previousEnd - 1; gap > maxGap {
+			add(
+				message,
+				message.Location(),
+				nil,
+				"Message %q does not use the %d field numbers from %d to %d, which is more than the maximum gap of %d.",
+				message.Name(),
+				gap,
+				previousEnd+1,
+				tagRange.start-1,
+				maxGap,
+			)
+		}
+		if tagRange.end > previousEnd {
+			previousEnd = tagRange.end
+		}
+	}
+	return nil
+}
+
+// CheckFieldNumberRange is a check function.
+var CheckFieldNumberRange = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	maxNumber int,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFieldCheckFunc(
+		func(add addFunc, field protosource.Field) error {
+			return checkFieldNumberRange(add, field, maxNumber)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkFieldNumberRange(add addFunc, field protosource.Field, maxNumber int) error {
+	number := field.Number()
+	if number >= reservedFieldNumberStart && number <= reservedFieldNumberEnd {
+		add(
+			field,
+			field.NumberLocation(),
+			nil,
+			"Field %q has number %d, which is in the range %d to %d reserved for the Protocol Buffers implementation.",
+			field.Name(),
+			number,
+			reservedFieldNumberStart,
+			reservedFieldNumberEnd,
+		)
+		return nil
+	}
+	if number > maxNumber {
+		add(
+			field,
+			field.NumberLocation(),
+			nil,
+			"Field %q has number %d, which is greater than the maximum of %d.",
+			field.Name(),
+			number,
+			maxNumber,
+		)
+	}
+	return nil
+}
+
 // CheckFileLowerSnakeCase is a check function.
 var CheckFileLowerSnakeCase = newFileCheckFunc(checkFileLowerSnakeCase)
 
@@ -361,6 +494,35 @@ func checkImportUsed(add addFunc, fileImport protosource.FileImport) error {
 	return nil
 }
 
+// CheckMessageFieldCount is a check function.
+var CheckMessageFieldCount = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	maxFields int,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newMessageCheckFunc(
+		func(add addFunc, message protosource.Message) error {
+			return checkMessageFieldCount(add, message, maxFields)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkMessageFieldCount(add addFunc, message protosource.Message, maxFields int) error {
+	if numFields := len(message.Fields()); numFields > maxFields {
+		add(
+			message,
+			message.NameLocation(),
+			nil,
+			"Message %q has %d fields, which is more than the maximum of %d.",
+			message.Name(),
+			numFields,
+			maxFields,
+		)
+	}
+	return nil
+}
+
 // CheckMessagePascalCase is a check function.
 var CheckMessagePascalCase = newMessageCheckFunc(checkMessagePascalCase)
 
@@ -407,6 +569,34 @@ func checkOneofLowerSnakeCase(add addFunc, oneof protosource.Oneof) error {
 	return nil
 }
 
+// CheckOneofNoSingleField is a check function.
+var CheckOneofNoSingleField = newOneofCheckFunc(checkOneofNoSingleField)
+
+func checkOneofNoSingleField(add addFunc, oneof protosource.Oneof) error {
+	fields := oneof.Fields()
+	if len(fields) != 1 {
+		return nil
+	}
+	// proto3 optional fields are implemented with a synthetic oneof
+	// containing only the field itself
+	if fields[0].Proto3Optional() {
+		return nil
+	}
+	add(
+		oneof,
+		oneof.Location(),
+		// also check the message for this comment ignore
+		// this allows users to set this "globally" for a message
+		[]protosource.Location{
+			oneof.Message().Location(),
+		},
+		"Oneof %q has a single field %q, consider using a field instead.",
+		oneof.Name(),
+		fields[0].Name(),
+	)
+	return nil
+}
+
 // CheckPackageDefined is a check function.
 var CheckPackageDefined = newFileCheckFunc(checkPackageDefined)
 
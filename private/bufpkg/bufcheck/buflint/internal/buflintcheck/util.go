@@ -28,6 +28,12 @@ import (
 // Both the Descriptor and Locations can be nil.
 type addFunc func(protosource.Descriptor, protosource.Location, []protosource.Location, string, ...interface{})
 
+// tagRange is a range of field numbers from start to end, inclusive.
+type tagRange struct {
+	start int
+	end   int
+}
+
 func fieldToLowerSnakeCase(s string) string {
 	// Try running this on googleapis and watch
 	// We allow both effectively by not passing the option
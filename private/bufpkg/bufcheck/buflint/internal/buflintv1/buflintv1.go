@@ -50,6 +50,10 @@ import "github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 //   - COMMENTS
 //   - UNARY_RPC
 //
+// The API_SURFACE category was added later, and contains the FIELD_DEPRECATED_COMMENT,
+// FIELD_NUMBER_GAP, FIELD_NUMBER_RANGE, MESSAGE_FIELD_COUNT, and ONEOF_NO_SINGLE_FIELD
+// rules. These rules are not in DEFAULT.
+//
 // The rules included in the MINIMAL lint category have also been adjusted.
 // The difference is shown below:
 //
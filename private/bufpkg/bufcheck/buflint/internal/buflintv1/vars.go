@@ -36,13 +36,18 @@ var (
 		buflintbuild.EnumValuePrefixRuleBuilder,
 		buflintbuild.EnumValueUpperSnakeCaseRuleBuilder,
 		buflintbuild.EnumZeroValueSuffixRuleBuilder,
+		buflintbuild.FieldDeprecatedCommentRuleBuilder,
 		buflintbuild.FieldLowerSnakeCaseRuleBuilder,
+		buflintbuild.FieldNumberGapRuleBuilder,
+		buflintbuild.FieldNumberRangeRuleBuilder,
 		buflintbuild.FileLowerSnakeCaseRuleBuilder,
 		buflintbuild.ImportNoPublicRuleBuilder,
 		buflintbuild.ImportNoWeakRuleBuilder,
 		buflintbuild.ImportUsedRuleBuilder,
+		buflintbuild.MessageFieldCountRuleBuilder,
 		buflintbuild.MessagePascalCaseRuleBuilder,
 		buflintbuild.OneofLowerSnakeCaseRuleBuilder,
+		buflintbuild.OneofNoSingleFieldRuleBuilder,
 		buflintbuild.PackageDefinedRuleBuilder,
 		buflintbuild.PackageDirectoryMatchRuleBuilder,
 		buflintbuild.PackageLowerSnakeCaseRuleBuilder,
@@ -121,10 +126,19 @@ var (
 		"ENUM_ZERO_VALUE_SUFFIX": {
 			"DEFAULT",
 		},
+		"FIELD_DEPRECATED_COMMENT": {
+			"API_SURFACE",
+		},
 		"FIELD_LOWER_SNAKE_CASE": {
 			"BASIC",
 			"DEFAULT",
 		},
+		"FIELD_NUMBER_GAP": {
+			"API_SURFACE",
+		},
+		"FIELD_NUMBER_RANGE": {
+			"API_SURFACE",
+		},
 		"FILE_LOWER_SNAKE_CASE": {
 			"DEFAULT",
 		},
@@ -140,6 +154,9 @@ var (
 			"BASIC",
 			"DEFAULT",
 		},
+		"MESSAGE_FIELD_COUNT": {
+			"API_SURFACE",
+		},
 		"MESSAGE_PASCAL_CASE": {
 			"BASIC",
 			"DEFAULT",
@@ -148,6 +165,9 @@ var (
 			"BASIC",
 			"DEFAULT",
 		},
+		"ONEOF_NO_SINGLE_FIELD": {
+			"API_SURFACE",
+		},
 		"PACKAGE_DEFINED": {
 			"MINIMAL",
 			"BASIC",
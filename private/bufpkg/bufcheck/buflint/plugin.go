@@ -0,0 +1,218 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+// versionSpecWithPlugins returns a copy of the VersionSpec that also contains the rules
+// provided by the plugins, along with a pluginChecker for every plugin.
+//
+// The image may be nil, in which case the rules of the plugins cannot be checked.
+func versionSpecWithPlugins(
+	ctx context.Context,
+	pluginRunner bufcheckplugin.Runner,
+	versionSpec *internal.VersionSpec,
+	pluginConfigs []*buflintconfig.PluginConfig,
+	image bufimage.Image,
+) (*internal.VersionSpec, []*pluginChecker, error) {
+	ruleBuilders := make([]*internal.RuleBuilder, len(versionSpec.RuleBuilders))
+	copy(ruleBuilders, versionSpec.RuleBuilders)
+	idToCategories := make(map[string][]string, len(versionSpec.IDToCategories))
+	for id, categories := range versionSpec.IDToCategories {
+		idToCategories[id] = categories
+	}
+	pluginCheckers := make([]*pluginChecker, 0, len(pluginConfigs))
+	for _, pluginConfig := range pluginConfigs {
+		if pluginConfig.Plugin == "" {
+			return nil, nil, errors.New("lint plugin must be set")
+		}
+		rules, err := pluginRunner.ListRules(ctx, pluginConfig.Plugin, pluginConfig.Options)
+		if err != nil {
+			return nil, nil, err
+		}
+		pluginChecker := newPluginChecker(pluginRunner, pluginConfig, image)
+		for _, rule := range rules {
+			if _, ok := idToCategories[rule.ID]; ok {
+				return nil, nil, fmt.Errorf("plugin %q provides rule %q which already exists", pluginConfig.Plugin, rule.ID)
+			}
+			idToCategories[rule.ID] = rule.Categories
+			ruleBuilders = append(
+				ruleBuilders,
+				internal.NewNopRuleBuilder(
+					rule.ID,
+					rule.Purpose,
+					pluginChecker.newCheckFunc(ctx),
+				),
+			)
+			pluginChecker.ruleIDs[rule.ID] = struct{}{}
+		}
+		pluginCheckers = append(pluginCheckers, pluginChecker)
+	}
+	return &internal.VersionSpec{
+		RuleBuilders:      ruleBuilders,
+		DefaultCategories: versionSpec.DefaultCategories,
+		IDToCategories:    idToCategories,
+	}, pluginCheckers, nil
+}
+
+// pluginChecker checks the rules of a single plugin.
+//
+// The plugin is run once for all of its configured rules, the first time
+// any of its rules is checked.
+type pluginChecker struct {
+	pluginRunner bufcheckplugin.Runner
+	pluginConfig *buflintconfig.PluginConfig
+	image        bufimage.Image
+	// ruleIDs are all the IDs of the rules provided by the plugin.
+	ruleIDs map[string]struct{}
+	// configuredRuleIDs are the IDs of the rules of the plugin that are
+	// used by the config, set with setRules.
+	configuredRuleIDs []string
+
+	once                sync.Once
+	idToFileAnnotations map[string][]bufanalysis.FileAnnotation
+	err                 error
+}
+
+func newPluginChecker(
+	pluginRunner bufcheckplugin.Runner,
+	pluginConfig *buflintconfig.PluginConfig,
+	image bufimage.Image,
+) *pluginChecker {
+	return &pluginChecker{
+		pluginRunner: pluginRunner,
+		pluginConfig: pluginConfig,
+		image:        image,
+		ruleIDs:      make(map[string]struct{}),
+	}
+}
+
+// setRules sets the rules used by the config.
+//
+// Must be called before any rules are checked.
+func (p *pluginChecker) setRules(rules []*internal.Rule) {
+	for _, rule := range rules {
+		if _, ok := p.ruleIDs[rule.ID()]; ok {
+			p.configuredRuleIDs = append(p.configuredRuleIDs, rule.ID())
+		}
+	}
+}
+
+func (p *pluginChecker) newCheckFunc(ctx context.Context) internal.CheckFunc {
+	return func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+		idToFileAnnotations, err := p.check(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pathToFile := make(map[string]protosource.File, len(files))
+		for _, file := range files {
+			pathToFile[file.Path()] = file
+		}
+		var fileAnnotations []bufanalysis.FileAnnotation
+		for _, fileAnnotation := range idToFileAnnotations[id] {
+			// Plugins only report paths, so only the ignores for files are supported.
+			var descriptors []protosource.Descriptor
+			if file, ok := pathToFile[fileAnnotation.FileInfo().Path()]; ok {
+				descriptors = []protosource.Descriptor{file}
+			}
+			if ignoreFunc(id, descriptors, nil) {
+				continue
+			}
+			fileAnnotations = append(fileAnnotations, fileAnnotation)
+		}
+		return fileAnnotations, nil
+	}
+}
+
+func (p *pluginChecker) check(ctx context.Context) (map[string][]bufanalysis.FileAnnotation, error) {
+	p.once.Do(func() {
+		if p.image == nil {
+			p.err = fmt.Errorf("no image to check with plugin %q", p.pluginConfig.Plugin)
+			return
+		}
+		fileAnnotations, err := p.pluginRunner.Check(
+			ctx,
+			p.pluginConfig.Plugin,
+			p.pluginConfig.Options,
+			p.image,
+			p.configuredRuleIDs,
+		)
+		if err != nil {
+			p.err = err
+			return
+		}
+		p.idToFileAnnotations = make(map[string][]bufanalysis.FileAnnotation)
+		for _, fileAnnotation := range fileAnnotations {
+			p.idToFileAnnotations[fileAnnotation.Type()] = append(p.idToFileAnnotations[fileAnnotation.Type()], fileAnnotation)
+		}
+	})
+	return p.idToFileAnnotations, p.err
+}
+
+// listRulesCachingPluginRunner is a bufcheckplugin.Runner that lists the
+// rules of every plugin with the same options at most once.
+//
+// The rules are needed every time a config is resolved, which happens
+// multiple times per config when listing, checking, and baselining.
+type listRulesCachingPluginRunner struct {
+	bufcheckplugin.Runner
+
+	lock       sync.Mutex
+	keyToRules map[string][]*bufcheckplugin.Rule
+}
+
+func newListRulesCachingPluginRunner(delegate bufcheckplugin.Runner) *listRulesCachingPluginRunner {
+	return &listRulesCachingPluginRunner{
+		Runner:     delegate,
+		keyToRules: make(map[string][]*bufcheckplugin.Rule),
+	}
+}
+
+func (r *listRulesCachingPluginRunner) ListRules(
+	ctx context.Context,
+	plugin string,
+	options map[string]string,
+) ([]*bufcheckplugin.Rule, error) {
+	// json.Marshal sorts the keys of maps, so equal options result in equal keys.
+	keyData, err := json.Marshal([]interface{}{plugin, options})
+	if err != nil {
+		return nil, err
+	}
+	key := string(keyData)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if rules, ok := r.keyToRules[key]; ok {
+		return rules, nil
+	}
+	rules, err := r.Runner.ListRules(ctx, plugin, options)
+	if err != nil {
+		return nil, err
+	}
+	r.keyToRules[key] = rules
+	return rules, nil
+}
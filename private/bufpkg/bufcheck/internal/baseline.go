@@ -0,0 +1,99 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"sync"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+// newBaselineIgnoreFunc returns a new IgnoreFunc that additionally ignores
+// the violations matched by the Matcher.
+func newBaselineIgnoreFunc(delegate IgnoreFunc, matcher *bufbaseline.Matcher) IgnoreFunc {
+	return func(id string, descriptors []protosource.Descriptor, locations []protosource.Location) bool {
+		if delegate(id, descriptors, locations) {
+			return true
+		}
+		entry, ok := baselineEntryForDescriptors(id, descriptors)
+		return ok && matcher.Match(entry)
+	}
+}
+
+// baselineRecorder records the violations that are not ignored.
+//
+// Safe for concurrent use, as rules are run concurrently.
+type baselineRecorder struct {
+	entries []bufbaseline.Entry
+	lock    sync.Mutex
+}
+
+func newBaselineRecorder() *baselineRecorder {
+	return &baselineRecorder{}
+}
+
+func (b *baselineRecorder) newIgnoreFunc(delegate IgnoreFunc) IgnoreFunc {
+	return func(id string, descriptors []protosource.Descriptor, locations []protosource.Location) bool {
+		if delegate(id, descriptors, locations) {
+			return true
+		}
+		if entry, ok := baselineEntryForDescriptors(id, descriptors); ok {
+			b.lock.Lock()
+			b.entries = append(b.entries, entry)
+			b.lock.Unlock()
+		}
+		return false
+	}
+}
+
+func (b *baselineRecorder) baseline() *bufbaseline.Baseline {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return bufbaseline.NewBaseline(b.entries...)
+}
+
+// baselineEntryForDescriptors returns the baseline Entry for the primary descriptor,
+// which is the first descriptor.
+//
+// If there is no primary descriptor, the first extra descriptor is used instead. This
+// is the case for violations for deleted elements that can only be reported against
+// the previous files, such as deleted files.
+//
+// Returns false if there are no descriptors.
+func baselineEntryForDescriptors(id string, descriptors []protosource.Descriptor) (bufbaseline.Entry, bool) {
+	var descriptor protosource.Descriptor
+	for _, candidate := range descriptors {
+		if candidate != nil {
+			descriptor = candidate
+			break
+		}
+	}
+	if descriptor == nil {
+		return bufbaseline.Entry{}, false
+	}
+	var elementPath string
+	switch t := descriptor.(type) {
+	case protosource.NamedDescriptor:
+		elementPath = t.FullName()
+	case protosource.FileImport:
+		elementPath = t.Import()
+	}
+	return bufbaseline.Entry{
+		ID:          id,
+		Path:        descriptor.File().Path(),
+		ElementPath: elementPath,
+	}, true
+}
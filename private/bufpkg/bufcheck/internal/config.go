@@ -19,6 +19,8 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 )
@@ -26,6 +28,10 @@ import (
 const (
 	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
 	defaultServiceSuffix       = "Service"
+	// defaultFieldNumberMax is the maximum field number allowed by Protocol Buffers.
+	defaultFieldNumberMax    = 536870911
+	defaultFieldNumberMaxGap = 100
+	defaultMessageMaxFields  = 100
 )
 
 // Config is the check config.
@@ -41,6 +47,16 @@ type Config struct {
 
 	AllowCommentIgnores    bool
 	IgnoreUnstablePackages bool
+
+	// IDToSeverity is a map from rule ID to the severity of its violations.
+	//
+	// Violations of rules that are not in the map are errors.
+	IDToSeverity map[string]bufanalysis.Severity
+
+	// Baseline contains the known violations that should not be reported.
+	//
+	// Can be nil.
+	Baseline *bufbaseline.Baseline
 }
 
 // ConfigBuilder is a config builder.
@@ -51,6 +67,8 @@ type ConfigBuilder struct {
 	IgnoreRootPaths               []string
 	IgnoreIDOrCategoryToRootPaths map[string][]string
 
+	IDOrCategoryToSeverity map[string]string
+
 	AllowCommentIgnores    bool
 	IgnoreUnstablePackages bool
 
@@ -59,6 +77,10 @@ type ConfigBuilder struct {
 	RPCAllowGoogleProtobufEmptyRequests  bool
 	RPCAllowGoogleProtobufEmptyResponses bool
 	ServiceSuffix                        string
+	FieldNumberMax                       int
+	FieldNumberMaxGap                    *int
+	MessageMaxFields                     int
+	CustomOptions                        []string
 }
 
 // NewConfig returns a new Config.
@@ -79,6 +101,19 @@ func newConfig(configBuilder ConfigBuilder, versionSpec *VersionSpec) (*Config,
 	if configBuilder.ServiceSuffix == "" {
 		configBuilder.ServiceSuffix = defaultServiceSuffix
 	}
+	if configBuilder.FieldNumberMax == 0 {
+		configBuilder.FieldNumberMax = defaultFieldNumberMax
+	}
+	if configBuilder.FieldNumberMaxGap == nil {
+		// 0 is a valid gap, so only unset values get the default
+		fieldNumberMaxGap := defaultFieldNumberMaxGap
+		configBuilder.FieldNumberMaxGap = &fieldNumberMaxGap
+	} else if *configBuilder.FieldNumberMaxGap < 0 {
+		return nil, fmt.Errorf("field_number_max_gap must not be negative, got %d", *configBuilder.FieldNumberMaxGap)
+	}
+	if configBuilder.MessageMaxFields == 0 {
+		configBuilder.MessageMaxFields = defaultMessageMaxFields
+	}
 	return newConfigForRuleBuilders(
 		configBuilder,
 		versionSpec.RuleBuilders,
@@ -184,15 +219,61 @@ func newConfigForRuleBuilders(
 		ignoreRootPaths[rootPath] = struct{}{}
 	}
 
+	idToSeverity, err := transformToIDToSeverity(configBuilder.IDOrCategoryToSeverity, idToCategories, categoryToIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		Rules:                  resultRules,
 		IgnoreIDToRootPaths:    ignoreIDToRootPaths,
 		IgnoreRootPaths:        ignoreRootPaths,
+		IDToSeverity:           idToSeverity,
 		AllowCommentIgnores:    configBuilder.AllowCommentIgnores,
 		IgnoreUnstablePackages: configBuilder.IgnoreUnstablePackages,
 	}, nil
 }
 
+// transformToIDToSeverity resolves the categories in idOrCategoryToSeverity to their IDs.
+//
+// A severity given for an ID takes precedence over a severity given for any of its
+// categories. If an ID is in multiple categories with different severities, the most
+// severe one is used.
+func transformToIDToSeverity(idOrCategoryToSeverity map[string]string, idToCategories map[string][]string, categoryToIDs map[string][]string) (map[string]bufanalysis.Severity, error) {
+	if len(idOrCategoryToSeverity) == 0 {
+		return nil, nil
+	}
+	idToSeverity := make(map[string]bufanalysis.Severity)
+	categoryIDToSeverity := make(map[string]bufanalysis.Severity)
+	for idOrCategory, severityString := range idOrCategoryToSeverity {
+		if idOrCategory == "" {
+			continue
+		}
+		severity, err := bufanalysis.ParseSeverity(severityString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid severity for %q: %w", idOrCategory, err)
+		}
+		if _, ok := idToCategories[idOrCategory]; ok {
+			idToSeverity[idOrCategory] = severity
+		} else if ids, ok := categoryToIDs[idOrCategory]; ok {
+			for _, id := range ids {
+				// lower values are more severe
+				if existing, ok := categoryIDToSeverity[id]; !ok || severity < existing {
+					categoryIDToSeverity[id] = severity
+				}
+			}
+		} else {
+			return nil, fmt.Errorf("%q is not a known id or category", idOrCategory)
+		}
+	}
+	for id, severity := range categoryIDToSeverity {
+		if _, ok := idToSeverity[id]; !ok {
+			idToSeverity[id] = severity
+		}
+	}
+	return idToSeverity, nil
+}
+
 func transformToIDMap(idsOrCategories []string, idToCategories map[string][]string, categoryToIDs map[string][]string) (map[string]struct{}, error) {
 	if len(idsOrCategories) == 0 {
 		return nil, nil
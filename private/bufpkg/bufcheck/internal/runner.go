@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbaseline"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/protosource"
 	"github.com/bufbuild/buf/private/pkg/protoversion"
@@ -61,8 +62,38 @@ func RunnerWithIgnorePrefix(ignorePrefix string) RunnerOption {
 }
 
 // Check runs the Rules.
+//
+// Violations contained in the Baseline of the Config are not reported.
 func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
-	rules := config.Rules
+	ignoreFunc := r.newIgnoreFunc(config)
+	if config.Baseline != nil {
+		ignoreFunc = newBaselineIgnoreFunc(ignoreFunc, config.Baseline.NewMatcher())
+	}
+	fileAnnotations, err := r.check(ctx, config.Rules, ignoreFunc, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	return fileAnnotationsWithSeverity(fileAnnotations, config.IDToSeverity), nil
+}
+
+// Baseline runs the Rules and returns a Baseline containing all reported violations.
+//
+// The Baseline of the Config is not used.
+func (r *Runner) Baseline(ctx context.Context, config *Config, previousFiles []protosource.File, files []protosource.File) (*bufbaseline.Baseline, error) {
+	recorder := newBaselineRecorder()
+	if _, err := r.check(ctx, config.Rules, recorder.newIgnoreFunc(r.newIgnoreFunc(config)), previousFiles, files); err != nil {
+		return nil, err
+	}
+	return recorder.baseline(), nil
+}
+
+func (r *Runner) check(
+	ctx context.Context,
+	rules []*Rule,
+	ignoreFunc IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+) ([]bufanalysis.FileAnnotation, error) {
 	if len(rules) == 0 {
 		return nil, nil
 	}
@@ -73,7 +104,6 @@ func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []prot
 	)
 	defer span.End()
 
-	ignoreFunc := r.newIgnoreFunc(config)
 	var fileAnnotations []bufanalysis.FileAnnotation
 	resultC := make(chan *result, len(rules))
 	for _, rule := range rules {
@@ -100,6 +130,18 @@ func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []prot
 	return fileAnnotations, nil
 }
 
+func fileAnnotationsWithSeverity(fileAnnotations []bufanalysis.FileAnnotation, idToSeverity map[string]bufanalysis.Severity) []bufanalysis.FileAnnotation {
+	if len(idToSeverity) == 0 {
+		return fileAnnotations
+	}
+	for i, fileAnnotation := range fileAnnotations {
+		if severity, ok := idToSeverity[fileAnnotation.Type()]; ok {
+			fileAnnotations[i] = bufanalysis.FileAnnotationWithSeverity(fileAnnotation, severity)
+		}
+	}
+	return fileAnnotations
+}
+
 func (r *Runner) newIgnoreFunc(config *Config) IgnoreFunc {
 	return func(id string, descriptors []protosource.Descriptor, locations []protosource.Location) bool {
 		if idIsIgnored(id, descriptors, config) {
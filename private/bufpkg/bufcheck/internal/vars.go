@@ -16,16 +16,18 @@ package internal
 
 // priority 1 is higher than priority two
 var topLevelCategoryToPriority = map[string]int{
-	"MINIMAL":   1,
-	"BASIC":     2,
-	"DEFAULT":   3,
-	"COMMENTS":  4,
-	"UNARY_RPC": 5,
-	"OTHER":     6,
-	"FILE":      1,
-	"PACKAGE":   2,
-	"WIRE_JSON": 3,
-	"WIRE":      4,
+	"MINIMAL":        1,
+	"BASIC":          2,
+	"DEFAULT":        3,
+	"COMMENTS":       4,
+	"UNARY_RPC":      5,
+	"OTHER":          6,
+	"API_SURFACE":    7,
+	"FILE":           1,
+	"PACKAGE":        2,
+	"WIRE_JSON":      3,
+	"WIRE":           4,
+	"CUSTOM_OPTIONS": 5,
 }
 
 func categoryLess(one string, two string) bool {
@@ -16,7 +16,6 @@ package bufimage
 
 import (
 	"fmt"
-	"sort"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
@@ -308,27 +307,38 @@ func ImageByDir(image Image) ([]Image, error) {
 			paths = append(paths, imageFile.Path())
 		}
 	}
-	dirToPaths := normalpath.ByDir(paths...)
-	// we need this to produce a deterministic order of the returned Images
-	dirs := make([]string, 0, len(dirToPaths))
-	for dir := range dirToPaths {
-		dirs = append(dirs, dir)
-	}
-	sort.Strings(dirs)
-	newImages := make([]Image, 0, len(dirToPaths))
-	for _, dir := range dirs {
-		paths, ok := dirToPaths[dir]
-		if !ok {
-			// this should never happen
-			return nil, fmt.Errorf("no dir for %q in dirToPaths", dir)
+	return imagesByKey(image, normalpath.ByDir(paths...))
+}
+
+// ImageByPackage returns multiple images that have non-imports split
+// by package.
+//
+// That is, each Image will only contain a single package's files
+// as it's non-imports, along with all required imports for the
+// files in that package. Files without a package are grouped together.
+func ImageByPackage(image Image) ([]Image, error) {
+	packageToPaths := make(map[string][]string)
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() {
+			pkg := imageFile.Proto().GetPackage()
+			packageToPaths[pkg] = append(packageToPaths[pkg], imageFile.Path())
 		}
-		newImage, err := ImageWithOnlyPaths(image, paths, nil)
-		if err != nil {
-			return nil, err
+	}
+	return imagesByKey(image, packageToPaths)
+}
+
+// ImageByFile returns multiple images that each have a single non-import.
+//
+// That is, each Image will only contain a single file as it's non-import,
+// along with all required imports for that file.
+func ImageByFile(image Image) ([]Image, error) {
+	pathToPaths := make(map[string][]string)
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() {
+			pathToPaths[imageFile.Path()] = []string{imageFile.Path()}
 		}
-		newImages = append(newImages, newImage)
 	}
-	return newImages, nil
+	return imagesByKey(image, pathToPaths)
 }
 
 // ImageToProtoImage returns a new ProtoImage for the Image.
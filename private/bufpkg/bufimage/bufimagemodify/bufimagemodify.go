@@ -106,6 +106,34 @@ func CcEnableArenas(
 	return ccEnableArenas(logger, sweeper, value, validatedOverrides), nil
 }
 
+// CcGenericServices returns a Modifier that sets the cc_generic_services
+// file option to the given value in all of the files contained in
+// the Image, except for the files within the except modules.
+func CcGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", CcGenericServicesID, err)
+	}
+	return ccGenericServices(logger, sweeper, value, except, moduleOverrides, validatedOverrides), nil
+}
+
+// FieldOptions returns a Modifier that sets the field options of the fields
+// matched by the selectors of the given overrides.
+func FieldOptions(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	overrides []FieldOptionOverride,
+) (Modifier, error) {
+	return fieldOptions(logger, sweeper, overrides)
+}
+
 // GoPackage returns a Modifier that sets the go_package file option
 // according to the given defaultImportPathPrefix, exceptions, and
 // overrides.
@@ -127,6 +155,24 @@ func GoPackage(
 	)
 }
 
+// JavaGenericServices returns a Modifier that sets the java_generic_services
+// file option to the given value in all of the files contained in
+// the Image, except for the files within the except modules.
+func JavaGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", JavaGenericServicesID, err)
+	}
+	return javaGenericServices(logger, sweeper, value, except, moduleOverrides, validatedOverrides), nil
+}
+
 // JavaMultipleFiles returns a Modifier that sets the java_multiple_files
 // file option to the given value in all of the files contained in
 // the Image.
@@ -188,6 +234,24 @@ func JavaStringCheckUtf8(
 	return javaStringCheckUtf8(logger, sweeper, value, validatedOverrides), nil
 }
 
+// JSType returns a Modifier that sets the jstype field option to the given
+// value on all of the 64-bit integer fields contained in the Image, except
+// for the fields within the except modules.
+func JSType(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value descriptorpb.FieldOptions_JSType,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]descriptorpb.FieldOptions_JSType,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToJSTypeOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", JSTypeID, err)
+	}
+	return jsType(logger, sweeper, value, except, moduleOverrides, validatedOverrides), nil
+}
+
 // OptimizeFor returns a Modifier that sets the optimize_for file
 // option to the given value in all of the files contained in
 // the Image.
@@ -204,6 +268,24 @@ func OptimizeFor(
 	return optimizeFor(logger, sweeper, value, validatedOverrides), nil
 }
 
+// PyGenericServices returns a Modifier that sets the py_generic_services
+// file option to the given value in all of the files contained in
+// the Image, except for the files within the except modules.
+func PyGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", PyGenericServicesID, err)
+	}
+	return pyGenericServices(logger, sweeper, value, except, moduleOverrides, validatedOverrides), nil
+}
+
 // GoPackageImportPathForFile returns the go_package import path for the given
 // ImageFile. If the package contains a version suffix, and if there are more
 // than two components, concatenate the final two components. Otherwise, we
@@ -223,6 +305,16 @@ func GoPackageImportPathForFile(imageFile bufimage.ImageFile, importPathPrefix s
 	return goPackageImportPath
 }
 
+// MessageOptions returns a Modifier that sets the message options of the
+// messages matched by the selectors of the given overrides.
+func MessageOptions(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	overrides []MessageOptionOverride,
+) (Modifier, error) {
+	return messageOptions(logger, sweeper, overrides)
+}
+
 // ObjcClassPrefix returns a Modifier that sets the objc_class_prefix file option
 // according to the package name. It is set to the uppercase first letter of each package sub-name,
 // not including the package version, with the following rules:
@@ -269,6 +361,20 @@ func PhpMetadataNamespace(
 	return phpMetadataNamespace(logger, sweeper, overrides)
 }
 
+// PhpClassPrefix returns a Modifier that sets the php_class_prefix file option
+// to the given value in all of the files contained in the Image, except for the
+// files within the except modules.
+func PhpClassPrefix(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultValue string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	overrides map[string]string,
+) (Modifier, error) {
+	return phpClassPrefix(logger, sweeper, defaultValue, except, moduleOverrides, overrides)
+}
+
 // RubyPackage returns a Modifier that sets the ruby_package file option
 // according to the given packagePrefix. It is set to the package name with each package sub-name capitalized
 // and each "." replaced with "::".
@@ -280,6 +386,20 @@ func RubyPackage(
 	return rubyPackage(logger, sweeper, overrides)
 }
 
+// SwiftPrefix returns a Modifier that sets the swift_prefix file option
+// to the given value in all of the files contained in the Image, except for
+// the files within the except modules.
+func SwiftPrefix(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultValue string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	overrides map[string]string,
+) (Modifier, error) {
+	return swiftPrefix(logger, sweeper, defaultValue, except, moduleOverrides, overrides)
+}
+
 // isWellKnownType returns true if the given path is one of the well-known types.
 func isWellKnownType(ctx context.Context, imageFile bufimage.ImageFile) bool {
 	return datawkt.Exists(imageFile.Path())
@@ -298,6 +418,14 @@ func int32SliceIsEqual(x []int32, y []int32) bool {
 	return true
 }
 
+// int32SliceHasPrefix returns true if x starts with the elements of prefix.
+func int32SliceHasPrefix(x []int32, prefix []int32) bool {
+	if len(x) < len(prefix) {
+		return false
+	}
+	return int32SliceIsEqual(x[:len(prefix)], prefix)
+}
+
 func stringOverridesToBoolOverrides(stringOverrides map[string]string) (map[string]bool, error) {
 	validatedOverrides := make(map[string]bool, len(stringOverrides))
 	for fileImportPath, overrideString := range stringOverrides {
@@ -321,3 +449,15 @@ func stringOverridesToOptimizeModeOverrides(stringOverrides map[string]string) (
 	}
 	return validatedOverrides, nil
 }
+
+func stringOverridesToJSTypeOverrides(stringOverrides map[string]string) (map[string]descriptorpb.FieldOptions_JSType, error) {
+	validatedOverrides := make(map[string]descriptorpb.FieldOptions_JSType, len(stringOverrides))
+	for fileImportPath, stringOverride := range stringOverrides {
+		jsType, ok := descriptorpb.FieldOptions_JSType_value[stringOverride]
+		if !ok {
+			return nil, fmt.Errorf("invalid jstype %s set for file %s", stringOverride, fileImportPath)
+		}
+		validatedOverrides[fileImportPath] = descriptorpb.FieldOptions_JSType(jsType)
+	}
+	return validatedOverrides, nil
+}
@@ -72,6 +72,29 @@ func assertFileOptionSourceCodeInfoNotEmpty(t *testing.T, image bufimage.Image,
 	}
 }
 
+func assertSourceCodeInfoPathsExist(t *testing.T, image bufimage.Image, paths ...[]int32) {
+	for _, path := range paths {
+		assert.True(t, testHasSourceCodeInfoPath(image, path), "expected path %v", path)
+	}
+}
+
+func assertSourceCodeInfoPathsNotExist(t *testing.T, image bufimage.Image, paths ...[]int32) {
+	for _, path := range paths {
+		assert.False(t, testHasSourceCodeInfoPath(image, path), "unexpected path %v", path)
+	}
+}
+
+func testHasSourceCodeInfoPath(image bufimage.Image, path []int32) bool {
+	for _, imageFile := range image.Files() {
+		for _, location := range imageFile.Proto().GetSourceCodeInfo().GetLocation() {
+			if int32SliceIsEqual(location.Path, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func testGetImage(t *testing.T, dirPath string, includeSourceInfo bool) bufimage.Image {
 	moduleFileSet := testGetModuleFileSet(t, dirPath)
 	var options []bufimagebuild.BuildOption
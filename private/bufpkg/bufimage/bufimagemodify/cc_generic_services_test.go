@@ -0,0 +1,172 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCcGenericServicesEmptyOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "emptyoptions")
+	t.Run("with SourceCodeInfo", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoEmpty(t, image, ccGenericServicesPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		ccGenericServicesModifier, err := CcGenericServices(zap.NewNop(), sweeper, false, nil, nil, nil)
+		require.NoError(t, err)
+		modifier := NewMultiModifier(ccGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("without SourceCodeInfo", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+		assertFileOptionSourceCodeInfoEmpty(t, image, ccGenericServicesPath, false)
+
+		sweeper := NewFileOptionSweeper()
+		modifier, err := CcGenericServices(zap.NewNop(), sweeper, true, nil, nil, nil)
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		for _, imageFile := range image.Files() {
+			assert.True(t, imageFile.Proto().GetOptions().GetCcGenericServices())
+		}
+	})
+
+	t.Run("with SourceCodeInfo and per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoEmpty(t, image, ccGenericServicesPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		ccGenericServicesModifier, err := CcGenericServices(zap.NewNop(), sweeper, false, nil, nil, map[string]string{"a.proto": "true"})
+		require.NoError(t, err)
+		modifier := NewMultiModifier(ccGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		for _, imageFile := range image.Files() {
+			assert.True(t, imageFile.Proto().GetOptions().GetCcGenericServices())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, ccGenericServicesPath, true)
+	})
+
+	t.Run("with invalid per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		_, err := CcGenericServices(zap.NewNop(), NewFileOptionSweeper(), false, nil, nil, map[string]string{"a.proto": "nope"})
+		require.Error(t, err)
+	})
+}
+
+func TestCcGenericServicesAllOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	image := testGetImage(t, dirPath, true)
+	assertFileOptionSourceCodeInfoNotEmpty(t, image, ccGenericServicesPath)
+
+	sweeper := NewFileOptionSweeper()
+	ccGenericServicesModifier, err := CcGenericServices(zap.NewNop(), sweeper, true, nil, nil, nil)
+	require.NoError(t, err)
+	modifier := NewMultiModifier(ccGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	for _, imageFile := range image.Files() {
+		assert.True(t, imageFile.Proto().GetOptions().GetCcGenericServices())
+	}
+	assertFileOptionSourceCodeInfoEmpty(t, image, ccGenericServicesPath, true)
+}
+
+func TestCcGenericServicesWithExceptAndOverride(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	testModuleIdentity, err := bufmoduleref.NewModuleIdentity(
+		testRemote,
+		testRepositoryOwner,
+		testRepositoryName,
+	)
+	require.NoError(t, err)
+
+	t.Run("with except", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		sweeper := NewFileOptionSweeper()
+		ccGenericServicesModifier, err := CcGenericServices(
+			zap.NewNop(),
+			sweeper,
+			true,
+			[]bufmoduleref.ModuleIdentity{testModuleIdentity},
+			nil,
+			nil,
+		)
+		require.NoError(t, err)
+		modifier := NewMultiModifier(ccGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("with override", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		sweeper := NewFileOptionSweeper()
+		ccGenericServicesModifier, err := CcGenericServices(
+			zap.NewNop(),
+			sweeper,
+			false,
+			nil,
+			map[bufmoduleref.ModuleIdentity]bool{
+				testModuleIdentity: true,
+			},
+			nil,
+		)
+		require.NoError(t, err)
+		modifier := NewMultiModifier(ccGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		for _, imageFile := range image.Files() {
+			assert.True(t, imageFile.Proto().GetOptions().GetCcGenericServices())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, ccGenericServicesPath, true)
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// FieldOptionOverride is an override of a field option.
+type FieldOptionOverride struct {
+	// Selector is the fully-qualified name of the package, message, or
+	// field that the override applies to. Every field nested within the
+	// selector is matched. If empty, the override applies to all fields.
+	Selector string
+	// Types are the types of the fields that the override applies to.
+	// If empty, the override applies to fields of all types.
+	Types []descriptorpb.FieldDescriptorProto_Type
+	// Option is the name of the option, such as "jstype", or the
+	// fully-qualified name of a custom option in parentheses, such
+	// as "(acme.option.v1.redacted)".
+	Option string
+	// Value is the value of the option, such as "JS_STRING".
+	Value string
+}
+
+type fieldOptionOverride struct {
+	*optionOverride
+	types map[descriptorpb.FieldDescriptorProto_Type]struct{}
+}
+
+func fieldOptions(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	overrides []FieldOptionOverride,
+) (Modifier, error) {
+	fieldOptionsDescriptor := (&descriptorpb.FieldOptions{}).ProtoReflect().Descriptor()
+	fieldOptionOverrides := make([]*fieldOptionOverride, len(overrides))
+	for i, override := range overrides {
+		optionOverride, err := newOptionOverride(fieldOptionsDescriptor, override.Selector, override.Option, override.Value)
+		if err != nil {
+			return nil, err
+		}
+		types := make(map[descriptorpb.FieldDescriptorProto_Type]struct{}, len(override.Types))
+		for _, fieldType := range override.Types {
+			types[fieldType] = struct{}{}
+		}
+		fieldOptionOverrides[i] = &fieldOptionOverride{
+			optionOverride: optionOverride,
+			types:          types,
+		}
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			resolver := newExtensionResolver(image)
+			seenOverrides := make(map[int]struct{}, len(fieldOptionOverrides))
+			for _, imageFile := range image.Files() {
+				if isWellKnownType(ctx, imageFile) {
+					continue
+				}
+				if err := forEachField(
+					imageFile.Proto(),
+					func(field *descriptorpb.FieldDescriptorProto, fullName string, path []int32) error {
+						for i, override := range fieldOptionOverrides {
+							if !override.matches(fullName) {
+								continue
+							}
+							if len(override.types) > 0 {
+								if _, ok := override.types[field.GetType()]; !ok {
+									continue
+								}
+							}
+							seenOverrides[i] = struct{}{}
+							if field.Options == nil {
+								field.Options = &descriptorpb.FieldOptions{}
+							}
+							optionNumber, err := override.apply(resolver, field.Options.ProtoReflect())
+							if err != nil {
+								return fmt.Errorf("%s: %w", fullName, err)
+							}
+							if optionNumber == 0 {
+								continue
+							}
+							if sweeper != nil {
+								sweeper.mark(imageFile.Path(), getChildPath(path, fieldOptionsFieldNumber, optionNumber))
+							}
+						}
+						return nil
+					},
+				); err != nil {
+					return err
+				}
+			}
+			for i, override := range overrides {
+				if _, ok := seenOverrides[i]; !ok {
+					logger.Sugar().Warnf("field option override %s for %q did not match any fields", override.Option, override.Selector)
+				}
+			}
+			return nil
+		},
+	), nil
+}
@@ -22,12 +22,6 @@ import (
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
-// fileOptionPath is the path prefix used for FileOptions.
-// All file option locations are preceded by a location
-// with a path set to the fileOptionPath.
-// https://github.com/protocolbuffers/protobuf/blob/053966b4959bdd21e4a24e657bcb97cb9de9e8a4/src/google/protobuf/descriptor.proto#L80
-var fileOptionPath = []int32{8}
-
 type fileOptionSweeper struct {
 	// Filepath -> SourceCodeInfo_Location.Path keys.
 	sourceCodeInfoPaths map[string]map[string]struct{}
@@ -40,7 +34,7 @@ func newFileOptionSweeper() *fileOptionSweeper {
 }
 
 // mark is used to mark the given SourceCodeInfo_Location indices for
-// deletion. This method should be called in each of the file option
+// deletion. This method should be called in each of the option
 // modifiers.
 func (s *fileOptionSweeper) mark(imageFilePath string, path []int32) {
 	paths, ok := s.sourceCodeInfoPaths[imageFilePath]
@@ -63,43 +57,110 @@ func (s *fileOptionSweeper) Sweep(ctx context.Context, image bufimage.Image) err
 			continue
 		}
 		// We can't just match on an exact path match because the target
-		// file option's parent path elements would remain (i.e [8]).
-		// Instead, we perform an initial pass to validate that the paths
-		// are structured as expect, and collect all of the indices that
-		// we need to delete.
+		// option's parent path elements would remain (i.e [8]), as well
+		// as any locations nested within the target option (i.e. the
+		// fields of a message-typed custom option). Instead, we perform
+		// an initial pass to validate that the paths are structured as
+		// expected, and collect all of the indices that we need to delete.
+		locations := descriptor.SourceCodeInfo.Location
 		indices := make(map[int]struct{}, len(paths)*2)
-		for i, location := range descriptor.SourceCodeInfo.Location {
+		parentIndices := make(map[int]struct{}, len(paths))
+		for i, location := range locations {
+			if !hasMarkedPathPrefix(paths, location.Path) {
+				continue
+			}
+			indices[i] = struct{}{}
 			if _, ok := paths[getPathKey(location.Path)]; !ok {
+				// This location is nested within a marked path, and
+				// shares its parent.
 				continue
 			}
-			if i == 0 {
+			parentIndex := getParentLocationIndex(locations, i)
+			if parentIndex < 0 {
 				return fmt.Errorf("path %v must have a preceding parent path", location.Path)
 			}
-			if !int32SliceIsEqual(descriptor.SourceCodeInfo.Location[i-1].Path, fileOptionPath) {
-				return fmt.Errorf("path %v must have a preceding parent path equal to %v", location.Path, fileOptionPath)
+			parentIndices[parentIndex] = struct{}{}
+		}
+		// The parent location is only deleted if all of its children are.
+		// File and message options have a parent location for each option
+		// declaration, whereas field options are declared in a single
+		// compact options list (i.e. [jstype = JS_STRING, ctype = CORD])
+		// that shares a single parent location.
+		for parentIndex := range parentIndices {
+			if !hasRemainingChildLocation(locations, parentIndex, indices) {
+				indices[parentIndex] = struct{}{}
 			}
-			// Add the target path and its parent.
-			indices[i-1] = struct{}{}
-			indices[i] = struct{}{}
 		}
 		// Now that we know exactly which indices to exclude, we can
 		// filter the SourceCodeInfo_Locations as needed.
-		locations := make(
+		sweptLocations := make(
 			[]*descriptorpb.SourceCodeInfo_Location,
 			0,
-			len(descriptor.SourceCodeInfo.Location)-len(indices),
+			len(locations)-len(indices),
 		)
-		for i, location := range descriptor.SourceCodeInfo.Location {
+		for i, location := range locations {
 			if _, ok := indices[i]; ok {
 				continue
 			}
-			locations = append(locations, location)
+			sweptLocations = append(sweptLocations, location)
 		}
-		descriptor.SourceCodeInfo.Location = locations
+		descriptor.SourceCodeInfo.Location = sweptLocations
 	}
 	return nil
 }
 
+// hasMarkedPathPrefix returns true if the path, or any of its prefixes, is marked.
+func hasMarkedPathPrefix(paths map[string]struct{}, path []int32) bool {
+	for i := len(path); i > 0; i-- {
+		if _, ok := paths[getPathKey(path[:i])]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getParentLocationIndex returns the index of the parent location that
+// precedes the location at the given index, or -1 if there is none.
+//
+// The parent location is the closest preceding location with a path equal
+// to the location's path without its last element. Only the locations
+// nested within the parent may be between the two.
+func getParentLocationIndex(locations []*descriptorpb.SourceCodeInfo_Location, index int) int {
+	path := locations[index].Path
+	if len(path) == 0 {
+		return -1
+	}
+	parentPath := path[:len(path)-1]
+	for i := index - 1; i >= 0; i-- {
+		if int32SliceIsEqual(locations[i].Path, parentPath) {
+			return i
+		}
+		if !int32SliceHasPrefix(locations[i].Path, parentPath) {
+			return -1
+		}
+	}
+	return -1
+}
+
+// hasRemainingChildLocation returns true if any of the locations nested
+// within the parent location at the given index are not going to be deleted.
+func hasRemainingChildLocation(
+	locations []*descriptorpb.SourceCodeInfo_Location,
+	parentIndex int,
+	indices map[int]struct{},
+) bool {
+	parentPath := locations[parentIndex].Path
+	for i := parentIndex + 1; i < len(locations); i++ {
+		if len(locations[i].Path) <= len(parentPath) || !int32SliceHasPrefix(locations[i].Path, parentPath) {
+			break
+		}
+		if _, ok := indices[i]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
 // getPathKey returns a unique key for the given path.
 func getPathKey(path []int32) string {
 	key := make([]byte, len(path)*4)
@@ -0,0 +1,138 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// JSTypeID is the ID of the jstype modifier.
+const JSTypeID = "JSTYPE"
+
+// jsTypePath is the SourceCodeInfo path for the jstype option within a field.
+// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L565
+var jsTypePath = []int32{fieldOptionsFieldNumber, 6}
+
+func jsType(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value descriptorpb.FieldOptions_JSType,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]descriptorpb.FieldOptions_JSType,
+	overrides map[string]descriptorpb.FieldOptions_JSType,
+) Modifier {
+	// Convert the bufmoduleref.ModuleIdentity types into
+	// strings so that they're comparable.
+	exceptModuleIdentityStrings := make(map[string]struct{}, len(except))
+	for _, moduleIdentity := range except {
+		exceptModuleIdentityStrings[moduleIdentity.IdentityString()] = struct{}{}
+	}
+	overrideModuleIdentityStrings := make(map[string]descriptorpb.FieldOptions_JSType, len(moduleOverrides))
+	for moduleIdentity, overrideValue := range moduleOverrides {
+		overrideModuleIdentityStrings[moduleIdentity.IdentityString()] = overrideValue
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			seenModuleIdentityStrings := make(map[string]struct{}, len(overrideModuleIdentityStrings))
+			seenOverrideFiles := make(map[string]struct{}, len(overrides))
+			for _, imageFile := range image.Files() {
+				modifierValue := value
+				if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+					moduleIdentityString := moduleIdentity.IdentityString()
+					if _, ok := exceptModuleIdentityStrings[moduleIdentityString]; ok {
+						continue
+					}
+					if moduleOverrideValue, ok := overrideModuleIdentityStrings[moduleIdentityString]; ok {
+						modifierValue = moduleOverrideValue
+						seenModuleIdentityStrings[moduleIdentityString] = struct{}{}
+					}
+				}
+				if overrideValue, ok := overrides[imageFile.Path()]; ok {
+					modifierValue = overrideValue
+					seenOverrideFiles[imageFile.Path()] = struct{}{}
+				}
+				if isWellKnownType(ctx, imageFile) {
+					continue
+				}
+				jsTypeForFile(sweeper, imageFile, modifierValue)
+			}
+			for moduleIdentityString := range overrideModuleIdentityStrings {
+				if _, ok := seenModuleIdentityStrings[moduleIdentityString]; !ok {
+					logger.Sugar().Warnf("jstype override for %q was unused", moduleIdentityString)
+				}
+			}
+			for overrideFile := range overrides {
+				if _, ok := seenOverrideFiles[overrideFile]; !ok {
+					logger.Sugar().Warnf("%s override for %q was unused", JSTypeID, overrideFile)
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// jsTypeForFile sets the jstype of the 64-bit integer fields, which are the
+// only fields that the jstype option can be set on.
+func jsTypeForFile(
+	sweeper Sweeper,
+	imageFile bufimage.ImageFile,
+	value descriptorpb.FieldOptions_JSType,
+) {
+	// The function never returns an error, so neither does forEachField.
+	_ = forEachField(
+		imageFile.Proto(),
+		func(field *descriptorpb.FieldDescriptorProto, _ string, path []int32) error {
+			if !isJSTypeFieldType(field.GetType()) {
+				return nil
+			}
+			options := field.GetOptions()
+			switch {
+			case options != nil && options.GetJstype() == value:
+				// The option is already set to the same value, don't do anything.
+				return nil
+			case options == nil && descriptorpb.Default_FieldOptions_Jstype == value:
+				// The option is not set, but the value we want to set is the
+				// same as the default, don't do anything.
+				return nil
+			}
+			if options == nil {
+				field.Options = &descriptorpb.FieldOptions{}
+			}
+			field.Options.Jstype = value.Enum()
+			if sweeper != nil {
+				sweeper.mark(imageFile.Path(), getChildPath(path, jsTypePath...))
+			}
+			return nil
+		},
+	)
+}
+
+func isJSTypeFieldType(fieldType descriptorpb.FieldDescriptorProto_Type) bool {
+	switch fieldType {
+	case descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestJSType(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "jstypeoptions")
+	image := testGetImage(t, dirPath, true)
+
+	sweeper := NewFileOptionSweeper()
+	modifier, err := JSType(zap.NewNop(), sweeper, descriptorpb.FieldOptions_JS_STRING, nil, nil, nil)
+	require.NoError(t, err)
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	require.NoError(t, sweeper.Sweep(context.Background(), image))
+	require.Equal(t, 1, len(image.Files()))
+	descriptor := image.Files()[0].Proto()
+	message := descriptor.GetMessageType()[0]
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, message.GetField()[0].GetOptions().GetJstype())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, message.GetField()[1].GetOptions().GetJstype())
+	// The jstype option cannot be set on fields that are not 64-bit integers.
+	assert.Nil(t, message.GetField()[2].GetOptions())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, message.GetField()[3].GetOptions().GetJstype())
+	nestedMessage := message.GetNestedType()[0]
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, nestedMessage.GetField()[0].GetOptions().GetJstype())
+	assert.Nil(t, nestedMessage.GetField()[1].GetOptions())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, descriptor.GetExtension()[0].GetOptions().GetJstype())
+	// The jstype option declared on the timestamp field is swept,
+	// along with the compact options that only contained it.
+	assertSourceCodeInfoPathsNotExist(
+		t,
+		image,
+		[]int32{4, 0, 2, 1, 8, 6},
+		[]int32{4, 0, 2, 1, 8},
+	)
+	assertSourceCodeInfoPathsExist(t, image, []int32{4, 0, 2, 1})
+}
+
+func TestJSTypeNormal(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "jstypeoptions")
+	image := testGetImage(t, dirPath, false)
+
+	modifier, err := JSType(zap.NewNop(), nil, descriptorpb.FieldOptions_JS_NORMAL, nil, nil, nil)
+	require.NoError(t, err)
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	descriptor := image.Files()[0].Proto()
+	message := descriptor.GetMessageType()[0]
+	// Fields without options are left as-is, as JS_NORMAL is the default.
+	assert.Nil(t, message.GetField()[0].GetOptions())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_NORMAL, message.GetField()[1].GetOptions().GetJstype())
+}
+
+func TestJSTypeWithExceptAndOverride(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "jstypeoptions")
+	testModuleIdentity, err := bufmoduleref.NewModuleIdentity(
+		testRemote,
+		testRepositoryOwner,
+		testRepositoryName,
+	)
+	require.NoError(t, err)
+
+	t.Run("with except", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		modifier, err := JSType(
+			zap.NewNop(),
+			nil,
+			descriptorpb.FieldOptions_JS_STRING,
+			[]bufmoduleref.ModuleIdentity{testModuleIdentity},
+			nil,
+			nil,
+		)
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("with override", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		modifier, err := JSType(
+			zap.NewNop(),
+			nil,
+			descriptorpb.FieldOptions_JS_STRING,
+			nil,
+			map[bufmoduleref.ModuleIdentity]descriptorpb.FieldOptions_JSType{
+				testModuleIdentity: descriptorpb.FieldOptions_JS_NUMBER,
+			},
+			nil,
+		)
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		message := image.Files()[0].Proto().GetMessageType()[0]
+		assert.Equal(t, descriptorpb.FieldOptions_JS_NUMBER, message.GetField()[0].GetOptions().GetJstype())
+	})
+
+	t.Run("with per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		modifier, err := JSType(
+			zap.NewNop(),
+			nil,
+			descriptorpb.FieldOptions_JS_STRING,
+			nil,
+			nil,
+			map[string]string{"a.proto": "JS_NUMBER"},
+		)
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		message := image.Files()[0].Proto().GetMessageType()[0]
+		assert.Equal(t, descriptorpb.FieldOptions_JS_NUMBER, message.GetField()[0].GetOptions().GetJstype())
+	})
+
+	t.Run("with invalid per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		_, err := JSType(
+			zap.NewNop(),
+			nil,
+			descriptorpb.FieldOptions_JS_STRING,
+			nil,
+			nil,
+			map[string]string{"a.proto": "JS_BIGINT"},
+		)
+		require.Error(t, err)
+	})
+}
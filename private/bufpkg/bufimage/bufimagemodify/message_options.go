@@ -0,0 +1,103 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// MessageOptionOverride is an override of a message option.
+type MessageOptionOverride struct {
+	// Selector is the fully-qualified name of the package or message that
+	// the override applies to. Every message nested within the selector is
+	// matched. If empty, the override applies to all messages.
+	Selector string
+	// Option is the name of the option, such as "deprecated", or the
+	// fully-qualified name of a custom option in parentheses, such
+	// as "(acme.option.v1.internal)".
+	Option string
+	// Value is the value of the option, such as "true".
+	Value string
+}
+
+func messageOptions(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	overrides []MessageOptionOverride,
+) (Modifier, error) {
+	messageOptionsDescriptor := (&descriptorpb.MessageOptions{}).ProtoReflect().Descriptor()
+	messageOptionOverrides := make([]*optionOverride, len(overrides))
+	for i, override := range overrides {
+		optionOverride, err := newOptionOverride(messageOptionsDescriptor, override.Selector, override.Option, override.Value)
+		if err != nil {
+			return nil, err
+		}
+		messageOptionOverrides[i] = optionOverride
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			resolver := newExtensionResolver(image)
+			seenOverrides := make(map[int]struct{}, len(messageOptionOverrides))
+			for _, imageFile := range image.Files() {
+				if isWellKnownType(ctx, imageFile) {
+					continue
+				}
+				if err := forEachMessage(
+					imageFile.Proto(),
+					func(message *descriptorpb.DescriptorProto, fullName string, path []int32) error {
+						if message.GetOptions().GetMapEntry() {
+							// Map entries are synthesized by the compiler, and
+							// cannot have any options set on them.
+							return nil
+						}
+						for i, override := range messageOptionOverrides {
+							if !override.matches(fullName) {
+								continue
+							}
+							seenOverrides[i] = struct{}{}
+							if message.Options == nil {
+								message.Options = &descriptorpb.MessageOptions{}
+							}
+							optionNumber, err := override.apply(resolver, message.Options.ProtoReflect())
+							if err != nil {
+								return fmt.Errorf("%s: %w", fullName, err)
+							}
+							if optionNumber == 0 {
+								continue
+							}
+							if sweeper != nil {
+								sweeper.mark(imageFile.Path(), getChildPath(path, messageOptionsFieldNumber, optionNumber))
+							}
+						}
+						return nil
+					},
+				); err != nil {
+					return err
+				}
+			}
+			for i, override := range overrides {
+				if _, ok := seenOverrides[i]; !ok {
+					logger.Sugar().Warnf("message option override %s for %q did not match any messages", override.Option, override.Selector)
+				}
+			}
+			return nil
+		},
+	), nil
+}
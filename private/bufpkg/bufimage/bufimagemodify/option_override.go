@@ -0,0 +1,355 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// messageOptionsFieldNumber is the field number of the options in a DescriptorProto.
+	// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L137
+	messageOptionsFieldNumber = 7
+	// fieldOptionsFieldNumber is the field number of the options in a FieldDescriptorProto.
+	// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L238
+	fieldOptionsFieldNumber = 8
+)
+
+// optionOverride is an override of a single option within an options message,
+// such as FieldOptions or MessageOptions.
+type optionOverride struct {
+	selector string
+	// Exactly one of fieldDescriptor or extensionName is set.
+	//
+	// The fieldDescriptor and value are set for the standard options, and
+	// are resolved when the override is constructed.
+	fieldDescriptor protoreflect.FieldDescriptor
+	value           protoreflect.Value
+	// The extensionName and rawValue are set for custom options, and
+	// are resolved against the Image when the override is applied.
+	extensionName protoreflect.FullName
+	rawValue      string
+}
+
+func newOptionOverride(
+	optionsDescriptor protoreflect.MessageDescriptor,
+	selector string,
+	option string,
+	value string,
+) (*optionOverride, error) {
+	selector = strings.TrimPrefix(selector, ".")
+	if strings.HasPrefix(option, "(") {
+		if !strings.HasSuffix(option, ")") {
+			return nil, fmt.Errorf("invalid custom option %q: only options of the form (full.name) are supported", option)
+		}
+		extensionName := protoreflect.FullName(strings.TrimPrefix(strings.TrimSuffix(option[1:], ")"), "."))
+		if !extensionName.IsValid() {
+			return nil, fmt.Errorf("invalid custom option %q", option)
+		}
+		return &optionOverride{
+			selector:      selector,
+			extensionName: extensionName,
+			rawValue:      value,
+		}, nil
+	}
+	fieldDescriptor := optionsDescriptor.Fields().ByName(protoreflect.Name(option))
+	if fieldDescriptor == nil {
+		return nil, fmt.Errorf("unknown option %q for %s", option, optionsDescriptor.FullName())
+	}
+	optionValue, err := parseOptionValue(fieldDescriptor, value)
+	if err != nil {
+		return nil, err
+	}
+	return &optionOverride{
+		selector:        selector,
+		fieldDescriptor: fieldDescriptor,
+		value:           optionValue,
+	}, nil
+}
+
+// matches returns true if the selector of the override matches the fully-qualified name.
+//
+// The selector matches the name itself, as well as all of the names nested within it.
+// An empty selector matches all names.
+func (o *optionOverride) matches(fullName string) bool {
+	return o.selector == "" || fullName == o.selector || strings.HasPrefix(fullName, o.selector+".")
+}
+
+// apply sets the option on the options message, and returns the number of the option.
+func (o *optionOverride) apply(
+	resolver *extensionResolver,
+	options protoreflect.Message,
+) (int32, error) {
+	if o.fieldDescriptor != nil {
+		if options.Has(o.fieldDescriptor) && optionValueIsEqual(options.Get(o.fieldDescriptor), o.value) {
+			// The option is already set to the same value, don't do anything.
+			return 0, nil
+		}
+		options.Set(o.fieldDescriptor, o.value)
+		return int32(o.fieldDescriptor.Number()), nil
+	}
+	extensionType, err := resolver.FindExtension(options.Descriptor().FullName(), o.extensionName)
+	if err != nil {
+		return 0, err
+	}
+	extensionDescriptor := extensionType.TypeDescriptor()
+	value, err := parseOptionValue(extensionDescriptor, o.rawValue)
+	if err != nil {
+		return 0, err
+	}
+	// Custom options are stored as unknown fields, as the extensions
+	// are not known to the Go runtime. The extension is encoded on its
+	// own, and replaces all of the existing occurrences of the option.
+	extensionOptions := dynamicpb.NewMessage(options.Descriptor())
+	extensionOptions.Set(extensionDescriptor, value)
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(extensionOptions)
+	if err != nil {
+		return 0, err
+	}
+	number := extensionDescriptor.Number()
+	unknown := removeUnknownFields(options.GetUnknown(), number)
+	options.SetUnknown(append(unknown, data...))
+	return int32(number), nil
+}
+
+// extensionResolver resolves the custom options defined within an Image.
+type extensionResolver struct {
+	image bufimage.Image
+	files *protoregistry.Files
+}
+
+func newExtensionResolver(image bufimage.Image) *extensionResolver {
+	return &extensionResolver{
+		image: image,
+	}
+}
+
+// FindExtension returns the extension with the given name, which
+// must extend the options message with the given name.
+func (r *extensionResolver) FindExtension(
+	optionsName protoreflect.FullName,
+	extensionName protoreflect.FullName,
+) (protoreflect.ExtensionType, error) {
+	if r.files == nil {
+		files, err := protodesc.NewFiles(bufimage.ImageToFileDescriptorSet(r.image))
+		if err != nil {
+			return nil, err
+		}
+		r.files = files
+	}
+	descriptor, err := r.files.FindDescriptorByName(extensionName)
+	if err != nil {
+		return nil, fmt.Errorf("custom option (%s) was not found: %w", extensionName, err)
+	}
+	extensionDescriptor, ok := descriptor.(protoreflect.ExtensionDescriptor)
+	if !ok || !extensionDescriptor.IsExtension() {
+		return nil, fmt.Errorf("custom option (%s) is not an extension", extensionName)
+	}
+	if extendee := extensionDescriptor.ContainingMessage().FullName(); extendee != optionsName {
+		return nil, fmt.Errorf("custom option (%s) extends %s, not %s", extensionName, extendee, optionsName)
+	}
+	return dynamicpb.NewExtensionType(extensionDescriptor), nil
+}
+
+// parseOptionValue parses the value of the option according to its kind.
+//
+// Only singular, scalar and enum options are supported.
+func parseOptionValue(fieldDescriptor protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	if fieldDescriptor.Cardinality() == protoreflect.Repeated {
+		return protoreflect.Value{}, fmt.Errorf("repeated option %s is not supported", fieldDescriptor.Name())
+	}
+	var (
+		optionValue protoreflect.Value
+		err         error
+	)
+	switch fieldDescriptor.Kind() {
+	case protoreflect.BoolKind:
+		var parsed bool
+		parsed, err = strconv.ParseBool(value)
+		optionValue = protoreflect.ValueOfBool(parsed)
+	case protoreflect.EnumKind:
+		enumValue := fieldDescriptor.Enum().Values().ByName(protoreflect.Name(value))
+		if enumValue == nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid value %q for option %s: not a value of %s", value, fieldDescriptor.Name(), fieldDescriptor.Enum().FullName())
+		}
+		optionValue = protoreflect.ValueOfEnum(enumValue.Number())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var parsed int64
+		parsed, err = strconv.ParseInt(value, 10, 32)
+		optionValue = protoreflect.ValueOfInt32(int32(parsed))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var parsed int64
+		parsed, err = strconv.ParseInt(value, 10, 64)
+		optionValue = protoreflect.ValueOfInt64(parsed)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var parsed uint64
+		parsed, err = strconv.ParseUint(value, 10, 32)
+		optionValue = protoreflect.ValueOfUint32(uint32(parsed))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var parsed uint64
+		parsed, err = strconv.ParseUint(value, 10, 64)
+		optionValue = protoreflect.ValueOfUint64(parsed)
+	case protoreflect.FloatKind:
+		var parsed float64
+		parsed, err = strconv.ParseFloat(value, 32)
+		optionValue = protoreflect.ValueOfFloat32(float32(parsed))
+	case protoreflect.DoubleKind:
+		var parsed float64
+		parsed, err = strconv.ParseFloat(value, 64)
+		optionValue = protoreflect.ValueOfFloat64(parsed)
+	case protoreflect.StringKind:
+		optionValue = protoreflect.ValueOfString(value)
+	case protoreflect.BytesKind:
+		optionValue = protoreflect.ValueOfBytes([]byte(value))
+	default:
+		return protoreflect.Value{}, fmt.Errorf("option %s of kind %v is not supported", fieldDescriptor.Name(), fieldDescriptor.Kind())
+	}
+	if err != nil {
+		return protoreflect.Value{}, fmt.Errorf("invalid value %q for option %s: %w", value, fieldDescriptor.Name(), err)
+	}
+	return optionValue, nil
+}
+
+// optionValueIsEqual returns true if the scalar or enum values are equal.
+func optionValueIsEqual(x protoreflect.Value, y protoreflect.Value) bool {
+	if xBytes, ok := x.Interface().([]byte); ok {
+		yBytes, ok := y.Interface().([]byte)
+		return ok && bytes.Equal(xBytes, yBytes)
+	}
+	return x.Interface() == y.Interface()
+}
+
+// removeUnknownFields returns the unknown fields without the fields with the given number.
+func removeUnknownFields(unknown protoreflect.RawFields, number protoreflect.FieldNumber) protoreflect.RawFields {
+	var result protoreflect.RawFields
+	for len(unknown) > 0 {
+		fieldNumber, _, length := protowire.ConsumeField(unknown)
+		if length < 0 {
+			// The unknown fields are malformed, leave them as-is.
+			return append(result, unknown...)
+		}
+		if fieldNumber != number {
+			result = append(result, unknown[:length]...)
+		}
+		unknown = unknown[length:]
+	}
+	return result
+}
+
+// forEachMessage calls f for each of the messages within the file, including
+// the nested messages, along with their fully-qualified names and
+// SourceCodeInfo paths.
+func forEachMessage(
+	descriptor *descriptorpb.FileDescriptorProto,
+	f func(*descriptorpb.DescriptorProto, string, []int32) error,
+) error {
+	return forEachMessageInMessages(
+		descriptor.GetMessageType(),
+		descriptor.GetPackage(),
+		[]int32{4},
+		f,
+	)
+}
+
+func forEachMessageInMessages(
+	messages []*descriptorpb.DescriptorProto,
+	scope string,
+	path []int32,
+	f func(*descriptorpb.DescriptorProto, string, []int32) error,
+) error {
+	for i, message := range messages {
+		fullName := getFullName(scope, message.GetName())
+		messagePath := getChildPath(path, int32(i))
+		if err := f(message, fullName, messagePath); err != nil {
+			return err
+		}
+		if err := forEachMessageInMessages(
+			message.GetNestedType(),
+			fullName,
+			getChildPath(messagePath, 3),
+			f,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachField calls f for each of the fields and extensions within the file,
+// including those within nested messages, along with their fully-qualified
+// names and SourceCodeInfo paths.
+func forEachField(
+	descriptor *descriptorpb.FileDescriptorProto,
+	f func(*descriptorpb.FieldDescriptorProto, string, []int32) error,
+) error {
+	if err := forEachFieldInFields(
+		descriptor.GetExtension(),
+		descriptor.GetPackage(),
+		[]int32{7},
+		f,
+	); err != nil {
+		return err
+	}
+	return forEachMessage(
+		descriptor,
+		func(message *descriptorpb.DescriptorProto, fullName string, path []int32) error {
+			if err := forEachFieldInFields(message.GetField(), fullName, getChildPath(path, 2), f); err != nil {
+				return err
+			}
+			return forEachFieldInFields(message.GetExtension(), fullName, getChildPath(path, 6), f)
+		},
+	)
+}
+
+func forEachFieldInFields(
+	fields []*descriptorpb.FieldDescriptorProto,
+	scope string,
+	path []int32,
+	f func(*descriptorpb.FieldDescriptorProto, string, []int32) error,
+) error {
+	for i, field := range fields {
+		if err := f(field, getFullName(scope, field.GetName()), getChildPath(path, int32(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getFullName(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// getChildPath returns a new path with the elements appended, so
+// that the returned path never shares memory with the given path.
+func getChildPath(path []int32, elems ...int32) []int32 {
+	childPath := make([]int32, 0, len(path)+len(elems))
+	childPath = append(childPath, path...)
+	return append(childPath, elems...)
+}
@@ -0,0 +1,278 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFieldOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "optionoverrides")
+	image := testGetImage(t, dirPath, true)
+
+	sweeper := NewFileOptionSweeper()
+	modifier, err := FieldOptions(
+		zap.NewNop(),
+		sweeper,
+		[]FieldOptionOverride{
+			{
+				Selector: "acme.weather.v1",
+				Types: []descriptorpb.FieldDescriptorProto_Type{
+					descriptorpb.FieldDescriptorProto_TYPE_INT64,
+					descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+				},
+				Option: "jstype",
+				Value:  "JS_STRING",
+			},
+			{
+				Selector: "acme.weather.v1.Weather.name",
+				Option:   "(acme.option.v1.redacted)",
+				Value:    "true",
+			},
+		},
+	)
+	require.NoError(t, err)
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	require.NoError(t, sweeper.Sweep(context.Background(), image))
+
+	imageFile := image.GetFile("a.proto")
+	require.NotNil(t, imageFile)
+	message := imageFile.Proto().GetMessageType()[0]
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, message.GetField()[0].GetOptions().GetJstype())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, message.GetNestedType()[0].GetField()[0].GetOptions().GetJstype())
+	assert.Nil(t, message.GetField()[1].GetOptions().Jstype)
+	assert.Equal(t, []uint64{1}, testGetUnknownVarints(message.GetField()[1].GetOptions(), 50000))
+	// The existing custom option is left as-is.
+	assert.Equal(t, []uint64{0}, testGetUnknownVarints(message.GetField()[0].GetOptions(), 50000))
+
+	// The compact options of the id field still contain the custom
+	// option, so only the jstype location is swept.
+	assertSourceCodeInfoPathsNotExist(t, image, []int32{4, 0, 2, 0, 8, 6})
+	assertSourceCodeInfoPathsExist(
+		t,
+		image,
+		[]int32{4, 0, 2, 0, 8},
+		[]int32{4, 0, 2, 0, 8, 50000},
+	)
+}
+
+func TestFieldOptionsCustomOptionOverride(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "optionoverrides")
+	image := testGetImage(t, dirPath, true)
+
+	sweeper := NewFileOptionSweeper()
+	modifier, err := FieldOptions(
+		zap.NewNop(),
+		sweeper,
+		[]FieldOptionOverride{
+			{
+				Selector: "acme.weather.v1.Weather.id",
+				Option:   "(acme.option.v1.redacted)",
+				Value:    "true",
+			},
+		},
+	)
+	require.NoError(t, err)
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	require.NoError(t, sweeper.Sweep(context.Background(), image))
+
+	message := image.GetFile("a.proto").Proto().GetMessageType()[0]
+	// The existing value is replaced rather than repeated.
+	assert.Equal(t, []uint64{1}, testGetUnknownVarints(message.GetField()[0].GetOptions(), 50000))
+	assert.Equal(t, descriptorpb.FieldOptions_JS_NUMBER, message.GetField()[0].GetOptions().GetJstype())
+	assertSourceCodeInfoPathsNotExist(t, image, []int32{4, 0, 2, 0, 8, 50000})
+	assertSourceCodeInfoPathsExist(
+		t,
+		image,
+		[]int32{4, 0, 2, 0, 8},
+		[]int32{4, 0, 2, 0, 8, 6},
+	)
+}
+
+func TestFieldOptionsError(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "optionoverrides")
+
+	t.Run("unknown option", func(t *testing.T) {
+		t.Parallel()
+		_, err := FieldOptions(
+			zap.NewNop(),
+			nil,
+			[]FieldOptionOverride{{Option: "js_type", Value: "JS_STRING"}},
+		)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid enum value", func(t *testing.T) {
+		t.Parallel()
+		_, err := FieldOptions(
+			zap.NewNop(),
+			nil,
+			[]FieldOptionOverride{{Option: "jstype", Value: "JS_BIGINT"}},
+		)
+		require.Error(t, err)
+	})
+
+	t.Run("repeated option", func(t *testing.T) {
+		t.Parallel()
+		_, err := FieldOptions(
+			zap.NewNop(),
+			nil,
+			[]FieldOptionOverride{{Option: "uninterpreted_option", Value: "foo"}},
+		)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown custom option", func(t *testing.T) {
+		t.Parallel()
+		modifier, err := FieldOptions(
+			zap.NewNop(),
+			nil,
+			[]FieldOptionOverride{{Option: "(acme.option.v1.unknown)", Value: "true"}},
+		)
+		require.NoError(t, err)
+		err = modifier.Modify(context.Background(), testGetImage(t, dirPath, false))
+		require.Error(t, err)
+	})
+
+	t.Run("custom option of wrong extendee", func(t *testing.T) {
+		t.Parallel()
+		modifier, err := FieldOptions(
+			zap.NewNop(),
+			nil,
+			[]FieldOptionOverride{{Option: "(acme.option.v1.owner)", Value: "weather-team"}},
+		)
+		require.NoError(t, err)
+		err = modifier.Modify(context.Background(), testGetImage(t, dirPath, false))
+		require.Error(t, err)
+	})
+}
+
+func TestMessageOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "optionoverrides")
+	image := testGetImage(t, dirPath, true)
+
+	sweeper := NewFileOptionSweeper()
+	modifier, err := MessageOptions(
+		zap.NewNop(),
+		sweeper,
+		[]MessageOptionOverride{
+			{
+				Selector: "acme.weather.v1.Weather",
+				Option:   "deprecated",
+				Value:    "true",
+			},
+			{
+				Selector: "acme.weather.v1.Weather.Station",
+				Option:   "(acme.option.v1.owner)",
+				Value:    "weather-team",
+			},
+		},
+	)
+	require.NoError(t, err)
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	require.NoError(t, sweeper.Sweep(context.Background(), image))
+
+	message := image.GetFile("a.proto").Proto().GetMessageType()[0]
+	assert.True(t, message.GetOptions().GetDeprecated())
+	nestedMessage := message.GetNestedType()[0]
+	assert.True(t, nestedMessage.GetOptions().GetDeprecated())
+	assert.Equal(t, protoreflect.RawFields(protowire.AppendString(protowire.AppendTag(nil, 50001, protowire.BytesType), "weather-team")), nestedMessage.GetOptions().ProtoReflect().GetUnknown())
+	assert.Empty(t, testGetUnknownVarints(message.GetOptions(), 50001))
+
+	// The deprecated option is declared on its own, so its parent is swept as well.
+	assertSourceCodeInfoPathsNotExist(
+		t,
+		image,
+		[]int32{4, 0, 7},
+		[]int32{4, 0, 7, 3},
+	)
+	assertSourceCodeInfoPathsExist(t, image, []int32{4, 0}, []int32{4, 0, 2, 0})
+}
+
+func TestMessageOptionsWithoutSourceCodeInfo(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "optionoverrides")
+	image := testGetImage(t, dirPath, false)
+
+	sweeper := NewFileOptionSweeper()
+	modifier, err := MessageOptions(
+		zap.NewNop(),
+		sweeper,
+		[]MessageOptionOverride{
+			{
+				Option: "deprecated",
+				Value:  "true",
+			},
+		},
+	)
+	require.NoError(t, err)
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	require.NoError(t, sweeper.Sweep(context.Background(), image))
+	for _, imageFile := range image.Files() {
+		assert.Empty(t, imageFile.Proto().SourceCodeInfo)
+	}
+	assert.True(t, image.GetFile("a.proto").Proto().GetMessageType()[0].GetOptions().GetDeprecated())
+	// The well-known types are never modified.
+	for _, message := range image.GetFile("google/protobuf/descriptor.proto").Proto().GetMessageType() {
+		assert.False(t, message.GetOptions().GetDeprecated())
+	}
+}
+
+// testGetUnknownVarints returns the values of the unknown varint fields with the given number.
+func testGetUnknownVarints(options interface{ ProtoReflect() protoreflect.Message }, number protowire.Number) []uint64 {
+	var values []uint64
+	unknown := options.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		fieldNumber, fieldType, length := protowire.ConsumeField(unknown)
+		if length < 0 {
+			return nil
+		}
+		if fieldNumber == number && fieldType == protowire.VarintType {
+			value, _ := protowire.ConsumeVarint(unknown[protowire.SizeTag(fieldNumber):])
+			values = append(values, value)
+		}
+		unknown = unknown[length:]
+	}
+	return values
+}
@@ -0,0 +1,124 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// PhpClassPrefixID is the ID of the php_class_prefix modifier.
+const PhpClassPrefixID = "PHP_CLASS_PREFIX"
+
+// phpClassPrefixPath is the SourceCodeInfo path for the php_class_prefix option.
+// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L438
+var phpClassPrefixPath = []int32{8, 40}
+
+func phpClassPrefix(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultValue string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	overrides map[string]string,
+) (Modifier, error) {
+	if defaultValue == "" {
+		return nil, fmt.Errorf("a non-empty php_class_prefix is required")
+	}
+	// Convert the bufmoduleref.ModuleIdentity types into
+	// strings so that they're comparable.
+	exceptModuleIdentityStrings := make(map[string]struct{}, len(except))
+	for _, moduleIdentity := range except {
+		exceptModuleIdentityStrings[moduleIdentity.IdentityString()] = struct{}{}
+	}
+	overrideModuleIdentityStrings := make(map[string]string, len(moduleOverrides))
+	for moduleIdentity, phpClassPrefixValue := range moduleOverrides {
+		overrideModuleIdentityStrings[moduleIdentity.IdentityString()] = phpClassPrefixValue
+	}
+	seenModuleIdentityStrings := make(map[string]struct{}, len(overrideModuleIdentityStrings))
+	seenOverrideFiles := make(map[string]struct{}, len(overrides))
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			for _, imageFile := range image.Files() {
+				phpClassPrefixValue := defaultValue
+				if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+					moduleIdentityString := moduleIdentity.IdentityString()
+					if moduleOverrideValue, ok := overrideModuleIdentityStrings[moduleIdentityString]; ok {
+						phpClassPrefixValue = moduleOverrideValue
+						seenModuleIdentityStrings[moduleIdentityString] = struct{}{}
+					}
+				}
+				if overrideValue, ok := overrides[imageFile.Path()]; ok {
+					phpClassPrefixValue = overrideValue
+					seenOverrideFiles[imageFile.Path()] = struct{}{}
+				}
+				if err := phpClassPrefixForFile(
+					ctx,
+					sweeper,
+					imageFile,
+					phpClassPrefixValue,
+					exceptModuleIdentityStrings,
+				); err != nil {
+					return err
+				}
+			}
+			for moduleIdentityString := range overrideModuleIdentityStrings {
+				if _, ok := seenModuleIdentityStrings[moduleIdentityString]; !ok {
+					logger.Sugar().Warnf("php_class_prefix override for %q was unused", moduleIdentityString)
+				}
+			}
+			for overrideFile := range overrides {
+				if _, ok := seenOverrideFiles[overrideFile]; !ok {
+					logger.Sugar().Warnf("%s override for %q was unused", PhpClassPrefixID, overrideFile)
+				}
+			}
+			return nil
+		},
+	), nil
+}
+
+func phpClassPrefixForFile(
+	ctx context.Context,
+	sweeper Sweeper,
+	imageFile bufimage.ImageFile,
+	phpClassPrefixValue string,
+	exceptModuleIdentityStrings map[string]struct{},
+) error {
+	if isWellKnownType(ctx, imageFile) || phpClassPrefixValue == "" {
+		// This is a well-known type or the value was overridden to be
+		// empty, so this is a no-op.
+		return nil
+	}
+	if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+		if _, ok := exceptModuleIdentityStrings[moduleIdentity.IdentityString()]; ok {
+			return nil
+		}
+	}
+	descriptor := imageFile.Proto()
+	if descriptor.Options == nil {
+		descriptor.Options = &descriptorpb.FileOptions{}
+	}
+	descriptor.Options.PhpClassPrefix = proto.String(phpClassPrefixValue)
+	if sweeper != nil {
+		sweeper.mark(imageFile.Path(), phpClassPrefixPath)
+	}
+	return nil
+}
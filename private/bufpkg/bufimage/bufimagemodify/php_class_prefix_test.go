@@ -0,0 +1,156 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPhpClassPrefixError(t *testing.T) {
+	t.Parallel()
+	_, err := PhpClassPrefix(zap.NewNop(), NewFileOptionSweeper(), "", nil, nil, nil)
+	require.Error(t, err)
+}
+
+func TestPhpClassPrefixEmptyOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "emptyoptions")
+	t.Run("with SourceCodeInfo", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoEmpty(t, image, phpClassPrefixPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		phpClassPrefixModifier, err := PhpClassPrefix(zap.NewNop(), sweeper, "ACME", nil, nil, nil)
+		require.NoError(t, err)
+		modifier := NewMultiModifier(phpClassPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		for _, imageFile := range image.Files() {
+			assert.Equal(t, "ACME", imageFile.Proto().GetOptions().GetPhpClassPrefix())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, phpClassPrefixPath, true)
+	})
+
+	t.Run("without SourceCodeInfo and with per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+		assertFileOptionSourceCodeInfoEmpty(t, image, phpClassPrefixPath, false)
+
+		sweeper := NewFileOptionSweeper()
+		modifier, err := PhpClassPrefix(zap.NewNop(), sweeper, "ACME", nil, nil, map[string]string{"a.proto": "OVERRIDE"})
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		for _, imageFile := range image.Files() {
+			assert.Equal(t, "OVERRIDE", imageFile.Proto().GetOptions().GetPhpClassPrefix())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, phpClassPrefixPath, false)
+	})
+}
+
+func TestPhpClassPrefixAllOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	image := testGetImage(t, dirPath, true)
+	assertFileOptionSourceCodeInfoNotEmpty(t, image, phpClassPrefixPath)
+
+	sweeper := NewFileOptionSweeper()
+	phpClassPrefixModifier, err := PhpClassPrefix(zap.NewNop(), sweeper, "ACME", nil, nil, nil)
+	require.NoError(t, err)
+	modifier := NewMultiModifier(phpClassPrefixModifier, ModifierFunc(sweeper.Sweep))
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	for _, imageFile := range image.Files() {
+		assert.Equal(t, "ACME", imageFile.Proto().GetOptions().GetPhpClassPrefix())
+	}
+	assertFileOptionSourceCodeInfoEmpty(t, image, phpClassPrefixPath, true)
+}
+
+func TestPhpClassPrefixWithExceptAndOverride(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	testModuleIdentity, err := bufmoduleref.NewModuleIdentity(
+		testRemote,
+		testRepositoryOwner,
+		testRepositoryName,
+	)
+	require.NoError(t, err)
+
+	t.Run("with except", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		sweeper := NewFileOptionSweeper()
+		phpClassPrefixModifier, err := PhpClassPrefix(
+			zap.NewNop(),
+			sweeper,
+			"ACME",
+			[]bufmoduleref.ModuleIdentity{testModuleIdentity},
+			nil,
+			nil,
+		)
+		require.NoError(t, err)
+		modifier := NewMultiModifier(phpClassPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("with override", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		sweeper := NewFileOptionSweeper()
+		phpClassPrefixModifier, err := PhpClassPrefix(
+			zap.NewNop(),
+			sweeper,
+			"ACME",
+			nil,
+			map[bufmoduleref.ModuleIdentity]string{
+				testModuleIdentity: "MODULE",
+			},
+			nil,
+		)
+		require.NoError(t, err)
+		modifier := NewMultiModifier(phpClassPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		for _, imageFile := range image.Files() {
+			assert.Equal(t, "MODULE", imageFile.Proto().GetOptions().GetPhpClassPrefix())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, phpClassPrefixPath, true)
+	})
+}
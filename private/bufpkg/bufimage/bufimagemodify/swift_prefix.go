@@ -0,0 +1,124 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// SwiftPrefixID is the ID of the swift_prefix modifier.
+const SwiftPrefixID = "SWIFT_PREFIX"
+
+// swiftPrefixPath is the SourceCodeInfo path for the swift_prefix option.
+// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L434
+var swiftPrefixPath = []int32{8, 39}
+
+func swiftPrefix(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultValue string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	overrides map[string]string,
+) (Modifier, error) {
+	if defaultValue == "" {
+		return nil, fmt.Errorf("a non-empty swift_prefix is required")
+	}
+	// Convert the bufmoduleref.ModuleIdentity types into
+	// strings so that they're comparable.
+	exceptModuleIdentityStrings := make(map[string]struct{}, len(except))
+	for _, moduleIdentity := range except {
+		exceptModuleIdentityStrings[moduleIdentity.IdentityString()] = struct{}{}
+	}
+	overrideModuleIdentityStrings := make(map[string]string, len(moduleOverrides))
+	for moduleIdentity, swiftPrefixValue := range moduleOverrides {
+		overrideModuleIdentityStrings[moduleIdentity.IdentityString()] = swiftPrefixValue
+	}
+	seenModuleIdentityStrings := make(map[string]struct{}, len(overrideModuleIdentityStrings))
+	seenOverrideFiles := make(map[string]struct{}, len(overrides))
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			for _, imageFile := range image.Files() {
+				swiftPrefixValue := defaultValue
+				if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+					moduleIdentityString := moduleIdentity.IdentityString()
+					if moduleOverrideValue, ok := overrideModuleIdentityStrings[moduleIdentityString]; ok {
+						swiftPrefixValue = moduleOverrideValue
+						seenModuleIdentityStrings[moduleIdentityString] = struct{}{}
+					}
+				}
+				if overrideValue, ok := overrides[imageFile.Path()]; ok {
+					swiftPrefixValue = overrideValue
+					seenOverrideFiles[imageFile.Path()] = struct{}{}
+				}
+				if err := swiftPrefixForFile(
+					ctx,
+					sweeper,
+					imageFile,
+					swiftPrefixValue,
+					exceptModuleIdentityStrings,
+				); err != nil {
+					return err
+				}
+			}
+			for moduleIdentityString := range overrideModuleIdentityStrings {
+				if _, ok := seenModuleIdentityStrings[moduleIdentityString]; !ok {
+					logger.Sugar().Warnf("swift_prefix override for %q was unused", moduleIdentityString)
+				}
+			}
+			for overrideFile := range overrides {
+				if _, ok := seenOverrideFiles[overrideFile]; !ok {
+					logger.Sugar().Warnf("%s override for %q was unused", SwiftPrefixID, overrideFile)
+				}
+			}
+			return nil
+		},
+	), nil
+}
+
+func swiftPrefixForFile(
+	ctx context.Context,
+	sweeper Sweeper,
+	imageFile bufimage.ImageFile,
+	swiftPrefixValue string,
+	exceptModuleIdentityStrings map[string]struct{},
+) error {
+	if isWellKnownType(ctx, imageFile) || swiftPrefixValue == "" {
+		// This is a well-known type or the value was overridden to be
+		// empty, so this is a no-op.
+		return nil
+	}
+	if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+		if _, ok := exceptModuleIdentityStrings[moduleIdentity.IdentityString()]; ok {
+			return nil
+		}
+	}
+	descriptor := imageFile.Proto()
+	if descriptor.Options == nil {
+		descriptor.Options = &descriptorpb.FileOptions{}
+	}
+	descriptor.Options.SwiftPrefix = proto.String(swiftPrefixValue)
+	if sweeper != nil {
+		sweeper.mark(imageFile.Path(), swiftPrefixPath)
+	}
+	return nil
+}
@@ -137,3 +137,65 @@ func TestMergeImagesOrdered(t *testing.T) {
 	}
 	assert.Equal(t, []string{"a.proto", "b.proto", "c.proto", "d.proto"}, paths)
 }
+
+func TestImageByPackageAndFile(t *testing.T) {
+	t.Parallel()
+	protoImage := &imagev1.Image{
+		File: []*imagev1.ImageFile{
+			{
+				Syntax:  proto.String("proto3"),
+				Name:    proto.String("a/a.proto"),
+				Package: proto.String("b"),
+			},
+			{
+				Syntax:     proto.String("proto3"),
+				Name:       proto.String("a/b.proto"),
+				Package:    proto.String("a"),
+				Dependency: []string{"a/a.proto"},
+			},
+			{
+				Syntax:  proto.String("proto3"),
+				Name:    proto.String("b/a.proto"),
+				Package: proto.String("b"),
+			},
+		},
+	}
+	image, err := NewImageForProto(protoImage)
+	require.NoError(t, err)
+
+	imagesByPackage, err := ImageByPackage(image)
+	require.NoError(t, err)
+	require.Len(t, imagesByPackage, 2)
+	assert.Equal(t, []string{"a/a.proto"}, testGetImportPaths(imagesByPackage[0]))
+	assert.Equal(t, []string{"a/b.proto"}, testGetNonImportPaths(imagesByPackage[0]))
+	assert.Empty(t, testGetImportPaths(imagesByPackage[1]))
+	assert.Equal(t, []string{"a/a.proto", "b/a.proto"}, testGetNonImportPaths(imagesByPackage[1]))
+
+	imagesByFile, err := ImageByFile(image)
+	require.NoError(t, err)
+	require.Len(t, imagesByFile, 3)
+	assert.Equal(t, []string{"a/a.proto"}, testGetNonImportPaths(imagesByFile[0]))
+	assert.Equal(t, []string{"a/a.proto"}, testGetImportPaths(imagesByFile[1]))
+	assert.Equal(t, []string{"a/b.proto"}, testGetNonImportPaths(imagesByFile[1]))
+	assert.Equal(t, []string{"b/a.proto"}, testGetNonImportPaths(imagesByFile[2]))
+}
+
+func testGetNonImportPaths(image Image) []string {
+	var paths []string
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() {
+			paths = append(paths, imageFile.Path())
+		}
+	}
+	return paths
+}
+
+func testGetImportPaths(image Image) []string {
+	var paths []string
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			paths = append(paths, imageFile.Path())
+		}
+	}
+	return paths
+}
@@ -17,6 +17,7 @@ package bufimage
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/gen/data/datawkt"
@@ -446,3 +447,23 @@ func isFileToGenerate(
 	}
 	return true
 }
+
+// imagesByKey returns an image for each key, sorted by key, that only
+// has the paths of the key as non-imports.
+func imagesByKey(image Image, keyToPaths map[string][]string) ([]Image, error) {
+	// we need this to produce a deterministic order of the returned Images
+	keys := make([]string, 0, len(keyToPaths))
+	for key := range keyToPaths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	newImages := make([]Image, 0, len(keyToPaths))
+	for _, key := range keys {
+		newImage, err := ImageWithOnlyPaths(image, keyToPaths[key], nil)
+		if err != nil {
+			return nil, err
+		}
+		newImages = append(newImages, newImage)
+	}
+	return newImages, nil
+}
@@ -44,6 +44,7 @@ type Dependency struct {
 	Owner      string
 	Repository string
 	Commit     string
+	Digest     string
 }
 
 // ReadConfig reads the lock file at ExternalConfigFilePath relative
@@ -88,6 +89,7 @@ func DependencyForExternalConfigDependencyV1(dep ExternalConfigDependencyV1) Dep
 		Owner:      dep.Owner,
 		Repository: dep.Repository,
 		Commit:     dep.Commit,
+		Digest:     dep.Digest,
 	}
 }
 
@@ -100,6 +102,7 @@ func ExternalConfigDependencyV1ForDependency(dep Dependency) ExternalConfigDepen
 		Owner:      dep.Owner,
 		Repository: dep.Repository,
 		Commit:     dep.Commit,
+		Digest:     dep.Digest,
 	}
 }
 
@@ -122,6 +125,7 @@ func DependencyForExternalConfigDependencyV1Beta1(dep ExternalConfigDependencyV1
 		Owner:      dep.Owner,
 		Repository: dep.Repository,
 		Commit:     dep.Commit,
+		Digest:     dep.Digest,
 	}
 }
 
@@ -134,6 +138,7 @@ func ExternalConfigDependencyV1Beta1ForDependency(dep Dependency) ExternalConfig
 		Owner:      dep.Owner,
 		Repository: dep.Repository,
 		Commit:     dep.Commit,
+		Digest:     dep.Digest,
 	}
 }
 
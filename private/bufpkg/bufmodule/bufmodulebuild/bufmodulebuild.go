@@ -20,11 +20,45 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.uber.org/zap"
 )
 
+// VendorDirPath is the path to the directory containing vendored dependencies,
+// relative to the root of the module.
+//
+// Files within this directory are never part of the module itself.
+const VendorDirPath = "buf_vendor"
+
+// VendorPathForModulePin returns the path that the dependency for the ModulePin
+// is vendored at, relative to the root of the module.
+//
+// This is of the form buf_vendor/remote/owner/repository/commit.
+func VendorPathForModulePin(modulePin bufmoduleref.ModulePin) string {
+	return normalpath.Join(
+		VendorDirPath,
+		modulePin.Remote(),
+		modulePin.Owner(),
+		modulePin.Repository(),
+		modulePin.Commit(),
+	)
+}
+
+// ValidateModuleDigest validates that the digest of the Module for the ModulePin
+// matches the digest recorded for it in a lock file.
+//
+// Only b3 digests can be validated.
+func ValidateModuleDigest(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	module bufmodule.Module,
+	expectedDigest string,
+) error {
+	return validateModuleDigest(ctx, modulePin, module, expectedDigest)
+}
+
 // ModuleFileSetBuilder builds ModuleFileSets from Modules.
 type ModuleFileSetBuilder interface {
 	Build(
@@ -52,6 +86,18 @@ func WithWorkspace(workspace bufmodule.Workspace) BuildModuleFileSetOption {
 	}
 }
 
+// WithModuleReadBucket returns a new BuildModuleFileSetOption that specifies the
+// bucket the module was built from.
+//
+// Dependencies vendored within VendorDirPath of this bucket are used before
+// consulting the ModuleReader. Vendored dependencies must match the digest
+// recorded for them in the lock file of the bucket.
+func WithModuleReadBucket(readBucket storage.ReadBucket) BuildModuleFileSetOption {
+	return func(buildModuleFileSetOptions *buildModuleFileSetOptions) {
+		buildModuleFileSetOptions.moduleReadBucket = readBucket
+	}
+}
+
 // ModuleBucketBuilder builds modules for buckets.
 type ModuleBucketBuilder interface {
 	// BuildForBucket builds a module for the given bucket.
@@ -88,6 +88,8 @@ func (b *moduleBucketBuilder) buildForBucket(
 	for root, excludes := range config.RootToExcludes {
 		roots = append(roots, root)
 		mappers := []storage.Mapper{
+			// vendored dependencies are never part of the module
+			storage.MatchNot(storage.MatchPathContained(VendorDirPath)),
 			// need to do match extension here
 			// https://github.com/bufbuild/buf/issues/113
 			storage.MatchPathExt(".proto"),
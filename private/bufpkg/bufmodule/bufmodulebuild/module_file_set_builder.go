@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"go.uber.org/zap"
 )
 
@@ -48,6 +49,7 @@ func (m *moduleFileSetBuilder) Build(
 		ctx,
 		module,
 		buildModuleFileSetOptions.workspace,
+		buildModuleFileSetOptions.moduleReadBucket,
 	)
 }
 
@@ -55,7 +57,16 @@ func (m *moduleFileSetBuilder) build(
 	ctx context.Context,
 	module bufmodule.Module,
 	workspace bufmodule.Workspace,
+	moduleReadBucket storage.ReadBucket,
 ) (bufmodule.ModuleFileSet, error) {
+	moduleReader := m.moduleReader
+	if moduleReadBucket != nil {
+		vendorModuleReader, err := newVendorModuleReader(ctx, m.logger, moduleReadBucket, moduleReader)
+		if err != nil {
+			return nil, err
+		}
+		moduleReader = vendorModuleReader
+	}
 	var dependencyModules []bufmodule.Module
 	if workspace != nil {
 		// From the perspective of the ModuleFileSet, we include all of the files
@@ -79,7 +90,7 @@ func (m *moduleFileSetBuilder) build(
 				continue
 			}
 		}
-		dependencyModule, err := m.moduleReader.GetModule(ctx, dependencyModulePin)
+		dependencyModule, err := moduleReader.GetModule(ctx, dependencyModulePin)
 		if err != nil {
 			return nil, err
 		}
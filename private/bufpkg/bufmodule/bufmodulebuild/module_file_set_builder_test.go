@@ -0,0 +1,149 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulebuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/buflock"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBuildModuleFileSetVendor(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	dependencyModulePin, err := bufmoduleref.NewModulePin(
+		"buf.build",
+		"acme",
+		"weather",
+		"",
+		bufmoduletesting.TestCommit,
+		time.Time{},
+	)
+	require.NoError(t, err)
+	dependencyReadBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"acme/weather/v1/weather.proto": []byte(`syntax = "proto3"; package acme.weather.v1;`),
+		},
+	)
+	require.NoError(t, err)
+	dependencyModule, err := bufmodule.NewModuleForBucket(
+		ctx,
+		dependencyReadBucket,
+		bufmodule.ModuleWithModuleIdentityAndCommit(dependencyModulePin, dependencyModulePin.Commit()),
+	)
+	require.NoError(t, err)
+	digest, err := bufmodule.ModuleDigestB3(ctx, dependencyModule)
+	require.NoError(t, err)
+
+	newModuleReadWriteBucket := func(t *testing.T, digest string) storage.ReadWriteBucket {
+		readWriteBucket := storagemem.NewReadWriteBucket()
+		require.NoError(t, storage.PutPath(ctx, readWriteBucket, "a.proto", []byte(`syntax = "proto3"; import "acme/weather/v1/weather.proto";`)))
+		require.NoError(
+			t,
+			buflock.WriteConfig(
+				ctx,
+				readWriteBucket,
+				&buflock.Config{
+					Dependencies: []buflock.Dependency{
+						{
+							Remote:     dependencyModulePin.Remote(),
+							Owner:      dependencyModulePin.Owner(),
+							Repository: dependencyModulePin.Repository(),
+							Commit:     dependencyModulePin.Commit(),
+							Digest:     digest,
+						},
+					},
+				},
+			),
+		)
+		require.NoError(
+			t,
+			bufmodule.ModuleToBucket(
+				ctx,
+				dependencyModule,
+				storage.MapReadWriteBucket(readWriteBucket, storage.MapOnPrefix(VendorPathForModulePin(dependencyModulePin))),
+			),
+		)
+		return readWriteBucket
+	}
+	build := func(t *testing.T, readBucket storage.ReadBucket) (bufmodule.ModuleFileSet, error) {
+		config, err := bufmoduleconfig.NewConfigV1(bufmoduleconfig.ExternalConfigV1{})
+		require.NoError(t, err)
+		module, err := NewModuleBucketBuilder(logger).BuildForBucket(ctx, readBucket, config)
+		require.NoError(t, err)
+		// The vendored files are not part of the module itself.
+		fileInfos, err := module.SourceFileInfos(ctx)
+		require.NoError(t, err)
+		require.Len(t, fileInfos, 1)
+		require.Equal(t, "a.proto", fileInfos[0].Path())
+		return NewModuleFileSetBuilder(logger, bufmodule.NewNopModuleReader()).Build(
+			ctx,
+			module,
+			WithModuleReadBucket(readBucket),
+		)
+	}
+
+	t.Run("vendored", func(t *testing.T) {
+		t.Parallel()
+		moduleFileSet, err := build(t, newModuleReadWriteBucket(t, digest))
+		require.NoError(t, err)
+		fileInfo, err := moduleFileSet.GetModuleFile(ctx, "acme/weather/v1/weather.proto")
+		require.NoError(t, err)
+		require.NoError(t, fileInfo.Close())
+	})
+	t.Run("digest_mismatch", func(t *testing.T) {
+		t.Parallel()
+		readWriteBucket := newModuleReadWriteBucket(t, digest)
+		require.NoError(
+			t,
+			storage.PutPath(
+				ctx,
+				readWriteBucket,
+				VendorPathForModulePin(dependencyModulePin)+"/acme/weather/v1/weather.proto",
+				[]byte(`syntax = "proto3"; package acme.weather.v2;`),
+			),
+		)
+		_, err := build(t, readWriteBucket)
+		require.ErrorContains(t, err, "but expected \""+digest+"\"")
+	})
+	t.Run("no_digest", func(t *testing.T) {
+		t.Parallel()
+		_, err := build(t, newModuleReadWriteBucket(t, ""))
+		require.ErrorContains(t, err, "has no digest")
+	})
+	t.Run("unsupported_digest", func(t *testing.T) {
+		t.Parallel()
+		_, err := build(t, newModuleReadWriteBucket(t, bufmoduletesting.TestDigest))
+		require.ErrorContains(t, err, "only b3 digests are supported")
+	})
+	t.Run("not_vendored", func(t *testing.T) {
+		t.Parallel()
+		readWriteBucket := newModuleReadWriteBucket(t, digest)
+		require.NoError(t, readWriteBucket.DeleteAll(ctx, VendorDirPath))
+		_, err := build(t, readWriteBucket)
+		require.True(t, storage.IsNotExist(err))
+	})
+}
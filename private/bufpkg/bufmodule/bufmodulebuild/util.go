@@ -15,12 +15,15 @@
 package bufmodulebuild
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 )
 
@@ -59,6 +62,25 @@ func applyModulePaths(
 	return bufmodule.ModuleWithTargetPaths(module, targetPaths, excludePaths)
 }
 
+func validateModuleDigest(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	module bufmodule.Module,
+	expectedDigest string,
+) error {
+	if !strings.HasPrefix(expectedDigest, b3DigestPrefix) {
+		return fmt.Errorf("dependency %q has digest %q which cannot be verified, only b3 digests are supported", modulePin.String(), expectedDigest)
+	}
+	digest, err := bufmodule.ModuleDigestB3(ctx, module)
+	if err != nil {
+		return err
+	}
+	if digest != expectedDigest {
+		return fmt.Errorf("dependency %q has digest %q but expected %q", modulePin.String(), digest, expectedDigest)
+	}
+	return nil
+}
+
 func pathsToTargetPaths(roots []string, paths []string, pathType normalpath.PathType) ([]string, error) {
 	if len(roots) == 0 {
 		// this should never happen
@@ -105,6 +127,9 @@ func pathToTargetPath(roots []string, path string, pathType normalpath.PathType)
 	}
 }
 
+// b3DigestPrefix is the prefix of digests produced by bufmodule.ModuleDigestB3.
+const b3DigestPrefix = "b3-"
+
 type buildOptions struct {
 	moduleIdentity bufmoduleref.ModuleIdentity
 	// If nil, all files are considered targets.
@@ -117,5 +142,6 @@ type buildOptions struct {
 }
 
 type buildModuleFileSetOptions struct {
-	workspace bufmodule.Workspace
+	workspace        bufmodule.Workspace
+	moduleReadBucket storage.ReadBucket
 }
@@ -0,0 +1,110 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulebuild
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/buflock"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// vendorModuleReader reads dependencies vendored within a module, and
+// falls back to the delegate for dependencies that were not vendored.
+type vendorModuleReader struct {
+	logger           *zap.Logger
+	moduleReadBucket storage.ReadBucket
+	delegate         bufmodule.ModuleReader
+	// identity:commit -> digest
+	digests map[string]string
+}
+
+func newVendorModuleReader(
+	ctx context.Context,
+	logger *zap.Logger,
+	moduleReadBucket storage.ReadBucket,
+	delegate bufmodule.ModuleReader,
+) (*vendorModuleReader, error) {
+	lockConfig, err := buflock.ReadConfig(ctx, moduleReadBucket)
+	if err != nil {
+		return nil, err
+	}
+	digests := make(map[string]string, len(lockConfig.Dependencies))
+	for _, dependency := range lockConfig.Dependencies {
+		modulePin, err := bufmoduleref.NewModulePin(
+			dependency.Remote,
+			dependency.Owner,
+			dependency.Repository,
+			"",
+			dependency.Commit,
+			time.Time{},
+		)
+		if err != nil {
+			return nil, err
+		}
+		digests[modulePin.String()] = dependency.Digest
+	}
+	return &vendorModuleReader{
+		logger:           logger,
+		moduleReadBucket: moduleReadBucket,
+		delegate:         delegate,
+		digests:          digests,
+	}, nil
+}
+
+func (v *vendorModuleReader) GetModule(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+) (bufmodule.Module, error) {
+	vendorPath := VendorPathForModulePin(modulePin)
+	vendorReadBucket := storage.MapReadBucket(
+		v.moduleReadBucket,
+		storage.MapOnPrefix(vendorPath),
+	)
+	exists, err := storage.Exists(ctx, vendorReadBucket, buflock.ExternalConfigFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return v.delegate.GetModule(ctx, modulePin)
+	}
+	expectedDigest := v.digests[modulePin.String()]
+	if expectedDigest == "" {
+		return nil, fmt.Errorf(
+			"dependency %q is vendored in %s but %s has no digest for it, run \"buf mod vendor --record-missing-digests\" to record it",
+			modulePin.String(),
+			VendorDirPath,
+			buflock.ExternalConfigFilePath,
+		)
+	}
+	module, err := bufmodule.NewModuleForBucket(
+		ctx,
+		vendorReadBucket,
+		bufmodule.ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateModuleDigest(ctx, modulePin, module, expectedDigest); err != nil {
+		return nil, fmt.Errorf("%s: %w", vendorPath, err)
+	}
+	v.logger.Debug("vendored_module", zap.String("module_pin", modulePin.String()))
+	return module, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufmodulelocal provides a registry of modules within a local directory.
+//
+// Modules with a remote of the form file:///path/to/registry are read from the
+// directory /path/to/registry without any network access. The directory uses the
+// same layout as the module cache:
+//
+//	owner/repository/commit/...
+//
+// where each commit directory contains the files of the module, including its
+// buf.yaml and buf.lock files. References that are not commits are resolved with
+// files of the form:
+//
+//	owner/repository/refs/reference
+//
+// that contain the commit the reference points to.
+package bufmodulelocal
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+)
+
+// RefsDirName is the name of the directory within a repository that contains
+// the commits that references point to.
+const RefsDirName = "refs"
+
+// NewModuleReader returns a new ModuleReader that reads modules with a file remote
+// from their local directory.
+//
+// Modules with any other remote are read from the delegate.
+func NewModuleReader(
+	storageosProvider storageos.Provider,
+	delegate bufmodule.ModuleReader,
+) bufmodule.ModuleReader {
+	return newModuleReader(
+		storageosProvider,
+		delegate,
+	)
+}
+
+// NewModuleResolver returns a new ModuleResolver that resolves modules with a file
+// remote against their local directory.
+//
+// Modules with any other remote are resolved by the delegate.
+func NewModuleResolver(
+	storageosProvider storageos.Provider,
+	delegate bufmodule.ModuleResolver,
+) bufmodule.ModuleResolver {
+	return newModuleResolver(
+		storageosProvider,
+		delegate,
+	)
+}
+
+// GetModulePins resolves the ModuleReferences to ModulePins, and returns them along
+// with the ModulePins of all of their transitive dependencies.
+//
+// This is the equivalent of the GetModulePins RPC of the resolve service.
+// The currentModulePins are returned for every dependency that is not resolved.
+// An error is returned if two dependencies are pinned to different commits
+// of the same module.
+func GetModulePins(
+	ctx context.Context,
+	moduleResolver bufmodule.ModuleResolver,
+	moduleReader bufmodule.ModuleReader,
+	moduleReferences []bufmoduleref.ModuleReference,
+	currentModulePins []bufmoduleref.ModulePin,
+) ([]bufmoduleref.ModulePin, error) {
+	return getModulePins(
+		ctx,
+		moduleResolver,
+		moduleReader,
+		moduleReferences,
+		currentModulePins,
+	)
+}
@@ -0,0 +1,155 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulelocal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulelocal"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/uuidutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRegistry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	storageosProvider := storageos.NewProvider()
+	registryDirPath := t.TempDir()
+	registryReadWriteBucket, err := storageosProvider.NewReadWriteBucket(registryDirPath)
+	require.NoError(t, err)
+	remote := bufmoduleref.FileRemotePrefix + normalpath.Normalize(registryDirPath)
+
+	weatherV1Pin := testPutModule(t, registryReadWriteBucket, remote, "acme", "weather", nil)
+	weatherV2Pin := testPutModule(t, registryReadWriteBucket, remote, "acme", "weather", nil)
+	// The main branch points to the latest weather commit, and the units
+	// module depends on the first one.
+	require.NoError(t, storage.PutPath(ctx, registryReadWriteBucket, "acme/weather/refs/main", []byte(weatherV2Pin.Commit()+"\n")))
+	unitsPin := testPutModule(t, registryReadWriteBucket, remote, "acme", "units", []bufmoduleref.ModulePin{weatherV1Pin})
+	require.NoError(t, storage.PutPath(ctx, registryReadWriteBucket, "acme/units/refs/v1.0.0", []byte(unitsPin.Commit())))
+
+	moduleResolver := bufmodulelocal.NewModuleResolver(storageosProvider, bufmodule.NewNopModuleResolver())
+	moduleReader := bufmodulelocal.NewModuleReader(storageosProvider, bufmodule.NewNopModuleReader())
+
+	moduleReference, err := bufmoduleref.ModuleReferenceForString(remote + "/acme/weather")
+	require.NoError(t, err)
+	modulePin, err := moduleResolver.GetModulePin(ctx, moduleReference)
+	require.NoError(t, err)
+	require.Equal(t, weatherV2Pin.String(), modulePin.String())
+	moduleReference, err = bufmoduleref.ModuleReferenceForString(remote + "/acme/weather:" + weatherV1Pin.Commit())
+	require.NoError(t, err)
+	modulePin, err = moduleResolver.GetModulePin(ctx, moduleReference)
+	require.NoError(t, err)
+	require.Equal(t, weatherV1Pin.String(), modulePin.String())
+	for _, notExistReference := range []string{
+		remote + "/acme/weather:v2.0.0",
+		remote + "/acme/weather:refs",
+		remote + "/acme/other",
+	} {
+		moduleReference, err = bufmoduleref.ModuleReferenceForString(notExistReference)
+		require.NoError(t, err)
+		_, err = moduleResolver.GetModulePin(ctx, moduleReference)
+		require.True(t, storage.IsNotExist(err), notExistReference)
+	}
+
+	module, err := moduleReader.GetModule(ctx, unitsPin)
+	require.NoError(t, err)
+	require.Equal(t, []bufmoduleref.ModulePin{weatherV1Pin}, module.DependencyModulePins())
+	moduleFile, err := module.GetModuleFile(ctx, "acme/units/v1/units.proto")
+	require.NoError(t, err)
+	require.NoError(t, moduleFile.Close())
+
+	// Modules with other remotes go to the delegates.
+	remoteModuleReference, err := bufmoduleref.ModuleReferenceForString("buf.build/acme/weather")
+	require.NoError(t, err)
+	_, err = moduleResolver.GetModulePin(ctx, remoteModuleReference)
+	require.True(t, storage.IsNotExist(err))
+	remoteModulePin, err := bufmoduleref.NewModulePin("buf.build", "acme", "weather", "", weatherV1Pin.Commit(), time.Time{})
+	require.NoError(t, err)
+	_, err = moduleReader.GetModule(ctx, remoteModulePin)
+	require.True(t, storage.IsNotExist(err))
+
+	unitsReference, err := bufmoduleref.ModuleReferenceForString(remote + "/acme/units:v1.0.0")
+	require.NoError(t, err)
+	modulePins, err := bufmodulelocal.GetModulePins(
+		ctx,
+		moduleResolver,
+		moduleReader,
+		[]bufmoduleref.ModuleReference{unitsReference},
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []bufmoduleref.ModulePin{unitsPin, weatherV1Pin}, modulePins)
+	weatherReference, err := bufmoduleref.ModuleReferenceForString(remote + "/acme/weather")
+	require.NoError(t, err)
+	_, err = bufmodulelocal.GetModulePins(
+		ctx,
+		moduleResolver,
+		moduleReader,
+		[]bufmoduleref.ModuleReference{unitsReference, weatherReference},
+		nil,
+	)
+	require.Error(t, err)
+}
+
+func testPutModule(
+	t *testing.T,
+	registryReadWriteBucket storage.ReadWriteBucket,
+	remote string,
+	owner string,
+	repository string,
+	dependencyModulePins []bufmoduleref.ModulePin,
+) bufmoduleref.ModulePin {
+	ctx := context.Background()
+	commitUUID, err := uuidutil.New()
+	require.NoError(t, err)
+	commit, err := uuidutil.ToDashless(commitUUID)
+	require.NoError(t, err)
+	modulePin, err := bufmoduleref.NewModulePin(remote, owner, repository, "", commit, time.Time{})
+	require.NoError(t, err)
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	require.NoError(
+		t,
+		storage.PutPath(
+			ctx,
+			readWriteBucket,
+			normalpath.Join(owner, repository, "v1", repository+".proto"),
+			[]byte(`syntax = "proto3";`),
+		),
+	)
+	require.NoError(t, bufmoduleref.PutDependencyModulePinsToBucket(ctx, readWriteBucket, dependencyModulePins))
+	module, err := bufmodule.NewModuleForBucket(
+		ctx,
+		readWriteBucket,
+		bufmodule.ModuleWithModuleIdentityAndCommit(modulePin, commit),
+	)
+	require.NoError(t, err)
+	require.NoError(
+		t,
+		bufmodule.ModuleToBucket(
+			ctx,
+			module,
+			storage.MapReadWriteBucket(registryReadWriteBucket, storage.MapOnPrefix(normalpath.Join(owner, repository, commit))),
+		),
+	)
+	return modulePin
+}
@@ -0,0 +1,64 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulelocal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+)
+
+type moduleReader struct {
+	storageosProvider storageos.Provider
+	delegate          bufmodule.ModuleReader
+}
+
+func newModuleReader(
+	storageosProvider storageos.Provider,
+	delegate bufmodule.ModuleReader,
+) *moduleReader {
+	return &moduleReader{
+		storageosProvider: storageosProvider,
+		delegate:          delegate,
+	}
+}
+
+func (m *moduleReader) GetModule(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+) (bufmodule.Module, error) {
+	if !bufmoduleref.IsFileRemote(modulePin.Remote()) {
+		return m.delegate.GetModule(ctx, modulePin)
+	}
+	if !isCommitDirName(modulePin.Commit()) {
+		return nil, fmt.Errorf("%s: invalid commit for a local registry", modulePin.String())
+	}
+	// This returns an error that fulfills storage.IsNotExist if the
+	// commit directory does not exist.
+	readBucket, err := m.storageosProvider.NewReadWriteBucket(
+		commitDirPath(modulePin, modulePin.Commit()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return bufmodule.NewModuleForBucket(
+		ctx,
+		readBucket,
+		bufmodule.ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
+	)
+}
@@ -0,0 +1,95 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulelocal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+)
+
+type moduleResolver struct {
+	storageosProvider storageos.Provider
+	delegate          bufmodule.ModuleResolver
+}
+
+func newModuleResolver(
+	storageosProvider storageos.Provider,
+	delegate bufmodule.ModuleResolver,
+) *moduleResolver {
+	return &moduleResolver{
+		storageosProvider: storageosProvider,
+		delegate:          delegate,
+	}
+}
+
+func (m *moduleResolver) GetModulePin(
+	ctx context.Context,
+	moduleReference bufmoduleref.ModuleReference,
+) (bufmoduleref.ModulePin, error) {
+	if !bufmoduleref.IsFileRemote(moduleReference.Remote()) {
+		return m.delegate.GetModulePin(ctx, moduleReference)
+	}
+	repositoryReadBucket, err := m.storageosProvider.NewReadWriteBucket(repositoryDirPath(moduleReference))
+	if err != nil {
+		if storage.IsNotExist(err) {
+			// Required by ModuleResolver interface spec
+			return nil, storage.NewErrNotExist(moduleReference.String())
+		}
+		return nil, err
+	}
+	commit := moduleReference.Reference()
+	refData, err := storage.ReadPath(
+		ctx,
+		repositoryReadBucket,
+		normalpath.Join(RefsDirName, moduleReference.Reference()),
+	)
+	if err != nil {
+		if !storage.IsNotExist(err) {
+			return nil, err
+		}
+		// If there is no ref, the reference must be a commit.
+		if !isCommitDirName(commit) {
+			return nil, storage.NewErrNotExist(moduleReference.String())
+		}
+	} else {
+		commit = strings.TrimSpace(string(refData))
+		if !isCommitDirName(commit) {
+			return nil, fmt.Errorf("%s: ref %q points to invalid commit %q", moduleReference.String(), moduleReference.Reference(), commit)
+		}
+	}
+	if _, err := m.storageosProvider.NewReadWriteBucket(commitDirPath(moduleReference, commit)); err != nil {
+		if storage.IsNotExist(err) {
+			// Required by ModuleResolver interface spec
+			return nil, storage.NewErrNotExist(moduleReference.String())
+		}
+		return nil, err
+	}
+	return bufmoduleref.NewModulePin(
+		moduleReference.Remote(),
+		moduleReference.Owner(),
+		moduleReference.Repository(),
+		"",
+		commit,
+		time.Time{},
+	)
+}
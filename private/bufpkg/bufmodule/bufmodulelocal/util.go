@@ -0,0 +1,92 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulelocal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+)
+
+func getModulePins(
+	ctx context.Context,
+	moduleResolver bufmodule.ModuleResolver,
+	moduleReader bufmodule.ModuleReader,
+	moduleReferences []bufmoduleref.ModuleReference,
+	currentModulePins []bufmoduleref.ModulePin,
+) ([]bufmoduleref.ModulePin, error) {
+	var resolvedModulePins []bufmoduleref.ModulePin
+	for _, moduleReference := range moduleReferences {
+		modulePin, err := moduleResolver.GetModulePin(ctx, moduleReference)
+		if err != nil {
+			return nil, err
+		}
+		resolvedModulePins = append(resolvedModulePins, modulePin)
+		module, err := moduleReader.GetModule(ctx, modulePin)
+		if err != nil {
+			return nil, err
+		}
+		// The lock file of a module contains all of its transitive dependencies.
+		resolvedModulePins = append(resolvedModulePins, module.DependencyModulePins()...)
+	}
+	mergedModulePins, err := bufmoduleref.MergeModulePins(resolvedModulePins)
+	if err != nil {
+		return nil, err
+	}
+	identityToModulePin := make(map[string]bufmoduleref.ModulePin, len(mergedModulePins))
+	for _, modulePin := range mergedModulePins {
+		identityToModulePin[modulePin.IdentityString()] = modulePin
+	}
+	for _, currentModulePin := range currentModulePins {
+		if _, ok := identityToModulePin[currentModulePin.IdentityString()]; !ok {
+			identityToModulePin[currentModulePin.IdentityString()] = currentModulePin
+		}
+	}
+	modulePins := make([]bufmoduleref.ModulePin, 0, len(identityToModulePin))
+	for _, modulePin := range identityToModulePin {
+		modulePins = append(modulePins, modulePin)
+	}
+	bufmoduleref.SortModulePins(modulePins)
+	return modulePins, nil
+}
+
+// isCommitDirName returns true if the commit can name a commit directory
+// within a repository.
+func isCommitDirName(commit string) bool {
+	return commit != "" &&
+		commit != "." &&
+		commit != ".." &&
+		commit != RefsDirName &&
+		!strings.ContainsAny(commit, `/\`)
+}
+
+// repositoryDirPath returns the directory of the repository for the ModuleIdentity,
+// which must have a file remote.
+func repositoryDirPath(moduleIdentity bufmoduleref.ModuleIdentity) string {
+	return normalpath.Join(
+		strings.TrimPrefix(moduleIdentity.Remote(), bufmoduleref.FileRemotePrefix),
+		moduleIdentity.Owner(),
+		moduleIdentity.Repository(),
+	)
+}
+
+// commitDirPath returns the directory of the commit of the repository for the
+// ModuleIdentity, which must have a file remote.
+func commitDirPath(moduleIdentity bufmoduleref.ModuleIdentity, commit string) string {
+	return normalpath.Join(repositoryDirPath(moduleIdentity), commit)
+}
@@ -30,6 +30,11 @@ import (
 const (
 	// Main is the default reference used if no other reference is specified.
 	Main = "main"
+	// FileRemotePrefix is the prefix of remotes that refer to a registry in a local
+	// directory instead of a BSR, for example file:///srv/buf-registry.
+	//
+	// The path of the directory must be absolute and must not contain ':'.
+	FileRemotePrefix = "file://"
 )
 
 // FileInfo contains module file info.
@@ -167,6 +172,11 @@ func ModuleIdentityForString(path string) (ModuleIdentity, error) {
 	return NewModuleIdentity(remote, owner, repository)
 }
 
+// IsFileRemote returns true if the remote refers to a registry in a local directory.
+func IsFileRemote(remote string) bool {
+	return strings.HasPrefix(remote, FileRemotePrefix)
+}
+
 // ModuleReference is a module reference.
 //
 // It references either a branch, tag, or a commit.
@@ -363,6 +373,33 @@ func ValidateModulePinsUniqueByIdentity(modulePins []ModulePin) error {
 	return nil
 }
 
+// MergeModulePins returns the module pins deduplicated by identity, in the order
+// they first appear.
+//
+// Returns an error if the same module is pinned at different commits.
+func MergeModulePins(modulePins []ModulePin) ([]ModulePin, error) {
+	identityToModulePin := make(map[string]ModulePin, len(modulePins))
+	mergedModulePins := make([]ModulePin, 0, len(modulePins))
+	for _, modulePin := range modulePins {
+		moduleIdentityString := modulePin.IdentityString()
+		existingModulePin, ok := identityToModulePin[moduleIdentityString]
+		if !ok {
+			identityToModulePin[moduleIdentityString] = modulePin
+			mergedModulePins = append(mergedModulePins, modulePin)
+			continue
+		}
+		if existingModulePin.Commit() != modulePin.Commit() {
+			return nil, fmt.Errorf(
+				"dependency %q is required at both commit %q and commit %q",
+				moduleIdentityString,
+				existingModulePin.Commit(),
+				modulePin.Commit(),
+			)
+		}
+	}
+	return mergedModulePins, nil
+}
+
 // ModuleReferenceEqual returns true if a equals b.
 func ModuleReferenceEqual(a ModuleReference, b ModuleReference) bool {
 	if (a == nil) != (b == nil) {
@@ -431,25 +468,30 @@ func PutDependencyModulePinsToBucket(
 	writeBucket storage.WriteBucket,
 	modulePins []ModulePin,
 ) error {
-	if err := ValidateModulePinsUniqueByIdentity(modulePins); err != nil {
+	return putDependencyModulePinsToBucket(ctx, writeBucket, modulePins, nil)
+}
+
+// PutDependencyModulePinsToReadWriteBucket writes the module dependencies to the read write bucket
+// in the form of a lock file.
+//
+// Unlike PutDependencyModulePinsToBucket, the digests recorded in the existing lock file are kept
+// for the dependencies that are still pinned to the same commit.
+func PutDependencyModulePinsToReadWriteBucket(
+	ctx context.Context,
+	readWriteBucket storage.ReadWriteBucket,
+	modulePins []ModulePin,
+) error {
+	existingLockFile, err := buflock.ReadConfig(ctx, readWriteBucket)
+	if err != nil {
 		return err
 	}
-	SortModulePins(modulePins)
-	lockFile := &buflock.Config{
-		Dependencies: make([]buflock.Dependency, 0, len(modulePins)),
-	}
-	for _, pin := range modulePins {
-		lockFile.Dependencies = append(
-			lockFile.Dependencies,
-			buflock.Dependency{
-				Remote:     pin.Remote(),
-				Owner:      pin.Owner(),
-				Repository: pin.Repository(),
-				Commit:     pin.Commit(),
-			},
-		)
+	dependencyKeyToDigest := make(map[string]string, len(existingLockFile.Dependencies))
+	for _, dep := range existingLockFile.Dependencies {
+		if dep.Digest != "" {
+			dependencyKeyToDigest[lockFileDependencyKey(dep.Remote, dep.Owner, dep.Repository, dep.Commit)] = dep.Digest
+		}
 	}
-	return buflock.WriteConfig(ctx, writeBucket, lockFile)
+	return putDependencyModulePinsToBucket(ctx, readWriteBucket, modulePins, dependencyKeyToDigest)
 }
 
 // SortFileInfos sorts the FileInfos by Path.
@@ -486,3 +528,36 @@ func SortModulePins(modulePins []ModulePin) {
 		return modulePinLess(modulePins[i], modulePins[j])
 	})
 }
+
+// dependencyKeyToDigest may be nil.
+func putDependencyModulePinsToBucket(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	modulePins []ModulePin,
+	dependencyKeyToDigest map[string]string,
+) error {
+	if err := ValidateModulePinsUniqueByIdentity(modulePins); err != nil {
+		return err
+	}
+	SortModulePins(modulePins)
+	lockFile := &buflock.Config{
+		Dependencies: make([]buflock.Dependency, 0, len(modulePins)),
+	}
+	for _, pin := range modulePins {
+		lockFile.Dependencies = append(
+			lockFile.Dependencies,
+			buflock.Dependency{
+				Remote:     pin.Remote(),
+				Owner:      pin.Owner(),
+				Repository: pin.Repository(),
+				Commit:     pin.Commit(),
+				Digest:     dependencyKeyToDigest[lockFileDependencyKey(pin.Remote(), pin.Owner(), pin.Repository(), pin.Commit())],
+			},
+		)
+	}
+	return buflock.WriteConfig(ctx, writeBucket, lockFile)
+}
+
+func lockFileDependencyKey(remote string, owner string, repository string, commit string) string {
+	return remote + "/" + owner + "/" + repository + ":" + commit
+}
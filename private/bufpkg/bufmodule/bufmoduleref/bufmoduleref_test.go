@@ -0,0 +1,126 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmoduleref
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/buflock"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeModulePins(t *testing.T) {
+	t.Parallel()
+	weatherPin := testNewModulePin(t, "weather", "aaaa")
+	timePin := testNewModulePin(t, "time", "bbbb")
+	mergedModulePins, err := MergeModulePins(
+		[]ModulePin{
+			weatherPin,
+			timePin,
+			testNewModulePin(t, "weather", "aaaa"),
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []ModulePin{weatherPin, timePin}, mergedModulePins)
+
+	_, err = MergeModulePins(
+		[]ModulePin{
+			weatherPin,
+			testNewModulePin(t, "weather", "cccc"),
+		},
+	)
+	assert.EqualError(t, err, `dependency "buf.build/acme/weather" is required at both commit "aaaa" and commit "cccc"`)
+}
+
+func TestPutDependencyModulePinsToReadWriteBucketKeepsDigests(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	require.NoError(
+		t,
+		buflock.WriteConfig(
+			ctx,
+			readWriteBucket,
+			&buflock.Config{
+				Dependencies: []buflock.Dependency{
+					{
+						Remote:     "buf.build",
+						Owner:      "acme",
+						Repository: "time",
+						Commit:     "bbbb",
+						Digest:     "b3-time",
+					},
+					{
+						Remote:     "buf.build",
+						Owner:      "acme",
+						Repository: "weather",
+						Commit:     "aaaa",
+						Digest:     "b3-weather",
+					},
+				},
+			},
+		),
+	)
+	require.NoError(
+		t,
+		PutDependencyModulePinsToReadWriteBucket(
+			ctx,
+			readWriteBucket,
+			[]ModulePin{
+				testNewModulePin(t, "weather", "aaaa"),
+				// The digest of a previous commit does not apply.
+				testNewModulePin(t, "time", "cccc"),
+				testNewModulePin(t, "units", "dddd"),
+			},
+		),
+	)
+	lockConfig, err := buflock.ReadConfig(ctx, readWriteBucket)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]buflock.Dependency{
+			{
+				Remote:     "buf.build",
+				Owner:      "acme",
+				Repository: "time",
+				Commit:     "cccc",
+			},
+			{
+				Remote:     "buf.build",
+				Owner:      "acme",
+				Repository: "units",
+				Commit:     "dddd",
+			},
+			{
+				Remote:     "buf.build",
+				Owner:      "acme",
+				Repository: "weather",
+				Commit:     "aaaa",
+				Digest:     "b3-weather",
+			},
+		},
+		lockConfig.Dependencies,
+	)
+}
+
+func testNewModulePin(t *testing.T, repository string, commit string) ModulePin {
+	modulePin, err := NewModulePin("buf.build", "acme", repository, "", commit, time.Time{})
+	require.NoError(t, err)
+	return modulePin
+}
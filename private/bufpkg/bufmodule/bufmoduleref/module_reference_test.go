@@ -91,6 +91,28 @@ func TestModuleReferenceForString(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedModuleReference, moduleReference)
 	require.False(t, IsCommitModuleReference(moduleReference))
+
+	expectedModuleReference, err = NewModuleReference("file:///srv/buf-registry", "barr", "baz", "main")
+	require.NoError(t, err)
+	require.Equal(t, "file:///srv/buf-registry/barr/baz", expectedModuleReference.String())
+	moduleReference, err = ModuleReferenceForString("file:///srv/buf-registry/barr/baz")
+	require.NoError(t, err)
+	require.Equal(t, expectedModuleReference, moduleReference)
+	require.True(t, IsFileRemote(moduleReference.Remote()))
+
+	expectedModuleReference, err = NewModuleReference("file:///srv/buf-registry", "barr", "baz", commit)
+	require.NoError(t, err)
+	require.Equal(t, "file:///srv/buf-registry/barr/baz:"+commit, expectedModuleReference.String())
+	moduleReference, err = ModuleReferenceForString("file:///srv/buf-registry/barr/baz:" + commit)
+	require.NoError(t, err)
+	require.Equal(t, expectedModuleReference, moduleReference)
+	require.True(t, IsCommitModuleReference(moduleReference))
+
+	expectedModuleReference, err = NewModuleReference("file:///registry", "barr", "baz", "some/draft")
+	require.NoError(t, err)
+	moduleReference, err = ModuleReferenceForString("file:///registry/barr/baz:some/draft")
+	require.NoError(t, err)
+	require.Equal(t, expectedModuleReference, moduleReference)
 }
 
 func TestModuleReferenceForStringError(t *testing.T) {
@@ -115,6 +137,22 @@ func TestModuleReferenceForStringError(t *testing.T) {
 			Name:  "Module without a branch or commit",
 			Input: "foo.com/barr/baz:",
 		},
+		{
+			Name:  "File module without a registry path",
+			Input: "file:///barr/baz",
+		},
+		{
+			Name:  "File module with a relative registry path",
+			Input: "file://registry/barr/baz",
+		},
+		{
+			Name:  "File module without a repository",
+			Input: "file:///registry/barr/:v1",
+		},
+		{
+			Name:  "File module without a branch or commit",
+			Input: "file:///registry/barr/baz:",
+		},
 	}
 	for _, testCase := range testCases {
 		testCase := testCase
@@ -22,6 +22,9 @@ import (
 // parseModuleReferenceComponents parses and returns the remote, owner, repository,
 // and ref (branch, commit, draft, or tag) from the given path.
 func parseModuleReferenceComponents(path string) (remote string, owner string, repository string, ref string, err error) {
+	if IsFileRemote(path) {
+		return parseFileModuleReferenceComponents(path)
+	}
 	// split by the first "/" to separate the remote and remaining part
 	slashSplit := strings.SplitN(path, "/", 2)
 	if len(slashSplit) != 2 {
@@ -48,8 +51,35 @@ func parseModuleReferenceComponents(path string) (remote string, owner string, r
 	return remote, owner, repository, ref, nil
 }
 
+// parseFileModuleReferenceComponents parses a path with a file remote, in the form
+// file:///path/to/registry/owner/repository{:reference}.
+func parseFileModuleReferenceComponents(path string) (remote string, owner string, repository string, ref string, err error) {
+	// The remote contains slashes itself, so we split off the reference first.
+	identityPath, ref, ok := strings.Cut(strings.TrimPrefix(path, FileRemotePrefix), ":")
+	if ok {
+		ref = strings.TrimSpace(ref)
+		if ref == "" || strings.Contains(ref, ":") {
+			return "", "", "", "", newInvalidModuleReferenceStringError(path)
+		}
+	}
+	remote, owner, repository, err = parseModuleIdentityComponents(FileRemotePrefix + identityPath)
+	if err != nil {
+		return "", "", "", "", newInvalidModuleReferenceStringError(path)
+	}
+	return remote, owner, repository, ref, nil
+}
+
 func parseModuleIdentityComponents(path string) (remote string, owner string, repository string, err error) {
 	slashSplit := strings.Split(path, "/")
+	if IsFileRemote(path) {
+		// The remote is the path to the registry, and the owner and
+		// repository are its last two components.
+		if len(slashSplit) < 5 {
+			return "", "", "", newInvalidModuleIdentityStringError(path)
+		}
+		remoteLen := len(slashSplit) - 2
+		slashSplit = append([]string{strings.Join(slashSplit[:remoteLen], "/")}, slashSplit[remoteLen:]...)
+	}
 	if len(slashSplit) != 3 {
 		return "", "", "", newInvalidModuleIdentityStringError(path)
 	}
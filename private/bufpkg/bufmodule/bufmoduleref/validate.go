@@ -22,6 +22,7 @@ import (
 	modulev1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/module/v1alpha1"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/netextended"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
 )
 
 // ValidateProtoModuleReference verifies the given module reference is well-formed.
@@ -203,6 +204,13 @@ func validateModuleIdentity(moduleIdentity ModuleIdentity) error {
 }
 
 func validateRemote(remote string) error {
+	if IsFileRemote(remote) {
+		dirPath := strings.TrimPrefix(remote, FileRemotePrefix)
+		if !strings.HasPrefix(dirPath, "/") || normalpath.Normalize(dirPath) != dirPath || strings.Contains(dirPath, ":") {
+			return fmt.Errorf("invalid remote %q: must be a file URL with an absolute path, for example %s/srv/buf-registry", remote, FileRemotePrefix)
+		}
+		return nil
+	}
 	if _, err := netextended.ValidateHostname(remote); err != nil {
 		return fmt.Errorf("invalid remote %q: %w", remote, err)
 	}
@@ -21,7 +21,9 @@ package appprotoexec
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
@@ -110,7 +112,9 @@ func GenerateWithPluginPath(pluginPath string) GenerateOption {
 //
 // protocPath and pluginPath are optional.
 //
-//   - If the plugin path is set, this returns a new binary handler for that path.
+//   - If the plugin path is set and has the .wasm extension, this returns a new WebAssembly handler
+//     for that path. The module is executed in an embedded, sandboxed WASI runtime.
+//   - If the plugin path is set otherwise, this returns a new binary handler for that path.
 //   - If the plugin path is unset, this does exec.LookPath for a binary named protoc-gen-pluginName,
 //     and if one is found, a new binary handler is returned for this.
 //   - Else, if the name is in ProtocProxyPluginNames, this returns a new protoc proxy handler.
@@ -127,6 +131,13 @@ func NewHandler(
 		option(handlerOptions)
 	}
 	if handlerOptions.pluginPath != "" {
+		if IsWASMPluginPath(handlerOptions.pluginPath) {
+			// WebAssembly modules are not executable, so exec.LookPath does not apply.
+			if _, err := os.Stat(handlerOptions.pluginPath); err != nil {
+				return nil, err
+			}
+			return newWASMHandler(logger, handlerOptions.pluginPath), nil
+		}
 		pluginPath, err := exec.LookPath(handlerOptions.pluginPath)
 		if err != nil {
 			return nil, err
@@ -151,6 +162,11 @@ func NewHandler(
 	return nil, fmt.Errorf("could not find protoc plugin for name %s", pluginName)
 }
 
+// IsWASMPluginPath returns true if the plugin path refers to a WebAssembly module.
+func IsWASMPluginPath(pluginPath string) bool {
+	return filepath.Ext(pluginPath) == ".wasm"
+}
+
 // HandlerOption is an option for a new Handler.
 type HandlerOption func(*handlerOptions)
 
@@ -16,11 +16,13 @@ package appprotoexec
 
 import (
 	"context"
+	"io"
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
 	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/pluginpb"
 )
@@ -64,6 +66,13 @@ func (g *generator) Generate(
 	if err != nil {
 		return nil, err
 	}
+	if closer, ok := handler.(io.Closer); ok {
+		// Some handlers, such as the WebAssembly handler, hold
+		// resources across requests that must be released.
+		defer func() {
+			retErr = multierr.Append(retErr, closer.Close())
+		}()
+	}
 	return appproto.NewGenerator(
 		g.logger,
 		handler,
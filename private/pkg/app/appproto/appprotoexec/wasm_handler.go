@@ -0,0 +1,163 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appprotoexec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+type wasmHandler struct {
+	logger     *zap.Logger
+	pluginPath string
+
+	// The compilation cache is shared across all invocations of this
+	// handler, so that the module is only compiled once even though
+	// a new runtime is created for every invocation.
+	compilationCache wazero.CompilationCache
+	// The module data is read once on the first invocation.
+	moduleDataOnce sync.Once
+	moduleData     []byte
+	moduleDataErr  error
+}
+
+func newWASMHandler(
+	logger *zap.Logger,
+	pluginPath string,
+) *wasmHandler {
+	return &wasmHandler{
+		logger:           logger.Named("appprotoexec"),
+		pluginPath:       pluginPath,
+		compilationCache: wazero.NewCompilationCache(),
+	}
+}
+
+// Close releases the compiled module.
+func (h *wasmHandler) Close() error {
+	return h.compilationCache.Close(context.Background())
+}
+
+func (h *wasmHandler) Handle(
+	ctx context.Context,
+	container app.EnvStderrContainer,
+	responseWriter appproto.ResponseBuilder,
+	request *pluginpb.CodeGeneratorRequest,
+) error {
+	ctx, span := trace.StartSpan(ctx, "plugin_wasm")
+	span.AddAttributes(trace.StringAttribute("plugin", filepath.Base(h.pluginPath)))
+	defer span.End()
+	moduleData, err := h.getModuleData()
+	if err != nil {
+		return err
+	}
+	requestData, err := protoencoding.NewWireMarshaler().Marshal(request)
+	if err != nil {
+		return err
+	}
+	responseBuffer := bytes.NewBuffer(nil)
+	if err := h.run(ctx, container, moduleData, requestData, responseBuffer); err != nil {
+		return err
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(responseBuffer.Bytes(), response); err != nil {
+		return err
+	}
+	response, err = normalizeCodeGeneratorResponse(response)
+	if err != nil {
+		return err
+	}
+	if response.GetSupportedFeatures()&uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL) != 0 {
+		responseWriter.SetFeatureProto3Optional()
+	}
+	for _, file := range response.File {
+		if err := responseWriter.AddFile(file); err != nil {
+			return err
+		}
+	}
+	// plugin.proto specifies that only non-empty errors are considered errors.
+	// This is also consistent with protoc's behavior.
+	// Ref: https://github.com/protocolbuffers/protobuf/blob/069f989b483e63005f87ab309de130677718bbec/src/google/protobuf/compiler/plugin.proto#L100-L108.
+	if response.GetError() != "" {
+		responseWriter.AddError(response.GetError())
+	}
+	return nil
+}
+
+// run executes the module with the request on stdin, writing stdout to the responseBuffer.
+//
+// The module is sandboxed: it has no access to the filesystem, the environment,
+// or the network, and only receives the plugin name as its single argument.
+func (h *wasmHandler) run(
+	ctx context.Context,
+	container app.EnvStderrContainer,
+	moduleData []byte,
+	requestData []byte,
+	responseBuffer *bytes.Buffer,
+) (retErr error) {
+	runtime := wazero.NewRuntimeWithConfig(
+		ctx,
+		wazero.NewRuntimeConfig().
+			WithCompilationCache(h.compilationCache).
+			WithCloseOnContextDone(true),
+	)
+	defer func() {
+		retErr = multierr.Append(retErr, runtime.Close(ctx))
+	}()
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return err
+	}
+	compiledModule, err := runtime.CompileModule(ctx, moduleData)
+	if err != nil {
+		return fmt.Errorf("could not compile WebAssembly plugin %s: %w", h.pluginPath, err)
+	}
+	if _, err := runtime.InstantiateModule(
+		ctx,
+		compiledModule,
+		wazero.NewModuleConfig().
+			WithName("").
+			WithArgs(filepath.Base(h.pluginPath)).
+			WithStdin(bytes.NewReader(requestData)).
+			WithStdout(responseBuffer).
+			WithStderr(container.Stderr()),
+	); err != nil {
+		if exitError, ok := err.(*sys.ExitError); ok {
+			return fmt.Errorf("WebAssembly plugin %s exited with code %d", h.pluginPath, exitError.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
+
+func (h *wasmHandler) getModuleData() ([]byte, error) {
+	h.moduleDataOnce.Do(func() {
+		h.moduleData, h.moduleDataErr = os.ReadFile(h.pluginPath)
+	})
+	return h.moduleData, h.moduleDataErr
+}
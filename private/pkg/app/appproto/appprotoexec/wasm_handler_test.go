@@ -0,0 +1,169 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appprotoexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestWASMHandler(t *testing.T) {
+	t.Parallel()
+	response := &pluginpb.CodeGeneratorResponse{
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{
+				Name:    proto.String("a.txt"),
+				Content: proto.String("a"),
+			},
+		},
+	}
+	responseData, err := proto.Marshal(response)
+	require.NoError(t, err)
+	pluginPath := filepath.Join(t.TempDir(), "protoc-gen-test.wasm")
+	require.NoError(t, os.WriteFile(pluginPath, testNewWASIModule(responseData, 0), 0600))
+	actualResponse, err := testWASMGenerate(t, pluginPath)
+	require.NoError(t, err)
+	require.Len(t, actualResponse.File, 1)
+	assert.Equal(t, "a.txt", actualResponse.File[0].GetName())
+	assert.Equal(t, "a", actualResponse.File[0].GetContent())
+}
+
+func TestWASMHandlerExitCode(t *testing.T) {
+	t.Parallel()
+	pluginPath := filepath.Join(t.TempDir(), "protoc-gen-test.wasm")
+	require.NoError(t, os.WriteFile(pluginPath, testNewWASIModule(nil, 3), 0600))
+	_, err := testWASMGenerate(t, pluginPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exited with code 3")
+}
+
+func TestWASMHandlerNotFound(t *testing.T) {
+	t.Parallel()
+	_, err := NewHandler(
+		zap.NewNop(),
+		storageos.NewProvider(),
+		nil,
+		"test",
+		HandlerWithPluginPath(filepath.Join(t.TempDir(), "protoc-gen-test.wasm")),
+	)
+	require.Error(t, err)
+}
+
+func testWASMGenerate(t *testing.T, pluginPath string) (*pluginpb.CodeGeneratorResponse, error) {
+	return NewGenerator(zap.NewNop(), storageos.NewProvider(), nil).Generate(
+		context.Background(),
+		app.NewContainer(nil, nil, nil, bytes.NewBuffer(nil)),
+		"test",
+		[]*pluginpb.CodeGeneratorRequest{
+			{
+				FileToGenerate: []string{"a.proto"},
+				ProtoFile: []*descriptorpb.FileDescriptorProto{
+					{
+						Name: proto.String("a.proto"),
+					},
+				},
+			},
+		},
+		GenerateWithPluginPath(pluginPath),
+	)
+}
+
+// testNewWASIModule returns the binary of a minimal WASI module that writes
+// the data to stdout, and then exits with the exit code if it is not zero.
+//
+// The data is stored in memory at offset 16, preceded by the iovec for fd_write.
+func testNewWASIModule(data []byte, exitCode byte) []byte {
+	const (
+		i32      = 0x7f
+		funcType = 0x60
+	)
+	var code []byte
+	// fd_write(fd=1, iovs=0, iovs_len=1, nwritten=8)
+	code = append(code, 0x41, 1, 0x41, 0, 0x41, 1, 0x41, 8, 0x10, 0, 0x1a)
+	if exitCode != 0 {
+		// proc_exit(exitCode)
+		code = append(code, 0x41, exitCode, 0x10, 1)
+	}
+	code = append(code, 0x0b)
+	memory := make([]byte, 16, 16+len(data))
+	binary.LittleEndian.PutUint32(memory[0:4], 16)
+	binary.LittleEndian.PutUint32(memory[4:8], uint32(len(data)))
+	memory = append(memory, data...)
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = testAppendWASMSection(module, 1, testConcat(
+		[]byte{3},
+		[]byte{funcType, 4, i32, i32, i32, i32, 1, i32},
+		[]byte{funcType, 1, i32, 0},
+		[]byte{funcType, 0, 0},
+	))
+	module = testAppendWASMSection(module, 2, testConcat(
+		[]byte{2},
+		testWASMName("wasi_snapshot_preview1"), testWASMName("fd_write"), []byte{0x00, 0},
+		testWASMName("wasi_snapshot_preview1"), testWASMName("proc_exit"), []byte{0x00, 1},
+	))
+	module = testAppendWASMSection(module, 3, []byte{1, 2})
+	module = testAppendWASMSection(module, 5, []byte{1, 0x00, 1})
+	module = testAppendWASMSection(module, 7, testConcat(
+		[]byte{2},
+		testWASMName("memory"), []byte{0x02, 0},
+		testWASMName("_start"), []byte{0x00, 2},
+	))
+	module = testAppendWASMSection(module, 10, testConcat(
+		[]byte{1},
+		testWASMVector(testConcat([]byte{0}, code)),
+	))
+	module = testAppendWASMSection(module, 11, testConcat(
+		[]byte{1, 0x00, 0x41, 0, 0x0b},
+		testWASMVector(memory),
+	))
+	return module
+}
+
+func testAppendWASMSection(module []byte, id byte, content []byte) []byte {
+	return append(append(module, id), testWASMVector(content)...)
+}
+
+func testWASMName(name string) []byte {
+	return testWASMVector([]byte(name))
+}
+
+func testWASMVector(content []byte) []byte {
+	// The length is an unsigned LEB128, which is the same encoding as a uvarint.
+	length := make([]byte, binary.MaxVarintLen64)
+	length = length[:binary.PutUvarint(length, uint64(len(content)))]
+	return append(length, content...)
+}
+
+func testConcat(slices ...[]byte) []byte {
+	var result []byte
+	for _, slice := range slices {
+		result = append(result, slice...)
+	}
+	return result
+}
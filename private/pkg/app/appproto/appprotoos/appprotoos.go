@@ -19,11 +19,18 @@ import (
 	"context"
 	"io"
 
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
+// ManifestFilePath is the path of the manifest, relative to the output directory,
+// that tracks which files were written by a ResponseWriter.
+//
+// See AddResponseWithClean.
+const ManifestFilePath = ".buf.gen.manifest.json"
+
 // ResponseWriter writes CodeGeneratorResponses to the OS filesystem.
 type ResponseWriter interface {
 	// Close writes all of the responses to disk. No further calls can be
@@ -40,7 +47,15 @@ type ResponseWriter interface {
 		ctx context.Context,
 		response *pluginpb.CodeGeneratorResponse,
 		pluginOut string,
+		options ...AddResponseOption,
 	) error
+	// Diff compares all of the responses to the content on disk, and writes
+	// a unified diff of any differences to the writer. Nothing is written to
+	// disk. No further calls can be made to the ResponseWriter after this call.
+	//
+	// Returns true if there were any differences, that is if Close would
+	// change the content on disk.
+	Diff(runner command.Runner, writer io.Writer) (bool, error)
 }
 
 // NewResponseWriter returns a new ResponseWriter.
@@ -66,3 +81,23 @@ func ResponseWriterWithCreateOutDirIfNotExists() ResponseWriterOption {
 		responseWriterOptions.createOutDirIfNotExists = true
 	}
 }
+
+// AddResponseOption is an option for AddResponse.
+type AddResponseOption func(*addResponseOptions)
+
+// AddResponseWithClean returns a new AddResponseOption that removes stale files
+// from the output directory when the responses are written.
+//
+// A manifest of the files written is stored in the output directory at ManifestFilePath.
+// When the responses are written, any file listed in the previous manifest that is
+// not written again is deleted, along with any directories left empty. Files that
+// were not written by a ResponseWriter, such as hand-written files, are never deleted.
+//
+// If any response added for an output directory has this option set, the entire
+// output directory is cleaned. This has no effect for .jar and .zip outputs, as
+// these are always overwritten in their entirety.
+func AddResponseWithClean() AddResponseOption {
+	return func(addResponseOptions *addResponseOptions) {
+		addResponseOptions.clean = true
+	}
+}
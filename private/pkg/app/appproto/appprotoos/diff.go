@@ -0,0 +1,237 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appprotoos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagearchive"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"go.uber.org/multierr"
+)
+
+// diffDirectory writes a diff between the files within the output directory
+// and the files in the readBucket that would be written to it.
+//
+// If clean is set, the files that would be deleted and the manifest are also diffed.
+//
+// Returns true if there was a diff.
+func (w *responseWriter) diffDirectory(
+	ctx context.Context,
+	runner command.Runner,
+	writer io.Writer,
+	readBucket storage.ReadBucket,
+	outDirPath string,
+	displayOutPath string,
+	clean bool,
+) (bool, error) {
+	var osReadBucket storage.ReadBucket = storagemem.NewReadWriteBucket()
+	// OK to use os.Stat instead of os.Lstat here.
+	if _, err := os.Stat(outDirPath); err == nil {
+		osReadBucket, err = w.storageosProvider.NewReadWriteBucket(outDirPath)
+		if err != nil {
+			return false, err
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+	filePaths, err := storage.AllPaths(ctx, readBucket, "")
+	if err != nil {
+		return false, err
+	}
+	var staleFilePaths []string
+	if clean {
+		previousFilePaths, err := readManifest(ctx, osReadBucket)
+		if err != nil {
+			return false, err
+		}
+		staleFilePaths = getStaleFilePaths(previousFilePaths, filePaths)
+		manifestData, err := marshalManifest(filePaths)
+		if err != nil {
+			return false, err
+		}
+		manifestReadBucket, err := storagemem.NewReadBucket(map[string][]byte{ManifestFilePath: manifestData})
+		if err != nil {
+			return false, err
+		}
+		readBucket = storage.MultiReadBucket(readBucket, manifestReadBucket)
+		filePaths = append(filePaths, ManifestFilePath)
+	}
+	return diffReadBuckets(
+		ctx,
+		runner,
+		writer,
+		osReadBucket,
+		readBucket,
+		filePaths,
+		staleFilePaths,
+		displayOutPath,
+	)
+}
+
+// diffZip writes a diff between the files within the zip archive at the
+// output file path and the files in the readBucket that would be zipped to it.
+//
+// Returns true if there was a diff.
+func diffZip(
+	ctx context.Context,
+	runner command.Runner,
+	writer io.Writer,
+	readBucket storage.ReadBucket,
+	outFilePath string,
+	displayOutPath string,
+) (_ bool, retErr error) {
+	zipReadWriteBucket := storagemem.NewReadWriteBucket()
+	file, err := os.Open(outFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if err == nil {
+		defer func() {
+			retErr = multierr.Append(retErr, file.Close())
+		}()
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return false, err
+		}
+		if err := storagearchive.Unzip(ctx, file, fileInfo.Size(), zipReadWriteBucket, nil, 0); err != nil {
+			return false, err
+		}
+	}
+	filePaths, err := storage.AllPaths(ctx, readBucket, "")
+	if err != nil {
+		return false, err
+	}
+	zipFilePaths, err := storage.AllPaths(ctx, zipReadWriteBucket, "")
+	if err != nil {
+		return false, err
+	}
+	return diffReadBuckets(
+		ctx,
+		runner,
+		writer,
+		zipReadWriteBucket,
+		readBucket,
+		filePaths,
+		// The entire archive is rewritten, so any file not in the readBucket is deleted.
+		getStaleFilePaths(zipFilePaths, filePaths),
+		displayOutPath,
+	)
+}
+
+// diffReadBuckets writes a diff for each of the file paths between the
+// currentReadBucket and the expectedReadBucket, and a diff for each of the
+// stale file paths that are within the currentReadBucket but will be deleted.
+//
+// Returns true if there was a diff.
+func diffReadBuckets(
+	ctx context.Context,
+	runner command.Runner,
+	writer io.Writer,
+	currentReadBucket storage.ReadBucket,
+	expectedReadBucket storage.ReadBucket,
+	filePaths []string,
+	staleFilePaths []string,
+	displayOutPath string,
+) (bool, error) {
+	sort.Strings(filePaths)
+	sort.Strings(staleFilePaths)
+	var diffPresent bool
+	for _, filePath := range filePaths {
+		expectedData, err := storage.ReadPath(ctx, expectedReadBucket, filePath)
+		if err != nil {
+			return false, err
+		}
+		currentData, err := storage.ReadPath(ctx, currentReadBucket, filePath)
+		if err != nil {
+			if !storage.IsNotExist(err) {
+				return false, err
+			}
+			if len(expectedData) == 0 {
+				// Even an empty file is a diff if it does not exist, but diff
+				// prints nothing for it, so we note that it would be created.
+				diffPresent = true
+				if err := writeEmptyFileNotice(writer, displayOutPath, filePath); err != nil {
+					return false, err
+				}
+				continue
+			}
+		}
+		if bytes.Equal(currentData, expectedData) {
+			continue
+		}
+		diffPresent = true
+		if err := writeDiff(ctx, runner, writer, currentData, expectedData, displayOutPath, filePath); err != nil {
+			return false, err
+		}
+	}
+	for _, staleFilePath := range staleFilePaths {
+		currentData, err := storage.ReadPath(ctx, currentReadBucket, staleFilePath)
+		if err != nil {
+			if storage.IsNotExist(err) {
+				continue
+			}
+			return false, err
+		}
+		diffPresent = true
+		if err := writeDiff(ctx, runner, writer, currentData, nil, displayOutPath, staleFilePath); err != nil {
+			return false, err
+		}
+	}
+	return diffPresent, nil
+}
+
+func writeDiff(
+	ctx context.Context,
+	runner command.Runner,
+	writer io.Writer,
+	currentData []byte,
+	expectedData []byte,
+	displayOutPath string,
+	filePath string,
+) error {
+	displayFilePath := filepath.Join(displayOutPath, normalpath.Unnormalize(filePath))
+	diffData, err := diff.Diff(
+		ctx,
+		runner,
+		currentData,
+		expectedData,
+		displayFilePath,
+		displayFilePath,
+		// The timestamps are those of temporary files, and are meaningless.
+		diff.DiffWithSuppressTimestamps(),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(diffData)
+	return err
+}
+
+func writeEmptyFileNotice(writer io.Writer, displayOutPath string, filePath string) error {
+	displayFilePath := filepath.Join(displayOutPath, normalpath.Unnormalize(filePath))
+	_, err := fmt.Fprintf(writer, "empty file %s would be created\n", displayFilePath)
+	return err
+}
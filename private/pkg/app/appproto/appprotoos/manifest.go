@@ -0,0 +1,149 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appprotoos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+)
+
+// manifest is the content of the file at ManifestFilePath.
+type manifest struct {
+	// Files are the normalized paths of the files written,
+	// relative to the output directory.
+	//
+	// Sorted.
+	Files []string `json:"files"`
+}
+
+// copyAndClean copies the generated files from the readBucket to the
+// readWriteBucket for the output directory, deletes any files listed
+// in the previous manifest that were not copied, and writes a new manifest.
+func copyAndClean(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	readWriteBucket storage.ReadWriteBucket,
+	outDirPath string,
+) error {
+	previousFilePaths, err := readManifest(ctx, readWriteBucket)
+	if err != nil {
+		return err
+	}
+	if _, err := storage.Copy(ctx, readBucket, readWriteBucket); err != nil {
+		return err
+	}
+	filePaths, err := storage.AllPaths(ctx, readBucket, "")
+	if err != nil {
+		return err
+	}
+	for _, staleFilePath := range getStaleFilePaths(previousFilePaths, filePaths) {
+		if err := readWriteBucket.Delete(ctx, staleFilePath); err != nil {
+			if storage.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		deleteEmptyParentDirs(outDirPath, staleFilePath)
+	}
+	return writeManifest(ctx, readWriteBucket, filePaths)
+}
+
+// readManifest returns the file paths in the manifest within the readBucket.
+//
+// If there is no manifest, this returns no file paths.
+func readManifest(ctx context.Context, readBucket storage.ReadBucket) ([]string, error) {
+	data, err := storage.ReadPath(ctx, readBucket, ManifestFilePath)
+	if err != nil {
+		if storage.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var manifest manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", ManifestFilePath, err)
+	}
+	filePaths := make([]string, 0, len(manifest.Files))
+	for _, filePath := range manifest.Files {
+		// Never trust a path that could escape the output directory.
+		normalizedFilePath, err := normalpath.NormalizeAndValidate(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path in %s: %w", ManifestFilePath, err)
+		}
+		filePaths = append(filePaths, normalizedFilePath)
+	}
+	return filePaths, nil
+}
+
+// writeManifest writes a manifest containing the file paths to the writeBucket.
+func writeManifest(ctx context.Context, writeBucket storage.WriteBucket, filePaths []string) error {
+	data, err := marshalManifest(filePaths)
+	if err != nil {
+		return err
+	}
+	return storage.PutPath(ctx, writeBucket, ManifestFilePath, data)
+}
+
+// marshalManifest returns the content of the manifest containing the file paths.
+func marshalManifest(filePaths []string) ([]byte, error) {
+	manifestFilePaths := make([]string, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		if filePath != ManifestFilePath {
+			manifestFilePaths = append(manifestFilePaths, filePath)
+		}
+	}
+	sort.Strings(manifestFilePaths)
+	data, err := json.MarshalIndent(&manifest{Files: manifestFilePaths}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// getStaleFilePaths returns the previous file paths that are not within the file paths.
+func getStaleFilePaths(previousFilePaths []string, filePaths []string) []string {
+	filePathMap := make(map[string]struct{}, len(filePaths))
+	for _, filePath := range filePaths {
+		filePathMap[filePath] = struct{}{}
+	}
+	var staleFilePaths []string
+	for _, previousFilePath := range previousFilePaths {
+		if _, ok := filePathMap[previousFilePath]; !ok {
+			staleFilePaths = append(staleFilePaths, previousFilePath)
+		}
+	}
+	return staleFilePaths
+}
+
+// deleteEmptyParentDirs deletes the parent directories of the file path
+// within the output directory until a non-empty directory is found.
+//
+// The output directory itself is never deleted.
+func deleteEmptyParentDirs(outDirPath string, filePath string) {
+	for dirPath := normalpath.Dir(filePath); dirPath != "."; dirPath = normalpath.Dir(dirPath) {
+		// os.Remove fails on non-empty directories, at which point
+		// none of the remaining parent directories are empty either.
+		if err := os.Remove(filepath.Join(outDirPath, normalpath.Unnormalize(dirPath))); err != nil {
+			return
+		}
+	}
+}
@@ -17,11 +17,13 @@ package appprotoos
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
 
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storagearchive"
@@ -63,10 +65,16 @@ type responseWriter struct {
 	// $ protoc example.proto --insertion-point-receiver_out=. --insertion-point-writer_out=$(pwd)
 	//
 	readWriteBuckets map[string]storage.ReadWriteBucket
+	// The output directory paths that should be cleaned of stale files
+	// when the responses are flushed.
+	cleanOutDirPaths map[string]struct{}
 	// Cache the functions used to flush all of the responses to disk.
 	// This holds all of the buckets in-memory so that we only write
 	// the results to disk if all of the responses are successful.
 	closers []func() error
+	// Cache the functions used to diff all of the responses against
+	// the content on disk, in the same order as the closers.
+	differs []func(command.Runner, io.Writer) (bool, error)
 	lock    sync.RWMutex
 }
 
@@ -85,6 +93,7 @@ func newResponseWriter(
 		responseWriter:          appproto.NewResponseWriter(logger),
 		createOutDirIfNotExists: responseWriterOptions.createOutDirIfNotExists,
 		readWriteBuckets:        make(map[string]storage.ReadWriteBucket),
+		cleanOutDirPaths:        make(map[string]struct{}),
 	}
 }
 
@@ -92,7 +101,12 @@ func (w *responseWriter) AddResponse(
 	ctx context.Context,
 	response *pluginpb.CodeGeneratorResponse,
 	pluginOut string,
+	options ...AddResponseOption,
 ) error {
+	addResponseOptions := newAddResponseOptions()
+	for _, option := range options {
+		option(addResponseOptions)
+	}
 	// It's important that we get a consistent output path
 	// so that we use the same in-memory bucket for paths
 	// set to the same directory.
@@ -108,10 +122,14 @@ func (w *responseWriter) AddResponse(
 	}
 	w.lock.Lock()
 	defer w.lock.Unlock()
+	if addResponseOptions.clean {
+		w.cleanOutDirPaths[absPluginOut] = struct{}{}
+	}
 	return w.addResponse(
 		ctx,
 		response,
 		absPluginOut,
+		normalpath.Unnormalize(pluginOut),
 		w.createOutDirIfNotExists,
 	)
 }
@@ -130,16 +148,38 @@ func (w *responseWriter) Close() error {
 			return err
 		}
 	}
-	// Re-initialize the cached values to be safe.
+	w.reset()
+	return nil
+}
+
+func (w *responseWriter) Diff(runner command.Runner, writer io.Writer) (bool, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var diffPresent bool
+	for _, diffFunc := range w.differs {
+		outDiffPresent, err := diffFunc(runner, writer)
+		if err != nil {
+			return false, err
+		}
+		diffPresent = diffPresent || outDiffPresent
+	}
+	w.reset()
+	return diffPresent, nil
+}
+
+// reset re-initializes the cached values to be safe.
+func (w *responseWriter) reset() {
 	w.readWriteBuckets = make(map[string]storage.ReadWriteBucket)
+	w.cleanOutDirPaths = make(map[string]struct{})
 	w.closers = nil
-	return nil
+	w.differs = nil
 }
 
 func (w *responseWriter) addResponse(
 	ctx context.Context,
 	response *pluginpb.CodeGeneratorResponse,
 	pluginOut string,
+	displayPluginOut string,
 	createOutDirIfNotExists bool,
 ) error {
 	switch filepath.Ext(pluginOut) {
@@ -148,6 +188,7 @@ func (w *responseWriter) addResponse(
 			ctx,
 			response,
 			pluginOut,
+			displayPluginOut,
 			true,
 			createOutDirIfNotExists,
 		)
@@ -156,6 +197,7 @@ func (w *responseWriter) addResponse(
 			ctx,
 			response,
 			pluginOut,
+			displayPluginOut,
 			false,
 			createOutDirIfNotExists,
 		)
@@ -164,6 +206,7 @@ func (w *responseWriter) addResponse(
 			ctx,
 			response,
 			pluginOut,
+			displayPluginOut,
 			createOutDirIfNotExists,
 		)
 	}
@@ -173,6 +216,7 @@ func (w *responseWriter) writeZip(
 	ctx context.Context,
 	response *pluginpb.CodeGeneratorResponse,
 	outFilePath string,
+	displayOutFilePath string,
 	includeManifest bool,
 	createOutDirIfNotExists bool,
 ) (retErr error) {
@@ -193,16 +237,11 @@ func (w *responseWriter) writeZip(
 	// OK to use os.Stat instead of os.Lstat here.
 	fileInfo, err := os.Stat(outDirPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			if createOutDirIfNotExists {
-				if err := os.MkdirAll(outDirPath, 0755); err != nil {
-					return err
-				}
-			} else {
-				return err
-			}
+		// A missing directory is created when the responses are flushed,
+		// so that nothing is written when the responses are diffed.
+		if !os.IsNotExist(err) || !createOutDirIfNotExists {
+			return err
 		}
-		return err
 	} else if !fileInfo.IsDir() {
 		return fmt.Errorf("not a directory: %s", outDirPath)
 	}
@@ -226,6 +265,11 @@ func (w *responseWriter) writeZip(
 	w.closers = append(w.closers, func() (retErr error) {
 		// We're done writing all of the content into this
 		// readWriteBucket, so we zip it when we flush.
+		if createOutDirIfNotExists {
+			if err := os.MkdirAll(outDirPath, 0755); err != nil {
+				return err
+			}
+		}
 		file, err := os.Create(outFilePath)
 		if err != nil {
 			return err
@@ -236,6 +280,9 @@ func (w *responseWriter) writeZip(
 		// protoc does not compress.
 		return storagearchive.Zip(ctx, readWriteBucket, file, false)
 	})
+	w.differs = append(w.differs, func(runner command.Runner, writer io.Writer) (bool, error) {
+		return diffZip(ctx, runner, writer, readWriteBucket, outFilePath, displayOutFilePath)
+	})
 	return nil
 }
 
@@ -243,6 +290,7 @@ func (w *responseWriter) writeDirectory(
 	ctx context.Context,
 	response *pluginpb.CodeGeneratorResponse,
 	outDirPath string,
+	displayOutDirPath string,
 	createOutDirIfNotExists bool,
 ) error {
 	if readWriteBucket, ok := w.readWriteBuckets[outDirPath]; ok {
@@ -284,11 +332,20 @@ func (w *responseWriter) writeDirectory(
 		if err != nil {
 			return err
 		}
+		// This is evaluated when the responses are flushed, as a later
+		// response for the same outDirPath may have requested cleaning.
+		if _, ok := w.cleanOutDirPaths[outDirPath]; ok {
+			return copyAndClean(ctx, readWriteBucket, osReadWriteBucket, outDirPath)
+		}
 		if _, err := storage.Copy(ctx, readWriteBucket, osReadWriteBucket); err != nil {
 			return err
 		}
 		return nil
 	})
+	w.differs = append(w.differs, func(runner command.Runner, writer io.Writer) (bool, error) {
+		_, clean := w.cleanOutDirPaths[outDirPath]
+		return w.diffDirectory(ctx, runner, writer, readWriteBucket, outDirPath, displayOutDirPath, clean)
+	})
 	return nil
 }
 
@@ -299,3 +356,11 @@ type responseWriterOptions struct {
 func newResponseWriterOptions() *responseWriterOptions {
 	return &responseWriterOptions{}
 }
+
+type addResponseOptions struct {
+	clean bool
+}
+
+func newAddResponseOptions() *addResponseOptions {
+	return &addResponseOptions{}
+}
@@ -0,0 +1,202 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appprotoos
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestResponseWriterClean(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	handWrittenFilePath := filepath.Join(outDirPath, "a", "hand_written.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(handWrittenFilePath), 0755))
+	require.NoError(t, os.WriteFile(handWrittenFilePath, []byte("hand written"), 0600))
+
+	testWriteResponse(t, outDirPath, true, "a/a.txt", "b/b.txt")
+	assert.FileExists(t, filepath.Join(outDirPath, "a", "a.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, "b", "b.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, ManifestFilePath))
+
+	// b/b.txt is no longer generated, so it and its directory are deleted.
+	testWriteResponse(t, outDirPath, true, "a/a.txt")
+	assert.FileExists(t, filepath.Join(outDirPath, "a", "a.txt"))
+	assert.NoFileExists(t, filepath.Join(outDirPath, "b", "b.txt"))
+	assert.NoDirExists(t, filepath.Join(outDirPath, "b"))
+	assert.FileExists(t, handWrittenFilePath)
+
+	// Without clean, nothing is deleted.
+	testWriteResponse(t, outDirPath, false, "c/c.txt")
+	assert.FileExists(t, filepath.Join(outDirPath, "a", "a.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, "c", "c.txt"))
+
+	// c/c.txt was generated without clean, so it is not tracked by the manifest.
+	testWriteResponse(t, outDirPath, true, "d/d.txt")
+	assert.NoFileExists(t, filepath.Join(outDirPath, "a", "a.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, "c", "c.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, "d", "d.txt"))
+	assert.FileExists(t, handWrittenFilePath)
+}
+
+func TestResponseWriterDiff(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	testWriteResponse(t, outDirPath, true, "a/a.txt", "b/b.txt")
+
+	diffPresent, diff := testDiffResponse(t, outDirPath, true, "a/a.txt", "b/b.txt")
+	assert.False(t, diffPresent)
+	assert.Empty(t, diff)
+
+	require.NoError(t, os.WriteFile(filepath.Join(outDirPath, "a", "a.txt"), []byte("modified\n"), 0600))
+	diffPresent, diff = testDiffResponse(t, outDirPath, true, "a/a.txt", "c/c.txt")
+	assert.True(t, diffPresent)
+	assert.Contains(t, diff, "-modified")
+	assert.Contains(t, diff, "+a/a.txt")
+	assert.Contains(t, diff, "+c/c.txt")
+	// b/b.txt would be deleted.
+	assert.Contains(t, diff, "-b/b.txt")
+	// Nothing is written.
+	data, err := os.ReadFile(filepath.Join(outDirPath, "a", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "modified\n", string(data))
+	assert.NoFileExists(t, filepath.Join(outDirPath, "c", "c.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, "b", "b.txt"))
+
+	zipFilePath := filepath.Join(outDirPath, "out.zip")
+	testWriteResponse(t, zipFilePath, false, "a/a.txt")
+	diffPresent, diff = testDiffResponse(t, zipFilePath, false, "a/a.txt")
+	assert.False(t, diffPresent)
+	assert.Empty(t, diff)
+	diffPresent, diff = testDiffResponse(t, zipFilePath, false, "b/b.txt")
+	assert.True(t, diffPresent)
+	assert.Contains(t, diff, "-a/a.txt")
+	assert.Contains(t, diff, "+b/b.txt")
+}
+
+func TestResponseWriterDiffEmptyFile(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	response := &pluginpb.CodeGeneratorResponse{
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{
+				Name:    proto.String("a/a.txt"),
+				Content: proto.String(""),
+			},
+		},
+	}
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), response, outDirPath))
+	buffer := bytes.NewBuffer(nil)
+	diffPresent, err := responseWriter.Diff(command.NewRunner(), buffer)
+	require.NoError(t, err)
+	assert.True(t, diffPresent)
+	assert.Contains(t, buffer.String(), filepath.Join(outDirPath, "a", "a.txt"))
+
+	responseWriter = NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), response, outDirPath))
+	require.NoError(t, responseWriter.Close())
+
+	// Once the empty file exists, there is no diff.
+	responseWriter = NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), response, outDirPath))
+	buffer.Reset()
+	diffPresent, err = responseWriter.Diff(command.NewRunner(), buffer)
+	require.NoError(t, err)
+	assert.False(t, diffPresent)
+	assert.Empty(t, buffer.String())
+}
+
+func TestResponseWriterDiffCreateOutDir(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	for _, outPath := range []string{
+		filepath.Join(tempDirPath, "dir", "out"),
+		filepath.Join(tempDirPath, "zip", "out.zip"),
+	} {
+		response := &pluginpb.CodeGeneratorResponse{
+			File: []*pluginpb.CodeGeneratorResponse_File{
+				{
+					Name:    proto.String("a/a.txt"),
+					Content: proto.String("a/a.txt\n"),
+				},
+			},
+		}
+		responseWriter := NewResponseWriter(
+			zap.NewNop(),
+			storageos.NewProvider(),
+			ResponseWriterWithCreateOutDirIfNotExists(),
+		)
+		require.NoError(t, responseWriter.AddResponse(context.Background(), response, outPath))
+		diffPresent, err := responseWriter.Diff(command.NewRunner(), bytes.NewBuffer(nil))
+		require.NoError(t, err)
+		assert.True(t, diffPresent)
+		// Diffing does not create the directories.
+		assert.NoDirExists(t, filepath.Dir(outPath))
+
+		responseWriter = NewResponseWriter(
+			zap.NewNop(),
+			storageos.NewProvider(),
+			ResponseWriterWithCreateOutDirIfNotExists(),
+		)
+		require.NoError(t, responseWriter.AddResponse(context.Background(), response, outPath))
+		require.NoError(t, responseWriter.Close())
+		assert.DirExists(t, filepath.Dir(outPath))
+	}
+}
+
+func testWriteResponse(t *testing.T, outDirPath string, clean bool, fileNames ...string) {
+	responseWriter := testNewResponseWriter(t, outDirPath, clean, fileNames...)
+	require.NoError(t, responseWriter.Close())
+}
+
+func testDiffResponse(t *testing.T, outDirPath string, clean bool, fileNames ...string) (bool, string) {
+	responseWriter := testNewResponseWriter(t, outDirPath, clean, fileNames...)
+	buffer := bytes.NewBuffer(nil)
+	diffPresent, err := responseWriter.Diff(command.NewRunner(), buffer)
+	require.NoError(t, err)
+	return diffPresent, buffer.String()
+}
+
+func testNewResponseWriter(t *testing.T, outDirPath string, clean bool, fileNames ...string) ResponseWriter {
+	response := &pluginpb.CodeGeneratorResponse{}
+	for _, fileName := range fileNames {
+		response.File = append(
+			response.File,
+			&pluginpb.CodeGeneratorResponse_File{
+				Name:    proto.String(fileName),
+				Content: proto.String(fileName + "\n"),
+			},
+		)
+	}
+	var options []AddResponseOption
+	if clean {
+		options = append(options, AddResponseWithClean())
+	}
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), response, outDirPath, options...))
+	return responseWriter
+}
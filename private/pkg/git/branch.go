@@ -35,6 +35,10 @@ func (r branch) checkout() string {
 	return ""
 }
 
+func (r branch) mergeBase() string {
+	return ""
+}
+
 // Used for logging
 func (r *branch) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + r.cloneBranch() + `"`), nil
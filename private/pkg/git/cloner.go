@@ -19,6 +19,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -39,6 +41,15 @@ import (
 // will fail to fetch so we need to pick something.
 const bufCloneOrigin = "bufCloneOrigin"
 
+const (
+	// mergeBaseHeadRef is the local ref that the clone target is fetched to when
+	// a merge base is requested.
+	mergeBaseHeadRef = "refs/buf/merge-base-head"
+	// mergeBaseOtherRef is the local ref that the other side of the merge base is
+	// fetched to when a merge base is requested.
+	mergeBaseOtherRef = "refs/buf/merge-base-other"
+)
+
 type cloner struct {
 	logger            *zap.Logger
 	storageosProvider storageos.Provider
@@ -144,14 +155,38 @@ func (c *cloner) CloneToBucket(
 		gitConfigAuthArgs = append(gitConfigAuthArgs, extraArgs...)
 	}
 	fetchRef, worktreeRef, checkoutRef := getRefspecsForName(options.Name)
-	fetchArgs := append(
-		gitConfigAuthArgs,
-		"--git-dir="+bareDir.AbsPath(),
-		"fetch",
-		"--depth", depthArg,
-		bufCloneOrigin,
-		fetchRef,
-	)
+	fetchRefs := []string{fetchRef}
+	var mergeBase string
+	if options.Name != nil {
+		mergeBase = options.Name.mergeBase()
+	}
+	if mergeBase != "" {
+		// Fetch both sides of the merge base into local refs so that the
+		// merge base can be computed after fetching.
+		fetchRefs = []string{
+			"+" + fetchRef + ":" + mergeBaseHeadRef,
+			"+" + mergeBase + ":" + mergeBaseOtherRef,
+		}
+	}
+	getFetchArgs := func(historyArgs ...string) []string {
+		fetchArgs := append(
+			[]string{},
+			gitConfigAuthArgs...,
+		)
+		fetchArgs = append(
+			fetchArgs,
+			"--git-dir="+bareDir.AbsPath(),
+			"fetch",
+		)
+		fetchArgs = append(fetchArgs, historyArgs...)
+		if len(options.SparseCheckoutDirPaths) != 0 {
+			// Only fetch the file contents that are checked out. This is
+			// ignored with a warning if the remote does not support it.
+			fetchArgs = append(fetchArgs, "--filter=blob:none")
+		}
+		fetchArgs = append(fetchArgs, bufCloneOrigin)
+		return append(fetchArgs, fetchRefs...)
+	}
 
 	if strings.HasPrefix(url, "ssh://") {
 		envContainer, err = c.getEnvContainerWithGitSSHCommand(envContainer)
@@ -163,19 +198,33 @@ func (c *cloner) CloneToBucket(
 	if err := c.runner.Run(
 		ctx,
 		"git",
-		command.RunWithArgs(fetchArgs...),
+		command.RunWithArgs(getFetchArgs("--depth", depthArg)...),
 		command.RunWithEnv(app.EnvironMap(envContainer)),
 		command.RunWithStderr(buffer),
 	); err != nil {
 		return newGitCommandError(err, buffer, bareDir)
 	}
 
+	if mergeBase != "" {
+		worktreeRef, err = c.getMergeBase(ctx, envContainer, bareDir, getFetchArgs("--unshallow"), mergeBase)
+		if err != nil {
+			return err
+		}
+	}
+
 	buffer.Reset()
 	args := append(
 		gitConfigAuthArgs,
 		"--git-dir="+bareDir.AbsPath(),
 		"worktree",
 		"add",
+	)
+	if len(options.SparseCheckoutDirPaths) != 0 {
+		// The files are checked out after the sparse checkout is configured.
+		args = append(args, "--no-checkout")
+	}
+	args = append(
+		args,
 		worktreeDir.AbsPath(),
 		worktreeRef,
 	)
@@ -189,6 +238,35 @@ func (c *cloner) CloneToBucket(
 		return newGitCommandError(err, buffer, worktreeDir)
 	}
 
+	if len(options.SparseCheckoutDirPaths) != 0 {
+		// In cone mode, the files directly within the parent directories of the
+		// directories are also checked out, which includes any configuration files
+		// in the parent directories.
+		//
+		// The index is empty after adding the worktree with --no-checkout, so
+		// the reset populates the index and checks out the matching files.
+		for _, sparseArgs := range [][]string{
+			append([]string{"sparse-checkout", "set", "--cone"}, options.SparseCheckoutDirPaths...),
+			{"reset", "--quiet", "--hard"},
+		} {
+			buffer.Reset()
+			args := append(
+				gitConfigAuthArgs,
+				sparseArgs...,
+			)
+			if err := c.runner.Run(
+				ctx,
+				"git",
+				command.RunWithArgs(args...),
+				command.RunWithEnv(app.EnvironMap(envContainer)),
+				command.RunWithStderr(buffer),
+				command.RunWithDir(worktreeDir.AbsPath()),
+			); err != nil {
+				return newGitCommandError(err, buffer, worktreeDir)
+			}
+		}
+	}
+
 	if checkoutRef != "" {
 		buffer.Reset()
 		args := append(
@@ -248,6 +326,66 @@ func (c *cloner) CloneToBucket(
 	return err
 }
 
+// getMergeBase returns the merge base of the fetched mergeBaseHeadRef and mergeBaseOtherRef.
+//
+// If there is no merge base within the fetched history and the repository is shallow,
+// the full history is fetched with the unshallowFetchArgs and the merge base is computed again.
+func (c *cloner) getMergeBase(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	bareDir tmp.Dir,
+	unshallowFetchArgs []string,
+	mergeBase string,
+) (string, error) {
+	mergeBaseArgs := []string{
+		"--git-dir=" + bareDir.AbsPath(),
+		"merge-base",
+		mergeBaseHeadRef,
+		mergeBaseOtherRef,
+	}
+	stdout := bytes.NewBuffer(nil)
+	buffer := bytes.NewBuffer(nil)
+	err := c.runner.Run(
+		ctx,
+		"git",
+		command.RunWithArgs(mergeBaseArgs...),
+		command.RunWithEnv(app.EnvironMap(envContainer)),
+		command.RunWithStdout(stdout),
+		command.RunWithStderr(buffer),
+	)
+	if err == nil {
+		return strings.TrimSpace(stdout.String()), nil
+	}
+	if _, statErr := os.Stat(filepath.Join(bareDir.AbsPath(), "shallow")); statErr != nil {
+		// The repository has the full history, so there is no merge base.
+		return "", fmt.Errorf("could not find merge base with %q: %w", mergeBase, newGitCommandError(err, buffer, bareDir))
+	}
+	c.logger.Debug("git_merge_base_unshallow", zap.String("merge_base", mergeBase))
+	buffer.Reset()
+	if err := c.runner.Run(
+		ctx,
+		"git",
+		command.RunWithArgs(unshallowFetchArgs...),
+		command.RunWithEnv(app.EnvironMap(envContainer)),
+		command.RunWithStderr(buffer),
+	); err != nil {
+		return "", newGitCommandError(err, buffer, bareDir)
+	}
+	stdout.Reset()
+	buffer.Reset()
+	if err := c.runner.Run(
+		ctx,
+		"git",
+		command.RunWithArgs(mergeBaseArgs...),
+		command.RunWithEnv(app.EnvironMap(envContainer)),
+		command.RunWithStdout(stdout),
+		command.RunWithStderr(buffer),
+	); err != nil {
+		return "", fmt.Errorf("could not find merge base with %q: %w", mergeBase, newGitCommandError(err, buffer, bareDir))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
 func (c *cloner) getArgsForHTTPSCommand(envContainer app.EnvContainer) ([]string, error) {
 	if c.options.HTTPSUsernameEnvKey == "" || c.options.HTTPSPasswordEnvKey == "" {
 		return nil, nil
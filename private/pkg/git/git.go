@@ -30,6 +30,9 @@ type Name interface {
 	cloneBranch() string
 	// If checkout returns a non-empty string, a checkout of the value will be performed after cloning.
 	checkout() string
+	// If mergeBase returns a non-empty string, the merge base of the value and the clone target
+	// will be checked out after cloning.
+	mergeBase() string
 }
 
 // NewBranchName returns a new Name for the branch.
@@ -52,6 +55,16 @@ func NewRefNameWithBranch(ref string, branch string) Name {
 	return newRefWithBranch(ref, branch)
 }
 
+// NewMergeBaseName returns a new Name for the merge base of the ref and HEAD.
+func NewMergeBaseName(ref string) Name {
+	return newMergeBase(ref, "")
+}
+
+// NewMergeBaseNameWithBranch returns a new Name for the merge base of the ref and the branch.
+func NewMergeBaseNameWithBranch(ref string, branch string) Name {
+	return newMergeBase(ref, branch)
+}
+
 // Cloner clones git repositories to buckets.
 type Cloner interface {
 	// CloneToBucket clones the repository to the bucket.
@@ -73,6 +86,13 @@ type CloneToBucketOptions struct {
 	Mapper            storage.Mapper
 	Name              Name
 	RecurseSubmodules bool
+	// SparseCheckoutDirPaths are the normalized directory paths to check out, relative
+	// to the root of the repository.
+	//
+	// If set, only the directories and the files directly within their parent directories
+	// are checked out, and only their file contents are fetched if the remote supports
+	// partial clones.
+	SparseCheckoutDirPaths []string
 }
 
 // NewCloner returns a new Cloner.
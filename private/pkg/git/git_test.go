@@ -36,8 +36,7 @@ import (
 func TestGitCloner(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
-	container, err := app.NewContainerForOS()
-	require.NoError(t, err)
+	container := app.NewContainer(app.EnvironMap(newTestEnvContainer(t)), nil, nil, nil)
 	runner := command.NewRunner()
 	originDir, workDir := createGitDirs(ctx, t, container, runner)
 
@@ -170,31 +169,128 @@ func TestGitCloner(t *testing.T) {
 		_, err = readBucket.Stat(ctx, "nonexistent")
 		assert.True(t, storage.IsNotExist(err))
 	})
+
+	t.Run("merge-base", func(t *testing.T) {
+		t.Parallel()
+		// The merge base is not within the fetched depth, so the full history is fetched.
+		readBucket := readBucketForName(ctx, t, runner, workDir, 1, NewMergeBaseName("origin/main"), false)
+
+		content, err := storage.ReadPath(ctx, readBucket, "test.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// commit 1", string(content))
+		_, err = readBucket.Stat(ctx, "nonexistent")
+		assert.True(t, storage.IsNotExist(err))
+	})
+
+	t.Run("merge-base_and_branch", func(t *testing.T) {
+		t.Parallel()
+		readBucket := readBucketForName(ctx, t, runner, workDir, 2, NewMergeBaseNameWithBranch("origin/main", "origin/remote-branch"), false)
+
+		content, err := storage.ReadPath(ctx, readBucket, "test.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// commit 3", string(content))
+		_, err = readBucket.Stat(ctx, "nonexistent")
+		assert.True(t, storage.IsNotExist(err))
+	})
+
+	t.Run("sparse", func(t *testing.T) {
+		t.Parallel()
+		readBucket := readBucketForOptions(
+			ctx,
+			t,
+			runner,
+			workDir,
+			1,
+			CloneToBucketOptions{
+				Mapper:                 storage.MatchPathExt(".proto"),
+				SparseCheckoutDirPaths: []string{"proto/a"},
+			},
+		)
+
+		content, err := storage.ReadPath(ctx, readBucket, "proto/a/a.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// proto/a", string(content))
+		// Files directly within parent directories are checked out.
+		content, err = storage.ReadPath(ctx, readBucket, "proto/b.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// proto", string(content))
+		content, err = storage.ReadPath(ctx, readBucket, "test.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// commit 2", string(content))
+		_, err = readBucket.Stat(ctx, "other/other.proto")
+		assert.True(t, storage.IsNotExist(err))
+	})
+
+	t.Run("sparse_multiple", func(t *testing.T) {
+		t.Parallel()
+		readBucket := readBucketForOptions(
+			ctx,
+			t,
+			runner,
+			workDir,
+			1,
+			CloneToBucketOptions{
+				Mapper:                 storage.MatchPathExt(".proto"),
+				SparseCheckoutDirPaths: []string{"proto/a", "other"},
+			},
+		)
+
+		content, err := storage.ReadPath(ctx, readBucket, "proto/a/a.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// proto/a", string(content))
+		content, err = storage.ReadPath(ctx, readBucket, "other/other.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// other", string(content))
+	})
 }
 
 func readBucketForName(ctx context.Context, t *testing.T, runner command.Runner, path string, depth uint32, name Name, recurseSubmodules bool) storage.ReadBucket {
-	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
-	cloner := NewCloner(zap.NewNop(), storageosProvider, runner, ClonerOptions{})
-	envContainer, err := app.NewEnvContainerForOS()
-	require.NoError(t, err)
-
-	readWriteBucket := storagemem.NewReadWriteBucket()
-	err = cloner.CloneToBucket(
+	return readBucketForOptions(
 		ctx,
-		envContainer,
-		"file://"+filepath.Join(path, ".git"),
+		t,
+		runner,
+		path,
 		depth,
-		readWriteBucket,
 		CloneToBucketOptions{
 			Mapper:            storage.MatchPathExt(".proto"),
 			Name:              name,
 			RecurseSubmodules: recurseSubmodules,
 		},
 	)
+}
+
+func readBucketForOptions(ctx context.Context, t *testing.T, runner command.Runner, path string, depth uint32, options CloneToBucketOptions) storage.ReadBucket {
+	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+	cloner := NewCloner(zap.NewNop(), storageosProvider, runner, ClonerOptions{})
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	err := cloner.CloneToBucket(
+		ctx,
+		newTestEnvContainer(t),
+		"file://"+filepath.Join(path, ".git"),
+		depth,
+		readWriteBucket,
+		options,
+	)
 	require.NoError(t, err)
 	return readWriteBucket
 }
 
+// newTestEnvContainer returns the EnvContainer for the operating system with the
+// file protocol allowed, as the submodule is added and cloned from a local path.
+// git only allows the file protocol for submodules by default before 2.38.1.
+func newTestEnvContainer(t *testing.T) app.EnvContainer {
+	envContainer, err := app.NewEnvContainerForOS()
+	require.NoError(t, err)
+	return app.NewEnvContainerWithOverrides(
+		envContainer,
+		map[string]string{
+			"GIT_CONFIG_COUNT":   "1",
+			"GIT_CONFIG_KEY_0":   "protocol.file.allow",
+			"GIT_CONFIG_VALUE_0": "always",
+		},
+	)
+}
+
 func createGitDirs(
 	ctx context.Context,
 	t *testing.T,
@@ -233,6 +329,12 @@ func createGitDirs(
 	runCommand(ctx, t, container, runner, "git", "-C", workPath, "config", "user.name", "Buf go tests")
 	runCommand(ctx, t, container, runner, "git", "-C", workPath, "checkout", "-b", "local-branch")
 	require.NoError(t, os.WriteFile(filepath.Join(workPath, "test.proto"), []byte("// commit 2"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(workPath, "proto", "a"), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join(workPath, "proto", "a", "a.proto"), []byte("// proto/a"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(workPath, "proto", "b.proto"), []byte("// proto"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(workPath, "other"), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join(workPath, "other", "other.proto"), []byte("// other"), 0600))
+	runCommand(ctx, t, container, runner, "git", "-C", workPath, "add", ".")
 	runCommand(ctx, t, container, runner, "git", "-C", workPath, "commit", "-a", "-m", "commit 2")
 
 	require.NoError(t, os.WriteFile(filepath.Join(originPath, "test.proto"), []byte("// commit 3"), 0600))
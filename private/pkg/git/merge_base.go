@@ -0,0 +1,61 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import "encoding/json"
+
+type mergeBase struct {
+	ref    string
+	branch string
+}
+
+func newMergeBase(ref string, branch string) *mergeBase {
+	return &mergeBase{
+		ref:    ref,
+		branch: branch,
+	}
+}
+
+func (r *mergeBase) cloneBranch() string {
+	if r == nil {
+		return ""
+	}
+	return r.branch
+}
+
+func (r *mergeBase) checkout() string {
+	return ""
+}
+
+func (r *mergeBase) mergeBase() string {
+	if r == nil {
+		return ""
+	}
+	return r.ref
+}
+
+func (r *mergeBase) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		MergeBase string
+		Branch    string
+	}{
+		MergeBase: r.mergeBase(),
+		Branch:    r.cloneBranch(),
+	})
+}
+
+func (r *mergeBase) String() string {
+	return r.mergeBase()
+}
@@ -0,0 +1,520 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/httpauth"
+	"go.uber.org/multierr"
+)
+
+// emptyConfig is the content of the config of all artifacts.
+var emptyConfig = []byte("{}")
+
+type client struct {
+	httpClient    *http.Client
+	authenticator httpauth.Authenticator
+
+	// registry and repository to bearer token
+	tokens    map[string]string
+	tokenLock sync.RWMutex
+}
+
+func newClient(httpClient *http.Client, authenticator httpauth.Authenticator) *client {
+	return &client{
+		httpClient:    httpClient,
+		authenticator: authenticator,
+		tokens:        make(map[string]string),
+	}
+}
+
+func (c *client) Pull(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	reference Reference,
+) (*Artifact, error) {
+	data, err := c.get(
+		ctx,
+		envContainer,
+		reference,
+		"manifests/"+getManifestReference(reference),
+		MediaTypeImageManifest,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if digest := reference.Digest(); digest != "" {
+		if err := verifyDigest(data, digest); err != nil {
+			return nil, fmt.Errorf("manifest of %s: %w", reference.String(), err)
+		}
+	}
+	var manifest externalManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("could not parse manifest of %s: %w", reference.String(), err)
+	}
+	if manifest.MediaType != "" && manifest.MediaType != MediaTypeImageManifest {
+		return nil, fmt.Errorf("manifest of %s has media type %q but must be %q", reference.String(), manifest.MediaType, MediaTypeImageManifest)
+	}
+	if len(manifest.Layers) != 1 {
+		return nil, fmt.Errorf("artifact %s has %d layers but must have exactly one", reference.String(), len(manifest.Layers))
+	}
+	layer := manifest.Layers[0]
+	content, err := c.get(ctx, envContainer, reference, "blobs/"+layer.Digest, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyDigest(content, layer.Digest); err != nil {
+		return nil, fmt.Errorf("layer of %s: %w", reference.String(), err)
+	}
+	return &Artifact{
+		ConfigMediaType: manifest.Config.MediaType,
+		LayerMediaType:  layer.MediaType,
+		Content:         content,
+	}, nil
+}
+
+func (c *client) Push(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	reference Reference,
+	artifact *Artifact,
+) error {
+	if reference.Tag() == "" {
+		return fmt.Errorf("cannot push to %s: the reference must have a tag", reference.String())
+	}
+	config, err := c.pushBlob(ctx, envContainer, reference, artifact.ConfigMediaType, emptyConfig)
+	if err != nil {
+		return err
+	}
+	layer, err := c.pushBlob(ctx, envContainer, reference, artifact.LayerMediaType, artifact.Content)
+	if err != nil {
+		return err
+	}
+	manifestData, err := json.Marshal(
+		&externalManifest{
+			SchemaVersion: 2,
+			MediaType:     MediaTypeImageManifest,
+			Config:        config,
+			Layers:        []externalDescriptor{layer},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	response, err := c.do(
+		ctx,
+		envContainer,
+		reference,
+		http.MethodPut,
+		c.getURL(reference, "manifests/"+reference.Tag()),
+		MediaTypeImageManifest,
+		manifestData,
+	)
+	if err != nil {
+		return err
+	}
+	return closeResponse(response, http.StatusCreated)
+}
+
+// pushBlob pushes the blob if it does not already exist in the repository,
+// and returns its descriptor.
+func (c *client) pushBlob(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	reference Reference,
+	mediaType string,
+	data []byte,
+) (externalDescriptor, error) {
+	descriptor := externalDescriptor{
+		MediaType: mediaType,
+		Digest:    getDigest(data),
+		Size:      int64(len(data)),
+	}
+	response, err := c.do(
+		ctx,
+		envContainer,
+		reference,
+		http.MethodHead,
+		c.getURL(reference, "blobs/"+descriptor.Digest),
+		"",
+		nil,
+	)
+	if err != nil {
+		return externalDescriptor{}, err
+	}
+	if err := response.Body.Close(); err != nil {
+		return externalDescriptor{}, err
+	}
+	if response.StatusCode == http.StatusOK {
+		return descriptor, nil
+	}
+	// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#post-then-put
+	response, err = c.do(
+		ctx,
+		envContainer,
+		reference,
+		http.MethodPost,
+		c.getURL(reference, "blobs/uploads/"),
+		"",
+		nil,
+	)
+	if err != nil {
+		return externalDescriptor{}, err
+	}
+	location := response.Header.Get("Location")
+	if err := closeResponse(response, http.StatusAccepted); err != nil {
+		return externalDescriptor{}, err
+	}
+	if location == "" {
+		return externalDescriptor{}, fmt.Errorf("registry %s did not return a location for the upload", reference.Registry())
+	}
+	uploadURL, err := response.Request.URL.Parse(location)
+	if err != nil {
+		return externalDescriptor{}, err
+	}
+	query := uploadURL.Query()
+	query.Set("digest", descriptor.Digest)
+	uploadURL.RawQuery = query.Encode()
+	response, err = c.do(
+		ctx,
+		envContainer,
+		reference,
+		http.MethodPut,
+		uploadURL.String(),
+		"application/octet-stream",
+		data,
+	)
+	if err != nil {
+		return externalDescriptor{}, err
+	}
+	if err := closeResponse(response, http.StatusCreated); err != nil {
+		return externalDescriptor{}, err
+	}
+	return descriptor, nil
+}
+
+// get returns the body of a successful GET request for the path within the repository.
+func (c *client) get(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	reference Reference,
+	path string,
+	accept string,
+) (_ []byte, retErr error) {
+	response, err := c.do(
+		ctx,
+		envContainer,
+		reference,
+		http.MethodGet,
+		c.getURL(reference, path),
+		"",
+		nil,
+		withAccept(accept),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, response.Body.Close())
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, newResponseError(response)
+	}
+	return io.ReadAll(response.Body)
+}
+
+// do sends the request, authenticating with the registry if it responds with a
+// bearer token challenge.
+func (c *client) do(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	reference Reference,
+	method string,
+	requestURL string,
+	contentType string,
+	body []byte,
+	options ...requestOption,
+) (*http.Response, error) {
+	tokenKey := reference.Registry() + "/" + reference.Repository()
+	newRequest := func() (*http.Request, error) {
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+		request, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			request.Header.Set("Content-Type", contentType)
+		}
+		for _, option := range options {
+			option(request)
+		}
+		c.tokenLock.RLock()
+		token, ok := c.tokens[tokenKey]
+		c.tokenLock.RUnlock()
+		if ok {
+			request.Header.Set("Authorization", "Bearer "+token)
+		} else if _, err := c.authenticator.SetAuth(envContainer, request); err != nil {
+			return nil, err
+		}
+		return request, nil
+	}
+	request, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusUnauthorized {
+		return response, nil
+	}
+	realm, params, ok := parseBearerChallenge(response.Header.Get("WWW-Authenticate"))
+	if !ok {
+		return response, nil
+	}
+	if err := response.Body.Close(); err != nil {
+		return nil, err
+	}
+	token, err := c.getToken(ctx, envContainer, realm, params)
+	if err != nil {
+		return nil, err
+	}
+	c.tokenLock.Lock()
+	c.tokens[tokenKey] = token
+	c.tokenLock.Unlock()
+	request, err = newRequest()
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient.Do(request)
+}
+
+// getToken gets a bearer token from the token service of a registry.
+//
+// https://docs.docker.com/registry/spec/auth/token/
+func (c *client) getToken(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	realm string,
+	params map[string]string,
+) (_ string, retErr error) {
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid bearer token realm %q: %w", realm, err)
+	}
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if value, ok := params[key]; ok {
+			query.Set(key, value)
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.authenticator.SetAuth(envContainer, request); err != nil {
+		return "", err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, response.Body.Close())
+	}()
+	if response.StatusCode != http.StatusOK {
+		return "", newResponseError(response)
+	}
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("could not parse bearer token response: %w", err)
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+	if tokenResponse.AccessToken != "" {
+		return tokenResponse.AccessToken, nil
+	}
+	return "", errors.New("bearer token response did not contain a token")
+}
+
+func (c *client) getURL(reference Reference, path string) string {
+	scheme := "https"
+	if isLoopbackRegistry(reference.Registry()) {
+		scheme = "http"
+	}
+	return scheme + "://" + reference.Registry() + "/v2/" + reference.Repository() + "/" + path
+}
+
+type externalManifest struct {
+	SchemaVersion int                  `json:"schemaVersion"`
+	MediaType     string               `json:"mediaType,omitempty"`
+	Config        externalDescriptor   `json:"config"`
+	Layers        []externalDescriptor `json:"layers"`
+}
+
+type externalDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type requestOption func(*http.Request)
+
+func withAccept(accept string) requestOption {
+	return func(request *http.Request) {
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+	}
+}
+
+// parseBearerChallenge parses a WWW-Authenticate header of the form
+// Bearer realm="...",service="...",scope="...".
+func parseBearerChallenge(header string) (string, map[string]string, bool) {
+	const prefix = "bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	params := make(map[string]string)
+	remainder := header[len(prefix):]
+	for remainder != "" {
+		equalsIndex := strings.IndexByte(remainder, '=')
+		if equalsIndex < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(remainder[:equalsIndex]))
+		remainder = remainder[equalsIndex+1:]
+		var value string
+		if strings.HasPrefix(remainder, `"`) {
+			endIndex := strings.IndexByte(remainder[1:], '"')
+			if endIndex < 0 {
+				return "", nil, false
+			}
+			value = remainder[1 : endIndex+1]
+			remainder = remainder[endIndex+2:]
+		} else {
+			commaIndex := strings.IndexByte(remainder, ',')
+			if commaIndex < 0 {
+				commaIndex = len(remainder)
+			}
+			value = remainder[:commaIndex]
+			remainder = remainder[commaIndex:]
+		}
+		params[key] = value
+		remainder = strings.TrimLeft(remainder, ", ")
+	}
+	realm, ok := params["realm"]
+	if !ok || realm == "" {
+		return "", nil, false
+	}
+	return realm, params, true
+}
+
+// isLoopbackRegistry returns true if the host of the registry is a loopback host.
+func isLoopbackRegistry(registry string) bool {
+	host := registry
+	if splitHost, _, err := net.SplitHostPort(registry); err == nil {
+		host = splitHost
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func getManifestReference(reference Reference) string {
+	if digest := reference.Digest(); digest != "" {
+		return digest
+	}
+	return reference.Tag()
+}
+
+func getDigest(data []byte) string {
+	digest := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(digest[:])
+}
+
+func verifyDigest(data []byte, expectedDigest string) error {
+	if !strings.HasPrefix(expectedDigest, "sha256:") {
+		return fmt.Errorf("unsupported digest %q: only sha256 digests are supported", expectedDigest)
+	}
+	if actualDigest := getDigest(data); actualDigest != expectedDigest {
+		return fmt.Errorf("digest was %s but expected %s", actualDigest, expectedDigest)
+	}
+	return nil
+}
+
+// closeResponse closes the body of the response, and returns an error if
+// the status code of the response is not the expected status code.
+func closeResponse(response *http.Response, expectedStatusCode int) (retErr error) {
+	defer func() {
+		retErr = multierr.Append(retErr, response.Body.Close())
+	}()
+	if response.StatusCode != expectedStatusCode {
+		return newResponseError(response)
+	}
+	return nil
+}
+
+// newResponseError returns an error for an unexpected response, including
+// the error message returned by the registry if any.
+func newResponseError(response *http.Response) error {
+	err := fmt.Errorf(
+		"%s %s: got HTTP status code %d",
+		response.Request.Method,
+		response.Request.URL.Redacted(),
+		response.StatusCode,
+	)
+	var errorResponse struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	// The body is limited as it is only used for the error message.
+	if json.NewDecoder(io.LimitReader(response.Body, 1<<16)).Decode(&errorResponse) == nil && len(errorResponse.Errors) > 0 {
+		messages := make([]string, 0, len(errorResponse.Errors))
+		for _, responseError := range errorResponse.Errors {
+			messages = append(messages, responseError.Code+": "+responseError.Message)
+		}
+		return fmt.Errorf("%w: %s", err, strings.Join(messages, ", "))
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oci implements a minimal client for the OCI distribution API that
+// pushes and pulls artifacts consisting of a single layer.
+//
+// See https://github.com/opencontainers/distribution-spec.
+package oci
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/httpauth"
+)
+
+const (
+	// MediaTypeImageManifest is the media type of OCI image manifests.
+	//
+	// Artifacts are stored as OCI image manifests, with the media type of the
+	// config identifying the type of artifact.
+	MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	// MediaTypeImageLayerTar is the media type of OCI tar layers.
+	MediaTypeImageLayerTar = "application/vnd.oci.image.layer.v1.tar"
+	// MediaTypeImageLayerTarGzip is the media type of OCI gzipped tar layers.
+	MediaTypeImageLayerTarGzip = "application/vnd.oci.image.layer.v1.tar+gzip"
+	// MediaTypeImageLayerTarZstd is the media type of OCI zstd-compressed tar layers.
+	MediaTypeImageLayerTarZstd = "application/vnd.oci.image.layer.v1.tar+zstd"
+)
+
+// Reference is a reference to an artifact within a registry.
+type Reference interface {
+	// Registry is the host of the registry, including the port if any.
+	//
+	// Example: "registry.local:5000"
+	Registry() string
+	// Repository is the name of the repository within the registry.
+	//
+	// Example: "protos/payments"
+	Repository() string
+	// Tag is the tag of the artifact.
+	//
+	// Exactly one of Tag and Digest is set.
+	Tag() string
+	// Digest is the digest of the manifest of the artifact.
+	//
+	// Exactly one of Tag and Digest is set.
+	// Example: "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	Digest() string
+	// String returns the reference in the form registry/repository:tag
+	// or registry/repository@digest.
+	String() string
+}
+
+// ParseReference parses the reference.
+//
+// The value must be in the form registry/repository[:tag] or registry/repository@digest,
+// without a scheme. If neither a tag nor a digest is given, the tag defaults to "latest".
+func ParseReference(value string) (Reference, error) {
+	reference, err := parseReference(value)
+	if err != nil {
+		return nil, err
+	}
+	return reference, nil
+}
+
+// Artifact is an artifact consisting of a single layer.
+type Artifact struct {
+	// ConfigMediaType is the media type of the config of the artifact.
+	//
+	// This identifies the type of the artifact. The content of the config is
+	// always the empty JSON object.
+	ConfigMediaType string
+	// LayerMediaType is the media type of the layer of the artifact.
+	LayerMediaType string
+	// Content is the content of the layer of the artifact.
+	Content []byte
+}
+
+// Client is a client for an OCI registry.
+type Client interface {
+	// Pull pulls the artifact for the reference.
+	//
+	// Returns an error if the artifact does not have exactly one layer, or if
+	// the content of the layer does not match its digest.
+	Pull(
+		ctx context.Context,
+		envContainer app.EnvContainer,
+		reference Reference,
+	) (*Artifact, error)
+	// Push pushes the artifact and tags it with the tag of the reference.
+	//
+	// Returns an error if the reference does not have a tag.
+	Push(
+		ctx context.Context,
+		envContainer app.EnvContainer,
+		reference Reference,
+		artifact *Artifact,
+	) error
+}
+
+// NewClient returns a new Client.
+//
+// Registries on loopback hosts such as localhost:5000 are accessed over http,
+// and all other registries are accessed over https. If the registry requires
+// authentication, the Authenticator is used to authenticate with the registry,
+// or with the token service of the registry if it uses bearer tokens.
+func NewClient(httpClient *http.Client, authenticator httpauth.Authenticator) Client {
+	return newClient(httpClient, authenticator)
+}
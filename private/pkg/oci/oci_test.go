@@ -0,0 +1,271 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/httpauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "test-token"
+
+func TestParseReference(t *testing.T) {
+	t.Parallel()
+	testParseReference(t, "localhost:5000/foo", "localhost:5000", "foo", "latest", "")
+	testParseReference(t, "registry.local/protos/payments:v3", "registry.local", "protos/payments", "v3", "")
+	testParseReference(
+		t,
+		"registry.local/protos/payments@sha256:"+strings.Repeat("a", 64),
+		"registry.local",
+		"protos/payments",
+		"",
+		"sha256:"+strings.Repeat("a", 64),
+	)
+	testParseReferenceError(t, "")
+	testParseReferenceError(t, "foo")
+	testParseReferenceError(t, "/foo")
+	testParseReferenceError(t, "oci://registry.local/foo")
+	testParseReferenceError(t, "registry.local/Foo")
+	testParseReferenceError(t, "registry.local/foo:")
+	testParseReferenceError(t, "registry.local/foo@sha256:abc")
+}
+
+func TestPushPull(t *testing.T) {
+	t.Parallel()
+	testPushPull(t, false)
+}
+
+func TestPushPullBearerToken(t *testing.T) {
+	t.Parallel()
+	testPushPull(t, true)
+}
+
+func TestPullNotFound(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(newTestRegistry(false))
+	t.Cleanup(server.Close)
+	client := NewClient(server.Client(), httpauth.NewNopAuthenticator())
+	reference := testNewReference(t, server, "foo:v1")
+	_, err := client.Pull(context.Background(), app.NewEnvContainer(nil), reference)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+	assert.Contains(t, err.Error(), "MANIFEST_UNKNOWN")
+}
+
+func TestPullDigestMismatch(t *testing.T) {
+	t.Parallel()
+	registry := newTestRegistry(false)
+	server := httptest.NewServer(registry)
+	t.Cleanup(server.Close)
+	client := NewClient(server.Client(), httpauth.NewNopAuthenticator())
+	ctx := context.Background()
+	envContainer := app.NewEnvContainer(nil)
+	err := client.Push(
+		ctx,
+		envContainer,
+		testNewReference(t, server, "foo:v1"),
+		&Artifact{
+			ConfigMediaType: "application/vnd.test.config.v1+json",
+			LayerMediaType:  MediaTypeImageLayerTarGzip,
+			Content:         []byte("content"),
+		},
+	)
+	require.NoError(t, err)
+	registry.lock.Lock()
+	for digest := range registry.blobs {
+		if digest == testGetDigest([]byte("content")) {
+			registry.blobs[digest] = []byte("modified")
+		}
+	}
+	registry.lock.Unlock()
+	_, err = client.Pull(ctx, envContainer, testNewReference(t, server, "foo:v1"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "digest")
+}
+
+func testPushPull(t *testing.T, requireToken bool) {
+	registry := newTestRegistry(requireToken)
+	server := httptest.NewServer(registry)
+	t.Cleanup(server.Close)
+	client := NewClient(server.Client(), httpauth.NewNopAuthenticator())
+	ctx := context.Background()
+	envContainer := app.NewEnvContainer(nil)
+	artifact := &Artifact{
+		ConfigMediaType: "application/vnd.test.config.v1+json",
+		LayerMediaType:  MediaTypeImageLayerTarGzip,
+		Content:         []byte("content"),
+	}
+	require.NoError(t, client.Push(ctx, envContainer, testNewReference(t, server, "protos/foo:v1"), artifact))
+	// Pushing again does not upload the existing blobs.
+	require.NoError(t, client.Push(ctx, envContainer, testNewReference(t, server, "protos/foo:v2"), artifact))
+	registry.lock.Lock()
+	assert.Equal(t, 2, registry.numUploads)
+	manifestDigest := registry.tags["protos/foo:v1"]
+	registry.lock.Unlock()
+
+	pulledArtifact, err := client.Pull(ctx, envContainer, testNewReference(t, server, "protos/foo:v2"))
+	require.NoError(t, err)
+	assert.Equal(t, artifact, pulledArtifact)
+	pulledArtifact, err = client.Pull(ctx, envContainer, testNewReference(t, server, "protos/foo@"+manifestDigest))
+	require.NoError(t, err)
+	assert.Equal(t, artifact, pulledArtifact)
+
+	err = client.Push(ctx, envContainer, testNewReference(t, server, "protos/foo@"+manifestDigest), artifact)
+	require.Error(t, err)
+}
+
+func testParseReference(
+	t *testing.T,
+	value string,
+	expectedRegistry string,
+	expectedRepository string,
+	expectedTag string,
+	expectedDigest string,
+) {
+	reference, err := ParseReference(value)
+	require.NoError(t, err, value)
+	assert.Equal(t, expectedRegistry, reference.Registry())
+	assert.Equal(t, expectedRepository, reference.Repository())
+	assert.Equal(t, expectedTag, reference.Tag())
+	assert.Equal(t, expectedDigest, reference.Digest())
+}
+
+func testParseReferenceError(t *testing.T, value string) {
+	reference, err := ParseReference(value)
+	assert.Error(t, err, value)
+	assert.Nil(t, reference, value)
+}
+
+func testNewReference(t *testing.T, server *httptest.Server, value string) Reference {
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	reference, err := ParseReference(serverURL.Host + "/" + value)
+	require.NoError(t, err)
+	return reference
+}
+
+func testGetDigest(data []byte) string {
+	digest := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(digest[:])
+}
+
+// testRegistry is a minimal in-memory OCI registry.
+type testRegistry struct {
+	requireToken bool
+
+	lock sync.Mutex
+	// digest to content, for both blobs and manifests
+	blobs map[string][]byte
+	// repository:tag to manifest digest
+	tags       map[string]string
+	numUploads int
+}
+
+func newTestRegistry(requireToken bool) *testRegistry {
+	return &testRegistry{
+		requireToken: requireToken,
+		blobs:        make(map[string][]byte),
+		tags:         make(map[string]string),
+	}
+}
+
+func (r *testRegistry) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path == "/token" {
+		if request.URL.Query().Get("service") != "test" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = io.WriteString(writer, `{"token":"`+testToken+`"}`)
+		return
+	}
+	if r.requireToken && request.Header.Get("Authorization") != "Bearer "+testToken {
+		writer.Header().Set(
+			"WWW-Authenticate",
+			`Bearer realm="http://`+request.Host+`/token",service="test",scope="repository:foo:pull,push"`,
+		)
+		writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(request.URL.Path, "/v2/")
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	switch {
+	case strings.HasSuffix(path, "/blobs/uploads/") && request.Method == http.MethodPost:
+		writer.Header().Set("Location", "/v2/"+path+"upload-id")
+		writer.WriteHeader(http.StatusAccepted)
+	case strings.HasSuffix(path, "/blobs/uploads/upload-id") && request.Method == http.MethodPut:
+		data, err := io.ReadAll(request.Body)
+		if err != nil || testGetDigest(data) != request.URL.Query().Get("digest") {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[testGetDigest(data)] = data
+		r.numUploads++
+		writer.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		data, ok := r.blobs[path[strings.LastIndex(path, "/")+1:]]
+		if !ok {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if request.Method == http.MethodGet {
+			_, _ = writer.Write(data)
+		}
+	case strings.Contains(path, "/manifests/"):
+		index := strings.LastIndex(path, "/manifests/")
+		repository, manifestReference := path[:index], path[index+len("/manifests/"):]
+		switch request.Method {
+		case http.MethodPut:
+			data, err := io.ReadAll(request.Body)
+			if err != nil || request.Header.Get("Content-Type") != MediaTypeImageManifest {
+				writer.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			r.blobs[testGetDigest(data)] = data
+			r.tags[repository+":"+manifestReference] = testGetDigest(data)
+			writer.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			digest := manifestReference
+			if !strings.HasPrefix(digest, "sha256:") {
+				digest = r.tags[repository+":"+manifestReference]
+			}
+			data, ok := r.blobs[digest]
+			if !ok {
+				writer.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(writer, `{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`)
+				return
+			}
+			writer.Header().Set("Content-Type", MediaTypeImageManifest)
+			_, _ = writer.Write(data)
+		default:
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const defaultTag = "latest"
+
+var (
+	// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-manifests
+	repositoryRegexp = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+	tagRegexp        = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+	digestRegexp     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+type reference struct {
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+func parseReference(value string) (*reference, error) {
+	if value == "" {
+		return nil, errors.New("OCI reference is empty")
+	}
+	if strings.Contains(value, "://") {
+		return nil, fmt.Errorf("OCI reference %q must not have a scheme", value)
+	}
+	slashIndex := strings.IndexByte(value, '/')
+	if slashIndex <= 0 {
+		return nil, fmt.Errorf("OCI reference %q must be in the form registry/repository[:tag]", value)
+	}
+	reference := &reference{
+		registry: value[:slashIndex],
+	}
+	remainder := value[slashIndex+1:]
+	if atIndex := strings.IndexByte(remainder, '@'); atIndex >= 0 {
+		reference.digest = remainder[atIndex+1:]
+		remainder = remainder[:atIndex]
+		if !digestRegexp.MatchString(reference.digest) {
+			return nil, fmt.Errorf("OCI reference %q has invalid digest %q: must be a sha256 digest", value, reference.digest)
+		}
+	} else if colonIndex := strings.LastIndexByte(remainder, ':'); colonIndex >= 0 && colonIndex > strings.LastIndexByte(remainder, '/') {
+		reference.tag = remainder[colonIndex+1:]
+		remainder = remainder[:colonIndex]
+		if !tagRegexp.MatchString(reference.tag) {
+			return nil, fmt.Errorf("OCI reference %q has invalid tag %q", value, reference.tag)
+		}
+	} else {
+		reference.tag = defaultTag
+	}
+	if !repositoryRegexp.MatchString(remainder) {
+		return nil, fmt.Errorf("OCI reference %q has invalid repository %q", value, remainder)
+	}
+	reference.repository = remainder
+	return reference, nil
+}
+
+func (r *reference) Registry() string {
+	return r.registry
+}
+
+func (r *reference) Repository() string {
+	return r.repository
+}
+
+func (r *reference) Tag() string {
+	return r.tag
+}
+
+func (r *reference) Digest() string {
+	return r.digest
+}
+
+func (r *reference) String() string {
+	if r.digest != "" {
+		return r.registry + "/" + r.repository + "@" + r.digest
+	}
+	return r.registry + "/" + r.repository + ":" + r.tag
+}
@@ -62,3 +62,27 @@ func (o *optionExtensionDescriptor) PresentExtensionNumbers() []int32 {
 	}
 	return fieldNumbers
 }
+
+func (o *optionExtensionDescriptor) OptionExtensionBytes(fieldNumber int32) ([]byte, error) {
+	if !o.message.ProtoReflect().Descriptor().ExtensionRanges().Has(protowire.Number(fieldNumber)) {
+		return nil, nil
+	}
+	// marshal deterministically so that known extensions and unknown fields
+	// are both present and in a stable order
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(o.message)
+	if err != nil {
+		return nil, err
+	}
+	var value []byte
+	for len(data) > 0 {
+		number, _, n := protowire.ConsumeField(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		if int32(number) == fieldNumber {
+			value = append(value, data[:n]...)
+		}
+		data = data[n:]
+	}
+	return value, nil
+}
@@ -133,6 +133,14 @@ type OptionExtensionDescriptor interface {
 	// PresentExtensionNumbers returns field numbers for all options that
 	// have a set value on this descriptor.
 	PresentExtensionNumbers() []int32
+
+	// OptionExtensionBytes returns the wire format bytes of the options extension
+	// field with the given number, including the tags.
+	//
+	// If the field appears multiple times, for example for repeated options, the
+	// bytes of all occurrences are concatenated in order.
+	// Returns nil if the extension is not set.
+	OptionExtensionBytes(fieldNumber int32) ([]byte, error)
 }
 
 // Location defines source code info location information.
@@ -370,6 +378,10 @@ type Field interface {
 	// Set vs unset matters for packed
 	// See the comments on descriptor.proto
 	Packed() *bool
+	// Deprecated returns true if the deprecated field option is set to true.
+	//
+	// Unset and false are not distinguished.
+	Deprecated() bool
 	// Empty string unless the field is part of an extension
 	Extendee() string
 